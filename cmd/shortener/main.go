@@ -2,6 +2,7 @@ package main
 
 import (
 	"fmt"
+	"os"
 
 	"github.com/pinbrain/urlshortener/internal/app"
 )
@@ -14,6 +15,20 @@ var (
 
 func main() {
 	fmt.Printf("Build version: %s\nBuild date: %s\nBuild commit: %s\n", buildVersion, buildDate, buildCommit)
+	// shortener migrate --from <хранилище> --to <хранилище> - перенос данных между хранилищами
+	if len(os.Args) > 1 && os.Args[1] == app.MigrateCommand {
+		if err := app.RunMigrate(os.Args[2:]); err != nil {
+			panic(err)
+		}
+		return
+	}
+	// shortener convert --from <файл> [--to <файл>] --format <json|binary> - перевод файла хранилища в другой формат
+	if len(os.Args) > 1 && os.Args[1] == app.ConvertCommand {
+		if err := app.RunConvert(os.Args[2:]); err != nil {
+			panic(err)
+		}
+		return
+	}
 	if err := app.Run(); err != nil {
 		panic(err)
 	}
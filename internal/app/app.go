@@ -58,9 +58,24 @@ func Run() error {
 		return err
 	}
 
+	if serverConf.MigrateOnly || serverConf.MigrateDown > 0 {
+		return runSchemaMigrations(ctx, serverConf)
+	}
+
 	urlStore, err := storage.NewURLStorage(storage.URLStorageConfig{
-		StorageFile: serverConf.StorageFile,
-		DSN:         serverConf.DSN,
+		StorageFile:  serverConf.StorageFile,
+		DSN:          serverConf.DSN,
+		AliasPattern: serverConf.AliasPattern,
+		IDGenerator:  serverConf.IDGenerator,
+		IDFormat: storage.IDFormat{
+			Length:        serverConf.IDLength,
+			Alphabet:      serverConf.IDAlphabet,
+			LegacyLengths: serverConf.IDLegacyLengths,
+		},
+		DeletedRetention: serverConf.DeletedRetention,
+		Uniqueness:       serverConf.URLUniqueness,
+		FileSync:         serverConf.FileSync,
+		FileFormat:       serverConf.FileFormat,
 	})
 	if err != nil {
 		return err
@@ -68,7 +83,12 @@ func Run() error {
 
 	logger.Log.Infow("Starting server", "addr", serverConf.ServerAddress)
 
-	service := service.NewService(urlStore, serverConf.BaseURL)
+	var serviceOpts []service.Option
+	if serverConf.CanonicalURLs || serverConf.StripTracking {
+		// Параметры отслеживания удаляются только из канонической формы, поэтому их удаление включает ее
+		serviceOpts = append(serviceOpts, service.WithCanonicalURLs(serverConf.StripTracking))
+	}
+	service := service.NewService(urlStore, serverConf.BaseURL, serviceOpts...)
 
 	var server *httpserver.URLShortenerServer
 	var grpcServer *grpc.Server
@@ -141,3 +161,22 @@ func Run() error {
 
 	return nil
 }
+
+// runSchemaMigrations применяет (или откатывает) миграции схемы БД без запуска сервиса.
+func runSchemaMigrations(ctx context.Context, serverConf config.ServerConf) error {
+	if serverConf.DSN == "" {
+		return errors.New("для миграции схемы необходимо задать строку подключения к БД")
+	}
+	if serverConf.MigrateDown > 0 {
+		if err := storage.RollbackPgSchema(ctx, serverConf.DSN, serverConf.MigrateDown); err != nil {
+			return err
+		}
+		logger.Log.Infow("Schema migrations rolled back", "count", serverConf.MigrateDown)
+		return nil
+	}
+	if err := storage.MigratePgSchema(ctx, serverConf.DSN, serverConf.URLUniqueness); err != nil {
+		return err
+	}
+	logger.Log.Info("Schema migrations applied")
+	return nil
+}
@@ -0,0 +1,47 @@
+package app
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+
+	"github.com/pinbrain/urlshortener/internal/logger"
+	"github.com/pinbrain/urlshortener/internal/storage"
+)
+
+// ConvertCommand - имя команды перевода файла хранилища в другой формат снимка.
+const ConvertCommand = "convert"
+
+// RunConvert переводит данные файлового хранилища (снимок и журнал операций) в снимок заданного формата.
+// args - аргументы команды: --from <имя файла> --to <имя файла> --format <json|binary>
+// (--to можно не задавать, тогда файл переводится на месте). Приложение должно быть остановлено.
+func RunConvert(args []string) error {
+	flags := flag.NewFlagSet(ConvertCommand, flag.ContinueOnError)
+	from := flags.String("from", "", "Имя файла хранилища")
+	to := flags.String("to", "", "Имя файла, в который записывается снимок (по умолчанию - исходный файл)")
+	format := flags.String("format", "", "Формат снимка (json, binary)")
+	logLevel := flags.String("l", "info", "Уровень логирования")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *from == "" || *format == "" {
+		return errors.New("необходимо задать файл хранилища (--from) и формат снимка (--format)")
+	}
+	if *to == "" {
+		*to = *from
+	}
+	fileFormat, err := storage.ParseFileFormat(*format)
+	if err != nil {
+		return err
+	}
+
+	if err = logger.Initialize(*logLevel); err != nil {
+		return err
+	}
+
+	if err = storage.ConvertFile(*from, *to, fileFormat); err != nil {
+		return fmt.Errorf("failed to convert storage file: %w", err)
+	}
+	logger.Log.Infow("Storage file converted", "from", *from, "to", *to, "format", fileFormat)
+	return nil
+}
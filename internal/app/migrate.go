@@ -0,0 +1,85 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os/signal"
+	"syscall"
+
+	"github.com/pinbrain/urlshortener/internal/logger"
+	"github.com/pinbrain/urlshortener/internal/migrate"
+	"github.com/pinbrain/urlshortener/internal/storage"
+)
+
+// MigrateCommand - имя команды переноса данных между хранилищами.
+const MigrateCommand = "migrate"
+
+// RunMigrate переносит пользователей и ссылки из одного хранилища в другое.
+// args - аргументы команды: --from <адрес хранилища> --to <адрес хранилища>
+// (адрес - file:<имя json файла> или строка подключения к БД postgres://...).
+func RunMigrate(args []string) error {
+	flags := flag.NewFlagSet(MigrateCommand, flag.ContinueOnError)
+	from := flags.String("from", "", "Адрес исходного хранилища (file:<имя файла> или postgres://...)")
+	to := flags.String("to", "", "Адрес хранилища, в которое переносятся данные (file:<имя файла> или postgres://...)")
+	uniqueness := flags.String("url-uniqueness", "", "Область уникальности полных ссылок, с которой работает приложение (global, user, none)")
+	logLevel := flags.String("l", "info", "Уровень логирования")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *from == "" || *to == "" {
+		return errors.New("необходимо задать исходное хранилище (--from) и хранилище для переноса (--to)")
+	}
+	if *from == *to {
+		return errors.New("исходное хранилище совпадает с хранилищем для переноса")
+	}
+	fromCfg, err := migrate.ParseStorage(*from)
+	if err != nil {
+		return err
+	}
+	toCfg, err := migrate.ParseStorage(*to)
+	if err != nil {
+		return err
+	}
+	// Исходное хранилище открывается только для чтения: без миграций, пересчета ключей уникальности и фоновых задач.
+	// Хранилище БД при открытии пересчитывает ключи уникальности, поэтому хранилищу для переноса задается область приложения
+	fromCfg.ReadOnly = true
+	toCfg.Uniqueness = *uniqueness
+
+	if err = logger.Initialize(*logLevel); err != nil {
+		return err
+	}
+
+	ctx, cancelCtx := signal.NotifyContext(
+		context.Background(),
+		syscall.SIGTERM,
+		syscall.SIGINT,
+		syscall.SIGQUIT,
+	)
+	defer cancelCtx()
+
+	fromStore, err := storage.NewURLStorage(fromCfg)
+	if err != nil {
+		return fmt.Errorf("failed to open source storage: %w", err)
+	}
+	defer fromStore.Close()
+	toStore, err := storage.NewURLStorage(toCfg)
+	if err != nil {
+		return fmt.Errorf("failed to open target storage: %w", err)
+	}
+	defer toStore.Close()
+
+	logger.Log.Info("Starting migration")
+	stats, err := migrate.Migrate(ctx, fromStore, toStore)
+	if err != nil {
+		return err
+	}
+	logger.Log.Infow("Migration completed",
+		"users", stats.Users,
+		"urls", stats.URLs,
+		"loaded_urls", stats.LoadedURLs,
+		"skipped_urls", stats.URLs-stats.LoadedURLs,
+	)
+	return nil
+}
@@ -9,28 +9,48 @@ import (
 	"net/url"
 	"os"
 	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/caarlos0/env/v11"
 )
 
 // ServerConf определяет структуру конфигурации.
 type ServerConf struct {
-	ServerAddress string     `env:"SERVER_ADDRESS" json:"server_address"`       // Адрес запуска HTTP-сервера.
-	GRPCAddress   string     `env:"GRPC_ADDRESS" json:"grpc_address"`           // Адрес запуска gRPC-сервера.
-	BaseURL       url.URL    `env:"BASE_URL" json:"-"`                          // Базовый адрес сокращённого URL.
-	LogLevel      string     `env:"LOG_LEVEL" json:"-"`                         // Уровень логирования.
-	StorageFile   string     `env:"FILE_STORAGE_PATH" json:"file_storage_path"` // Полное имя файла, куда сохраняются данные.
-	DSN           string     `env:"DATABASE_DSN" json:"database_dsn"`           // Строка с адресом подключения к БД.
-	EnableHTTPS   bool       `env:"ENABLE_HTTPS" json:"enable_https"`           // Признак включения HTTPS
-	JSONConfig    string     `env:"CONFIG" json:"-"`                            // Имя файла json с конфигурацией
-	TrustedSubnet *net.IPNet `env:"-" json:"-"`                                 // Доверенная подсеть (CIDR)
+	ServerAddress   string     `env:"SERVER_ADDRESS" json:"server_address"`                        // Адрес запуска HTTP-сервера.
+	GRPCAddress     string     `env:"GRPC_ADDRESS" json:"grpc_address"`                            // Адрес запуска gRPC-сервера.
+	BaseURL         url.URL    `env:"BASE_URL" json:"-"`                                           // Базовый адрес сокращённого URL.
+	LogLevel        string     `env:"LOG_LEVEL" json:"-"`                                          // Уровень логирования.
+	StorageFile     string     `env:"FILE_STORAGE_PATH" json:"file_storage_path"`                  // Полное имя файла, куда сохраняются данные.
+	DSN             string     `env:"DATABASE_DSN" json:"database_dsn"`                            // Строка с адресом подключения к БД.
+	EnableHTTPS     bool       `env:"ENABLE_HTTPS" json:"enable_https"`                            // Признак включения HTTPS
+	JSONConfig      string     `env:"CONFIG" json:"-"`                                             // Имя файла json с конфигурацией
+	TrustedSubnet   *net.IPNet `env:"-" json:"-"`                                                  // Доверенная подсеть (CIDR)
+	AliasPattern    string     `env:"ALIAS_PATTERN" json:"alias_pattern"`                          // Шаблон пользовательских сокращенных ссылок
+	IDGenerator     string     `env:"ID_GENERATOR" json:"id_generator"`                            // Генератор сокращенных ссылок (random, sequential, hash)
+	IDLength        int        `env:"ID_LENGTH" json:"id_length"`                                  // Длина сокращенных ссылок
+	IDAlphabet      string     `env:"ID_ALPHABET" json:"id_alphabet"`                              // Набор символов сокращенных ссылок
+	IDLegacyLengths []int      `env:"ID_LEGACY_LENGTHS" envSeparator:"," json:"id_legacy_lengths"` // Длины ссылок прежних форматов
+	URLUniqueness   string     `env:"URL_UNIQUENESS" json:"url_uniqueness"`                        // Область уникальности полных ссылок (global, user, none)
+	CanonicalURLs   bool       `env:"CANONICAL_URLS" json:"canonical_urls"`                        // Проверять уникальность полных ссылок по их канонической форме
+	StripTracking   bool       `env:"STRIP_TRACKING_PARAMS" json:"strip_tracking_params"`          // Не учитывать параметры отслеживания (utm_*, fbclid и т.п.) в канонической форме
+	FileSync        string     `env:"FILE_SYNC" json:"file_sync"`                                  // Политика сброса журнала операций файла на диск (always, interval, never)
+	FileFormat      string     `env:"FILE_FORMAT" json:"file_format"`                              // Формат снимка данных файла (json, binary)
+
+	DeletedRetention time.Duration `env:"DELETED_RETENTION" json:"-"` // Срок хранения удаленных ссылок (0 - хранятся бессрочно)
+
+	MigrateOnly bool `env:"MIGRATE_ONLY" json:"-"` // Применить миграции схемы БД и завершить работу
+	MigrateDown int  `env:"MIGRATE_DOWN" json:"-"` // Откатить указанное количество последних миграций схемы БД и завершить работу
 }
 
 // JSONServerConf определяет структуру файла конфигурации json.
 type JSONServerConf struct {
 	ServerConf
-	BaseURL       string `json:"base_url"`
-	TrustedSubnet string `json:"trusted_subnet"`
+	BaseURL          string `json:"base_url"`
+	TrustedSubnet    string `json:"trusted_subnet"`
+	DeletedRetention string `json:"deleted_retention"`
 }
 
 // validateBaseURL проверяет корректность базового адреса сокращенных ссылок.
@@ -53,6 +73,47 @@ func validateFileName(file string) error {
 	return nil
 }
 
+// validateAliasPattern проверяет корректность шаблона пользовательских сокращенных ссылок.
+func validateAliasPattern(pattern string) error {
+	if pattern == "" {
+		return nil
+	}
+	_, err := regexp.Compile(pattern)
+	return err
+}
+
+// parseIntList разбирает список целых чисел, разделенных запятыми.
+func parseIntList(list string) ([]int, error) {
+	if list == "" {
+		return nil, nil
+	}
+	var result []int
+	for _, item := range strings.Split(list, ",") {
+		value, err := strconv.Atoi(strings.TrimSpace(item))
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, value)
+	}
+	return result, nil
+}
+
+// validateRetention проверяет корректность срока хранения удаленных ссылок.
+func validateRetention(retention time.Duration) error {
+	if retention < 0 {
+		return errors.New("срок хранения удаленных ссылок не может быть отрицательным")
+	}
+	return nil
+}
+
+// validateMigrateDown проверяет корректность количества откатываемых миграций схемы БД.
+func validateMigrateDown(steps int) error {
+	if steps < 0 {
+		return errors.New("количество откатываемых миграций не может быть отрицательным")
+	}
+	return nil
+}
+
 // parseCIDR разбирает строку CIDR и возвращает *net.IPNet.
 func parseCIDR(cidr string) (*net.IPNet, error) {
 	if cidr == "" {
@@ -72,6 +133,20 @@ func loadFlags(cfg *ServerConf) error {
 	flag.StringVar(&cfg.LogLevel, "l", "info", "Уровень логирования")
 	flag.StringVar(&cfg.DSN, "d", "", "Строка с адресом подключения к БД")
 	flag.BoolVar(&cfg.EnableHTTPS, "s", false, "Флаг включения HTTPS")
+	flag.StringVar(&cfg.AliasPattern, "alias-pattern", "", "Шаблон пользовательских сокращенных ссылок")
+	flag.StringVar(&cfg.IDGenerator, "id-generator", "", "Генератор сокращенных ссылок (random, sequential, hash)")
+	flag.IntVar(&cfg.IDLength, "id-length", 0, "Длина сокращенных ссылок")
+	flag.StringVar(&cfg.IDAlphabet, "id-alphabet", "", "Набор символов сокращенных ссылок")
+	flag.StringVar(&cfg.URLUniqueness, "url-uniqueness", "", "Область уникальности полных ссылок (global, user, none)")
+	flag.BoolVar(&cfg.CanonicalURLs, "canonical-urls", false, "Проверять уникальность полных ссылок по их канонической форме")
+	flag.BoolVar(&cfg.StripTracking, "strip-tracking-params", false,
+		"Не учитывать параметры отслеживания (utm_*, fbclid и т.п.) в канонической форме полных ссылок")
+	flag.StringVar(&cfg.FileSync, "file-sync", "", "Политика сброса журнала операций файла на диск (always, interval, never)")
+	flag.StringVar(&cfg.FileFormat, "file-format", "", "Формат снимка данных файла (json, binary)")
+	flag.DurationVar(&cfg.DeletedRetention, "deleted-retention", 0, "Срок хранения удаленных ссылок (0 - хранятся бессрочно)")
+	flag.BoolVar(&cfg.MigrateOnly, "migrate-only", false, "Применить миграции схемы БД и завершить работу")
+	flag.IntVar(&cfg.MigrateDown, "migrate-down", 0, "Откатить указанное количество последних миграций схемы БД и завершить работу")
+	idLegacyLengths := flag.String("id-legacy-lengths", "", "Длины сокращенных ссылок прежних форматов (через запятую)")
 	storageFileStr := flag.String("f", "", "Полное имя файла, куда сохраняются данные")
 	baseURLStr := flag.String("b", "http://localhost:8080", "Базовый адрес результирующего сокращённого URL")
 	trustedSubnet := flag.String("t", "", "Доверенная подсеть (CIDR)")
@@ -103,6 +178,23 @@ func loadFlags(cfg *ServerConf) error {
 		return err
 	}
 
+	if err = validateAliasPattern(cfg.AliasPattern); err != nil {
+		return err
+	}
+
+	cfg.IDLegacyLengths, err = parseIntList(*idLegacyLengths)
+	if err != nil {
+		return err
+	}
+
+	if err = validateRetention(cfg.DeletedRetention); err != nil {
+		return err
+	}
+
+	if err = validateMigrateDown(cfg.MigrateDown); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -121,6 +213,18 @@ func loadEnvs(cfg *ServerConf) error {
 		return err
 	}
 
+	if err = validateAliasPattern(cfg.AliasPattern); err != nil {
+		return err
+	}
+
+	if err = validateRetention(cfg.DeletedRetention); err != nil {
+		return err
+	}
+
+	if err = validateMigrateDown(cfg.MigrateDown); err != nil {
+		return err
+	}
+
 	trustedSubnet := os.Getenv("TRUSTED_SUBNET")
 	if trustedSubnet != "" {
 		cfg.TrustedSubnet, err = parseCIDR(trustedSubnet)
@@ -169,6 +273,48 @@ func loadJSON(cfg *ServerConf) error {
 	if !cfg.EnableHTTPS {
 		cfg.EnableHTTPS = jsonCfg.EnableHTTPS
 	}
+	if cfg.AliasPattern == "" {
+		if err = validateAliasPattern(jsonCfg.AliasPattern); err != nil {
+			return err
+		}
+		cfg.AliasPattern = jsonCfg.AliasPattern
+	}
+	if cfg.IDGenerator == "" {
+		cfg.IDGenerator = jsonCfg.IDGenerator
+	}
+	if cfg.IDLength == 0 {
+		cfg.IDLength = jsonCfg.IDLength
+	}
+	if cfg.IDAlphabet == "" {
+		cfg.IDAlphabet = jsonCfg.IDAlphabet
+	}
+	if len(cfg.IDLegacyLengths) == 0 {
+		cfg.IDLegacyLengths = jsonCfg.IDLegacyLengths
+	}
+	if cfg.URLUniqueness == "" {
+		cfg.URLUniqueness = jsonCfg.URLUniqueness
+	}
+	if !cfg.CanonicalURLs {
+		cfg.CanonicalURLs = jsonCfg.CanonicalURLs
+	}
+	if !cfg.StripTracking {
+		cfg.StripTracking = jsonCfg.StripTracking
+	}
+	if cfg.FileSync == "" {
+		cfg.FileSync = jsonCfg.FileSync
+	}
+	if cfg.FileFormat == "" {
+		cfg.FileFormat = jsonCfg.FileFormat
+	}
+	if cfg.DeletedRetention == 0 && jsonCfg.DeletedRetention != "" {
+		cfg.DeletedRetention, err = time.ParseDuration(jsonCfg.DeletedRetention)
+		if err != nil {
+			return err
+		}
+		if err = validateRetention(cfg.DeletedRetention); err != nil {
+			return err
+		}
+	}
 	if cfg.TrustedSubnet == nil && jsonCfg.TrustedSubnet != "" {
 		cfg.TrustedSubnet, err = parseCIDR(jsonCfg.TrustedSubnet)
 		if err != nil {
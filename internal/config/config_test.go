@@ -4,6 +4,7 @@ import (
 	"flag"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -29,6 +30,37 @@ func TestValidateStorageFileName(t *testing.T) {
 	assert.Error(t, err)
 }
 
+func TestValidateAliasPattern(t *testing.T) {
+	assert.NoError(t, validateAliasPattern(""))
+	assert.NoError(t, validateAliasPattern(`^[a-z-]{3,32}$`))
+	assert.Error(t, validateAliasPattern(`^[a-z`))
+}
+
+func TestParseIntList(t *testing.T) {
+	list, err := parseIntList("")
+	require.NoError(t, err)
+	assert.Empty(t, list)
+
+	list, err = parseIntList("6, 8,10")
+	require.NoError(t, err)
+	assert.Equal(t, []int{6, 8, 10}, list)
+
+	_, err = parseIntList("6,a")
+	assert.Error(t, err)
+}
+
+func TestValidateRetention(t *testing.T) {
+	assert.NoError(t, validateRetention(0))
+	assert.NoError(t, validateRetention(24*time.Hour))
+	assert.Error(t, validateRetention(-time.Second))
+}
+
+func TestValidateMigrateDown(t *testing.T) {
+	assert.NoError(t, validateMigrateDown(0))
+	assert.NoError(t, validateMigrateDown(2))
+	assert.Error(t, validateMigrateDown(-1))
+}
+
 func TestLoadFlags(t *testing.T) {
 	fs := flag.NewFlagSet("test", flag.ContinueOnError)
 	oldFlagSet := flag.CommandLine
@@ -127,7 +159,8 @@ func TestLoadJSON(t *testing.T) {
 		"base_url": "http://json.com",
 		"file_storage_path": "/tmp/json.json",
 		"database_dsn": "json_dsn",
-		"enable_https": true
+		"enable_https": true,
+		"deleted_retention": "720h"
 	}`
 	_, err = tmpFile.Write([]byte(jsonConfig))
 	if err != nil {
@@ -156,6 +189,9 @@ func TestLoadJSON(t *testing.T) {
 	if !cfg.EnableHTTPS {
 		t.Errorf("Expected HTTPS to be enabled, but it wasn't")
 	}
+	if cfg.DeletedRetention != 720*time.Hour {
+		t.Errorf("Expected deleted retention 720h, got %s", cfg.DeletedRetention)
+	}
 }
 
 func TestInitConfig(t *testing.T) {
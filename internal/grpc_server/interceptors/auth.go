@@ -22,10 +22,15 @@ const userIDMetaKey = "user_id"
 
 // Перечень методов, доступных для авторизованных пользователей.
 var authProtectedMethods = map[string]bool{
-	pb.URLShortener_ShortenURL_FullMethodName:      true,
-	pb.URLShortener_ShortenBatchURL_FullMethodName: true,
-	pb.URLShortener_GetUserURLs_FullMethodName:     true,
-	pb.URLShortener_DeleteUserURLs_FullMethodName:  true,
+	pb.URLShortener_ShortenURL_FullMethodName:         true,
+	pb.URLShortener_ShortenBatchURL_FullMethodName:    true,
+	pb.URLShortener_GetUserURLs_FullMethodName:        true,
+	pb.URLShortener_ExportUserURLs_FullMethodName:     true,
+	pb.URLShortener_GetDeletedUserURLs_FullMethodName: true,
+	pb.URLShortener_RestoreUserURLs_FullMethodName:    true,
+	pb.URLShortener_UpdateURL_FullMethodName:          true,
+	pb.URLShortener_DeleteUserURLs_FullMethodName:     true,
+	pb.URLShortener_GetURLStats_FullMethodName:        true,
 }
 
 // AuthInterceptor описывает структуру перехватчика для авторизации и аутентификации.
@@ -44,6 +49,37 @@ func NewAuthInterceptor(service *service.Service) *AuthInterceptor {
 func (i *AuthInterceptor) AuthenticateUser(
 	ctx context.Context, req interface{}, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler,
 ) (interface{}, error) {
+	ctx, err := i.authenticate(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+// AuthenticateUserStream аутентифицирует пользователя потокового запроса.
+func (i *AuthInterceptor) AuthenticateUserStream(
+	srv interface{}, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler,
+) error {
+	ctx, err := i.authenticate(ss.Context())
+	if err != nil {
+		return err
+	}
+	return handler(srv, &ctxServerStream{ServerStream: ss, ctx: ctx})
+}
+
+// ctxServerStream описывает поток запроса с подмененным контекстом.
+type ctxServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+// Context возвращает контекст потока.
+func (s *ctxServerStream) Context() context.Context {
+	return s.ctx
+}
+
+// authenticate определяет (или создает) пользователя запроса и возвращает контекст с ним.
+func (i *AuthInterceptor) authenticate(ctx context.Context) (context.Context, error) {
 	var userID int
 	var err error
 	if md, ok := metadata.FromIncomingContext(ctx); ok {
@@ -53,7 +89,7 @@ func (i *AuthInterceptor) AuthenticateUser(
 			userID, err = strconv.Atoi(idString)
 			if err != nil {
 				logger.Log.Errorw("Wrong user id format", "err", err)
-				return nil, status.Error(codes.Unauthenticated, "Wrong user id format")
+				return ctx, status.Error(codes.Unauthenticated, "Wrong user id format")
 			}
 		}
 	}
@@ -64,22 +100,20 @@ func (i *AuthInterceptor) AuthenticateUser(
 		userData, err = i.service.GetUser(ctx, userID)
 		if err != nil {
 			if errors.Is(err, service.ErrNotFound) {
-				return nil, status.Error(codes.Unauthenticated, "Request user not found")
+				return ctx, status.Error(codes.Unauthenticated, "Request user not found")
 			}
 			logger.Log.Errorw("Error in getting user data", "err", err)
-			return nil, status.Error(codes.Unauthenticated, "Failed to get request user data")
+			return ctx, status.Error(codes.Unauthenticated, "Failed to get request user data")
 		}
 	} else {
 		userData, err = i.service.CreateUser(ctx)
 		if err != nil {
 			logger.Log.Errorw("Error creating new user", "err", err)
-			return nil, status.Error(codes.Internal, "Internal Server Error")
+			return ctx, status.Error(codes.Internal, "Internal Server Error")
 		}
 	}
 
-	ctx = appCtx.CtxWithUser(ctx, &appCtx.CtxUser{ID: userData.ID})
-
-	return handler(ctx, req)
+	return appCtx.CtxWithUser(ctx, &appCtx.CtxUser{ID: userData.ID}), nil
 }
 
 // RequireUser проверяет что пользователь авторизован.
@@ -96,3 +130,18 @@ func (i *AuthInterceptor) RequireUser(
 	}
 	return handler(ctx, req)
 }
+
+// RequireUserStream проверяет что пользователь потокового запроса авторизован.
+// В противном случае прерывает обработку запроса и возвращает ошибку Unauthorized.
+func (i *AuthInterceptor) RequireUserStream(
+	srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler,
+) error {
+	if !authProtectedMethods[info.FullMethod] {
+		return handler(srv, ss)
+	}
+	user := appCtx.GetCtxUser(ss.Context())
+	if user == nil || user.ID <= 0 {
+		return status.Error(codes.Unauthenticated, "Unauthorized")
+	}
+	return handler(srv, ss)
+}
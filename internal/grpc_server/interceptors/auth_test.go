@@ -186,3 +186,45 @@ func TestRequireUser(t *testing.T) {
 		})
 	}
 }
+
+// testServerStream - поток запроса для тестов перехватчиков.
+type testServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *testServerStream) Context() context.Context {
+	return s.ctx
+}
+
+func TestAuthStream(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockStorage := mocks.NewMockURLStorage(ctrl)
+	baseURL := url.URL{Scheme: "http", Host: "localhost:8080"}
+	service := service.NewService(mockStorage, baseURL)
+	authInterceptor := NewAuthInterceptor(&service)
+	info := &grpc.StreamServerInfo{FullMethod: pb.URLShortener_ExportUserURLs_FullMethodName}
+
+	mockStorage.EXPECT().GetUser(gomock.Any(), 5).Times(1).Return(&storage.User{ID: 5}, nil)
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(userIDMetaKey, "5"))
+	var handlerUser *appCtx.CtxUser
+	err := authInterceptor.AuthenticateUserStream(nil, &testServerStream{ctx: ctx}, info,
+		func(srv any, ss grpc.ServerStream) error {
+			handlerUser = appCtx.GetCtxUser(ss.Context())
+			return authInterceptor.RequireUserStream(srv, ss, info, func(any, grpc.ServerStream) error {
+				return nil
+			})
+		})
+	require.NoError(t, err)
+	require.NotNil(t, handlerUser)
+	assert.Equal(t, 5, handlerUser.ID)
+
+	err = authInterceptor.RequireUserStream(nil, &testServerStream{ctx: context.Background()}, info,
+		func(any, grpc.ServerStream) error {
+			return nil
+		})
+	code, _ := status.FromError(err)
+	assert.Equal(t, codes.Unauthenticated, code.Code())
+}
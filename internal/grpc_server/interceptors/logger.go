@@ -26,3 +26,21 @@ func LoggerInterceptor(
 	)
 	return resp, err
 }
+
+// LoggerStreamInterceptor логирует входящие потоковые запросы.
+func LoggerStreamInterceptor(
+	srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler,
+) error {
+	start := time.Now()
+	err := handler(srv, ss)
+	duration := time.Since(start)
+	status, _ := status.FromError(err)
+
+	logger.Log.Infow(
+		"gRPC stream",
+		"method", info.FullMethod,
+		"duration", duration,
+		"code", status.Code(),
+	)
+	return err
+}
@@ -9,6 +9,7 @@ package proto
 import (
 	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
 	reflect "reflect"
 	sync "sync"
 )
@@ -25,7 +26,13 @@ type ShortenURLReq struct {
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	OriginalUrl string `protobuf:"bytes,1,opt,name=original_url,json=originalUrl,proto3" json:"original_url,omitempty"`
+	OriginalUrl string                 `protobuf:"bytes,1,opt,name=original_url,json=originalUrl,proto3" json:"original_url,omitempty"`
+	Alias       string                 `protobuf:"bytes,2,opt,name=alias,proto3" json:"alias,omitempty"`
+	ExpiresAt   *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`
+	TtlSeconds  int64                  `protobuf:"varint,4,opt,name=ttl_seconds,json=ttlSeconds,proto3" json:"ttl_seconds,omitempty"`
+	MaxClicks   int32                  `protobuf:"varint,5,opt,name=max_clicks,json=maxClicks,proto3" json:"max_clicks,omitempty"`
+	Password    string                 `protobuf:"bytes,6,opt,name=password,proto3" json:"password,omitempty"`
+	Tags        []string               `protobuf:"bytes,7,rep,name=tags,proto3" json:"tags,omitempty"`
 }
 
 func (x *ShortenURLReq) Reset() {
@@ -67,6 +74,48 @@ func (x *ShortenURLReq) GetOriginalUrl() string {
 	return ""
 }
 
+func (x *ShortenURLReq) GetAlias() string {
+	if x != nil {
+		return x.Alias
+	}
+	return ""
+}
+
+func (x *ShortenURLReq) GetExpiresAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return nil
+}
+
+func (x *ShortenURLReq) GetTtlSeconds() int64 {
+	if x != nil {
+		return x.TtlSeconds
+	}
+	return 0
+}
+
+func (x *ShortenURLReq) GetMaxClicks() int32 {
+	if x != nil {
+		return x.MaxClicks
+	}
+	return 0
+}
+
+func (x *ShortenURLReq) GetPassword() string {
+	if x != nil {
+		return x.Password
+	}
+	return ""
+}
+
+func (x *ShortenURLReq) GetTags() []string {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
 type ShortenURLRes struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -213,7 +262,8 @@ type GetURLReq struct {
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	UrlId string `protobuf:"bytes,1,opt,name=url_id,json=urlId,proto3" json:"url_id,omitempty"`
+	UrlId    string `protobuf:"bytes,1,opt,name=url_id,json=urlId,proto3" json:"url_id,omitempty"`
+	Password string `protobuf:"bytes,2,opt,name=password,proto3" json:"password,omitempty"`
 }
 
 func (x *GetURLReq) Reset() {
@@ -255,6 +305,13 @@ func (x *GetURLReq) GetUrlId() string {
 	return ""
 }
 
+func (x *GetURLReq) GetPassword() string {
+	if x != nil {
+		return x.Password
+	}
+	return ""
+}
+
 type GetURLRes struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -306,6 +363,12 @@ type GetUsersURLsReq struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
+
+	Tags   []string `protobuf:"bytes,1,rep,name=tags,proto3" json:"tags,omitempty"`
+	Limit  int32    `protobuf:"varint,2,opt,name=limit,proto3" json:"limit,omitempty"`
+	Cursor string   `protobuf:"bytes,3,opt,name=cursor,proto3" json:"cursor,omitempty"`
+	Query  string   `protobuf:"bytes,4,opt,name=query,proto3" json:"query,omitempty"`
+	Domain string   `protobuf:"bytes,5,opt,name=domain,proto3" json:"domain,omitempty"`
 }
 
 func (x *GetUsersURLsReq) Reset() {
@@ -340,12 +403,48 @@ func (*GetUsersURLsReq) Descriptor() ([]byte, []int) {
 	return file_internal_grpc_server_proto_urlshortener_proto_rawDescGZIP(), []int{6}
 }
 
+func (x *GetUsersURLsReq) GetTags() []string {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
+func (x *GetUsersURLsReq) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *GetUsersURLsReq) GetCursor() string {
+	if x != nil {
+		return x.Cursor
+	}
+	return ""
+}
+
+func (x *GetUsersURLsReq) GetQuery() string {
+	if x != nil {
+		return x.Query
+	}
+	return ""
+}
+
+func (x *GetUsersURLsReq) GetDomain() string {
+	if x != nil {
+		return x.Domain
+	}
+	return ""
+}
+
 type GetUsersURLsRes struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Urls []*GetUsersURLsRes_UserURL `protobuf:"bytes,1,rep,name=urls,proto3" json:"urls,omitempty"`
+	Urls       []*GetUsersURLsRes_UserURL `protobuf:"bytes,1,rep,name=urls,proto3" json:"urls,omitempty"`
+	NextCursor string                     `protobuf:"bytes,2,opt,name=next_cursor,json=nextCursor,proto3" json:"next_cursor,omitempty"`
 }
 
 func (x *GetUsersURLsRes) Reset() {
@@ -387,16 +486,21 @@ func (x *GetUsersURLsRes) GetUrls() []*GetUsersURLsRes_UserURL {
 	return nil
 }
 
-type DeleteUserURLsReq struct {
+func (x *GetUsersURLsRes) GetNextCursor() string {
+	if x != nil {
+		return x.NextCursor
+	}
+	return ""
+}
+
+type ExportUserURLsReq struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
-
-	Urls []string `protobuf:"bytes,1,rep,name=urls,proto3" json:"urls,omitempty"`
 }
 
-func (x *DeleteUserURLsReq) Reset() {
-	*x = DeleteUserURLsReq{}
+func (x *ExportUserURLsReq) Reset() {
+	*x = ExportUserURLsReq{}
 	if protoimpl.UnsafeEnabled {
 		mi := &file_internal_grpc_server_proto_urlshortener_proto_msgTypes[8]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -404,13 +508,13 @@ func (x *DeleteUserURLsReq) Reset() {
 	}
 }
 
-func (x *DeleteUserURLsReq) String() string {
+func (x *ExportUserURLsReq) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*DeleteUserURLsReq) ProtoMessage() {}
+func (*ExportUserURLsReq) ProtoMessage() {}
 
-func (x *DeleteUserURLsReq) ProtoReflect() protoreflect.Message {
+func (x *ExportUserURLsReq) ProtoReflect() protoreflect.Message {
 	mi := &file_internal_grpc_server_proto_urlshortener_proto_msgTypes[8]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -422,26 +526,24 @@ func (x *DeleteUserURLsReq) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use DeleteUserURLsReq.ProtoReflect.Descriptor instead.
-func (*DeleteUserURLsReq) Descriptor() ([]byte, []int) {
+// Deprecated: Use ExportUserURLsReq.ProtoReflect.Descriptor instead.
+func (*ExportUserURLsReq) Descriptor() ([]byte, []int) {
 	return file_internal_grpc_server_proto_urlshortener_proto_rawDescGZIP(), []int{8}
 }
 
-func (x *DeleteUserURLsReq) GetUrls() []string {
-	if x != nil {
-		return x.Urls
-	}
-	return nil
-}
-
-type DeleteUserURLsRes struct {
+type ExportUserURLsRes struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
+
+	OriginalUrl string                 `protobuf:"bytes,1,opt,name=original_url,json=originalUrl,proto3" json:"original_url,omitempty"`
+	ShortUrl    string                 `protobuf:"bytes,2,opt,name=short_url,json=shortUrl,proto3" json:"short_url,omitempty"`
+	Tags        []string               `protobuf:"bytes,3,rep,name=tags,proto3" json:"tags,omitempty"`
+	ExpiresAt   *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`
 }
 
-func (x *DeleteUserURLsRes) Reset() {
-	*x = DeleteUserURLsRes{}
+func (x *ExportUserURLsRes) Reset() {
+	*x = ExportUserURLsRes{}
 	if protoimpl.UnsafeEnabled {
 		mi := &file_internal_grpc_server_proto_urlshortener_proto_msgTypes[9]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -449,13 +551,13 @@ func (x *DeleteUserURLsRes) Reset() {
 	}
 }
 
-func (x *DeleteUserURLsRes) String() string {
+func (x *ExportUserURLsRes) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*DeleteUserURLsRes) ProtoMessage() {}
+func (*ExportUserURLsRes) ProtoMessage() {}
 
-func (x *DeleteUserURLsRes) ProtoReflect() protoreflect.Message {
+func (x *ExportUserURLsRes) ProtoReflect() protoreflect.Message {
 	mi := &file_internal_grpc_server_proto_urlshortener_proto_msgTypes[9]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -467,19 +569,47 @@ func (x *DeleteUserURLsRes) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use DeleteUserURLsRes.ProtoReflect.Descriptor instead.
-func (*DeleteUserURLsRes) Descriptor() ([]byte, []int) {
+// Deprecated: Use ExportUserURLsRes.ProtoReflect.Descriptor instead.
+func (*ExportUserURLsRes) Descriptor() ([]byte, []int) {
 	return file_internal_grpc_server_proto_urlshortener_proto_rawDescGZIP(), []int{9}
 }
 
-type GetStatsReq struct {
+func (x *ExportUserURLsRes) GetOriginalUrl() string {
+	if x != nil {
+		return x.OriginalUrl
+	}
+	return ""
+}
+
+func (x *ExportUserURLsRes) GetShortUrl() string {
+	if x != nil {
+		return x.ShortUrl
+	}
+	return ""
+}
+
+func (x *ExportUserURLsRes) GetTags() []string {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
+func (x *ExportUserURLsRes) GetExpiresAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return nil
+}
+
+type GetDeletedUserURLsReq struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 }
 
-func (x *GetStatsReq) Reset() {
-	*x = GetStatsReq{}
+func (x *GetDeletedUserURLsReq) Reset() {
+	*x = GetDeletedUserURLsReq{}
 	if protoimpl.UnsafeEnabled {
 		mi := &file_internal_grpc_server_proto_urlshortener_proto_msgTypes[10]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -487,13 +617,13 @@ func (x *GetStatsReq) Reset() {
 	}
 }
 
-func (x *GetStatsReq) String() string {
+func (x *GetDeletedUserURLsReq) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetStatsReq) ProtoMessage() {}
+func (*GetDeletedUserURLsReq) ProtoMessage() {}
 
-func (x *GetStatsReq) ProtoReflect() protoreflect.Message {
+func (x *GetDeletedUserURLsReq) ProtoReflect() protoreflect.Message {
 	mi := &file_internal_grpc_server_proto_urlshortener_proto_msgTypes[10]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -505,22 +635,21 @@ func (x *GetStatsReq) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GetStatsReq.ProtoReflect.Descriptor instead.
-func (*GetStatsReq) Descriptor() ([]byte, []int) {
+// Deprecated: Use GetDeletedUserURLsReq.ProtoReflect.Descriptor instead.
+func (*GetDeletedUserURLsReq) Descriptor() ([]byte, []int) {
 	return file_internal_grpc_server_proto_urlshortener_proto_rawDescGZIP(), []int{10}
 }
 
-type GetStatsRes struct {
+type GetDeletedUserURLsRes struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	Urls  int32 `protobuf:"varint,1,opt,name=urls,proto3" json:"urls,omitempty"`
-	Users int32 `protobuf:"varint,2,opt,name=users,proto3" json:"users,omitempty"`
+	Urls []*GetUsersURLsRes_UserURL `protobuf:"bytes,1,rep,name=urls,proto3" json:"urls,omitempty"`
 }
 
-func (x *GetStatsRes) Reset() {
-	*x = GetStatsRes{}
+func (x *GetDeletedUserURLsRes) Reset() {
+	*x = GetDeletedUserURLsRes{}
 	if protoimpl.UnsafeEnabled {
 		mi := &file_internal_grpc_server_proto_urlshortener_proto_msgTypes[11]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -528,13 +657,13 @@ func (x *GetStatsRes) Reset() {
 	}
 }
 
-func (x *GetStatsRes) String() string {
+func (x *GetDeletedUserURLsRes) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetStatsRes) ProtoMessage() {}
+func (*GetDeletedUserURLsRes) ProtoMessage() {}
 
-func (x *GetStatsRes) ProtoReflect() protoreflect.Message {
+func (x *GetDeletedUserURLsRes) ProtoReflect() protoreflect.Message {
 	mi := &file_internal_grpc_server_proto_urlshortener_proto_msgTypes[11]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -546,33 +675,28 @@ func (x *GetStatsRes) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GetStatsRes.ProtoReflect.Descriptor instead.
-func (*GetStatsRes) Descriptor() ([]byte, []int) {
+// Deprecated: Use GetDeletedUserURLsRes.ProtoReflect.Descriptor instead.
+func (*GetDeletedUserURLsRes) Descriptor() ([]byte, []int) {
 	return file_internal_grpc_server_proto_urlshortener_proto_rawDescGZIP(), []int{11}
 }
 
-func (x *GetStatsRes) GetUrls() int32 {
+func (x *GetDeletedUserURLsRes) GetUrls() []*GetUsersURLsRes_UserURL {
 	if x != nil {
 		return x.Urls
 	}
-	return 0
-}
-
-func (x *GetStatsRes) GetUsers() int32 {
-	if x != nil {
-		return x.Users
-	}
-	return 0
+	return nil
 }
 
-type PingReq struct {
+type RestoreUserURLsReq struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
+
+	Urls []string `protobuf:"bytes,1,rep,name=urls,proto3" json:"urls,omitempty"`
 }
 
-func (x *PingReq) Reset() {
-	*x = PingReq{}
+func (x *RestoreUserURLsReq) Reset() {
+	*x = RestoreUserURLsReq{}
 	if protoimpl.UnsafeEnabled {
 		mi := &file_internal_grpc_server_proto_urlshortener_proto_msgTypes[12]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -580,13 +704,13 @@ func (x *PingReq) Reset() {
 	}
 }
 
-func (x *PingReq) String() string {
+func (x *RestoreUserURLsReq) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*PingReq) ProtoMessage() {}
+func (*RestoreUserURLsReq) ProtoMessage() {}
 
-func (x *PingReq) ProtoReflect() protoreflect.Message {
+func (x *RestoreUserURLsReq) ProtoReflect() protoreflect.Message {
 	mi := &file_internal_grpc_server_proto_urlshortener_proto_msgTypes[12]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -598,19 +722,26 @@ func (x *PingReq) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use PingReq.ProtoReflect.Descriptor instead.
-func (*PingReq) Descriptor() ([]byte, []int) {
+// Deprecated: Use RestoreUserURLsReq.ProtoReflect.Descriptor instead.
+func (*RestoreUserURLsReq) Descriptor() ([]byte, []int) {
 	return file_internal_grpc_server_proto_urlshortener_proto_rawDescGZIP(), []int{12}
 }
 
-type PingRes struct {
+func (x *RestoreUserURLsReq) GetUrls() []string {
+	if x != nil {
+		return x.Urls
+	}
+	return nil
+}
+
+type RestoreUserURLsRes struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 }
 
-func (x *PingRes) Reset() {
-	*x = PingRes{}
+func (x *RestoreUserURLsRes) Reset() {
+	*x = RestoreUserURLsRes{}
 	if protoimpl.UnsafeEnabled {
 		mi := &file_internal_grpc_server_proto_urlshortener_proto_msgTypes[13]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -618,13 +749,13 @@ func (x *PingRes) Reset() {
 	}
 }
 
-func (x *PingRes) String() string {
+func (x *RestoreUserURLsRes) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*PingRes) ProtoMessage() {}
+func (*RestoreUserURLsRes) ProtoMessage() {}
 
-func (x *PingRes) ProtoReflect() protoreflect.Message {
+func (x *RestoreUserURLsRes) ProtoReflect() protoreflect.Message {
 	mi := &file_internal_grpc_server_proto_urlshortener_proto_msgTypes[13]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -636,22 +767,21 @@ func (x *PingRes) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use PingRes.ProtoReflect.Descriptor instead.
-func (*PingRes) Descriptor() ([]byte, []int) {
+// Deprecated: Use RestoreUserURLsRes.ProtoReflect.Descriptor instead.
+func (*RestoreUserURLsRes) Descriptor() ([]byte, []int) {
 	return file_internal_grpc_server_proto_urlshortener_proto_rawDescGZIP(), []int{13}
 }
 
-type ShortenBatchURLReq_BatchURL struct {
+type TagList struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	CorrelationId string `protobuf:"bytes,1,opt,name=correlation_id,json=correlationId,proto3" json:"correlation_id,omitempty"`
-	OriginalUrl   string `protobuf:"bytes,2,opt,name=original_url,json=originalUrl,proto3" json:"original_url,omitempty"`
+	Tags []string `protobuf:"bytes,1,rep,name=tags,proto3" json:"tags,omitempty"`
 }
 
-func (x *ShortenBatchURLReq_BatchURL) Reset() {
-	*x = ShortenBatchURLReq_BatchURL{}
+func (x *TagList) Reset() {
+	*x = TagList{}
 	if protoimpl.UnsafeEnabled {
 		mi := &file_internal_grpc_server_proto_urlshortener_proto_msgTypes[14]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -659,13 +789,13 @@ func (x *ShortenBatchURLReq_BatchURL) Reset() {
 	}
 }
 
-func (x *ShortenBatchURLReq_BatchURL) String() string {
+func (x *TagList) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ShortenBatchURLReq_BatchURL) ProtoMessage() {}
+func (*TagList) ProtoMessage() {}
 
-func (x *ShortenBatchURLReq_BatchURL) ProtoReflect() protoreflect.Message {
+func (x *TagList) ProtoReflect() protoreflect.Message {
 	mi := &file_internal_grpc_server_proto_urlshortener_proto_msgTypes[14]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -677,36 +807,30 @@ func (x *ShortenBatchURLReq_BatchURL) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ShortenBatchURLReq_BatchURL.ProtoReflect.Descriptor instead.
-func (*ShortenBatchURLReq_BatchURL) Descriptor() ([]byte, []int) {
-	return file_internal_grpc_server_proto_urlshortener_proto_rawDescGZIP(), []int{2, 0}
-}
-
-func (x *ShortenBatchURLReq_BatchURL) GetCorrelationId() string {
-	if x != nil {
-		return x.CorrelationId
-	}
-	return ""
+// Deprecated: Use TagList.ProtoReflect.Descriptor instead.
+func (*TagList) Descriptor() ([]byte, []int) {
+	return file_internal_grpc_server_proto_urlshortener_proto_rawDescGZIP(), []int{14}
 }
 
-func (x *ShortenBatchURLReq_BatchURL) GetOriginalUrl() string {
+func (x *TagList) GetTags() []string {
 	if x != nil {
-		return x.OriginalUrl
+		return x.Tags
 	}
-	return ""
+	return nil
 }
 
-type ShortenBatchURLRes_BatchURL struct {
+type UpdateURLReq struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	CorrelationId string `protobuf:"bytes,1,opt,name=correlation_id,json=correlationId,proto3" json:"correlation_id,omitempty"`
-	ShortUrl      string `protobuf:"bytes,2,opt,name=short_url,json=shortUrl,proto3" json:"short_url,omitempty"`
+	UrlId       string   `protobuf:"bytes,1,opt,name=url_id,json=urlId,proto3" json:"url_id,omitempty"`
+	OriginalUrl string   `protobuf:"bytes,2,opt,name=original_url,json=originalUrl,proto3" json:"original_url,omitempty"`
+	Tags        *TagList `protobuf:"bytes,3,opt,name=tags,proto3" json:"tags,omitempty"`
 }
 
-func (x *ShortenBatchURLRes_BatchURL) Reset() {
-	*x = ShortenBatchURLRes_BatchURL{}
+func (x *UpdateURLReq) Reset() {
+	*x = UpdateURLReq{}
 	if protoimpl.UnsafeEnabled {
 		mi := &file_internal_grpc_server_proto_urlshortener_proto_msgTypes[15]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -714,13 +838,13 @@ func (x *ShortenBatchURLRes_BatchURL) Reset() {
 	}
 }
 
-func (x *ShortenBatchURLRes_BatchURL) String() string {
+func (x *UpdateURLReq) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ShortenBatchURLRes_BatchURL) ProtoMessage() {}
+func (*UpdateURLReq) ProtoMessage() {}
 
-func (x *ShortenBatchURLRes_BatchURL) ProtoReflect() protoreflect.Message {
+func (x *UpdateURLReq) ProtoReflect() protoreflect.Message {
 	mi := &file_internal_grpc_server_proto_urlshortener_proto_msgTypes[15]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -732,36 +856,43 @@ func (x *ShortenBatchURLRes_BatchURL) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ShortenBatchURLRes_BatchURL.ProtoReflect.Descriptor instead.
-func (*ShortenBatchURLRes_BatchURL) Descriptor() ([]byte, []int) {
-	return file_internal_grpc_server_proto_urlshortener_proto_rawDescGZIP(), []int{3, 0}
+// Deprecated: Use UpdateURLReq.ProtoReflect.Descriptor instead.
+func (*UpdateURLReq) Descriptor() ([]byte, []int) {
+	return file_internal_grpc_server_proto_urlshortener_proto_rawDescGZIP(), []int{15}
 }
 
-func (x *ShortenBatchURLRes_BatchURL) GetCorrelationId() string {
+func (x *UpdateURLReq) GetUrlId() string {
 	if x != nil {
-		return x.CorrelationId
+		return x.UrlId
 	}
 	return ""
 }
 
-func (x *ShortenBatchURLRes_BatchURL) GetShortUrl() string {
+func (x *UpdateURLReq) GetOriginalUrl() string {
 	if x != nil {
-		return x.ShortUrl
+		return x.OriginalUrl
 	}
 	return ""
 }
 
-type GetUsersURLsRes_UserURL struct {
+func (x *UpdateURLReq) GetTags() *TagList {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
+type UpdateURLRes struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
 	unknownFields protoimpl.UnknownFields
 
-	OriginalUrl string `protobuf:"bytes,1,opt,name=original_url,json=originalUrl,proto3" json:"original_url,omitempty"`
-	ShortUrl    string `protobuf:"bytes,2,opt,name=short_url,json=shortUrl,proto3" json:"short_url,omitempty"`
+	OriginalUrl string   `protobuf:"bytes,1,opt,name=original_url,json=originalUrl,proto3" json:"original_url,omitempty"`
+	Tags        []string `protobuf:"bytes,2,rep,name=tags,proto3" json:"tags,omitempty"`
 }
 
-func (x *GetUsersURLsRes_UserURL) Reset() {
-	*x = GetUsersURLsRes_UserURL{}
+func (x *UpdateURLRes) Reset() {
+	*x = UpdateURLRes{}
 	if protoimpl.UnsafeEnabled {
 		mi := &file_internal_grpc_server_proto_urlshortener_proto_msgTypes[16]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -769,13 +900,13 @@ func (x *GetUsersURLsRes_UserURL) Reset() {
 	}
 }
 
-func (x *GetUsersURLsRes_UserURL) String() string {
+func (x *UpdateURLRes) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetUsersURLsRes_UserURL) ProtoMessage() {}
+func (*UpdateURLRes) ProtoMessage() {}
 
-func (x *GetUsersURLsRes_UserURL) ProtoReflect() protoreflect.Message {
+func (x *UpdateURLRes) ProtoReflect() protoreflect.Message {
 	mi := &file_internal_grpc_server_proto_urlshortener_proto_msgTypes[16]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -787,137 +918,988 @@ func (x *GetUsersURLsRes_UserURL) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GetUsersURLsRes_UserURL.ProtoReflect.Descriptor instead.
-func (*GetUsersURLsRes_UserURL) Descriptor() ([]byte, []int) {
-	return file_internal_grpc_server_proto_urlshortener_proto_rawDescGZIP(), []int{7, 0}
+// Deprecated: Use UpdateURLRes.ProtoReflect.Descriptor instead.
+func (*UpdateURLRes) Descriptor() ([]byte, []int) {
+	return file_internal_grpc_server_proto_urlshortener_proto_rawDescGZIP(), []int{16}
 }
 
-func (x *GetUsersURLsRes_UserURL) GetOriginalUrl() string {
+func (x *UpdateURLRes) GetOriginalUrl() string {
 	if x != nil {
 		return x.OriginalUrl
 	}
 	return ""
 }
 
-func (x *GetUsersURLsRes_UserURL) GetShortUrl() string {
+func (x *UpdateURLRes) GetTags() []string {
 	if x != nil {
-		return x.ShortUrl
+		return x.Tags
 	}
-	return ""
+	return nil
 }
 
-var File_internal_grpc_server_proto_urlshortener_proto protoreflect.FileDescriptor
+type DeleteUserURLsReq struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
 
-var file_internal_grpc_server_proto_urlshortener_proto_rawDesc = []byte{
-	0x0a, 0x2d, 0x69, 0x6e, 0x74, 0x65, 0x72, 0x6e, 0x61, 0x6c, 0x2f, 0x67, 0x72, 0x70, 0x63, 0x5f,
-	0x73, 0x65, 0x72, 0x76, 0x65, 0x72, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2f, 0x75, 0x72, 0x6c,
-	0x73, 0x68, 0x6f, 0x72, 0x74, 0x65, 0x6e, 0x65, 0x72, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12,
-	0x0c, 0x75, 0x72, 0x6c, 0x73, 0x68, 0x6f, 0x72, 0x74, 0x65, 0x6e, 0x65, 0x72, 0x22, 0x32, 0x0a,
-	0x0d, 0x53, 0x68, 0x6f, 0x72, 0x74, 0x65, 0x6e, 0x55, 0x52, 0x4c, 0x52, 0x65, 0x71, 0x12, 0x21,
-	0x0a, 0x0c, 0x6f, 0x72, 0x69, 0x67, 0x69, 0x6e, 0x61, 0x6c, 0x5f, 0x75, 0x72, 0x6c, 0x18, 0x01,
-	0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x6f, 0x72, 0x69, 0x67, 0x69, 0x6e, 0x61, 0x6c, 0x55, 0x72,
-	0x6c, 0x22, 0x2c, 0x0a, 0x0d, 0x53, 0x68, 0x6f, 0x72, 0x74, 0x65, 0x6e, 0x55, 0x52, 0x4c, 0x52,
-	0x65, 0x73, 0x12, 0x1b, 0x0a, 0x09, 0x73, 0x68, 0x6f, 0x72, 0x74, 0x5f, 0x75, 0x72, 0x6c, 0x18,
-	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x73, 0x68, 0x6f, 0x72, 0x74, 0x55, 0x72, 0x6c, 0x22,
-	0xa9, 0x01, 0x0a, 0x12, 0x53, 0x68, 0x6f, 0x72, 0x74, 0x65, 0x6e, 0x42, 0x61, 0x74, 0x63, 0x68,
-	0x55, 0x52, 0x4c, 0x52, 0x65, 0x71, 0x12, 0x3d, 0x0a, 0x04, 0x75, 0x72, 0x6c, 0x73, 0x18, 0x01,
-	0x20, 0x03, 0x28, 0x0b, 0x32, 0x29, 0x2e, 0x75, 0x72, 0x6c, 0x73, 0x68, 0x6f, 0x72, 0x74, 0x65,
-	0x6e, 0x65, 0x72, 0x2e, 0x53, 0x68, 0x6f, 0x72, 0x74, 0x65, 0x6e, 0x42, 0x61, 0x74, 0x63, 0x68,
-	0x55, 0x52, 0x4c, 0x52, 0x65, 0x71, 0x2e, 0x42, 0x61, 0x74, 0x63, 0x68, 0x55, 0x52, 0x4c, 0x52,
-	0x04, 0x75, 0x72, 0x6c, 0x73, 0x1a, 0x54, 0x0a, 0x08, 0x42, 0x61, 0x74, 0x63, 0x68, 0x55, 0x52,
-	0x4c, 0x12, 0x25, 0x0a, 0x0e, 0x63, 0x6f, 0x72, 0x72, 0x65, 0x6c, 0x61, 0x74, 0x69, 0x6f, 0x6e,
-	0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0d, 0x63, 0x6f, 0x72, 0x72, 0x65,
-	0x6c, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x49, 0x64, 0x12, 0x21, 0x0a, 0x0c, 0x6f, 0x72, 0x69, 0x67,
-	0x69, 0x6e, 0x61, 0x6c, 0x5f, 0x75, 0x72, 0x6c, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b,
-	0x6f, 0x72, 0x69, 0x67, 0x69, 0x6e, 0x61, 0x6c, 0x55, 0x72, 0x6c, 0x22, 0xa3, 0x01, 0x0a, 0x12,
-	0x53, 0x68, 0x6f, 0x72, 0x74, 0x65, 0x6e, 0x42, 0x61, 0x74, 0x63, 0x68, 0x55, 0x52, 0x4c, 0x52,
-	0x65, 0x73, 0x12, 0x3d, 0x0a, 0x04, 0x75, 0x72, 0x6c, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b,
-	0x32, 0x29, 0x2e, 0x75, 0x72, 0x6c, 0x73, 0x68, 0x6f, 0x72, 0x74, 0x65, 0x6e, 0x65, 0x72, 0x2e,
-	0x53, 0x68, 0x6f, 0x72, 0x74, 0x65, 0x6e, 0x42, 0x61, 0x74, 0x63, 0x68, 0x55, 0x52, 0x4c, 0x52,
-	0x65, 0x73, 0x2e, 0x42, 0x61, 0x74, 0x63, 0x68, 0x55, 0x52, 0x4c, 0x52, 0x04, 0x75, 0x72, 0x6c,
-	0x73, 0x1a, 0x4e, 0x0a, 0x08, 0x42, 0x61, 0x74, 0x63, 0x68, 0x55, 0x52, 0x4c, 0x12, 0x25, 0x0a,
-	0x0e, 0x63, 0x6f, 0x72, 0x72, 0x65, 0x6c, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x69, 0x64, 0x18,
-	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0d, 0x63, 0x6f, 0x72, 0x72, 0x65, 0x6c, 0x61, 0x74, 0x69,
-	0x6f, 0x6e, 0x49, 0x64, 0x12, 0x1b, 0x0a, 0x09, 0x73, 0x68, 0x6f, 0x72, 0x74, 0x5f, 0x75, 0x72,
-	0x6c, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x73, 0x68, 0x6f, 0x72, 0x74, 0x55, 0x72,
-	0x6c, 0x22, 0x22, 0x0a, 0x09, 0x47, 0x65, 0x74, 0x55, 0x52, 0x4c, 0x52, 0x65, 0x71, 0x12, 0x15,
-	0x0a, 0x06, 0x75, 0x72, 0x6c, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05,
-	0x75, 0x72, 0x6c, 0x49, 0x64, 0x22, 0x2e, 0x0a, 0x09, 0x47, 0x65, 0x74, 0x55, 0x52, 0x4c, 0x52,
-	0x65, 0x73, 0x12, 0x21, 0x0a, 0x0c, 0x6f, 0x72, 0x69, 0x67, 0x69, 0x6e, 0x61, 0x6c, 0x5f, 0x75,
-	0x72, 0x6c, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x6f, 0x72, 0x69, 0x67, 0x69, 0x6e,
-	0x61, 0x6c, 0x55, 0x72, 0x6c, 0x22, 0x11, 0x0a, 0x0f, 0x47, 0x65, 0x74, 0x55, 0x73, 0x65, 0x72,
-	0x73, 0x55, 0x52, 0x4c, 0x73, 0x52, 0x65, 0x71, 0x22, 0x97, 0x01, 0x0a, 0x0f, 0x47, 0x65, 0x74,
-	0x55, 0x73, 0x65, 0x72, 0x73, 0x55, 0x52, 0x4c, 0x73, 0x52, 0x65, 0x73, 0x12, 0x39, 0x0a, 0x04,
-	0x75, 0x72, 0x6c, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x25, 0x2e, 0x75, 0x72, 0x6c,
-	0x73, 0x68, 0x6f, 0x72, 0x74, 0x65, 0x6e, 0x65, 0x72, 0x2e, 0x47, 0x65, 0x74, 0x55, 0x73, 0x65,
-	0x72, 0x73, 0x55, 0x52, 0x4c, 0x73, 0x52, 0x65, 0x73, 0x2e, 0x55, 0x73, 0x65, 0x72, 0x55, 0x52,
-	0x4c, 0x52, 0x04, 0x75, 0x72, 0x6c, 0x73, 0x1a, 0x49, 0x0a, 0x07, 0x55, 0x73, 0x65, 0x72, 0x55,
-	0x52, 0x4c, 0x12, 0x21, 0x0a, 0x0c, 0x6f, 0x72, 0x69, 0x67, 0x69, 0x6e, 0x61, 0x6c, 0x5f, 0x75,
-	0x72, 0x6c, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x6f, 0x72, 0x69, 0x67, 0x69, 0x6e,
-	0x61, 0x6c, 0x55, 0x72, 0x6c, 0x12, 0x1b, 0x0a, 0x09, 0x73, 0x68, 0x6f, 0x72, 0x74, 0x5f, 0x75,
-	0x72, 0x6c, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x73, 0x68, 0x6f, 0x72, 0x74, 0x55,
-	0x72, 0x6c, 0x22, 0x27, 0x0a, 0x11, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x55, 0x73, 0x65, 0x72,
-	0x55, 0x52, 0x4c, 0x73, 0x52, 0x65, 0x71, 0x12, 0x12, 0x0a, 0x04, 0x75, 0x72, 0x6c, 0x73, 0x18,
-	0x01, 0x20, 0x03, 0x28, 0x09, 0x52, 0x04, 0x75, 0x72, 0x6c, 0x73, 0x22, 0x13, 0x0a, 0x11, 0x44,
-	0x65, 0x6c, 0x65, 0x74, 0x65, 0x55, 0x73, 0x65, 0x72, 0x55, 0x52, 0x4c, 0x73, 0x52, 0x65, 0x73,
-	0x22, 0x0d, 0x0a, 0x0b, 0x47, 0x65, 0x74, 0x53, 0x74, 0x61, 0x74, 0x73, 0x52, 0x65, 0x71, 0x22,
-	0x37, 0x0a, 0x0b, 0x47, 0x65, 0x74, 0x53, 0x74, 0x61, 0x74, 0x73, 0x52, 0x65, 0x73, 0x12, 0x12,
-	0x0a, 0x04, 0x75, 0x72, 0x6c, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x04, 0x75, 0x72,
-	0x6c, 0x73, 0x12, 0x14, 0x0a, 0x05, 0x75, 0x73, 0x65, 0x72, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28,
-	0x05, 0x52, 0x05, 0x75, 0x73, 0x65, 0x72, 0x73, 0x22, 0x09, 0x0a, 0x07, 0x50, 0x69, 0x6e, 0x67,
-	0x52, 0x65, 0x71, 0x22, 0x09, 0x0a, 0x07, 0x50, 0x69, 0x6e, 0x67, 0x52, 0x65, 0x73, 0x32, 0x82,
-	0x04, 0x0a, 0x0c, 0x55, 0x52, 0x4c, 0x53, 0x68, 0x6f, 0x72, 0x74, 0x65, 0x6e, 0x65, 0x72, 0x12,
-	0x46, 0x0a, 0x0a, 0x53, 0x68, 0x6f, 0x72, 0x74, 0x65, 0x6e, 0x55, 0x52, 0x4c, 0x12, 0x1b, 0x2e,
-	0x75, 0x72, 0x6c, 0x73, 0x68, 0x6f, 0x72, 0x74, 0x65, 0x6e, 0x65, 0x72, 0x2e, 0x53, 0x68, 0x6f,
-	0x72, 0x74, 0x65, 0x6e, 0x55, 0x52, 0x4c, 0x52, 0x65, 0x71, 0x1a, 0x1b, 0x2e, 0x75, 0x72, 0x6c,
-	0x73, 0x68, 0x6f, 0x72, 0x74, 0x65, 0x6e, 0x65, 0x72, 0x2e, 0x53, 0x68, 0x6f, 0x72, 0x74, 0x65,
-	0x6e, 0x55, 0x52, 0x4c, 0x52, 0x65, 0x73, 0x12, 0x55, 0x0a, 0x0f, 0x53, 0x68, 0x6f, 0x72, 0x74,
-	0x65, 0x6e, 0x42, 0x61, 0x74, 0x63, 0x68, 0x55, 0x52, 0x4c, 0x12, 0x20, 0x2e, 0x75, 0x72, 0x6c,
-	0x73, 0x68, 0x6f, 0x72, 0x74, 0x65, 0x6e, 0x65, 0x72, 0x2e, 0x53, 0x68, 0x6f, 0x72, 0x74, 0x65,
-	0x6e, 0x42, 0x61, 0x74, 0x63, 0x68, 0x55, 0x52, 0x4c, 0x52, 0x65, 0x71, 0x1a, 0x20, 0x2e, 0x75,
-	0x72, 0x6c, 0x73, 0x68, 0x6f, 0x72, 0x74, 0x65, 0x6e, 0x65, 0x72, 0x2e, 0x53, 0x68, 0x6f, 0x72,
-	0x74, 0x65, 0x6e, 0x42, 0x61, 0x74, 0x63, 0x68, 0x55, 0x52, 0x4c, 0x52, 0x65, 0x73, 0x12, 0x3a,
-	0x0a, 0x06, 0x47, 0x65, 0x74, 0x55, 0x52, 0x4c, 0x12, 0x17, 0x2e, 0x75, 0x72, 0x6c, 0x73, 0x68,
-	0x6f, 0x72, 0x74, 0x65, 0x6e, 0x65, 0x72, 0x2e, 0x47, 0x65, 0x74, 0x55, 0x52, 0x4c, 0x52, 0x65,
-	0x71, 0x1a, 0x17, 0x2e, 0x75, 0x72, 0x6c, 0x73, 0x68, 0x6f, 0x72, 0x74, 0x65, 0x6e, 0x65, 0x72,
-	0x2e, 0x47, 0x65, 0x74, 0x55, 0x52, 0x4c, 0x52, 0x65, 0x73, 0x12, 0x4b, 0x0a, 0x0b, 0x47, 0x65,
-	0x74, 0x55, 0x73, 0x65, 0x72, 0x55, 0x52, 0x4c, 0x73, 0x12, 0x1d, 0x2e, 0x75, 0x72, 0x6c, 0x73,
-	0x68, 0x6f, 0x72, 0x74, 0x65, 0x6e, 0x65, 0x72, 0x2e, 0x47, 0x65, 0x74, 0x55, 0x73, 0x65, 0x72,
-	0x73, 0x55, 0x52, 0x4c, 0x73, 0x52, 0x65, 0x71, 0x1a, 0x1d, 0x2e, 0x75, 0x72, 0x6c, 0x73, 0x68,
-	0x6f, 0x72, 0x74, 0x65, 0x6e, 0x65, 0x72, 0x2e, 0x47, 0x65, 0x74, 0x55, 0x73, 0x65, 0x72, 0x73,
-	0x55, 0x52, 0x4c, 0x73, 0x52, 0x65, 0x73, 0x12, 0x52, 0x0a, 0x0e, 0x44, 0x65, 0x6c, 0x65, 0x74,
-	0x65, 0x55, 0x73, 0x65, 0x72, 0x55, 0x52, 0x4c, 0x73, 0x12, 0x1f, 0x2e, 0x75, 0x72, 0x6c, 0x73,
-	0x68, 0x6f, 0x72, 0x74, 0x65, 0x6e, 0x65, 0x72, 0x2e, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x55,
-	0x73, 0x65, 0x72, 0x55, 0x52, 0x4c, 0x73, 0x52, 0x65, 0x71, 0x1a, 0x1f, 0x2e, 0x75, 0x72, 0x6c,
-	0x73, 0x68, 0x6f, 0x72, 0x74, 0x65, 0x6e, 0x65, 0x72, 0x2e, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65,
-	0x55, 0x73, 0x65, 0x72, 0x55, 0x52, 0x4c, 0x73, 0x52, 0x65, 0x73, 0x12, 0x40, 0x0a, 0x08, 0x47,
-	0x65, 0x74, 0x53, 0x74, 0x61, 0x74, 0x73, 0x12, 0x19, 0x2e, 0x75, 0x72, 0x6c, 0x73, 0x68, 0x6f,
-	0x72, 0x74, 0x65, 0x6e, 0x65, 0x72, 0x2e, 0x47, 0x65, 0x74, 0x53, 0x74, 0x61, 0x74, 0x73, 0x52,
-	0x65, 0x71, 0x1a, 0x19, 0x2e, 0x75, 0x72, 0x6c, 0x73, 0x68, 0x6f, 0x72, 0x74, 0x65, 0x6e, 0x65,
-	0x72, 0x2e, 0x47, 0x65, 0x74, 0x53, 0x74, 0x61, 0x74, 0x73, 0x52, 0x65, 0x73, 0x12, 0x34, 0x0a,
-	0x04, 0x50, 0x69, 0x6e, 0x67, 0x12, 0x15, 0x2e, 0x75, 0x72, 0x6c, 0x73, 0x68, 0x6f, 0x72, 0x74,
-	0x65, 0x6e, 0x65, 0x72, 0x2e, 0x50, 0x69, 0x6e, 0x67, 0x52, 0x65, 0x71, 0x1a, 0x15, 0x2e, 0x75,
-	0x72, 0x6c, 0x73, 0x68, 0x6f, 0x72, 0x74, 0x65, 0x6e, 0x65, 0x72, 0x2e, 0x50, 0x69, 0x6e, 0x67,
-	0x52, 0x65, 0x73, 0x42, 0x36, 0x5a, 0x34, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f,
-	0x6d, 0x2f, 0x70, 0x69, 0x6e, 0x62, 0x72, 0x61, 0x69, 0x6e, 0x2f, 0x75, 0x72, 0x6c, 0x73, 0x68,
-	0x6f, 0x72, 0x74, 0x65, 0x6e, 0x65, 0x72, 0x2f, 0x69, 0x6e, 0x74, 0x65, 0x72, 0x6e, 0x61, 0x6c,
-	0x2f, 0x67, 0x72, 0x70, 0x63, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x06, 0x70, 0x72, 0x6f,
-	0x74, 0x6f, 0x33,
+	Urls []string `protobuf:"bytes,1,rep,name=urls,proto3" json:"urls,omitempty"`
 }
 
-var (
-	file_internal_grpc_server_proto_urlshortener_proto_rawDescOnce sync.Once
-	file_internal_grpc_server_proto_urlshortener_proto_rawDescData = file_internal_grpc_server_proto_urlshortener_proto_rawDesc
-)
+func (x *DeleteUserURLsReq) Reset() {
+	*x = DeleteUserURLsReq{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_internal_grpc_server_proto_urlshortener_proto_msgTypes[17]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
 
-func file_internal_grpc_server_proto_urlshortener_proto_rawDescGZIP() []byte {
-	file_internal_grpc_server_proto_urlshortener_proto_rawDescOnce.Do(func() {
-		file_internal_grpc_server_proto_urlshortener_proto_rawDescData = protoimpl.X.CompressGZIP(file_internal_grpc_server_proto_urlshortener_proto_rawDescData)
-	})
-	return file_internal_grpc_server_proto_urlshortener_proto_rawDescData
+func (x *DeleteUserURLsReq) String() string {
+	return protoimpl.X.MessageStringOf(x)
 }
 
-var file_internal_grpc_server_proto_urlshortener_proto_msgTypes = make([]protoimpl.MessageInfo, 17)
+func (*DeleteUserURLsReq) ProtoMessage() {}
+
+func (x *DeleteUserURLsReq) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_grpc_server_proto_urlshortener_proto_msgTypes[17]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteUserURLsReq.ProtoReflect.Descriptor instead.
+func (*DeleteUserURLsReq) Descriptor() ([]byte, []int) {
+	return file_internal_grpc_server_proto_urlshortener_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *DeleteUserURLsReq) GetUrls() []string {
+	if x != nil {
+		return x.Urls
+	}
+	return nil
+}
+
+type DeleteUserURLsRes struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *DeleteUserURLsRes) Reset() {
+	*x = DeleteUserURLsRes{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_internal_grpc_server_proto_urlshortener_proto_msgTypes[18]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *DeleteUserURLsRes) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteUserURLsRes) ProtoMessage() {}
+
+func (x *DeleteUserURLsRes) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_grpc_server_proto_urlshortener_proto_msgTypes[18]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteUserURLsRes.ProtoReflect.Descriptor instead.
+func (*DeleteUserURLsRes) Descriptor() ([]byte, []int) {
+	return file_internal_grpc_server_proto_urlshortener_proto_rawDescGZIP(), []int{18}
+}
+
+type GetURLStatsReq struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	UrlId string `protobuf:"bytes,1,opt,name=url_id,json=urlId,proto3" json:"url_id,omitempty"`
+}
+
+func (x *GetURLStatsReq) Reset() {
+	*x = GetURLStatsReq{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_internal_grpc_server_proto_urlshortener_proto_msgTypes[19]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetURLStatsReq) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetURLStatsReq) ProtoMessage() {}
+
+func (x *GetURLStatsReq) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_grpc_server_proto_urlshortener_proto_msgTypes[19]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetURLStatsReq.ProtoReflect.Descriptor instead.
+func (*GetURLStatsReq) Descriptor() ([]byte, []int) {
+	return file_internal_grpc_server_proto_urlshortener_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *GetURLStatsReq) GetUrlId() string {
+	if x != nil {
+		return x.UrlId
+	}
+	return ""
+}
+
+type GetURLStatsRes struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	TotalClicks    int32                       `protobuf:"varint,1,opt,name=total_clicks,json=totalClicks,proto3" json:"total_clicks,omitempty"`
+	UniqueVisitors int32                       `protobuf:"varint,2,opt,name=unique_visitors,json=uniqueVisitors,proto3" json:"unique_visitors,omitempty"`
+	ClicksPerDay   []*GetURLStatsRes_DayClicks `protobuf:"bytes,3,rep,name=clicks_per_day,json=clicksPerDay,proto3" json:"clicks_per_day,omitempty"`
+	TopReferrers   []*GetURLStatsRes_TopValue  `protobuf:"bytes,4,rep,name=top_referrers,json=topReferrers,proto3" json:"top_referrers,omitempty"`
+	TopUserAgents  []*GetURLStatsRes_TopValue  `protobuf:"bytes,5,rep,name=top_user_agents,json=topUserAgents,proto3" json:"top_user_agents,omitempty"`
+}
+
+func (x *GetURLStatsRes) Reset() {
+	*x = GetURLStatsRes{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_internal_grpc_server_proto_urlshortener_proto_msgTypes[20]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetURLStatsRes) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetURLStatsRes) ProtoMessage() {}
+
+func (x *GetURLStatsRes) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_grpc_server_proto_urlshortener_proto_msgTypes[20]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetURLStatsRes.ProtoReflect.Descriptor instead.
+func (*GetURLStatsRes) Descriptor() ([]byte, []int) {
+	return file_internal_grpc_server_proto_urlshortener_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *GetURLStatsRes) GetTotalClicks() int32 {
+	if x != nil {
+		return x.TotalClicks
+	}
+	return 0
+}
+
+func (x *GetURLStatsRes) GetUniqueVisitors() int32 {
+	if x != nil {
+		return x.UniqueVisitors
+	}
+	return 0
+}
+
+func (x *GetURLStatsRes) GetClicksPerDay() []*GetURLStatsRes_DayClicks {
+	if x != nil {
+		return x.ClicksPerDay
+	}
+	return nil
+}
+
+func (x *GetURLStatsRes) GetTopReferrers() []*GetURLStatsRes_TopValue {
+	if x != nil {
+		return x.TopReferrers
+	}
+	return nil
+}
+
+func (x *GetURLStatsRes) GetTopUserAgents() []*GetURLStatsRes_TopValue {
+	if x != nil {
+		return x.TopUserAgents
+	}
+	return nil
+}
+
+type GetStatsReq struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *GetStatsReq) Reset() {
+	*x = GetStatsReq{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_internal_grpc_server_proto_urlshortener_proto_msgTypes[21]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetStatsReq) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetStatsReq) ProtoMessage() {}
+
+func (x *GetStatsReq) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_grpc_server_proto_urlshortener_proto_msgTypes[21]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetStatsReq.ProtoReflect.Descriptor instead.
+func (*GetStatsReq) Descriptor() ([]byte, []int) {
+	return file_internal_grpc_server_proto_urlshortener_proto_rawDescGZIP(), []int{21}
+}
+
+type GetStatsRes struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Urls       int32 `protobuf:"varint,1,opt,name=urls,proto3" json:"urls,omitempty"`
+	Users      int32 `protobuf:"varint,2,opt,name=users,proto3" json:"users,omitempty"`
+	PurgedUrls int32 `protobuf:"varint,3,opt,name=purged_urls,json=purgedUrls,proto3" json:"purged_urls,omitempty"`
+}
+
+func (x *GetStatsRes) Reset() {
+	*x = GetStatsRes{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_internal_grpc_server_proto_urlshortener_proto_msgTypes[22]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetStatsRes) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetStatsRes) ProtoMessage() {}
+
+func (x *GetStatsRes) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_grpc_server_proto_urlshortener_proto_msgTypes[22]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetStatsRes.ProtoReflect.Descriptor instead.
+func (*GetStatsRes) Descriptor() ([]byte, []int) {
+	return file_internal_grpc_server_proto_urlshortener_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *GetStatsRes) GetUrls() int32 {
+	if x != nil {
+		return x.Urls
+	}
+	return 0
+}
+
+func (x *GetStatsRes) GetUsers() int32 {
+	if x != nil {
+		return x.Users
+	}
+	return 0
+}
+
+func (x *GetStatsRes) GetPurgedUrls() int32 {
+	if x != nil {
+		return x.PurgedUrls
+	}
+	return 0
+}
+
+type PingReq struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *PingReq) Reset() {
+	*x = PingReq{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_internal_grpc_server_proto_urlshortener_proto_msgTypes[23]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *PingReq) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PingReq) ProtoMessage() {}
+
+func (x *PingReq) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_grpc_server_proto_urlshortener_proto_msgTypes[23]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PingReq.ProtoReflect.Descriptor instead.
+func (*PingReq) Descriptor() ([]byte, []int) {
+	return file_internal_grpc_server_proto_urlshortener_proto_rawDescGZIP(), []int{23}
+}
+
+type PingRes struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+}
+
+func (x *PingRes) Reset() {
+	*x = PingRes{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_internal_grpc_server_proto_urlshortener_proto_msgTypes[24]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *PingRes) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PingRes) ProtoMessage() {}
+
+func (x *PingRes) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_grpc_server_proto_urlshortener_proto_msgTypes[24]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PingRes.ProtoReflect.Descriptor instead.
+func (*PingRes) Descriptor() ([]byte, []int) {
+	return file_internal_grpc_server_proto_urlshortener_proto_rawDescGZIP(), []int{24}
+}
+
+type ShortenBatchURLReq_BatchURL struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	CorrelationId string                 `protobuf:"bytes,1,opt,name=correlation_id,json=correlationId,proto3" json:"correlation_id,omitempty"`
+	OriginalUrl   string                 `protobuf:"bytes,2,opt,name=original_url,json=originalUrl,proto3" json:"original_url,omitempty"`
+	Alias         string                 `protobuf:"bytes,3,opt,name=alias,proto3" json:"alias,omitempty"`
+	ExpiresAt     *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`
+	TtlSeconds    int64                  `protobuf:"varint,5,opt,name=ttl_seconds,json=ttlSeconds,proto3" json:"ttl_seconds,omitempty"`
+	MaxClicks     int32                  `protobuf:"varint,6,opt,name=max_clicks,json=maxClicks,proto3" json:"max_clicks,omitempty"`
+	Password      string                 `protobuf:"bytes,7,opt,name=password,proto3" json:"password,omitempty"`
+	Tags          []string               `protobuf:"bytes,8,rep,name=tags,proto3" json:"tags,omitempty"`
+}
+
+func (x *ShortenBatchURLReq_BatchURL) Reset() {
+	*x = ShortenBatchURLReq_BatchURL{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_internal_grpc_server_proto_urlshortener_proto_msgTypes[25]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ShortenBatchURLReq_BatchURL) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ShortenBatchURLReq_BatchURL) ProtoMessage() {}
+
+func (x *ShortenBatchURLReq_BatchURL) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_grpc_server_proto_urlshortener_proto_msgTypes[25]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ShortenBatchURLReq_BatchURL.ProtoReflect.Descriptor instead.
+func (*ShortenBatchURLReq_BatchURL) Descriptor() ([]byte, []int) {
+	return file_internal_grpc_server_proto_urlshortener_proto_rawDescGZIP(), []int{2, 0}
+}
+
+func (x *ShortenBatchURLReq_BatchURL) GetCorrelationId() string {
+	if x != nil {
+		return x.CorrelationId
+	}
+	return ""
+}
+
+func (x *ShortenBatchURLReq_BatchURL) GetOriginalUrl() string {
+	if x != nil {
+		return x.OriginalUrl
+	}
+	return ""
+}
+
+func (x *ShortenBatchURLReq_BatchURL) GetAlias() string {
+	if x != nil {
+		return x.Alias
+	}
+	return ""
+}
+
+func (x *ShortenBatchURLReq_BatchURL) GetExpiresAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return nil
+}
+
+func (x *ShortenBatchURLReq_BatchURL) GetTtlSeconds() int64 {
+	if x != nil {
+		return x.TtlSeconds
+	}
+	return 0
+}
+
+func (x *ShortenBatchURLReq_BatchURL) GetMaxClicks() int32 {
+	if x != nil {
+		return x.MaxClicks
+	}
+	return 0
+}
+
+func (x *ShortenBatchURLReq_BatchURL) GetPassword() string {
+	if x != nil {
+		return x.Password
+	}
+	return ""
+}
+
+func (x *ShortenBatchURLReq_BatchURL) GetTags() []string {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
+type ShortenBatchURLRes_BatchURL struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	CorrelationId string `protobuf:"bytes,1,opt,name=correlation_id,json=correlationId,proto3" json:"correlation_id,omitempty"`
+	ShortUrl      string `protobuf:"bytes,2,opt,name=short_url,json=shortUrl,proto3" json:"short_url,omitempty"`
+}
+
+func (x *ShortenBatchURLRes_BatchURL) Reset() {
+	*x = ShortenBatchURLRes_BatchURL{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_internal_grpc_server_proto_urlshortener_proto_msgTypes[26]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ShortenBatchURLRes_BatchURL) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ShortenBatchURLRes_BatchURL) ProtoMessage() {}
+
+func (x *ShortenBatchURLRes_BatchURL) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_grpc_server_proto_urlshortener_proto_msgTypes[26]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ShortenBatchURLRes_BatchURL.ProtoReflect.Descriptor instead.
+func (*ShortenBatchURLRes_BatchURL) Descriptor() ([]byte, []int) {
+	return file_internal_grpc_server_proto_urlshortener_proto_rawDescGZIP(), []int{3, 0}
+}
+
+func (x *ShortenBatchURLRes_BatchURL) GetCorrelationId() string {
+	if x != nil {
+		return x.CorrelationId
+	}
+	return ""
+}
+
+func (x *ShortenBatchURLRes_BatchURL) GetShortUrl() string {
+	if x != nil {
+		return x.ShortUrl
+	}
+	return ""
+}
+
+type GetUsersURLsRes_UserURL struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	OriginalUrl string   `protobuf:"bytes,1,opt,name=original_url,json=originalUrl,proto3" json:"original_url,omitempty"`
+	ShortUrl    string   `protobuf:"bytes,2,opt,name=short_url,json=shortUrl,proto3" json:"short_url,omitempty"`
+	Tags        []string `protobuf:"bytes,3,rep,name=tags,proto3" json:"tags,omitempty"`
+}
+
+func (x *GetUsersURLsRes_UserURL) Reset() {
+	*x = GetUsersURLsRes_UserURL{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_internal_grpc_server_proto_urlshortener_proto_msgTypes[27]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetUsersURLsRes_UserURL) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetUsersURLsRes_UserURL) ProtoMessage() {}
+
+func (x *GetUsersURLsRes_UserURL) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_grpc_server_proto_urlshortener_proto_msgTypes[27]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetUsersURLsRes_UserURL.ProtoReflect.Descriptor instead.
+func (*GetUsersURLsRes_UserURL) Descriptor() ([]byte, []int) {
+	return file_internal_grpc_server_proto_urlshortener_proto_rawDescGZIP(), []int{7, 0}
+}
+
+func (x *GetUsersURLsRes_UserURL) GetOriginalUrl() string {
+	if x != nil {
+		return x.OriginalUrl
+	}
+	return ""
+}
+
+func (x *GetUsersURLsRes_UserURL) GetShortUrl() string {
+	if x != nil {
+		return x.ShortUrl
+	}
+	return ""
+}
+
+func (x *GetUsersURLsRes_UserURL) GetTags() []string {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
+type GetURLStatsRes_DayClicks struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Day    string `protobuf:"bytes,1,opt,name=day,proto3" json:"day,omitempty"`
+	Clicks int32  `protobuf:"varint,2,opt,name=clicks,proto3" json:"clicks,omitempty"`
+}
+
+func (x *GetURLStatsRes_DayClicks) Reset() {
+	*x = GetURLStatsRes_DayClicks{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_internal_grpc_server_proto_urlshortener_proto_msgTypes[28]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetURLStatsRes_DayClicks) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetURLStatsRes_DayClicks) ProtoMessage() {}
+
+func (x *GetURLStatsRes_DayClicks) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_grpc_server_proto_urlshortener_proto_msgTypes[28]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetURLStatsRes_DayClicks.ProtoReflect.Descriptor instead.
+func (*GetURLStatsRes_DayClicks) Descriptor() ([]byte, []int) {
+	return file_internal_grpc_server_proto_urlshortener_proto_rawDescGZIP(), []int{20, 0}
+}
+
+func (x *GetURLStatsRes_DayClicks) GetDay() string {
+	if x != nil {
+		return x.Day
+	}
+	return ""
+}
+
+func (x *GetURLStatsRes_DayClicks) GetClicks() int32 {
+	if x != nil {
+		return x.Clicks
+	}
+	return 0
+}
+
+type GetURLStatsRes_TopValue struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Value string `protobuf:"bytes,1,opt,name=value,proto3" json:"value,omitempty"`
+	Count int32  `protobuf:"varint,2,opt,name=count,proto3" json:"count,omitempty"`
+}
+
+func (x *GetURLStatsRes_TopValue) Reset() {
+	*x = GetURLStatsRes_TopValue{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_internal_grpc_server_proto_urlshortener_proto_msgTypes[29]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *GetURLStatsRes_TopValue) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetURLStatsRes_TopValue) ProtoMessage() {}
+
+func (x *GetURLStatsRes_TopValue) ProtoReflect() protoreflect.Message {
+	mi := &file_internal_grpc_server_proto_urlshortener_proto_msgTypes[29]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetURLStatsRes_TopValue.ProtoReflect.Descriptor instead.
+func (*GetURLStatsRes_TopValue) Descriptor() ([]byte, []int) {
+	return file_internal_grpc_server_proto_urlshortener_proto_rawDescGZIP(), []int{20, 1}
+}
+
+func (x *GetURLStatsRes_TopValue) GetValue() string {
+	if x != nil {
+		return x.Value
+	}
+	return ""
+}
+
+func (x *GetURLStatsRes_TopValue) GetCount() int32 {
+	if x != nil {
+		return x.Count
+	}
+	return 0
+}
+
+var File_internal_grpc_server_proto_urlshortener_proto protoreflect.FileDescriptor
+
+var file_internal_grpc_server_proto_urlshortener_proto_rawDesc = []byte{
+	0x0a, 0x2d, 0x69, 0x6e, 0x74, 0x65, 0x72, 0x6e, 0x61, 0x6c, 0x2f, 0x67, 0x72, 0x70, 0x63, 0x5f,
+	0x73, 0x65, 0x72, 0x76, 0x65, 0x72, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x2f, 0x75, 0x72, 0x6c,
+	0x73, 0x68, 0x6f, 0x72, 0x74, 0x65, 0x6e, 0x65, 0x72, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12,
+	0x0c, 0x75, 0x72, 0x6c, 0x73, 0x68, 0x6f, 0x72, 0x74, 0x65, 0x6e, 0x65, 0x72, 0x1a, 0x1f, 0x67,
+	0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2f, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2f, 0x74,
+	0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0xf3,
+	0x01, 0x0a, 0x0d, 0x53, 0x68, 0x6f, 0x72, 0x74, 0x65, 0x6e, 0x55, 0x52, 0x4c, 0x52, 0x65, 0x71,
+	0x12, 0x21, 0x0a, 0x0c, 0x6f, 0x72, 0x69, 0x67, 0x69, 0x6e, 0x61, 0x6c, 0x5f, 0x75, 0x72, 0x6c,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x6f, 0x72, 0x69, 0x67, 0x69, 0x6e, 0x61, 0x6c,
+	0x55, 0x72, 0x6c, 0x12, 0x14, 0x0a, 0x05, 0x61, 0x6c, 0x69, 0x61, 0x73, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x05, 0x61, 0x6c, 0x69, 0x61, 0x73, 0x12, 0x39, 0x0a, 0x0a, 0x65, 0x78, 0x70,
+	0x69, 0x72, 0x65, 0x73, 0x5f, 0x61, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e,
+	0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e,
+	0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x09, 0x65, 0x78, 0x70, 0x69, 0x72,
+	0x65, 0x73, 0x41, 0x74, 0x12, 0x1f, 0x0a, 0x0b, 0x74, 0x74, 0x6c, 0x5f, 0x73, 0x65, 0x63, 0x6f,
+	0x6e, 0x64, 0x73, 0x18, 0x04, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0a, 0x74, 0x74, 0x6c, 0x53, 0x65,
+	0x63, 0x6f, 0x6e, 0x64, 0x73, 0x12, 0x1d, 0x0a, 0x0a, 0x6d, 0x61, 0x78, 0x5f, 0x63, 0x6c, 0x69,
+	0x63, 0x6b, 0x73, 0x18, 0x05, 0x20, 0x01, 0x28, 0x05, 0x52, 0x09, 0x6d, 0x61, 0x78, 0x43, 0x6c,
+	0x69, 0x63, 0x6b, 0x73, 0x12, 0x1a, 0x0a, 0x08, 0x70, 0x61, 0x73, 0x73, 0x77, 0x6f, 0x72, 0x64,
+	0x18, 0x06, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x70, 0x61, 0x73, 0x73, 0x77, 0x6f, 0x72, 0x64,
+	0x12, 0x12, 0x0a, 0x04, 0x74, 0x61, 0x67, 0x73, 0x18, 0x07, 0x20, 0x03, 0x28, 0x09, 0x52, 0x04,
+	0x74, 0x61, 0x67, 0x73, 0x22, 0x2c, 0x0a, 0x0d, 0x53, 0x68, 0x6f, 0x72, 0x74, 0x65, 0x6e, 0x55,
+	0x52, 0x4c, 0x52, 0x65, 0x73, 0x12, 0x1b, 0x0a, 0x09, 0x73, 0x68, 0x6f, 0x72, 0x74, 0x5f, 0x75,
+	0x72, 0x6c, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x73, 0x68, 0x6f, 0x72, 0x74, 0x55,
+	0x72, 0x6c, 0x22, 0xeb, 0x02, 0x0a, 0x12, 0x53, 0x68, 0x6f, 0x72, 0x74, 0x65, 0x6e, 0x42, 0x61,
+	0x74, 0x63, 0x68, 0x55, 0x52, 0x4c, 0x52, 0x65, 0x71, 0x12, 0x3d, 0x0a, 0x04, 0x75, 0x72, 0x6c,
+	0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x29, 0x2e, 0x75, 0x72, 0x6c, 0x73, 0x68, 0x6f,
+	0x72, 0x74, 0x65, 0x6e, 0x65, 0x72, 0x2e, 0x53, 0x68, 0x6f, 0x72, 0x74, 0x65, 0x6e, 0x42, 0x61,
+	0x74, 0x63, 0x68, 0x55, 0x52, 0x4c, 0x52, 0x65, 0x71, 0x2e, 0x42, 0x61, 0x74, 0x63, 0x68, 0x55,
+	0x52, 0x4c, 0x52, 0x04, 0x75, 0x72, 0x6c, 0x73, 0x1a, 0x95, 0x02, 0x0a, 0x08, 0x42, 0x61, 0x74,
+	0x63, 0x68, 0x55, 0x52, 0x4c, 0x12, 0x25, 0x0a, 0x0e, 0x63, 0x6f, 0x72, 0x72, 0x65, 0x6c, 0x61,
+	0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0d, 0x63,
+	0x6f, 0x72, 0x72, 0x65, 0x6c, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x49, 0x64, 0x12, 0x21, 0x0a, 0x0c,
+	0x6f, 0x72, 0x69, 0x67, 0x69, 0x6e, 0x61, 0x6c, 0x5f, 0x75, 0x72, 0x6c, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x0b, 0x6f, 0x72, 0x69, 0x67, 0x69, 0x6e, 0x61, 0x6c, 0x55, 0x72, 0x6c, 0x12,
+	0x14, 0x0a, 0x05, 0x61, 0x6c, 0x69, 0x61, 0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05,
+	0x61, 0x6c, 0x69, 0x61, 0x73, 0x12, 0x39, 0x0a, 0x0a, 0x65, 0x78, 0x70, 0x69, 0x72, 0x65, 0x73,
+	0x5f, 0x61, 0x74, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67,
+	0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65,
+	0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x09, 0x65, 0x78, 0x70, 0x69, 0x72, 0x65, 0x73, 0x41, 0x74,
+	0x12, 0x1f, 0x0a, 0x0b, 0x74, 0x74, 0x6c, 0x5f, 0x73, 0x65, 0x63, 0x6f, 0x6e, 0x64, 0x73, 0x18,
+	0x05, 0x20, 0x01, 0x28, 0x03, 0x52, 0x0a, 0x74, 0x74, 0x6c, 0x53, 0x65, 0x63, 0x6f, 0x6e, 0x64,
+	0x73, 0x12, 0x1d, 0x0a, 0x0a, 0x6d, 0x61, 0x78, 0x5f, 0x63, 0x6c, 0x69, 0x63, 0x6b, 0x73, 0x18,
+	0x06, 0x20, 0x01, 0x28, 0x05, 0x52, 0x09, 0x6d, 0x61, 0x78, 0x43, 0x6c, 0x69, 0x63, 0x6b, 0x73,
+	0x12, 0x1a, 0x0a, 0x08, 0x70, 0x61, 0x73, 0x73, 0x77, 0x6f, 0x72, 0x64, 0x18, 0x07, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x08, 0x70, 0x61, 0x73, 0x73, 0x77, 0x6f, 0x72, 0x64, 0x12, 0x12, 0x0a, 0x04,
+	0x74, 0x61, 0x67, 0x73, 0x18, 0x08, 0x20, 0x03, 0x28, 0x09, 0x52, 0x04, 0x74, 0x61, 0x67, 0x73,
+	0x22, 0xa3, 0x01, 0x0a, 0x12, 0x53, 0x68, 0x6f, 0x72, 0x74, 0x65, 0x6e, 0x42, 0x61, 0x74, 0x63,
+	0x68, 0x55, 0x52, 0x4c, 0x52, 0x65, 0x73, 0x12, 0x3d, 0x0a, 0x04, 0x75, 0x72, 0x6c, 0x73, 0x18,
+	0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x29, 0x2e, 0x75, 0x72, 0x6c, 0x73, 0x68, 0x6f, 0x72, 0x74,
+	0x65, 0x6e, 0x65, 0x72, 0x2e, 0x53, 0x68, 0x6f, 0x72, 0x74, 0x65, 0x6e, 0x42, 0x61, 0x74, 0x63,
+	0x68, 0x55, 0x52, 0x4c, 0x52, 0x65, 0x73, 0x2e, 0x42, 0x61, 0x74, 0x63, 0x68, 0x55, 0x52, 0x4c,
+	0x52, 0x04, 0x75, 0x72, 0x6c, 0x73, 0x1a, 0x4e, 0x0a, 0x08, 0x42, 0x61, 0x74, 0x63, 0x68, 0x55,
+	0x52, 0x4c, 0x12, 0x25, 0x0a, 0x0e, 0x63, 0x6f, 0x72, 0x72, 0x65, 0x6c, 0x61, 0x74, 0x69, 0x6f,
+	0x6e, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0d, 0x63, 0x6f, 0x72, 0x72,
+	0x65, 0x6c, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x49, 0x64, 0x12, 0x1b, 0x0a, 0x09, 0x73, 0x68, 0x6f,
+	0x72, 0x74, 0x5f, 0x75, 0x72, 0x6c, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x73, 0x68,
+	0x6f, 0x72, 0x74, 0x55, 0x72, 0x6c, 0x22, 0x3e, 0x0a, 0x09, 0x47, 0x65, 0x74, 0x55, 0x52, 0x4c,
+	0x52, 0x65, 0x71, 0x12, 0x15, 0x0a, 0x06, 0x75, 0x72, 0x6c, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x05, 0x75, 0x72, 0x6c, 0x49, 0x64, 0x12, 0x1a, 0x0a, 0x08, 0x70, 0x61,
+	0x73, 0x73, 0x77, 0x6f, 0x72, 0x64, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x70, 0x61,
+	0x73, 0x73, 0x77, 0x6f, 0x72, 0x64, 0x22, 0x2e, 0x0a, 0x09, 0x47, 0x65, 0x74, 0x55, 0x52, 0x4c,
+	0x52, 0x65, 0x73, 0x12, 0x21, 0x0a, 0x0c, 0x6f, 0x72, 0x69, 0x67, 0x69, 0x6e, 0x61, 0x6c, 0x5f,
+	0x75, 0x72, 0x6c, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x6f, 0x72, 0x69, 0x67, 0x69,
+	0x6e, 0x61, 0x6c, 0x55, 0x72, 0x6c, 0x22, 0x81, 0x01, 0x0a, 0x0f, 0x47, 0x65, 0x74, 0x55, 0x73,
+	0x65, 0x72, 0x73, 0x55, 0x52, 0x4c, 0x73, 0x52, 0x65, 0x71, 0x12, 0x12, 0x0a, 0x04, 0x74, 0x61,
+	0x67, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x09, 0x52, 0x04, 0x74, 0x61, 0x67, 0x73, 0x12, 0x14,
+	0x0a, 0x05, 0x6c, 0x69, 0x6d, 0x69, 0x74, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x05, 0x6c,
+	0x69, 0x6d, 0x69, 0x74, 0x12, 0x16, 0x0a, 0x06, 0x63, 0x75, 0x72, 0x73, 0x6f, 0x72, 0x18, 0x03,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x63, 0x75, 0x72, 0x73, 0x6f, 0x72, 0x12, 0x14, 0x0a, 0x05,
+	0x71, 0x75, 0x65, 0x72, 0x79, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x71, 0x75, 0x65,
+	0x72, 0x79, 0x12, 0x16, 0x0a, 0x06, 0x64, 0x6f, 0x6d, 0x61, 0x69, 0x6e, 0x18, 0x05, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x06, 0x64, 0x6f, 0x6d, 0x61, 0x69, 0x6e, 0x22, 0xcc, 0x01, 0x0a, 0x0f, 0x47,
+	0x65, 0x74, 0x55, 0x73, 0x65, 0x72, 0x73, 0x55, 0x52, 0x4c, 0x73, 0x52, 0x65, 0x73, 0x12, 0x39,
+	0x0a, 0x04, 0x75, 0x72, 0x6c, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x25, 0x2e, 0x75,
+	0x72, 0x6c, 0x73, 0x68, 0x6f, 0x72, 0x74, 0x65, 0x6e, 0x65, 0x72, 0x2e, 0x47, 0x65, 0x74, 0x55,
+	0x73, 0x65, 0x72, 0x73, 0x55, 0x52, 0x4c, 0x73, 0x52, 0x65, 0x73, 0x2e, 0x55, 0x73, 0x65, 0x72,
+	0x55, 0x52, 0x4c, 0x52, 0x04, 0x75, 0x72, 0x6c, 0x73, 0x12, 0x1f, 0x0a, 0x0b, 0x6e, 0x65, 0x78,
+	0x74, 0x5f, 0x63, 0x75, 0x72, 0x73, 0x6f, 0x72, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a,
+	0x6e, 0x65, 0x78, 0x74, 0x43, 0x75, 0x72, 0x73, 0x6f, 0x72, 0x1a, 0x5d, 0x0a, 0x07, 0x55, 0x73,
+	0x65, 0x72, 0x55, 0x52, 0x4c, 0x12, 0x21, 0x0a, 0x0c, 0x6f, 0x72, 0x69, 0x67, 0x69, 0x6e, 0x61,
+	0x6c, 0x5f, 0x75, 0x72, 0x6c, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x6f, 0x72, 0x69,
+	0x67, 0x69, 0x6e, 0x61, 0x6c, 0x55, 0x72, 0x6c, 0x12, 0x1b, 0x0a, 0x09, 0x73, 0x68, 0x6f, 0x72,
+	0x74, 0x5f, 0x75, 0x72, 0x6c, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x73, 0x68, 0x6f,
+	0x72, 0x74, 0x55, 0x72, 0x6c, 0x12, 0x12, 0x0a, 0x04, 0x74, 0x61, 0x67, 0x73, 0x18, 0x03, 0x20,
+	0x03, 0x28, 0x09, 0x52, 0x04, 0x74, 0x61, 0x67, 0x73, 0x22, 0x13, 0x0a, 0x11, 0x45, 0x78, 0x70,
+	0x6f, 0x72, 0x74, 0x55, 0x73, 0x65, 0x72, 0x55, 0x52, 0x4c, 0x73, 0x52, 0x65, 0x71, 0x22, 0xa2,
+	0x01, 0x0a, 0x11, 0x45, 0x78, 0x70, 0x6f, 0x72, 0x74, 0x55, 0x73, 0x65, 0x72, 0x55, 0x52, 0x4c,
+	0x73, 0x52, 0x65, 0x73, 0x12, 0x21, 0x0a, 0x0c, 0x6f, 0x72, 0x69, 0x67, 0x69, 0x6e, 0x61, 0x6c,
+	0x5f, 0x75, 0x72, 0x6c, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x6f, 0x72, 0x69, 0x67,
+	0x69, 0x6e, 0x61, 0x6c, 0x55, 0x72, 0x6c, 0x12, 0x1b, 0x0a, 0x09, 0x73, 0x68, 0x6f, 0x72, 0x74,
+	0x5f, 0x75, 0x72, 0x6c, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x73, 0x68, 0x6f, 0x72,
+	0x74, 0x55, 0x72, 0x6c, 0x12, 0x12, 0x0a, 0x04, 0x74, 0x61, 0x67, 0x73, 0x18, 0x03, 0x20, 0x03,
+	0x28, 0x09, 0x52, 0x04, 0x74, 0x61, 0x67, 0x73, 0x12, 0x39, 0x0a, 0x0a, 0x65, 0x78, 0x70, 0x69,
+	0x72, 0x65, 0x73, 0x5f, 0x61, 0x74, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67,
+	0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54,
+	0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x09, 0x65, 0x78, 0x70, 0x69, 0x72, 0x65,
+	0x73, 0x41, 0x74, 0x22, 0x17, 0x0a, 0x15, 0x47, 0x65, 0x74, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65,
+	0x64, 0x55, 0x73, 0x65, 0x72, 0x55, 0x52, 0x4c, 0x73, 0x52, 0x65, 0x71, 0x22, 0x52, 0x0a, 0x15,
+	0x47, 0x65, 0x74, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x64, 0x55, 0x73, 0x65, 0x72, 0x55, 0x52,
+	0x4c, 0x73, 0x52, 0x65, 0x73, 0x12, 0x39, 0x0a, 0x04, 0x75, 0x72, 0x6c, 0x73, 0x18, 0x01, 0x20,
+	0x03, 0x28, 0x0b, 0x32, 0x25, 0x2e, 0x75, 0x72, 0x6c, 0x73, 0x68, 0x6f, 0x72, 0x74, 0x65, 0x6e,
+	0x65, 0x72, 0x2e, 0x47, 0x65, 0x74, 0x55, 0x73, 0x65, 0x72, 0x73, 0x55, 0x52, 0x4c, 0x73, 0x52,
+	0x65, 0x73, 0x2e, 0x55, 0x73, 0x65, 0x72, 0x55, 0x52, 0x4c, 0x52, 0x04, 0x75, 0x72, 0x6c, 0x73,
+	0x22, 0x28, 0x0a, 0x12, 0x52, 0x65, 0x73, 0x74, 0x6f, 0x72, 0x65, 0x55, 0x73, 0x65, 0x72, 0x55,
+	0x52, 0x4c, 0x73, 0x52, 0x65, 0x71, 0x12, 0x12, 0x0a, 0x04, 0x75, 0x72, 0x6c, 0x73, 0x18, 0x01,
+	0x20, 0x03, 0x28, 0x09, 0x52, 0x04, 0x75, 0x72, 0x6c, 0x73, 0x22, 0x14, 0x0a, 0x12, 0x52, 0x65,
+	0x73, 0x74, 0x6f, 0x72, 0x65, 0x55, 0x73, 0x65, 0x72, 0x55, 0x52, 0x4c, 0x73, 0x52, 0x65, 0x73,
+	0x22, 0x1d, 0x0a, 0x07, 0x54, 0x61, 0x67, 0x4c, 0x69, 0x73, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x74,
+	0x61, 0x67, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x09, 0x52, 0x04, 0x74, 0x61, 0x67, 0x73, 0x22,
+	0x73, 0x0a, 0x0c, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x55, 0x52, 0x4c, 0x52, 0x65, 0x71, 0x12,
+	0x15, 0x0a, 0x06, 0x75, 0x72, 0x6c, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x05, 0x75, 0x72, 0x6c, 0x49, 0x64, 0x12, 0x21, 0x0a, 0x0c, 0x6f, 0x72, 0x69, 0x67, 0x69, 0x6e,
+	0x61, 0x6c, 0x5f, 0x75, 0x72, 0x6c, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x6f, 0x72,
+	0x69, 0x67, 0x69, 0x6e, 0x61, 0x6c, 0x55, 0x72, 0x6c, 0x12, 0x29, 0x0a, 0x04, 0x74, 0x61, 0x67,
+	0x73, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x15, 0x2e, 0x75, 0x72, 0x6c, 0x73, 0x68, 0x6f,
+	0x72, 0x74, 0x65, 0x6e, 0x65, 0x72, 0x2e, 0x54, 0x61, 0x67, 0x4c, 0x69, 0x73, 0x74, 0x52, 0x04,
+	0x74, 0x61, 0x67, 0x73, 0x22, 0x45, 0x0a, 0x0c, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x55, 0x52,
+	0x4c, 0x52, 0x65, 0x73, 0x12, 0x21, 0x0a, 0x0c, 0x6f, 0x72, 0x69, 0x67, 0x69, 0x6e, 0x61, 0x6c,
+	0x5f, 0x75, 0x72, 0x6c, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x6f, 0x72, 0x69, 0x67,
+	0x69, 0x6e, 0x61, 0x6c, 0x55, 0x72, 0x6c, 0x12, 0x12, 0x0a, 0x04, 0x74, 0x61, 0x67, 0x73, 0x18,
+	0x02, 0x20, 0x03, 0x28, 0x09, 0x52, 0x04, 0x74, 0x61, 0x67, 0x73, 0x22, 0x27, 0x0a, 0x11, 0x44,
+	0x65, 0x6c, 0x65, 0x74, 0x65, 0x55, 0x73, 0x65, 0x72, 0x55, 0x52, 0x4c, 0x73, 0x52, 0x65, 0x71,
+	0x12, 0x12, 0x0a, 0x04, 0x75, 0x72, 0x6c, 0x73, 0x18, 0x01, 0x20, 0x03, 0x28, 0x09, 0x52, 0x04,
+	0x75, 0x72, 0x6c, 0x73, 0x22, 0x13, 0x0a, 0x11, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x55, 0x73,
+	0x65, 0x72, 0x55, 0x52, 0x4c, 0x73, 0x52, 0x65, 0x73, 0x22, 0x27, 0x0a, 0x0e, 0x47, 0x65, 0x74,
+	0x55, 0x52, 0x4c, 0x53, 0x74, 0x61, 0x74, 0x73, 0x52, 0x65, 0x71, 0x12, 0x15, 0x0a, 0x06, 0x75,
+	0x72, 0x6c, 0x5f, 0x69, 0x64, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x75, 0x72, 0x6c,
+	0x49, 0x64, 0x22, 0xb4, 0x03, 0x0a, 0x0e, 0x47, 0x65, 0x74, 0x55, 0x52, 0x4c, 0x53, 0x74, 0x61,
+	0x74, 0x73, 0x52, 0x65, 0x73, 0x12, 0x21, 0x0a, 0x0c, 0x74, 0x6f, 0x74, 0x61, 0x6c, 0x5f, 0x63,
+	0x6c, 0x69, 0x63, 0x6b, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0b, 0x74, 0x6f, 0x74,
+	0x61, 0x6c, 0x43, 0x6c, 0x69, 0x63, 0x6b, 0x73, 0x12, 0x27, 0x0a, 0x0f, 0x75, 0x6e, 0x69, 0x71,
+	0x75, 0x65, 0x5f, 0x76, 0x69, 0x73, 0x69, 0x74, 0x6f, 0x72, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x05, 0x52, 0x0e, 0x75, 0x6e, 0x69, 0x71, 0x75, 0x65, 0x56, 0x69, 0x73, 0x69, 0x74, 0x6f, 0x72,
+	0x73, 0x12, 0x4c, 0x0a, 0x0e, 0x63, 0x6c, 0x69, 0x63, 0x6b, 0x73, 0x5f, 0x70, 0x65, 0x72, 0x5f,
+	0x64, 0x61, 0x79, 0x18, 0x03, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x26, 0x2e, 0x75, 0x72, 0x6c, 0x73,
+	0x68, 0x6f, 0x72, 0x74, 0x65, 0x6e, 0x65, 0x72, 0x2e, 0x47, 0x65, 0x74, 0x55, 0x52, 0x4c, 0x53,
+	0x74, 0x61, 0x74, 0x73, 0x52, 0x65, 0x73, 0x2e, 0x44, 0x61, 0x79, 0x43, 0x6c, 0x69, 0x63, 0x6b,
+	0x73, 0x52, 0x0c, 0x63, 0x6c, 0x69, 0x63, 0x6b, 0x73, 0x50, 0x65, 0x72, 0x44, 0x61, 0x79, 0x12,
+	0x4a, 0x0a, 0x0d, 0x74, 0x6f, 0x70, 0x5f, 0x72, 0x65, 0x66, 0x65, 0x72, 0x72, 0x65, 0x72, 0x73,
+	0x18, 0x04, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x25, 0x2e, 0x75, 0x72, 0x6c, 0x73, 0x68, 0x6f, 0x72,
+	0x74, 0x65, 0x6e, 0x65, 0x72, 0x2e, 0x47, 0x65, 0x74, 0x55, 0x52, 0x4c, 0x53, 0x74, 0x61, 0x74,
+	0x73, 0x52, 0x65, 0x73, 0x2e, 0x54, 0x6f, 0x70, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x52, 0x0c, 0x74,
+	0x6f, 0x70, 0x52, 0x65, 0x66, 0x65, 0x72, 0x72, 0x65, 0x72, 0x73, 0x12, 0x4d, 0x0a, 0x0f, 0x74,
+	0x6f, 0x70, 0x5f, 0x75, 0x73, 0x65, 0x72, 0x5f, 0x61, 0x67, 0x65, 0x6e, 0x74, 0x73, 0x18, 0x05,
+	0x20, 0x03, 0x28, 0x0b, 0x32, 0x25, 0x2e, 0x75, 0x72, 0x6c, 0x73, 0x68, 0x6f, 0x72, 0x74, 0x65,
+	0x6e, 0x65, 0x72, 0x2e, 0x47, 0x65, 0x74, 0x55, 0x52, 0x4c, 0x53, 0x74, 0x61, 0x74, 0x73, 0x52,
+	0x65, 0x73, 0x2e, 0x54, 0x6f, 0x70, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x52, 0x0d, 0x74, 0x6f, 0x70,
+	0x55, 0x73, 0x65, 0x72, 0x41, 0x67, 0x65, 0x6e, 0x74, 0x73, 0x1a, 0x35, 0x0a, 0x09, 0x44, 0x61,
+	0x79, 0x43, 0x6c, 0x69, 0x63, 0x6b, 0x73, 0x12, 0x10, 0x0a, 0x03, 0x64, 0x61, 0x79, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x64, 0x61, 0x79, 0x12, 0x16, 0x0a, 0x06, 0x63, 0x6c, 0x69,
+	0x63, 0x6b, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x06, 0x63, 0x6c, 0x69, 0x63, 0x6b,
+	0x73, 0x1a, 0x36, 0x0a, 0x08, 0x54, 0x6f, 0x70, 0x56, 0x61, 0x6c, 0x75, 0x65, 0x12, 0x14, 0x0a,
+	0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x05, 0x76, 0x61,
+	0x6c, 0x75, 0x65, 0x12, 0x14, 0x0a, 0x05, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x18, 0x02, 0x20, 0x01,
+	0x28, 0x05, 0x52, 0x05, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x22, 0x0d, 0x0a, 0x0b, 0x47, 0x65, 0x74,
+	0x53, 0x74, 0x61, 0x74, 0x73, 0x52, 0x65, 0x71, 0x22, 0x58, 0x0a, 0x0b, 0x47, 0x65, 0x74, 0x53,
+	0x74, 0x61, 0x74, 0x73, 0x52, 0x65, 0x73, 0x12, 0x12, 0x0a, 0x04, 0x75, 0x72, 0x6c, 0x73, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x05, 0x52, 0x04, 0x75, 0x72, 0x6c, 0x73, 0x12, 0x14, 0x0a, 0x05, 0x75,
+	0x73, 0x65, 0x72, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x05, 0x52, 0x05, 0x75, 0x73, 0x65, 0x72,
+	0x73, 0x12, 0x1f, 0x0a, 0x0b, 0x70, 0x75, 0x72, 0x67, 0x65, 0x64, 0x5f, 0x75, 0x72, 0x6c, 0x73,
+	0x18, 0x03, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0a, 0x70, 0x75, 0x72, 0x67, 0x65, 0x64, 0x55, 0x72,
+	0x6c, 0x73, 0x22, 0x09, 0x0a, 0x07, 0x50, 0x69, 0x6e, 0x67, 0x52, 0x65, 0x71, 0x22, 0x09, 0x0a,
+	0x07, 0x50, 0x69, 0x6e, 0x67, 0x52, 0x65, 0x73, 0x32, 0x9f, 0x07, 0x0a, 0x0c, 0x55, 0x52, 0x4c,
+	0x53, 0x68, 0x6f, 0x72, 0x74, 0x65, 0x6e, 0x65, 0x72, 0x12, 0x46, 0x0a, 0x0a, 0x53, 0x68, 0x6f,
+	0x72, 0x74, 0x65, 0x6e, 0x55, 0x52, 0x4c, 0x12, 0x1b, 0x2e, 0x75, 0x72, 0x6c, 0x73, 0x68, 0x6f,
+	0x72, 0x74, 0x65, 0x6e, 0x65, 0x72, 0x2e, 0x53, 0x68, 0x6f, 0x72, 0x74, 0x65, 0x6e, 0x55, 0x52,
+	0x4c, 0x52, 0x65, 0x71, 0x1a, 0x1b, 0x2e, 0x75, 0x72, 0x6c, 0x73, 0x68, 0x6f, 0x72, 0x74, 0x65,
+	0x6e, 0x65, 0x72, 0x2e, 0x53, 0x68, 0x6f, 0x72, 0x74, 0x65, 0x6e, 0x55, 0x52, 0x4c, 0x52, 0x65,
+	0x73, 0x12, 0x55, 0x0a, 0x0f, 0x53, 0x68, 0x6f, 0x72, 0x74, 0x65, 0x6e, 0x42, 0x61, 0x74, 0x63,
+	0x68, 0x55, 0x52, 0x4c, 0x12, 0x20, 0x2e, 0x75, 0x72, 0x6c, 0x73, 0x68, 0x6f, 0x72, 0x74, 0x65,
+	0x6e, 0x65, 0x72, 0x2e, 0x53, 0x68, 0x6f, 0x72, 0x74, 0x65, 0x6e, 0x42, 0x61, 0x74, 0x63, 0x68,
+	0x55, 0x52, 0x4c, 0x52, 0x65, 0x71, 0x1a, 0x20, 0x2e, 0x75, 0x72, 0x6c, 0x73, 0x68, 0x6f, 0x72,
+	0x74, 0x65, 0x6e, 0x65, 0x72, 0x2e, 0x53, 0x68, 0x6f, 0x72, 0x74, 0x65, 0x6e, 0x42, 0x61, 0x74,
+	0x63, 0x68, 0x55, 0x52, 0x4c, 0x52, 0x65, 0x73, 0x12, 0x3a, 0x0a, 0x06, 0x47, 0x65, 0x74, 0x55,
+	0x52, 0x4c, 0x12, 0x17, 0x2e, 0x75, 0x72, 0x6c, 0x73, 0x68, 0x6f, 0x72, 0x74, 0x65, 0x6e, 0x65,
+	0x72, 0x2e, 0x47, 0x65, 0x74, 0x55, 0x52, 0x4c, 0x52, 0x65, 0x71, 0x1a, 0x17, 0x2e, 0x75, 0x72,
+	0x6c, 0x73, 0x68, 0x6f, 0x72, 0x74, 0x65, 0x6e, 0x65, 0x72, 0x2e, 0x47, 0x65, 0x74, 0x55, 0x52,
+	0x4c, 0x52, 0x65, 0x73, 0x12, 0x4b, 0x0a, 0x0b, 0x47, 0x65, 0x74, 0x55, 0x73, 0x65, 0x72, 0x55,
+	0x52, 0x4c, 0x73, 0x12, 0x1d, 0x2e, 0x75, 0x72, 0x6c, 0x73, 0x68, 0x6f, 0x72, 0x74, 0x65, 0x6e,
+	0x65, 0x72, 0x2e, 0x47, 0x65, 0x74, 0x55, 0x73, 0x65, 0x72, 0x73, 0x55, 0x52, 0x4c, 0x73, 0x52,
+	0x65, 0x71, 0x1a, 0x1d, 0x2e, 0x75, 0x72, 0x6c, 0x73, 0x68, 0x6f, 0x72, 0x74, 0x65, 0x6e, 0x65,
+	0x72, 0x2e, 0x47, 0x65, 0x74, 0x55, 0x73, 0x65, 0x72, 0x73, 0x55, 0x52, 0x4c, 0x73, 0x52, 0x65,
+	0x73, 0x12, 0x54, 0x0a, 0x0e, 0x45, 0x78, 0x70, 0x6f, 0x72, 0x74, 0x55, 0x73, 0x65, 0x72, 0x55,
+	0x52, 0x4c, 0x73, 0x12, 0x1f, 0x2e, 0x75, 0x72, 0x6c, 0x73, 0x68, 0x6f, 0x72, 0x74, 0x65, 0x6e,
+	0x65, 0x72, 0x2e, 0x45, 0x78, 0x70, 0x6f, 0x72, 0x74, 0x55, 0x73, 0x65, 0x72, 0x55, 0x52, 0x4c,
+	0x73, 0x52, 0x65, 0x71, 0x1a, 0x1f, 0x2e, 0x75, 0x72, 0x6c, 0x73, 0x68, 0x6f, 0x72, 0x74, 0x65,
+	0x6e, 0x65, 0x72, 0x2e, 0x45, 0x78, 0x70, 0x6f, 0x72, 0x74, 0x55, 0x73, 0x65, 0x72, 0x55, 0x52,
+	0x4c, 0x73, 0x52, 0x65, 0x73, 0x30, 0x01, 0x12, 0x5e, 0x0a, 0x12, 0x47, 0x65, 0x74, 0x44, 0x65,
+	0x6c, 0x65, 0x74, 0x65, 0x64, 0x55, 0x73, 0x65, 0x72, 0x55, 0x52, 0x4c, 0x73, 0x12, 0x23, 0x2e,
+	0x75, 0x72, 0x6c, 0x73, 0x68, 0x6f, 0x72, 0x74, 0x65, 0x6e, 0x65, 0x72, 0x2e, 0x47, 0x65, 0x74,
+	0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x64, 0x55, 0x73, 0x65, 0x72, 0x55, 0x52, 0x4c, 0x73, 0x52,
+	0x65, 0x71, 0x1a, 0x23, 0x2e, 0x75, 0x72, 0x6c, 0x73, 0x68, 0x6f, 0x72, 0x74, 0x65, 0x6e, 0x65,
+	0x72, 0x2e, 0x47, 0x65, 0x74, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x64, 0x55, 0x73, 0x65, 0x72,
+	0x55, 0x52, 0x4c, 0x73, 0x52, 0x65, 0x73, 0x12, 0x55, 0x0a, 0x0f, 0x52, 0x65, 0x73, 0x74, 0x6f,
+	0x72, 0x65, 0x55, 0x73, 0x65, 0x72, 0x55, 0x52, 0x4c, 0x73, 0x12, 0x20, 0x2e, 0x75, 0x72, 0x6c,
+	0x73, 0x68, 0x6f, 0x72, 0x74, 0x65, 0x6e, 0x65, 0x72, 0x2e, 0x52, 0x65, 0x73, 0x74, 0x6f, 0x72,
+	0x65, 0x55, 0x73, 0x65, 0x72, 0x55, 0x52, 0x4c, 0x73, 0x52, 0x65, 0x71, 0x1a, 0x20, 0x2e, 0x75,
+	0x72, 0x6c, 0x73, 0x68, 0x6f, 0x72, 0x74, 0x65, 0x6e, 0x65, 0x72, 0x2e, 0x52, 0x65, 0x73, 0x74,
+	0x6f, 0x72, 0x65, 0x55, 0x73, 0x65, 0x72, 0x55, 0x52, 0x4c, 0x73, 0x52, 0x65, 0x73, 0x12, 0x43,
+	0x0a, 0x09, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x55, 0x52, 0x4c, 0x12, 0x1a, 0x2e, 0x75, 0x72,
+	0x6c, 0x73, 0x68, 0x6f, 0x72, 0x74, 0x65, 0x6e, 0x65, 0x72, 0x2e, 0x55, 0x70, 0x64, 0x61, 0x74,
+	0x65, 0x55, 0x52, 0x4c, 0x52, 0x65, 0x71, 0x1a, 0x1a, 0x2e, 0x75, 0x72, 0x6c, 0x73, 0x68, 0x6f,
+	0x72, 0x74, 0x65, 0x6e, 0x65, 0x72, 0x2e, 0x55, 0x70, 0x64, 0x61, 0x74, 0x65, 0x55, 0x52, 0x4c,
+	0x52, 0x65, 0x73, 0x12, 0x52, 0x0a, 0x0e, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x55, 0x73, 0x65,
+	0x72, 0x55, 0x52, 0x4c, 0x73, 0x12, 0x1f, 0x2e, 0x75, 0x72, 0x6c, 0x73, 0x68, 0x6f, 0x72, 0x74,
+	0x65, 0x6e, 0x65, 0x72, 0x2e, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x55, 0x73, 0x65, 0x72, 0x55,
+	0x52, 0x4c, 0x73, 0x52, 0x65, 0x71, 0x1a, 0x1f, 0x2e, 0x75, 0x72, 0x6c, 0x73, 0x68, 0x6f, 0x72,
+	0x74, 0x65, 0x6e, 0x65, 0x72, 0x2e, 0x44, 0x65, 0x6c, 0x65, 0x74, 0x65, 0x55, 0x73, 0x65, 0x72,
+	0x55, 0x52, 0x4c, 0x73, 0x52, 0x65, 0x73, 0x12, 0x49, 0x0a, 0x0b, 0x47, 0x65, 0x74, 0x55, 0x52,
+	0x4c, 0x53, 0x74, 0x61, 0x74, 0x73, 0x12, 0x1c, 0x2e, 0x75, 0x72, 0x6c, 0x73, 0x68, 0x6f, 0x72,
+	0x74, 0x65, 0x6e, 0x65, 0x72, 0x2e, 0x47, 0x65, 0x74, 0x55, 0x52, 0x4c, 0x53, 0x74, 0x61, 0x74,
+	0x73, 0x52, 0x65, 0x71, 0x1a, 0x1c, 0x2e, 0x75, 0x72, 0x6c, 0x73, 0x68, 0x6f, 0x72, 0x74, 0x65,
+	0x6e, 0x65, 0x72, 0x2e, 0x47, 0x65, 0x74, 0x55, 0x52, 0x4c, 0x53, 0x74, 0x61, 0x74, 0x73, 0x52,
+	0x65, 0x73, 0x12, 0x40, 0x0a, 0x08, 0x47, 0x65, 0x74, 0x53, 0x74, 0x61, 0x74, 0x73, 0x12, 0x19,
+	0x2e, 0x75, 0x72, 0x6c, 0x73, 0x68, 0x6f, 0x72, 0x74, 0x65, 0x6e, 0x65, 0x72, 0x2e, 0x47, 0x65,
+	0x74, 0x53, 0x74, 0x61, 0x74, 0x73, 0x52, 0x65, 0x71, 0x1a, 0x19, 0x2e, 0x75, 0x72, 0x6c, 0x73,
+	0x68, 0x6f, 0x72, 0x74, 0x65, 0x6e, 0x65, 0x72, 0x2e, 0x47, 0x65, 0x74, 0x53, 0x74, 0x61, 0x74,
+	0x73, 0x52, 0x65, 0x73, 0x12, 0x34, 0x0a, 0x04, 0x50, 0x69, 0x6e, 0x67, 0x12, 0x15, 0x2e, 0x75,
+	0x72, 0x6c, 0x73, 0x68, 0x6f, 0x72, 0x74, 0x65, 0x6e, 0x65, 0x72, 0x2e, 0x50, 0x69, 0x6e, 0x67,
+	0x52, 0x65, 0x71, 0x1a, 0x15, 0x2e, 0x75, 0x72, 0x6c, 0x73, 0x68, 0x6f, 0x72, 0x74, 0x65, 0x6e,
+	0x65, 0x72, 0x2e, 0x50, 0x69, 0x6e, 0x67, 0x52, 0x65, 0x73, 0x42, 0x36, 0x5a, 0x34, 0x67, 0x69,
+	0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x70, 0x69, 0x6e, 0x62, 0x72, 0x61, 0x69,
+	0x6e, 0x2f, 0x75, 0x72, 0x6c, 0x73, 0x68, 0x6f, 0x72, 0x74, 0x65, 0x6e, 0x65, 0x72, 0x2f, 0x69,
+	0x6e, 0x74, 0x65, 0x72, 0x6e, 0x61, 0x6c, 0x2f, 0x67, 0x72, 0x70, 0x63, 0x2f, 0x70, 0x72, 0x6f,
+	0x74, 0x6f, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_internal_grpc_server_proto_urlshortener_proto_rawDescOnce sync.Once
+	file_internal_grpc_server_proto_urlshortener_proto_rawDescData = file_internal_grpc_server_proto_urlshortener_proto_rawDesc
+)
+
+func file_internal_grpc_server_proto_urlshortener_proto_rawDescGZIP() []byte {
+	file_internal_grpc_server_proto_urlshortener_proto_rawDescOnce.Do(func() {
+		file_internal_grpc_server_proto_urlshortener_proto_rawDescData = protoimpl.X.CompressGZIP(file_internal_grpc_server_proto_urlshortener_proto_rawDescData)
+	})
+	return file_internal_grpc_server_proto_urlshortener_proto_rawDescData
+}
+
+var file_internal_grpc_server_proto_urlshortener_proto_msgTypes = make([]protoimpl.MessageInfo, 30)
 var file_internal_grpc_server_proto_urlshortener_proto_goTypes = []any{
 	(*ShortenURLReq)(nil),               // 0: urlshortener.ShortenURLReq
 	(*ShortenURLRes)(nil),               // 1: urlshortener.ShortenURLRes
@@ -927,39 +1909,71 @@ var file_internal_grpc_server_proto_urlshortener_proto_goTypes = []any{
 	(*GetURLRes)(nil),                   // 5: urlshortener.GetURLRes
 	(*GetUsersURLsReq)(nil),             // 6: urlshortener.GetUsersURLsReq
 	(*GetUsersURLsRes)(nil),             // 7: urlshortener.GetUsersURLsRes
-	(*DeleteUserURLsReq)(nil),           // 8: urlshortener.DeleteUserURLsReq
-	(*DeleteUserURLsRes)(nil),           // 9: urlshortener.DeleteUserURLsRes
-	(*GetStatsReq)(nil),                 // 10: urlshortener.GetStatsReq
-	(*GetStatsRes)(nil),                 // 11: urlshortener.GetStatsRes
-	(*PingReq)(nil),                     // 12: urlshortener.PingReq
-	(*PingRes)(nil),                     // 13: urlshortener.PingRes
-	(*ShortenBatchURLReq_BatchURL)(nil), // 14: urlshortener.ShortenBatchURLReq.BatchURL
-	(*ShortenBatchURLRes_BatchURL)(nil), // 15: urlshortener.ShortenBatchURLRes.BatchURL
-	(*GetUsersURLsRes_UserURL)(nil),     // 16: urlshortener.GetUsersURLsRes.UserURL
+	(*ExportUserURLsReq)(nil),           // 8: urlshortener.ExportUserURLsReq
+	(*ExportUserURLsRes)(nil),           // 9: urlshortener.ExportUserURLsRes
+	(*GetDeletedUserURLsReq)(nil),       // 10: urlshortener.GetDeletedUserURLsReq
+	(*GetDeletedUserURLsRes)(nil),       // 11: urlshortener.GetDeletedUserURLsRes
+	(*RestoreUserURLsReq)(nil),          // 12: urlshortener.RestoreUserURLsReq
+	(*RestoreUserURLsRes)(nil),          // 13: urlshortener.RestoreUserURLsRes
+	(*TagList)(nil),                     // 14: urlshortener.TagList
+	(*UpdateURLReq)(nil),                // 15: urlshortener.UpdateURLReq
+	(*UpdateURLRes)(nil),                // 16: urlshortener.UpdateURLRes
+	(*DeleteUserURLsReq)(nil),           // 17: urlshortener.DeleteUserURLsReq
+	(*DeleteUserURLsRes)(nil),           // 18: urlshortener.DeleteUserURLsRes
+	(*GetURLStatsReq)(nil),              // 19: urlshortener.GetURLStatsReq
+	(*GetURLStatsRes)(nil),              // 20: urlshortener.GetURLStatsRes
+	(*GetStatsReq)(nil),                 // 21: urlshortener.GetStatsReq
+	(*GetStatsRes)(nil),                 // 22: urlshortener.GetStatsRes
+	(*PingReq)(nil),                     // 23: urlshortener.PingReq
+	(*PingRes)(nil),                     // 24: urlshortener.PingRes
+	(*ShortenBatchURLReq_BatchURL)(nil), // 25: urlshortener.ShortenBatchURLReq.BatchURL
+	(*ShortenBatchURLRes_BatchURL)(nil), // 26: urlshortener.ShortenBatchURLRes.BatchURL
+	(*GetUsersURLsRes_UserURL)(nil),     // 27: urlshortener.GetUsersURLsRes.UserURL
+	(*GetURLStatsRes_DayClicks)(nil),    // 28: urlshortener.GetURLStatsRes.DayClicks
+	(*GetURLStatsRes_TopValue)(nil),     // 29: urlshortener.GetURLStatsRes.TopValue
+	(*timestamppb.Timestamp)(nil),       // 30: google.protobuf.Timestamp
 }
 var file_internal_grpc_server_proto_urlshortener_proto_depIdxs = []int32{
-	14, // 0: urlshortener.ShortenBatchURLReq.urls:type_name -> urlshortener.ShortenBatchURLReq.BatchURL
-	15, // 1: urlshortener.ShortenBatchURLRes.urls:type_name -> urlshortener.ShortenBatchURLRes.BatchURL
-	16, // 2: urlshortener.GetUsersURLsRes.urls:type_name -> urlshortener.GetUsersURLsRes.UserURL
-	0,  // 3: urlshortener.URLShortener.ShortenURL:input_type -> urlshortener.ShortenURLReq
-	2,  // 4: urlshortener.URLShortener.ShortenBatchURL:input_type -> urlshortener.ShortenBatchURLReq
-	4,  // 5: urlshortener.URLShortener.GetURL:input_type -> urlshortener.GetURLReq
-	6,  // 6: urlshortener.URLShortener.GetUserURLs:input_type -> urlshortener.GetUsersURLsReq
-	8,  // 7: urlshortener.URLShortener.DeleteUserURLs:input_type -> urlshortener.DeleteUserURLsReq
-	10, // 8: urlshortener.URLShortener.GetStats:input_type -> urlshortener.GetStatsReq
-	12, // 9: urlshortener.URLShortener.Ping:input_type -> urlshortener.PingReq
-	1,  // 10: urlshortener.URLShortener.ShortenURL:output_type -> urlshortener.ShortenURLRes
-	3,  // 11: urlshortener.URLShortener.ShortenBatchURL:output_type -> urlshortener.ShortenBatchURLRes
-	5,  // 12: urlshortener.URLShortener.GetURL:output_type -> urlshortener.GetURLRes
-	7,  // 13: urlshortener.URLShortener.GetUserURLs:output_type -> urlshortener.GetUsersURLsRes
-	9,  // 14: urlshortener.URLShortener.DeleteUserURLs:output_type -> urlshortener.DeleteUserURLsRes
-	11, // 15: urlshortener.URLShortener.GetStats:output_type -> urlshortener.GetStatsRes
-	13, // 16: urlshortener.URLShortener.Ping:output_type -> urlshortener.PingRes
-	10, // [10:17] is the sub-list for method output_type
-	3,  // [3:10] is the sub-list for method input_type
-	3,  // [3:3] is the sub-list for extension type_name
-	3,  // [3:3] is the sub-list for extension extendee
-	0,  // [0:3] is the sub-list for field type_name
+	30, // 0: urlshortener.ShortenURLReq.expires_at:type_name -> google.protobuf.Timestamp
+	25, // 1: urlshortener.ShortenBatchURLReq.urls:type_name -> urlshortener.ShortenBatchURLReq.BatchURL
+	26, // 2: urlshortener.ShortenBatchURLRes.urls:type_name -> urlshortener.ShortenBatchURLRes.BatchURL
+	27, // 3: urlshortener.GetUsersURLsRes.urls:type_name -> urlshortener.GetUsersURLsRes.UserURL
+	30, // 4: urlshortener.ExportUserURLsRes.expires_at:type_name -> google.protobuf.Timestamp
+	27, // 5: urlshortener.GetDeletedUserURLsRes.urls:type_name -> urlshortener.GetUsersURLsRes.UserURL
+	14, // 6: urlshortener.UpdateURLReq.tags:type_name -> urlshortener.TagList
+	28, // 7: urlshortener.GetURLStatsRes.clicks_per_day:type_name -> urlshortener.GetURLStatsRes.DayClicks
+	29, // 8: urlshortener.GetURLStatsRes.top_referrers:type_name -> urlshortener.GetURLStatsRes.TopValue
+	29, // 9: urlshortener.GetURLStatsRes.top_user_agents:type_name -> urlshortener.GetURLStatsRes.TopValue
+	30, // 10: urlshortener.ShortenBatchURLReq.BatchURL.expires_at:type_name -> google.protobuf.Timestamp
+	0,  // 11: urlshortener.URLShortener.ShortenURL:input_type -> urlshortener.ShortenURLReq
+	2,  // 12: urlshortener.URLShortener.ShortenBatchURL:input_type -> urlshortener.ShortenBatchURLReq
+	4,  // 13: urlshortener.URLShortener.GetURL:input_type -> urlshortener.GetURLReq
+	6,  // 14: urlshortener.URLShortener.GetUserURLs:input_type -> urlshortener.GetUsersURLsReq
+	8,  // 15: urlshortener.URLShortener.ExportUserURLs:input_type -> urlshortener.ExportUserURLsReq
+	10, // 16: urlshortener.URLShortener.GetDeletedUserURLs:input_type -> urlshortener.GetDeletedUserURLsReq
+	12, // 17: urlshortener.URLShortener.RestoreUserURLs:input_type -> urlshortener.RestoreUserURLsReq
+	15, // 18: urlshortener.URLShortener.UpdateURL:input_type -> urlshortener.UpdateURLReq
+	17, // 19: urlshortener.URLShortener.DeleteUserURLs:input_type -> urlshortener.DeleteUserURLsReq
+	19, // 20: urlshortener.URLShortener.GetURLStats:input_type -> urlshortener.GetURLStatsReq
+	21, // 21: urlshortener.URLShortener.GetStats:input_type -> urlshortener.GetStatsReq
+	23, // 22: urlshortener.URLShortener.Ping:input_type -> urlshortener.PingReq
+	1,  // 23: urlshortener.URLShortener.ShortenURL:output_type -> urlshortener.ShortenURLRes
+	3,  // 24: urlshortener.URLShortener.ShortenBatchURL:output_type -> urlshortener.ShortenBatchURLRes
+	5,  // 25: urlshortener.URLShortener.GetURL:output_type -> urlshortener.GetURLRes
+	7,  // 26: urlshortener.URLShortener.GetUserURLs:output_type -> urlshortener.GetUsersURLsRes
+	9,  // 27: urlshortener.URLShortener.ExportUserURLs:output_type -> urlshortener.ExportUserURLsRes
+	11, // 28: urlshortener.URLShortener.GetDeletedUserURLs:output_type -> urlshortener.GetDeletedUserURLsRes
+	13, // 29: urlshortener.URLShortener.RestoreUserURLs:output_type -> urlshortener.RestoreUserURLsRes
+	16, // 30: urlshortener.URLShortener.UpdateURL:output_type -> urlshortener.UpdateURLRes
+	18, // 31: urlshortener.URLShortener.DeleteUserURLs:output_type -> urlshortener.DeleteUserURLsRes
+	20, // 32: urlshortener.URLShortener.GetURLStats:output_type -> urlshortener.GetURLStatsRes
+	22, // 33: urlshortener.URLShortener.GetStats:output_type -> urlshortener.GetStatsRes
+	24, // 34: urlshortener.URLShortener.Ping:output_type -> urlshortener.PingRes
+	23, // [23:35] is the sub-list for method output_type
+	11, // [11:23] is the sub-list for method input_type
+	11, // [11:11] is the sub-list for extension type_name
+	11, // [11:11] is the sub-list for extension extendee
+	0,  // [0:11] is the sub-list for field type_name
 }
 
 func init() { file_internal_grpc_server_proto_urlshortener_proto_init() }
@@ -1065,7 +2079,7 @@ func file_internal_grpc_server_proto_urlshortener_proto_init() {
 			}
 		}
 		file_internal_grpc_server_proto_urlshortener_proto_msgTypes[8].Exporter = func(v any, i int) any {
-			switch v := v.(*DeleteUserURLsReq); i {
+			switch v := v.(*ExportUserURLsReq); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1077,7 +2091,7 @@ func file_internal_grpc_server_proto_urlshortener_proto_init() {
 			}
 		}
 		file_internal_grpc_server_proto_urlshortener_proto_msgTypes[9].Exporter = func(v any, i int) any {
-			switch v := v.(*DeleteUserURLsRes); i {
+			switch v := v.(*ExportUserURLsRes); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1089,7 +2103,7 @@ func file_internal_grpc_server_proto_urlshortener_proto_init() {
 			}
 		}
 		file_internal_grpc_server_proto_urlshortener_proto_msgTypes[10].Exporter = func(v any, i int) any {
-			switch v := v.(*GetStatsReq); i {
+			switch v := v.(*GetDeletedUserURLsReq); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1101,7 +2115,7 @@ func file_internal_grpc_server_proto_urlshortener_proto_init() {
 			}
 		}
 		file_internal_grpc_server_proto_urlshortener_proto_msgTypes[11].Exporter = func(v any, i int) any {
-			switch v := v.(*GetStatsRes); i {
+			switch v := v.(*GetDeletedUserURLsRes); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1113,7 +2127,7 @@ func file_internal_grpc_server_proto_urlshortener_proto_init() {
 			}
 		}
 		file_internal_grpc_server_proto_urlshortener_proto_msgTypes[12].Exporter = func(v any, i int) any {
-			switch v := v.(*PingReq); i {
+			switch v := v.(*RestoreUserURLsReq); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1125,7 +2139,7 @@ func file_internal_grpc_server_proto_urlshortener_proto_init() {
 			}
 		}
 		file_internal_grpc_server_proto_urlshortener_proto_msgTypes[13].Exporter = func(v any, i int) any {
-			switch v := v.(*PingRes); i {
+			switch v := v.(*RestoreUserURLsRes); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1137,7 +2151,7 @@ func file_internal_grpc_server_proto_urlshortener_proto_init() {
 			}
 		}
 		file_internal_grpc_server_proto_urlshortener_proto_msgTypes[14].Exporter = func(v any, i int) any {
-			switch v := v.(*ShortenBatchURLReq_BatchURL); i {
+			switch v := v.(*TagList); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1149,7 +2163,7 @@ func file_internal_grpc_server_proto_urlshortener_proto_init() {
 			}
 		}
 		file_internal_grpc_server_proto_urlshortener_proto_msgTypes[15].Exporter = func(v any, i int) any {
-			switch v := v.(*ShortenBatchURLRes_BatchURL); i {
+			switch v := v.(*UpdateURLReq); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1161,6 +2175,138 @@ func file_internal_grpc_server_proto_urlshortener_proto_init() {
 			}
 		}
 		file_internal_grpc_server_proto_urlshortener_proto_msgTypes[16].Exporter = func(v any, i int) any {
+			switch v := v.(*UpdateURLRes); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_internal_grpc_server_proto_urlshortener_proto_msgTypes[17].Exporter = func(v any, i int) any {
+			switch v := v.(*DeleteUserURLsReq); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_internal_grpc_server_proto_urlshortener_proto_msgTypes[18].Exporter = func(v any, i int) any {
+			switch v := v.(*DeleteUserURLsRes); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_internal_grpc_server_proto_urlshortener_proto_msgTypes[19].Exporter = func(v any, i int) any {
+			switch v := v.(*GetURLStatsReq); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_internal_grpc_server_proto_urlshortener_proto_msgTypes[20].Exporter = func(v any, i int) any {
+			switch v := v.(*GetURLStatsRes); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_internal_grpc_server_proto_urlshortener_proto_msgTypes[21].Exporter = func(v any, i int) any {
+			switch v := v.(*GetStatsReq); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_internal_grpc_server_proto_urlshortener_proto_msgTypes[22].Exporter = func(v any, i int) any {
+			switch v := v.(*GetStatsRes); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_internal_grpc_server_proto_urlshortener_proto_msgTypes[23].Exporter = func(v any, i int) any {
+			switch v := v.(*PingReq); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_internal_grpc_server_proto_urlshortener_proto_msgTypes[24].Exporter = func(v any, i int) any {
+			switch v := v.(*PingRes); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_internal_grpc_server_proto_urlshortener_proto_msgTypes[25].Exporter = func(v any, i int) any {
+			switch v := v.(*ShortenBatchURLReq_BatchURL); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_internal_grpc_server_proto_urlshortener_proto_msgTypes[26].Exporter = func(v any, i int) any {
+			switch v := v.(*ShortenBatchURLRes_BatchURL); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_internal_grpc_server_proto_urlshortener_proto_msgTypes[27].Exporter = func(v any, i int) any {
 			switch v := v.(*GetUsersURLsRes_UserURL); i {
 			case 0:
 				return &v.state
@@ -1172,6 +2318,30 @@ func file_internal_grpc_server_proto_urlshortener_proto_init() {
 				return nil
 			}
 		}
+		file_internal_grpc_server_proto_urlshortener_proto_msgTypes[28].Exporter = func(v any, i int) any {
+			switch v := v.(*GetURLStatsRes_DayClicks); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_internal_grpc_server_proto_urlshortener_proto_msgTypes[29].Exporter = func(v any, i int) any {
+			switch v := v.(*GetURLStatsRes_TopValue); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
 	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
@@ -1179,7 +2349,7 @@ func file_internal_grpc_server_proto_urlshortener_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_internal_grpc_server_proto_urlshortener_proto_rawDesc,
 			NumEnums:      0,
-			NumMessages:   17,
+			NumMessages:   30,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
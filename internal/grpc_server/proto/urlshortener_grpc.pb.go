@@ -19,13 +19,18 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	URLShortener_ShortenURL_FullMethodName      = "/urlshortener.URLShortener/ShortenURL"
-	URLShortener_ShortenBatchURL_FullMethodName = "/urlshortener.URLShortener/ShortenBatchURL"
-	URLShortener_GetURL_FullMethodName          = "/urlshortener.URLShortener/GetURL"
-	URLShortener_GetUserURLs_FullMethodName     = "/urlshortener.URLShortener/GetUserURLs"
-	URLShortener_DeleteUserURLs_FullMethodName  = "/urlshortener.URLShortener/DeleteUserURLs"
-	URLShortener_GetStats_FullMethodName        = "/urlshortener.URLShortener/GetStats"
-	URLShortener_Ping_FullMethodName            = "/urlshortener.URLShortener/Ping"
+	URLShortener_ShortenURL_FullMethodName         = "/urlshortener.URLShortener/ShortenURL"
+	URLShortener_ShortenBatchURL_FullMethodName    = "/urlshortener.URLShortener/ShortenBatchURL"
+	URLShortener_GetURL_FullMethodName             = "/urlshortener.URLShortener/GetURL"
+	URLShortener_GetUserURLs_FullMethodName        = "/urlshortener.URLShortener/GetUserURLs"
+	URLShortener_ExportUserURLs_FullMethodName     = "/urlshortener.URLShortener/ExportUserURLs"
+	URLShortener_GetDeletedUserURLs_FullMethodName = "/urlshortener.URLShortener/GetDeletedUserURLs"
+	URLShortener_RestoreUserURLs_FullMethodName    = "/urlshortener.URLShortener/RestoreUserURLs"
+	URLShortener_UpdateURL_FullMethodName          = "/urlshortener.URLShortener/UpdateURL"
+	URLShortener_DeleteUserURLs_FullMethodName     = "/urlshortener.URLShortener/DeleteUserURLs"
+	URLShortener_GetURLStats_FullMethodName        = "/urlshortener.URLShortener/GetURLStats"
+	URLShortener_GetStats_FullMethodName           = "/urlshortener.URLShortener/GetStats"
+	URLShortener_Ping_FullMethodName               = "/urlshortener.URLShortener/Ping"
 )
 
 // URLShortenerClient is the client API for URLShortener service.
@@ -36,7 +41,12 @@ type URLShortenerClient interface {
 	ShortenBatchURL(ctx context.Context, in *ShortenBatchURLReq, opts ...grpc.CallOption) (*ShortenBatchURLRes, error)
 	GetURL(ctx context.Context, in *GetURLReq, opts ...grpc.CallOption) (*GetURLRes, error)
 	GetUserURLs(ctx context.Context, in *GetUsersURLsReq, opts ...grpc.CallOption) (*GetUsersURLsRes, error)
+	ExportUserURLs(ctx context.Context, in *ExportUserURLsReq, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ExportUserURLsRes], error)
+	GetDeletedUserURLs(ctx context.Context, in *GetDeletedUserURLsReq, opts ...grpc.CallOption) (*GetDeletedUserURLsRes, error)
+	RestoreUserURLs(ctx context.Context, in *RestoreUserURLsReq, opts ...grpc.CallOption) (*RestoreUserURLsRes, error)
+	UpdateURL(ctx context.Context, in *UpdateURLReq, opts ...grpc.CallOption) (*UpdateURLRes, error)
 	DeleteUserURLs(ctx context.Context, in *DeleteUserURLsReq, opts ...grpc.CallOption) (*DeleteUserURLsRes, error)
+	GetURLStats(ctx context.Context, in *GetURLStatsReq, opts ...grpc.CallOption) (*GetURLStatsRes, error)
 	GetStats(ctx context.Context, in *GetStatsReq, opts ...grpc.CallOption) (*GetStatsRes, error)
 	Ping(ctx context.Context, in *PingReq, opts ...grpc.CallOption) (*PingRes, error)
 }
@@ -89,6 +99,55 @@ func (c *uRLShortenerClient) GetUserURLs(ctx context.Context, in *GetUsersURLsRe
 	return out, nil
 }
 
+func (c *uRLShortenerClient) ExportUserURLs(ctx context.Context, in *ExportUserURLsReq, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ExportUserURLsRes], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &URLShortener_ServiceDesc.Streams[0], URLShortener_ExportUserURLs_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[ExportUserURLsReq, ExportUserURLsRes]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type URLShortener_ExportUserURLsClient = grpc.ServerStreamingClient[ExportUserURLsRes]
+
+func (c *uRLShortenerClient) GetDeletedUserURLs(ctx context.Context, in *GetDeletedUserURLsReq, opts ...grpc.CallOption) (*GetDeletedUserURLsRes, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetDeletedUserURLsRes)
+	err := c.cc.Invoke(ctx, URLShortener_GetDeletedUserURLs_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *uRLShortenerClient) RestoreUserURLs(ctx context.Context, in *RestoreUserURLsReq, opts ...grpc.CallOption) (*RestoreUserURLsRes, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RestoreUserURLsRes)
+	err := c.cc.Invoke(ctx, URLShortener_RestoreUserURLs_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *uRLShortenerClient) UpdateURL(ctx context.Context, in *UpdateURLReq, opts ...grpc.CallOption) (*UpdateURLRes, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(UpdateURLRes)
+	err := c.cc.Invoke(ctx, URLShortener_UpdateURL_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *uRLShortenerClient) DeleteUserURLs(ctx context.Context, in *DeleteUserURLsReq, opts ...grpc.CallOption) (*DeleteUserURLsRes, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(DeleteUserURLsRes)
@@ -99,6 +158,16 @@ func (c *uRLShortenerClient) DeleteUserURLs(ctx context.Context, in *DeleteUserU
 	return out, nil
 }
 
+func (c *uRLShortenerClient) GetURLStats(ctx context.Context, in *GetURLStatsReq, opts ...grpc.CallOption) (*GetURLStatsRes, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetURLStatsRes)
+	err := c.cc.Invoke(ctx, URLShortener_GetURLStats_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *uRLShortenerClient) GetStats(ctx context.Context, in *GetStatsReq, opts ...grpc.CallOption) (*GetStatsRes, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(GetStatsRes)
@@ -127,7 +196,12 @@ type URLShortenerServer interface {
 	ShortenBatchURL(context.Context, *ShortenBatchURLReq) (*ShortenBatchURLRes, error)
 	GetURL(context.Context, *GetURLReq) (*GetURLRes, error)
 	GetUserURLs(context.Context, *GetUsersURLsReq) (*GetUsersURLsRes, error)
+	ExportUserURLs(*ExportUserURLsReq, grpc.ServerStreamingServer[ExportUserURLsRes]) error
+	GetDeletedUserURLs(context.Context, *GetDeletedUserURLsReq) (*GetDeletedUserURLsRes, error)
+	RestoreUserURLs(context.Context, *RestoreUserURLsReq) (*RestoreUserURLsRes, error)
+	UpdateURL(context.Context, *UpdateURLReq) (*UpdateURLRes, error)
 	DeleteUserURLs(context.Context, *DeleteUserURLsReq) (*DeleteUserURLsRes, error)
+	GetURLStats(context.Context, *GetURLStatsReq) (*GetURLStatsRes, error)
 	GetStats(context.Context, *GetStatsReq) (*GetStatsRes, error)
 	Ping(context.Context, *PingReq) (*PingRes, error)
 	mustEmbedUnimplementedURLShortenerServer()
@@ -152,9 +226,24 @@ func (UnimplementedURLShortenerServer) GetURL(context.Context, *GetURLReq) (*Get
 func (UnimplementedURLShortenerServer) GetUserURLs(context.Context, *GetUsersURLsReq) (*GetUsersURLsRes, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetUserURLs not implemented")
 }
+func (UnimplementedURLShortenerServer) ExportUserURLs(*ExportUserURLsReq, grpc.ServerStreamingServer[ExportUserURLsRes]) error {
+	return status.Errorf(codes.Unimplemented, "method ExportUserURLs not implemented")
+}
+func (UnimplementedURLShortenerServer) GetDeletedUserURLs(context.Context, *GetDeletedUserURLsReq) (*GetDeletedUserURLsRes, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetDeletedUserURLs not implemented")
+}
+func (UnimplementedURLShortenerServer) RestoreUserURLs(context.Context, *RestoreUserURLsReq) (*RestoreUserURLsRes, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RestoreUserURLs not implemented")
+}
+func (UnimplementedURLShortenerServer) UpdateURL(context.Context, *UpdateURLReq) (*UpdateURLRes, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateURL not implemented")
+}
 func (UnimplementedURLShortenerServer) DeleteUserURLs(context.Context, *DeleteUserURLsReq) (*DeleteUserURLsRes, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method DeleteUserURLs not implemented")
 }
+func (UnimplementedURLShortenerServer) GetURLStats(context.Context, *GetURLStatsReq) (*GetURLStatsRes, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetURLStats not implemented")
+}
 func (UnimplementedURLShortenerServer) GetStats(context.Context, *GetStatsReq) (*GetStatsRes, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetStats not implemented")
 }
@@ -254,6 +343,71 @@ func _URLShortener_GetUserURLs_Handler(srv interface{}, ctx context.Context, dec
 	return interceptor(ctx, in, info, handler)
 }
 
+func _URLShortener_ExportUserURLs_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ExportUserURLsReq)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(URLShortenerServer).ExportUserURLs(m, &grpc.GenericServerStream[ExportUserURLsReq, ExportUserURLsRes]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type URLShortener_ExportUserURLsServer = grpc.ServerStreamingServer[ExportUserURLsRes]
+
+func _URLShortener_GetDeletedUserURLs_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetDeletedUserURLsReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(URLShortenerServer).GetDeletedUserURLs(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: URLShortener_GetDeletedUserURLs_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(URLShortenerServer).GetDeletedUserURLs(ctx, req.(*GetDeletedUserURLsReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _URLShortener_RestoreUserURLs_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RestoreUserURLsReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(URLShortenerServer).RestoreUserURLs(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: URLShortener_RestoreUserURLs_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(URLShortenerServer).RestoreUserURLs(ctx, req.(*RestoreUserURLsReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _URLShortener_UpdateURL_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateURLReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(URLShortenerServer).UpdateURL(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: URLShortener_UpdateURL_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(URLShortenerServer).UpdateURL(ctx, req.(*UpdateURLReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _URLShortener_DeleteUserURLs_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(DeleteUserURLsReq)
 	if err := dec(in); err != nil {
@@ -272,6 +426,24 @@ func _URLShortener_DeleteUserURLs_Handler(srv interface{}, ctx context.Context,
 	return interceptor(ctx, in, info, handler)
 }
 
+func _URLShortener_GetURLStats_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetURLStatsReq)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(URLShortenerServer).GetURLStats(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: URLShortener_GetURLStats_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(URLShortenerServer).GetURLStats(ctx, req.(*GetURLStatsReq))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _URLShortener_GetStats_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(GetStatsReq)
 	if err := dec(in); err != nil {
@@ -331,10 +503,26 @@ var URLShortener_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GetUserURLs",
 			Handler:    _URLShortener_GetUserURLs_Handler,
 		},
+		{
+			MethodName: "GetDeletedUserURLs",
+			Handler:    _URLShortener_GetDeletedUserURLs_Handler,
+		},
+		{
+			MethodName: "RestoreUserURLs",
+			Handler:    _URLShortener_RestoreUserURLs_Handler,
+		},
+		{
+			MethodName: "UpdateURL",
+			Handler:    _URLShortener_UpdateURL_Handler,
+		},
 		{
 			MethodName: "DeleteUserURLs",
 			Handler:    _URLShortener_DeleteUserURLs_Handler,
 		},
+		{
+			MethodName: "GetURLStats",
+			Handler:    _URLShortener_GetURLStats_Handler,
+		},
 		{
 			MethodName: "GetStats",
 			Handler:    _URLShortener_GetStats_Handler,
@@ -344,6 +532,12 @@ var URLShortener_ServiceDesc = grpc.ServiceDesc{
 			Handler:    _URLShortener_Ping_Handler,
 		},
 	},
-	Streams:  []grpc.StreamDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "ExportUserURLs",
+			Handler:       _URLShortener_ExportUserURLs_Handler,
+			ServerStreams: true,
+		},
+	},
 	Metadata: "internal/grpc_server/proto/urlshortener.proto",
 }
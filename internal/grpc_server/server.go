@@ -5,6 +5,7 @@ import (
 	"context"
 	"errors"
 	"net"
+	"time"
 
 	"github.com/pinbrain/urlshortener/internal/grpc_server/interceptors"
 	pb "github.com/pinbrain/urlshortener/internal/grpc_server/proto"
@@ -13,6 +14,7 @@ import (
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 // URLShortenerServer описывает структуру gRPC сервера.
@@ -32,6 +34,11 @@ func NewGRPCServer(service *service.Service, trustedSubnet *net.IPNet) *grpc.Ser
 			authInterceptor.RequireUser,
 			ipGuardInterceptor.GuardByIP,
 		),
+		grpc.ChainStreamInterceptor(
+			interceptors.LoggerStreamInterceptor,
+			authInterceptor.AuthenticateUserStream,
+			authInterceptor.RequireUserStream,
+		),
 	)
 	pb.RegisterURLShortenerServer(s, &URLShortenerServer{
 		service: service,
@@ -45,13 +52,32 @@ func (s *URLShortenerServer) ShortenURL(
 	ctx context.Context, in *pb.ShortenURLReq,
 ) (*pb.ShortenURLRes, error) {
 	var response pb.ShortenURLRes
-	shortenURL, err := s.service.ShortenURL(ctx, in.GetOriginalUrl())
+	shortenURL, err := s.service.ShortenURL(ctx, in.GetOriginalUrl(), service.ShortenOptions{
+		Alias:     in.GetAlias(),
+		ExpiresAt: expiresAtFromProto(in.GetExpiresAt()),
+		TTL:       time.Duration(in.GetTtlSeconds()) * time.Second,
+		MaxClicks: int(in.GetMaxClicks()),
+		Password:  in.GetPassword(),
+		Tags:      in.GetTags(),
+	})
 	if err != nil {
 		switch {
 		case errors.Is(err, service.ErrInvalidURL):
 			return nil, status.Error(codes.InvalidArgument, "Некорректная ссылка для сокращения")
 		case errors.Is(err, service.ErrURLConflict):
 			return nil, status.Error(codes.AlreadyExists, "Ссылка уже сохранена")
+		case errors.Is(err, service.ErrInvalidAlias):
+			return nil, status.Error(codes.InvalidArgument, "Некорректный алиас сокращенной ссылки")
+		case errors.Is(err, service.ErrAliasConflict):
+			return nil, status.Error(codes.AlreadyExists, "Сокращенная ссылка с таким алиасом уже существует")
+		case errors.Is(err, service.ErrInvalidExpiry):
+			return nil, status.Error(codes.InvalidArgument, "Некорректный срок действия сокращенной ссылки")
+		case errors.Is(err, service.ErrInvalidLimit):
+			return nil, status.Error(codes.InvalidArgument, "Некорректный лимит переходов по сокращенной ссылке")
+		case errors.Is(err, service.ErrInvalidPassword):
+			return nil, status.Error(codes.InvalidArgument, "Некорректный пароль сокращенной ссылки")
+		case errors.Is(err, service.ErrInvalidTags):
+			return nil, status.Error(codes.InvalidArgument, "Некорректные теги сокращенной ссылки")
 		default:
 			logger.Log.Errorw("Error while saving url for shorten", "err", err)
 			return nil, status.Error(codes.Internal, "Internal server error")
@@ -61,6 +87,14 @@ func (s *URLShortenerServer) ShortenURL(
 	return &response, nil
 }
 
+// expiresAtFromProto возвращает время истечения срока действия ссылки (нулевое, если не задано).
+func expiresAtFromProto(expiresAt *timestamppb.Timestamp) time.Time {
+	if expiresAt == nil {
+		return time.Time{}
+	}
+	return expiresAt.AsTime()
+}
+
 // ShortenBatchURL обрабатывает запрос на сокращение нескольких ссылок.
 func (s *URLShortenerServer) ShortenBatchURL(
 	ctx context.Context, in *pb.ShortenBatchURLReq,
@@ -73,6 +107,12 @@ func (s *URLShortenerServer) ShortenBatchURL(
 		batchURL = append(batchURL, service.BatchURL{
 			CorrelationID: url.GetCorrelationId(),
 			OriginalURL:   url.GetOriginalUrl(),
+			Alias:         url.GetAlias(),
+			ExpiresAt:     expiresAtFromProto(url.GetExpiresAt()),
+			TTL:           time.Duration(url.GetTtlSeconds()) * time.Second,
+			MaxClicks:     int(url.GetMaxClicks()),
+			Password:      url.GetPassword(),
+			Tags:          url.GetTags(),
 		})
 	}
 	savedBatch, err := s.service.ShortenBatchURL(ctx, batchURL)
@@ -82,6 +122,18 @@ func (s *URLShortenerServer) ShortenBatchURL(
 			return nil, status.Error(codes.AlreadyExists, "В запросе ссылки, которые уже были ранее сохранены")
 		case errors.Is(err, service.ErrNoData):
 			return nil, status.Error(codes.NotFound, "Отсутствуют данные для сокращения")
+		case errors.Is(err, service.ErrInvalidAlias):
+			return nil, status.Error(codes.InvalidArgument, "Некорректный алиас сокращенной ссылки")
+		case errors.Is(err, service.ErrAliasConflict):
+			return nil, status.Error(codes.AlreadyExists, "Сокращенная ссылка с таким алиасом уже существует")
+		case errors.Is(err, service.ErrInvalidExpiry):
+			return nil, status.Error(codes.InvalidArgument, "Некорректный срок действия сокращенной ссылки")
+		case errors.Is(err, service.ErrInvalidLimit):
+			return nil, status.Error(codes.InvalidArgument, "Некорректный лимит переходов по сокращенной ссылке")
+		case errors.Is(err, service.ErrInvalidPassword):
+			return nil, status.Error(codes.InvalidArgument, "Некорректный пароль сокращенной ссылки")
+		case errors.Is(err, service.ErrInvalidTags):
+			return nil, status.Error(codes.InvalidArgument, "Некорректные теги сокращенной ссылки")
 		default:
 			logger.Log.Errorw("Error in saving batch of urls in store", "err", err)
 			return nil, status.Error(codes.Internal, "Internal server error")
@@ -101,13 +153,19 @@ func (s *URLShortenerServer) GetURL(
 	ctx context.Context, in *pb.GetURLReq,
 ) (*pb.GetURLRes, error) {
 	var response pb.GetURLRes
-	url, err := s.service.GetURL(ctx, in.GetUrlId())
+	url, _, err := s.service.GetURL(ctx, in.GetUrlId(), in.GetPassword())
 	if err != nil {
 		switch {
 		case errors.Is(err, service.ErrInvalidURL):
 			return nil, status.Error(codes.InvalidArgument, "Некорректная ссылка")
 		case errors.Is(err, service.ErrIsDeleted):
 			return nil, status.Error(codes.NotFound, "Ссылка удалена")
+		case errors.Is(err, service.ErrExpired):
+			return nil, status.Error(codes.NotFound, "Срок действия ссылки истек")
+		case errors.Is(err, service.ErrClickLimit):
+			return nil, status.Error(codes.NotFound, "Исчерпан лимит переходов по ссылке")
+		case errors.Is(err, service.ErrPasswordRequired), errors.Is(err, service.ErrWrongPassword):
+			return nil, status.Error(codes.PermissionDenied, "Неверный пароль ссылки")
 		case errors.Is(err, service.ErrNotFound):
 			return nil, status.Error(codes.NotFound, "Ссылка не найдена")
 		default:
@@ -120,15 +178,81 @@ func (s *URLShortenerServer) GetURL(
 }
 
 // GetUserURLs обрабатывает запрос на получение ссылок, сокращенных пользователем.
+// Если в запросе переданы теги, возвращаются только ссылки со всеми этими тегами,
+// если переданы query и domain - только ссылки, содержащие строку query и ведущие на домен domain.
+// Если задан limit, ссылки возвращаются постранично (токен следующей страницы - в next_cursor).
 func (s *URLShortenerServer) GetUserURLs(
-	ctx context.Context, _ *pb.GetUsersURLsReq,
+	ctx context.Context, in *pb.GetUsersURLsReq,
 ) (*pb.GetUsersURLsRes, error) {
 	response := pb.GetUsersURLsRes{
 		Urls: []*pb.GetUsersURLsRes_UserURL{},
 	}
-	userURLs, err := s.service.GetUserURLs(ctx)
+	userURLs, nextCursor, err := s.service.GetUserURLs(ctx, service.UserURLsQuery{
+		Tags:   in.GetTags(),
+		Query:  in.GetQuery(),
+		Domain: in.GetDomain(),
+		Limit:  int(in.GetLimit()),
+		Cursor: in.GetCursor(),
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidTags):
+			return nil, status.Error(codes.InvalidArgument, "Некорректные теги сокращенной ссылки")
+		case errors.Is(err, service.ErrInvalidPage):
+			return nil, status.Error(codes.InvalidArgument, "Некорректные параметры страницы")
+		default:
+			logger.Log.Errorw("Error getting user urls", "err", err)
+			return nil, status.Error(codes.Internal, "Internal server error")
+		}
+	}
+	response.NextCursor = nextCursor
+	for _, url := range userURLs {
+		response.Urls = append(response.Urls, &pb.GetUsersURLsRes_UserURL{
+			OriginalUrl: url.OriginalURL,
+			ShortUrl:    url.ShortURL,
+			Tags:        url.Tags,
+		})
+	}
+	return &response, nil
+}
+
+// ExportUserURLs обрабатывает запрос на выгрузку всех ссылок пользователя.
+// Ссылки передаются в поток по мере чтения из хранилища.
+func (s *URLShortenerServer) ExportUserURLs(
+	_ *pb.ExportUserURLsReq, stream pb.URLShortener_ExportUserURLsServer,
+) error {
+	err := s.service.ExportUserURLs(stream.Context(), func(url service.URLData) error {
+		res := &pb.ExportUserURLsRes{
+			OriginalUrl: url.OriginalURL,
+			ShortUrl:    url.ShortURL,
+			Tags:        url.Tags,
+		}
+		if !url.ExpiresAt.IsZero() {
+			res.ExpiresAt = timestamppb.New(url.ExpiresAt)
+		}
+		return stream.Send(res)
+	})
+	if err != nil {
+		if !errors.Is(err, service.ErrStorageError) {
+			// Ошибка отправки в поток (например, клиент отключился)
+			return err
+		}
+		logger.Log.Errorw("Error exporting user urls", "err", err)
+		return status.Error(codes.Internal, "Internal server error")
+	}
+	return nil
+}
+
+// GetDeletedUserURLs обрабатывает запрос на получение удаленных ссылок пользователя.
+func (s *URLShortenerServer) GetDeletedUserURLs(
+	ctx context.Context, _ *pb.GetDeletedUserURLsReq,
+) (*pb.GetDeletedUserURLsRes, error) {
+	response := pb.GetDeletedUserURLsRes{
+		Urls: []*pb.GetUsersURLsRes_UserURL{},
+	}
+	userURLs, err := s.service.GetDeletedUserURLs(ctx)
 	if err != nil {
-		logger.Log.Errorw("Error getting user urls", "err", err)
+		logger.Log.Errorw("Error getting deleted user urls", "err", err)
 		return nil, status.Error(codes.Internal, "Internal server error")
 	}
 	for _, url := range userURLs {
@@ -140,6 +264,58 @@ func (s *URLShortenerServer) GetUserURLs(
 	return &response, nil
 }
 
+// RestoreUserURLs обрабатывает запрос на восстановление удаленных ссылок.
+func (s *URLShortenerServer) RestoreUserURLs(
+	ctx context.Context, in *pb.RestoreUserURLsReq,
+) (*pb.RestoreUserURLsRes, error) {
+	var response pb.RestoreUserURLsRes
+
+	err := s.service.RestoreUserURLs(ctx, in.GetUrls())
+	if err != nil {
+		if errors.Is(err, service.ErrNoData) {
+			return nil, status.Error(codes.NotFound, "Отсутствуют данные для восстановления")
+		}
+		logger.Log.Errorw("Error restoring user urls", "err", err)
+		return nil, status.Error(codes.Internal, "Internal server error")
+	}
+
+	return &response, nil
+}
+
+// UpdateURL обрабатывает запрос на изменение сокращенной ссылки пользователя:
+// полной ссылки, на которую она ведет, и (или) ее тегов (если передан список тегов).
+// В ответе возвращаются полная ссылка и теги, сохраненные после изменения.
+func (s *URLShortenerServer) UpdateURL(
+	ctx context.Context, in *pb.UpdateURLReq,
+) (*pb.UpdateURLRes, error) {
+	var response pb.UpdateURLRes
+	updated, err := s.service.UpdateURL(ctx, in.GetUrlId(), service.URLUpdate{
+		OriginalURL: in.GetOriginalUrl(),
+		Tags:        in.GetTags().GetTags(),
+		UpdateTags:  in.GetTags() != nil,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrNotFound):
+			return nil, status.Error(codes.NotFound, "Сокращенная ссылка не найдена")
+		case errors.Is(err, service.ErrNoData):
+			return nil, status.Error(codes.InvalidArgument, "Отсутствуют данные для изменения")
+		case errors.Is(err, service.ErrInvalidURL):
+			return nil, status.Error(codes.InvalidArgument, "Некорректная ссылка")
+		case errors.Is(err, service.ErrInvalidTags):
+			return nil, status.Error(codes.InvalidArgument, "Некорректные теги сокращенной ссылки")
+		case errors.Is(err, service.ErrURLConflict):
+			return nil, status.Error(codes.AlreadyExists, "Ссылка уже сохранена")
+		default:
+			logger.Log.Errorw("Error updating user url", "err", err)
+			return nil, status.Error(codes.Internal, "Internal server error")
+		}
+	}
+	response.OriginalUrl = updated.OriginalURL
+	response.Tags = updated.Tags
+	return &response, nil
+}
+
 // DeleteUserURLs обрабатывает запрос на удаление сокращенных ссылок.
 func (s *URLShortenerServer) DeleteUserURLs(
 	ctx context.Context, in *pb.DeleteUserURLsReq,
@@ -158,6 +334,43 @@ func (s *URLShortenerServer) DeleteUserURLs(
 	return &response, nil
 }
 
+// GetURLStats обрабатывает запрос на получение статистики переходов по ссылке пользователя.
+func (s *URLShortenerServer) GetURLStats(
+	ctx context.Context, in *pb.GetURLStatsReq,
+) (*pb.GetURLStatsRes, error) {
+	stats, err := s.service.GetURLStats(ctx, in.GetUrlId())
+	if err != nil {
+		if errors.Is(err, service.ErrNotFound) {
+			return nil, status.Error(codes.NotFound, "Сокращенная ссылка не найдена")
+		}
+		logger.Log.Errorw("Error getting url stats", "err", err)
+		return nil, status.Error(codes.Internal, "Internal server error")
+	}
+	response := pb.GetURLStatsRes{
+		TotalClicks:    int32(stats.TotalClicks),
+		UniqueVisitors: int32(stats.UniqueVisitors),
+	}
+	for _, day := range stats.ClicksPerDay {
+		response.ClicksPerDay = append(response.ClicksPerDay, &pb.GetURLStatsRes_DayClicks{
+			Day:    day.Day,
+			Clicks: int32(day.Clicks),
+		})
+	}
+	for _, referrer := range stats.TopReferrers {
+		response.TopReferrers = append(response.TopReferrers, &pb.GetURLStatsRes_TopValue{
+			Value: referrer.Value,
+			Count: int32(referrer.Count),
+		})
+	}
+	for _, userAgent := range stats.TopUserAgents {
+		response.TopUserAgents = append(response.TopUserAgents, &pb.GetURLStatsRes_TopValue{
+			Value: userAgent.Value,
+			Count: int32(userAgent.Count),
+		})
+	}
+	return &response, nil
+}
+
 // GetStats обрабатывает запрос на получение статистики хранилища.
 func (s *URLShortenerServer) GetStats(
 	ctx context.Context, _ *pb.GetStatsReq,
@@ -171,8 +384,13 @@ func (s *URLShortenerServer) GetStats(
 	if err != nil {
 		return nil, status.Error(codes.Internal, "Internal server error")
 	}
+	purgedURLs, err := s.service.GetPurgedURLsCount(ctx)
+	if err != nil {
+		return nil, status.Error(codes.Internal, "Internal server error")
+	}
 	response.Urls = int32(urls)
 	response.Users = int32(users)
+	response.PurgedUrls = int32(purgedURLs)
 	return &response, nil
 }
 
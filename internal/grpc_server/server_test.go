@@ -4,7 +4,9 @@ import (
 	"context"
 	"errors"
 	"net/url"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
 	appCtx "github.com/pinbrain/urlshortener/internal/context"
@@ -17,6 +19,7 @@ import (
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 func TestNewGRPCServer(t *testing.T) {
@@ -77,6 +80,48 @@ func TestShortenURL(t *testing.T) {
 			wantErr: true,
 			errCode: codes.AlreadyExists,
 		},
+		{
+			name: "Алиас уже занят",
+			urlStore: &urlStore{
+				urlStoreError: storage.ErrAliasConflict,
+			},
+			request: &pb.ShortenURLReq{OriginalUrl: "http://some.ru", Alias: "spring-sale"},
+			wantErr: true,
+			errCode: codes.AlreadyExists,
+		},
+		{
+			name: "Некорректный алиас",
+			urlStore: &urlStore{
+				urlStoreError: storage.ErrInvalidAlias,
+			},
+			request: &pb.ShortenURLReq{OriginalUrl: "http://some.ru", Alias: "a!"},
+			wantErr: true,
+			errCode: codes.InvalidArgument,
+		},
+		{
+			name: "Успешный запрос со сроком действия",
+			urlStore: &urlStore{
+				urlID: "abc",
+			},
+			request: &pb.ShortenURLReq{
+				OriginalUrl: "http://some.ru",
+				ExpiresAt:   timestamppb.New(time.Date(2100, 1, 1, 0, 0, 0, 0, time.UTC)),
+			},
+			expected: &pb.ShortenURLRes{ShortUrl: "http://localhost:8080/abc"},
+			wantErr:  false,
+		},
+		{
+			name:    "Отрицательный срок действия",
+			request: &pb.ShortenURLReq{OriginalUrl: "http://some.ru", TtlSeconds: -10},
+			wantErr: true,
+			errCode: codes.InvalidArgument,
+		},
+		{
+			name:    "Отрицательный лимит переходов",
+			request: &pb.ShortenURLReq{OriginalUrl: "http://some.ru", MaxClicks: -1},
+			wantErr: true,
+			errCode: codes.InvalidArgument,
+		},
 		{
 			name: "Ошибка хранилища",
 			urlStore: &urlStore{
@@ -91,7 +136,11 @@ func TestShortenURL(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			if tt.urlStore != nil {
-				mockStorage.EXPECT().SaveURL(gomock.Any(), tt.request.GetOriginalUrl(), gomock.Any()).
+				mockStorage.EXPECT().SaveURL(gomock.Any(), storage.ShortenURL{
+					Original:  tt.request.GetOriginalUrl(),
+					Shorten:   tt.request.GetAlias(),
+					ExpiresAt: expiresAtFromProto(tt.request.GetExpiresAt()),
+				}, gomock.Any()).
 					Times(1).Return(tt.urlStore.urlID, tt.urlStore.urlStoreError)
 			} else {
 				mockStorage.EXPECT().SaveURL(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
@@ -280,6 +329,46 @@ func TestGetURL(t *testing.T) {
 			wantErr: true,
 			errCode: codes.NotFound,
 		},
+		{
+			name: "Истек срок действия ссылки",
+			urlStore: &urlStore{
+				isValid:       true,
+				urlStoreError: storage.ErrExpired,
+			},
+			request: &pb.GetURLReq{UrlId: "abc"},
+			wantErr: true,
+			errCode: codes.NotFound,
+		},
+		{
+			name: "Исчерпан лимит переходов",
+			urlStore: &urlStore{
+				isValid:       true,
+				urlStoreError: storage.ErrClickLimit,
+			},
+			request: &pb.GetURLReq{UrlId: "abc"},
+			wantErr: true,
+			errCode: codes.NotFound,
+		},
+		{
+			name: "Неверный пароль",
+			urlStore: &urlStore{
+				isValid:       true,
+				urlStoreError: storage.ErrWrongPassword,
+			},
+			request: &pb.GetURLReq{UrlId: "abc", Password: "wrong"},
+			wantErr: true,
+			errCode: codes.PermissionDenied,
+		},
+		{
+			name: "Успешный запрос с паролем",
+			urlStore: &urlStore{
+				url:     "http://some.ru",
+				isValid: true,
+			},
+			request:  &pb.GetURLReq{UrlId: "abc", Password: "secret"},
+			expected: &pb.GetURLRes{OriginalUrl: "http://some.ru"},
+			wantErr:  false,
+		},
 		{
 			name: "Ссылка не найдена",
 			urlStore: &urlStore{
@@ -307,12 +396,12 @@ func TestGetURL(t *testing.T) {
 				mockStorage.EXPECT().IsValidID(tt.request.GetUrlId()).
 					Times(1).Return(tt.urlStore.isValid)
 				if tt.urlStore.isValid {
-					mockStorage.EXPECT().GetURL(gomock.Any(), tt.request.GetUrlId()).
-						Times(1).Return(tt.urlStore.url, tt.urlStore.urlStoreError)
+					mockStorage.EXPECT().GetURL(gomock.Any(), tt.request.GetUrlId(), tt.request.GetPassword()).
+						Times(1).Return(tt.urlStore.url, tt.request.GetUrlId(), tt.urlStore.urlStoreError)
 				}
 			} else {
 				mockStorage.EXPECT().IsValidID(gomock.Any()).Times(0)
-				mockStorage.EXPECT().GetURL(gomock.Any(), gomock.Any()).Times(0)
+				mockStorage.EXPECT().GetURL(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
 			}
 			response, err := server.GetURL(context.Background(), tt.request)
 			if !tt.wantErr {
@@ -344,6 +433,8 @@ func TestGetUserURLs(t *testing.T) {
 		name     string
 		urlStore *urlStore
 		user     *appCtx.CtxUser
+		request  *pb.GetUsersURLsReq
+		filter   storage.UserURLsFilter
 		expected *pb.GetUsersURLsRes
 		wantErr  bool
 		errCode  codes.Code
@@ -353,16 +444,62 @@ func TestGetUserURLs(t *testing.T) {
 			urlStore: &urlStore{
 				userURLs: []storage.ShortenURL{
 					{Original: "http://some1.ru", Shorten: "abc1"},
-					{Original: "http://some2.ru", Shorten: "abc2"},
+					{Original: "http://some2.ru", Shorten: "abc2", Tags: []string{"news"}},
 				},
 			},
 			user: &appCtx.CtxUser{ID: 1},
 			expected: &pb.GetUsersURLsRes{Urls: []*pb.GetUsersURLsRes_UserURL{
 				{OriginalUrl: "http://some1.ru", ShortUrl: "abc1"},
-				{OriginalUrl: "http://some2.ru", ShortUrl: "abc2"},
+				{OriginalUrl: "http://some2.ru", ShortUrl: "abc2", Tags: []string{"news"}},
+			}},
+			wantErr: false,
+		},
+		{
+			name: "Фильтр по тегам",
+			urlStore: &urlStore{
+				userURLs: []storage.ShortenURL{
+					{Original: "http://some2.ru", Shorten: "abc2", Tags: []string{"news", "sale"}},
+				},
+			},
+			user:    &appCtx.CtxUser{ID: 1},
+			request: &pb.GetUsersURLsReq{Tags: []string{"news", "sale"}},
+			filter:  storage.UserURLsFilter{Tags: []string{"news", "sale"}},
+			expected: &pb.GetUsersURLsRes{Urls: []*pb.GetUsersURLsRes_UserURL{
+				{OriginalUrl: "http://some2.ru", ShortUrl: "abc2", Tags: []string{"news", "sale"}},
 			}},
 			wantErr: false,
 		},
+		{
+			name: "Страница ссылок",
+			urlStore: &urlStore{
+				userURLs: []storage.ShortenURL{
+					{Original: "http://some1.ru", Shorten: "abc1"},
+					{Original: "http://some2.ru", Shorten: "abc2"},
+				},
+			},
+			user:    &appCtx.CtxUser{ID: 1},
+			request: &pb.GetUsersURLsReq{Limit: 1},
+			filter:  storage.UserURLsFilter{Limit: 2},
+			expected: &pb.GetUsersURLsRes{
+				Urls:       []*pb.GetUsersURLsRes_UserURL{{OriginalUrl: "http://some1.ru", ShortUrl: "abc1"}},
+				NextCursor: "YWJjMQ",
+			},
+			wantErr: false,
+		},
+		{
+			name:    "Некорректный размер страницы",
+			user:    &appCtx.CtxUser{ID: 1},
+			request: &pb.GetUsersURLsReq{Limit: -1},
+			wantErr: true,
+			errCode: codes.InvalidArgument,
+		},
+		{
+			name:    "Некорректный тег",
+			user:    &appCtx.CtxUser{ID: 1},
+			request: &pb.GetUsersURLsReq{Tags: []string{strings.Repeat("a", 65)}},
+			wantErr: true,
+			errCode: codes.InvalidArgument,
+		},
 		{
 			name: "Ошибка хранилища",
 			urlStore: &urlStore{
@@ -381,19 +518,386 @@ func TestGetUserURLs(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			if tt.urlStore != nil {
-				mockStorage.EXPECT().GetUserURLs(gomock.Any(), tt.user.ID).
+				mockStorage.EXPECT().GetUserURLs(gomock.Any(), tt.user.ID, tt.filter).
 					Times(1).Return(tt.urlStore.userURLs, tt.urlStore.storeError)
 			} else {
-				mockStorage.EXPECT().GetUserURLs(gomock.Any(), gomock.Any()).Times(0)
+				mockStorage.EXPECT().GetUserURLs(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
 			}
 			ctx := context.Background()
 			if tt.user != nil {
 				ctx = appCtx.CtxWithUser(ctx, tt.user)
 			}
-			response, err := server.GetUserURLs(ctx, &pb.GetUsersURLsReq{})
+			response, err := server.GetUserURLs(ctx, tt.request)
 			if !tt.wantErr {
 				require.NoError(t, err)
 				assert.ElementsMatch(t, tt.expected.GetUrls(), response.GetUrls())
+				assert.Equal(t, tt.expected.GetNextCursor(), response.GetNextCursor())
+			} else {
+				code, _ := status.FromError(err)
+				assert.Equal(t, tt.errCode, code.Code())
+			}
+		})
+	}
+}
+
+func TestGetURLStats(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockStorage := mocks.NewMockURLStorage(ctrl)
+	baseURL := url.URL{Scheme: "http", Host: "localhost:8080"}
+	service := service.NewService(mockStorage, baseURL)
+	server := URLShortenerServer{service: &service}
+
+	type urlStore struct {
+		storeError error
+		stats      *storage.URLStats
+	}
+
+	tests := []struct {
+		name     string
+		urlStore *urlStore
+		user     *appCtx.CtxUser
+		wantErr  bool
+		errCode  codes.Code
+	}{
+		{
+			name: "Успешный запрос",
+			urlStore: &urlStore{
+				stats: &storage.URLStats{
+					TotalClicks:    3,
+					UniqueVisitors: 2,
+					ClicksPerDay:   []storage.DayClicks{{Day: "2024-05-01", Clicks: 3}},
+					TopReferrers:   []storage.TopValue{{Value: "http://ref.ru", Count: 2}},
+					TopUserAgents:  []storage.TopValue{{Value: "agent", Count: 3}},
+				},
+			},
+			user:    &appCtx.CtxUser{ID: 1},
+			wantErr: false,
+		},
+		{
+			name: "Ссылка другого пользователя",
+			urlStore: &urlStore{
+				storeError: storage.ErrNoData,
+			},
+			user:    &appCtx.CtxUser{ID: 2},
+			wantErr: true,
+			errCode: codes.NotFound,
+		},
+		{
+			name: "Ошибка хранилища",
+			urlStore: &urlStore{
+				storeError: errors.New("store error"),
+			},
+			user:    &appCtx.CtxUser{ID: 1},
+			wantErr: true,
+			errCode: codes.Internal,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockStorage.EXPECT().IsValidID("abc1").Times(1).Return(true)
+			mockStorage.EXPECT().GetURLStats(gomock.Any(), tt.user.ID, "abc1").
+				Times(1).Return(tt.urlStore.stats, tt.urlStore.storeError)
+			ctx := appCtx.CtxWithUser(context.Background(), tt.user)
+			response, err := server.GetURLStats(ctx, &pb.GetURLStatsReq{UrlId: "abc1"})
+			if !tt.wantErr {
+				require.NoError(t, err)
+				assert.Equal(t, int32(3), response.GetTotalClicks())
+				assert.Equal(t, int32(2), response.GetUniqueVisitors())
+				require.Len(t, response.GetClicksPerDay(), 1)
+				assert.Equal(t, "2024-05-01", response.GetClicksPerDay()[0].GetDay())
+				require.Len(t, response.GetTopReferrers(), 1)
+				assert.Equal(t, "http://ref.ru", response.GetTopReferrers()[0].GetValue())
+				require.Len(t, response.GetTopUserAgents(), 1)
+				assert.Equal(t, int32(3), response.GetTopUserAgents()[0].GetCount())
+			} else {
+				code, _ := status.FromError(err)
+				assert.Equal(t, tt.errCode, code.Code())
+			}
+		})
+	}
+}
+
+func TestUpdateURL(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockStorage := mocks.NewMockURLStorage(ctrl)
+	baseURL := url.URL{Scheme: "http", Host: "localhost:8080"}
+	service := service.NewService(mockStorage, baseURL)
+	server := URLShortenerServer{service: &service}
+
+	type urlStore struct {
+		storeError error
+		tags       []string            // Ожидаемые новые теги (если в запросе передан список тегов)
+		stored     *storage.ShortenURL // Сохраненная ссылка после изменения
+	}
+
+	tests := []struct {
+		name     string
+		urlStore *urlStore
+		request  *pb.UpdateURLReq
+		expected *pb.UpdateURLRes
+		wantErr  bool
+		errCode  codes.Code
+	}{
+		{
+			name: "Успешный запрос",
+			urlStore: &urlStore{
+				stored: &storage.ShortenURL{Original: "http://new.ru", Shorten: "abc1", Tags: []string{"news"}},
+			},
+			request:  &pb.UpdateURLReq{UrlId: "abc1", OriginalUrl: "http://new.ru"},
+			expected: &pb.UpdateURLRes{OriginalUrl: "http://new.ru", Tags: []string{"news"}},
+			wantErr:  false,
+		},
+		{
+			name: "Изменение тегов",
+			urlStore: &urlStore{
+				tags:   []string{"news", "sale"},
+				stored: &storage.ShortenURL{Original: "http://old.ru", Shorten: "abc1", Tags: []string{"news", "sale"}},
+			},
+			request:  &pb.UpdateURLReq{UrlId: "abc1", Tags: &pb.TagList{Tags: []string{"sale", "news"}}},
+			expected: &pb.UpdateURLRes{OriginalUrl: "http://old.ru", Tags: []string{"news", "sale"}},
+			wantErr:  false,
+		},
+		{
+			name:    "Отсутствуют данные для изменения",
+			request: &pb.UpdateURLReq{UrlId: "abc1"},
+			wantErr: true,
+			errCode: codes.InvalidArgument,
+		},
+		{
+			name:    "Некорректный тег",
+			request: &pb.UpdateURLReq{UrlId: "abc1", Tags: &pb.TagList{Tags: []string{strings.Repeat("a", 65)}}},
+			wantErr: true,
+			errCode: codes.InvalidArgument,
+		},
+		{
+			name:    "Некорректная ссылка",
+			request: &pb.UpdateURLReq{UrlId: "abc1", OriginalUrl: "new.ru"},
+			wantErr: true,
+			errCode: codes.InvalidArgument,
+		},
+		{
+			name:     "Ссылка другого пользователя",
+			urlStore: &urlStore{storeError: storage.ErrNoData},
+			request:  &pb.UpdateURLReq{UrlId: "abc1", OriginalUrl: "http://new.ru"},
+			wantErr:  true,
+			errCode:  codes.NotFound,
+		},
+		{
+			name:     "Такая ссылка уже сохранена",
+			urlStore: &urlStore{storeError: storage.ErrConflict},
+			request:  &pb.UpdateURLReq{UrlId: "abc1", OriginalUrl: "http://new.ru"},
+			wantErr:  true,
+			errCode:  codes.AlreadyExists,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockStorage.EXPECT().IsValidID(tt.request.GetUrlId()).Times(1).Return(true)
+			if tt.urlStore != nil {
+				update := storage.URLUpdate{
+					Original:   tt.request.GetOriginalUrl(),
+					Tags:       tt.urlStore.tags,
+					UpdateTags: tt.request.GetTags() != nil,
+				}
+				mockStorage.EXPECT().UpdateURL(gomock.Any(), 1, tt.request.GetUrlId(), update).
+					Times(1).Return(tt.urlStore.stored, tt.urlStore.storeError)
+			} else {
+				mockStorage.EXPECT().UpdateURL(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			}
+			ctx := appCtx.CtxWithUser(context.Background(), &appCtx.CtxUser{ID: 1})
+			response, err := server.UpdateURL(ctx, tt.request)
+			if !tt.wantErr {
+				require.NoError(t, err)
+				assert.Equal(t, tt.expected.GetOriginalUrl(), response.GetOriginalUrl())
+				assert.Equal(t, tt.expected.GetTags(), response.GetTags())
+			} else {
+				code, _ := status.FromError(err)
+				assert.Equal(t, tt.errCode, code.Code())
+			}
+		})
+	}
+}
+
+// testExportStream - поток ответа ExportUserURLs для тестов.
+type testExportStream struct {
+	grpc.ServerStream
+	ctx     context.Context
+	sent    []*pb.ExportUserURLsRes
+	sendErr error
+}
+
+func (s *testExportStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *testExportStream) Send(res *pb.ExportUserURLsRes) error {
+	if s.sendErr != nil {
+		return s.sendErr
+	}
+	s.sent = append(s.sent, res)
+	return nil
+}
+
+func TestExportUserURLs(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockStorage := mocks.NewMockURLStorage(ctrl)
+	baseURL := url.URL{Scheme: "http", Host: "localhost:8080"}
+	service := service.NewService(mockStorage, baseURL)
+	server := URLShortenerServer{service: &service}
+	ctx := appCtx.CtxWithUser(context.Background(), &appCtx.CtxUser{ID: 1})
+	expiresAt := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	type urlStore struct {
+		storeError error
+		userURLs   []storage.ShortenURL
+	}
+
+	tests := []struct {
+		name     string
+		urlStore urlStore
+		sendErr  error
+		expected []*pb.ExportUserURLsRes
+		wantErr  bool
+		errCode  codes.Code
+	}{
+		{
+			name: "Успешный запрос",
+			urlStore: urlStore{userURLs: []storage.ShortenURL{
+				{Original: "http://some1.ru", Shorten: "abc1", Tags: []string{"news"}},
+				{Original: "http://some2.ru", Shorten: "abc2", ExpiresAt: expiresAt},
+			}},
+			expected: []*pb.ExportUserURLsRes{
+				{OriginalUrl: "http://some1.ru", ShortUrl: "http://localhost:8080/abc1", Tags: []string{"news"}},
+				{OriginalUrl: "http://some2.ru", ShortUrl: "http://localhost:8080/abc2", ExpiresAt: timestamppb.New(expiresAt)},
+			},
+		},
+		{
+			name:     "Ошибка хранилища",
+			urlStore: urlStore{storeError: errors.New("store error")},
+			wantErr:  true,
+			errCode:  codes.Internal,
+		},
+		{
+			name: "Ошибка отправки",
+			urlStore: urlStore{userURLs: []storage.ShortenURL{
+				{Original: "http://some1.ru", Shorten: "abc1"},
+			}},
+			sendErr: status.Error(codes.Canceled, "canceled"),
+			wantErr: true,
+			errCode: codes.Canceled,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockStorage.EXPECT().ExportUserURLs(gomock.Any(), 1, gomock.Any()).
+				Times(1).
+				DoAndReturn(func(_ context.Context, _ int, fn func(url storage.ShortenURL) error) error {
+					for _, url := range tt.urlStore.userURLs {
+						if err := fn(url); err != nil {
+							return err
+						}
+					}
+					return tt.urlStore.storeError
+				})
+			stream := &testExportStream{ctx: ctx, sendErr: tt.sendErr}
+			err := server.ExportUserURLs(&pb.ExportUserURLsReq{}, stream)
+			if !tt.wantErr {
+				require.NoError(t, err)
+				require.Len(t, stream.sent, len(tt.expected))
+				for i := range tt.expected {
+					assert.Equal(t, tt.expected[i].GetOriginalUrl(), stream.sent[i].GetOriginalUrl())
+					assert.Equal(t, tt.expected[i].GetShortUrl(), stream.sent[i].GetShortUrl())
+					assert.Equal(t, tt.expected[i].GetTags(), stream.sent[i].GetTags())
+					assert.Equal(t, tt.expected[i].GetExpiresAt().AsTime(), stream.sent[i].GetExpiresAt().AsTime())
+				}
+			} else {
+				code, _ := status.FromError(err)
+				assert.Equal(t, tt.errCode, code.Code())
+			}
+		})
+	}
+}
+
+func TestGetDeletedUserURLs(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockStorage := mocks.NewMockURLStorage(ctrl)
+	baseURL := url.URL{Scheme: "http", Host: "localhost:8080"}
+	service := service.NewService(mockStorage, baseURL)
+	server := URLShortenerServer{service: &service}
+	ctx := appCtx.CtxWithUser(context.Background(), &appCtx.CtxUser{ID: 1})
+
+	mockStorage.EXPECT().GetDeletedUserURLs(gomock.Any(), 1).
+		Times(1).Return([]storage.ShortenURL{{Original: "http://some1.ru", Shorten: "abc1"}}, nil)
+	response, err := server.GetDeletedUserURLs(ctx, &pb.GetDeletedUserURLsReq{})
+	require.NoError(t, err)
+	require.Len(t, response.GetUrls(), 1)
+	assert.Equal(t, "http://some1.ru", response.GetUrls()[0].GetOriginalUrl())
+	assert.Equal(t, "abc1", response.GetUrls()[0].GetShortUrl())
+
+	mockStorage.EXPECT().GetDeletedUserURLs(gomock.Any(), 1).
+		Times(1).Return(nil, errors.New("store error"))
+	_, err = server.GetDeletedUserURLs(ctx, &pb.GetDeletedUserURLsReq{})
+	code, _ := status.FromError(err)
+	assert.Equal(t, codes.Internal, code.Code())
+}
+
+func TestRestoreUserURLs(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockStorage := mocks.NewMockURLStorage(ctrl)
+	baseURL := url.URL{Scheme: "http", Host: "localhost:8080"}
+	service := service.NewService(mockStorage, baseURL)
+	server := URLShortenerServer{service: &service}
+
+	tests := []struct {
+		name       string
+		storeError error
+		request    *pb.RestoreUserURLsReq
+		wantErr    bool
+		errCode    codes.Code
+	}{
+		{
+			name:    "Успешный запрос",
+			request: &pb.RestoreUserURLsReq{Urls: []string{"abc1", "abc2"}},
+			wantErr: false,
+		},
+		{
+			name:    "Нет данных для восстановления",
+			request: &pb.RestoreUserURLsReq{Urls: []string{}},
+			wantErr: true,
+			errCode: codes.NotFound,
+		},
+		{
+			name:       "Ошибка хранилища",
+			storeError: errors.New("store error"),
+			request:    &pb.RestoreUserURLsReq{Urls: []string{"abc1"}},
+			wantErr:    true,
+			errCode:    codes.Internal,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if len(tt.request.GetUrls()) > 0 {
+				mockStorage.EXPECT().RestoreUserURLs(gomock.Any(), 1, tt.request.GetUrls()).
+					Times(1).Return(tt.storeError)
+			}
+			ctx := appCtx.CtxWithUser(context.Background(), &appCtx.CtxUser{ID: 1})
+			_, err := server.RestoreUserURLs(ctx, tt.request)
+			if !tt.wantErr {
+				require.NoError(t, err)
 			} else {
 				code, _ := status.FromError(err)
 				assert.Equal(t, tt.errCode, code.Code())
@@ -490,8 +994,9 @@ func TestGetStats(t *testing.T) {
 		count int
 	}
 	type urlStore struct {
-		users *stat
-		urls  *stat
+		users  *stat
+		urls   *stat
+		purged *stat
 	}
 
 	tests := []struct {
@@ -510,8 +1015,11 @@ func TestGetStats(t *testing.T) {
 				urls: &stat{
 					count: 20,
 				},
+				purged: &stat{
+					count: 5,
+				},
 			},
-			expected: &pb.GetStatsRes{Users: 10, Urls: 20},
+			expected: &pb.GetStatsRes{Users: 10, Urls: 20, PurgedUrls: 5},
 			wantErr:  false,
 		},
 		{
@@ -553,12 +1061,19 @@ func TestGetStats(t *testing.T) {
 			} else {
 				mockStorage.EXPECT().GetUsersCount(gomock.Any()).Times(0)
 			}
+			if tt.urlStore.purged != nil {
+				mockStorage.EXPECT().GetPurgedURLsCount(gomock.Any()).
+					Times(1).Return(tt.urlStore.purged.count, tt.urlStore.purged.err)
+			} else {
+				mockStorage.EXPECT().GetPurgedURLsCount(gomock.Any()).Times(0)
+			}
 
 			result, err := server.GetStats(context.Background(), &pb.GetStatsReq{})
 			if !tt.wantErr {
 				require.NoError(t, err)
 				assert.Equal(t, tt.expected.GetUrls(), result.GetUrls())
 				assert.Equal(t, tt.expected.GetUsers(), result.GetUsers())
+				assert.Equal(t, tt.expected.GetPurgedUrls(), result.GetPurgedUrls())
 			} else {
 				code, _ := status.FromError(err)
 				assert.Equal(t, tt.errCode, code.Code())
@@ -26,7 +26,7 @@ func ExampleURLHandler_HandleJSONShortenURL() {
 		log.Fatal(err)
 	}
 	service := service.NewService(mockStorage, *baseURL)
-	handler := handlers.NewURLHandler(&service, *baseURL)
+	handler := handlers.NewURLHandler(&service, *baseURL, nil)
 
 	reqBody := `{"url":"http://example.com"}`
 	request := httptest.NewRequest(http.MethodPost, "/api/shorten", strings.NewReader(reqBody))
@@ -53,7 +53,7 @@ func ExampleURLHandler_HandleShortenBatchURL() {
 		log.Fatal(err)
 	}
 	service := service.NewService(mockStorage, *baseURL)
-	handler := handlers.NewURLHandler(&service, *baseURL)
+	handler := handlers.NewURLHandler(&service, *baseURL, nil)
 
 	reqBody := `[
 								{
@@ -90,9 +90,9 @@ func ExampleURLHandler_HandleRedirect() {
 		log.Fatal(err)
 	}
 	service := service.NewService(mockStorage, *baseURL)
-	handler := handlers.NewURLHandler(&service, *baseURL)
+	handler := handlers.NewURLHandler(&service, *baseURL, nil)
 
-	shortURL, err := mockStorage.SaveURL(context.Background(), "http://example.com", 1)
+	shortURL, err := mockStorage.SaveURL(context.Background(), storage.ShortenURL{Original: "http://example.com"}, 1)
 	if err != nil {
 		log.Fatal(err)
 	}
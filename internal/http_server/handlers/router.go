@@ -27,6 +27,7 @@ func NewURLRouter(urlHandler URLHandler, service *service.Service, trustedSubnet
 	r.Route("/", func(r chi.Router) {
 		r.Get("/ping", urlHandler.HandlePing)
 		r.Get("/{urlID}", urlHandler.HandleRedirect)
+		r.Post("/{urlID}", urlHandler.HandleRedirect)
 		r.Post("/", urlHandler.HandleShortenURL)
 	})
 	r.Route("/api", func(r chi.Router) {
@@ -37,6 +38,12 @@ func NewURLRouter(urlHandler URLHandler, service *service.Service, trustedSubnet
 			r.Use(amw.RequireUser)
 			r.Get("/urls", urlHandler.HandleGetUsersURLs)
 			r.Delete("/urls", urlHandler.HandleDeleteUserURLs)
+			r.Get("/urls/deleted", urlHandler.HandleGetDeletedUserURLs)
+			r.Post("/urls/restore", urlHandler.HandleRestoreUserURLs)
+			r.Post("/urls/import", urlHandler.HandleImportURLs)
+			r.Get("/urls/export", urlHandler.HandleExportURLs)
+			r.Patch("/urls/{urlID}", urlHandler.HandleUpdateURL)
+			r.Get("/urls/{urlID}/stats", urlHandler.HandleGetURLStats)
 		})
 
 		r.Route("/internal", func(r chi.Router) {
@@ -1,29 +1,45 @@
 package handlers
 
 import (
+	"encoding/csv"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"html/template"
 	"io"
+	"net"
 	"net/http"
 	"net/url"
+	"strconv"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/pinbrain/urlshortener/internal/logger"
 	"github.com/pinbrain/urlshortener/internal/service"
 )
 
+// nextCursorHeader - заголовок ответа с токеном следующей страницы ссылок пользователя.
+const nextCursorHeader = "X-Next-Cursor"
+
 // URLHandler определяет структуру обработчика запросов сервиса.
 type URLHandler struct {
-	service *service.Service // Сервис с бизнес логикой приложения
-	baseURL *url.URL         // Базовый url сокращаемых ссылок
-	wg      *sync.WaitGroup  // Waiting group для go рутин хендлера
+	service       *service.Service // Сервис с бизнес логикой приложения
+	baseURL       *url.URL         // Базовый url сокращаемых ссылок
+	wg            *sync.WaitGroup  // Waiting group для go рутин хендлера
+	trustedSubnet *net.IPNet       // Доверенная подсеть прокси, заголовку X-Real-IP которых можно верить
 }
 
 // shortenRequest определяет формат запроса на сокращение ссылки.
 type shortenRequest struct {
-	URL string `json:"url"`
+	URL       string    `json:"url"`
+	Alias     string    `json:"alias,omitempty"`      // Пользовательский id сокращенной ссылки (необязательный)
+	ExpiresAt time.Time `json:"expires_at,omitempty"` // Время истечения срока действия ссылки в формате RFC 3339 (необязательное)
+	TTL       int64     `json:"ttl,omitempty"`        // Срок действия ссылки в секундах (необязательный)
+	MaxClicks int       `json:"max_clicks,omitempty"` // Максимальное количество переходов по ссылке (необязательное)
+	Password  string    `json:"password,omitempty"`   // Пароль для перехода по ссылке (необязательный)
+	Tags      []string  `json:"tags,omitempty"`       // Теги ссылки (необязательные)
 }
 
 // shortenRequest определяет формат ответа на сокращение ссылки.
@@ -33,8 +49,14 @@ type shortenResponse struct {
 
 // shortenRequest определяет формат запроса на сокращение нескольких ссылок.
 type batchShortenRequest struct {
-	CorrelationID string `json:"correlation_id"`
-	OriginalURL   string `json:"original_url"`
+	CorrelationID string    `json:"correlation_id"`
+	OriginalURL   string    `json:"original_url"`
+	Alias         string    `json:"alias,omitempty"`      // Пользовательский id сокращенной ссылки (необязательный)
+	ExpiresAt     time.Time `json:"expires_at,omitempty"` // Время истечения срока действия ссылки в формате RFC 3339 (необязательное)
+	TTL           int64     `json:"ttl,omitempty"`        // Срок действия ссылки в секундах (необязательный)
+	MaxClicks     int       `json:"max_clicks,omitempty"` // Максимальное количество переходов по ссылке (необязательное)
+	Password      string    `json:"password,omitempty"`   // Пароль для перехода по ссылке (необязательный)
+	Tags          []string  `json:"tags,omitempty"`       // Теги ссылки (необязательные)
 }
 
 // shortenRequest определяет формат ответа на сокращение нескольких ссылок.
@@ -45,21 +67,100 @@ type batchShortenResponse struct {
 
 // userURLResponse определяет формат ответа на запрос ссылок, сокращенных пользователем.
 type userURLResponse struct {
-	OriginalURL string `json:"original_url"` // Исходная ссылка
-	ShortURL    string `json:"short_url"`    // Сокращенная ссылка
+	OriginalURL string   `json:"original_url"`   // Исходная ссылка
+	ShortURL    string   `json:"short_url"`      // Сокращенная ссылка
+	Tags        []string `json:"tags,omitempty"` // Теги ссылки
 }
 
+// passwordFormTmpl - HTML форма ввода пароля для перехода по защищенной ссылке.
+var passwordFormTmpl = template.Must(template.New("password").Parse(`<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><title>Защищенная ссылка</title></head>
+<body>
+<form method="POST">
+{{if .}}<p>{{.}}</p>{{end}}
+<label>Пароль: <input type="password" name="password" autofocus></label>
+<button type="submit">Перейти</button>
+</form>
+</body>
+</html>
+`))
+
 type statsResponse struct {
-	URLs  int `json:"urls"`  // количество сокращённых URL в сервисе
-	Users int `json:"users"` // количество пользователей в сервисе
+	URLs       int `json:"urls"`        // количество сокращённых URL в сервисе
+	Users      int `json:"users"`       // количество пользователей в сервисе
+	PurgedURLs int `json:"purged_urls"` // количество окончательно удаленных URL
+}
+
+// importResultResponse определяет формат результата импорта одной ссылки.
+type importResultResponse struct {
+	Row         int    `json:"row"`                 // Номер строки CSV (без учета строки заголовка)
+	OriginalURL string `json:"original_url"`        // Исходная ссылка
+	ShortURL    string `json:"short_url,omitempty"` // Созданная или уже существующая сокращенная ссылка
+	Status      string `json:"status"`              // Результат импорта
+}
+
+// importErrorResponse определяет формат ответа на прерванный импорт.
+type importErrorResponse struct {
+	Error   string                 `json:"error"`   // Причина, по которой импорт прерван
+	Results []importResultResponse `json:"results"` // Результаты строк, обработанных до ошибки (они уже сохранены)
+}
+
+// importTagsSeparator - разделитель тегов в столбце тегов CSV импорта (и выгрузки).
+const importTagsSeparator = "|"
+
+// exportURLResponse определяет формат выгруженной ссылки в формате NDJSON.
+type exportURLResponse struct {
+	OriginalURL string     `json:"original_url"`         // Исходная ссылка
+	ShortURL    string     `json:"short_url"`            // Сокращенная ссылка
+	Tags        []string   `json:"tags,omitempty"`       // Теги ссылки
+	ExpiresAt   *time.Time `json:"expires_at,omitempty"` // Время истечения срока действия ссылки
+}
+
+// exportCSVHeader - строка заголовка выгрузки ссылок в формате CSV.
+var exportCSVHeader = []string{"original_url", "short_url", "tags", "expires_at"}
+
+// updateURLRequest определяет формат запроса на изменение сокращенной ссылки.
+type updateURLRequest struct {
+	URL  string    `json:"url,omitempty"`  // Новая полная ссылка (необязательная)
+	Tags *[]string `json:"tags,omitempty"` // Новые теги ссылки, заменяющие текущие (необязательные)
+}
+
+// updateURLResponse определяет формат ответа на изменение сокращенной ссылки.
+type updateURLResponse struct {
+	ShortURL    string   `json:"short_url"`              // Сокращенная ссылка
+	OriginalURL string   `json:"original_url,omitempty"` // Новая полная ссылка (если изменялась)
+	Tags        []string `json:"tags,omitempty"`         // Новые теги ссылки (если изменялись)
+}
+
+// urlStatsResponse определяет формат ответа на запрос статистики переходов по ссылке.
+type urlStatsResponse struct {
+	TotalClicks    int                 `json:"total_clicks"`    // Общее количество переходов
+	UniqueVisitors int                 `json:"unique_visitors"` // Количество уникальных посетителей
+	ClicksPerDay   []dayClicksResponse `json:"clicks_per_day"`  // Количество переходов по дням
+	TopReferrers   []topValueResponse  `json:"top_referrers"`   // Самые частые источники переходов
+	TopUserAgents  []topValueResponse  `json:"top_user_agents"` // Самые частые User-Agent клиентов
+}
+
+// dayClicksResponse определяет формат количества переходов за день.
+type dayClicksResponse struct {
+	Day    string `json:"day"`    // День в формате 2006-01-02 (UTC)
+	Clicks int    `json:"clicks"` // Количество переходов
+}
+
+// topValueResponse определяет формат значения из топа статистики.
+type topValueResponse struct {
+	Value string `json:"value"`
+	Count int    `json:"count"`
 }
 
 // NewURLHandler создает и возвращает новый обработчик запросов.
-func NewURLHandler(service *service.Service, baseURL url.URL) URLHandler {
+func NewURLHandler(service *service.Service, baseURL url.URL, trustedSubnet *net.IPNet) URLHandler {
 	return URLHandler{
-		service: service,
-		baseURL: &baseURL,
-		wg:      &sync.WaitGroup{},
+		service:       service,
+		baseURL:       &baseURL,
+		wg:            &sync.WaitGroup{},
+		trustedSubnet: trustedSubnet,
 	}
 }
 
@@ -77,7 +178,7 @@ func (h *URLHandler) HandleShortenURL(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	url := string(body)
-	shortURL, err := h.service.ShortenURL(r.Context(), url)
+	shortURL, err := h.service.ShortenURL(r.Context(), url, service.ShortenOptions{})
 	if err != nil {
 		switch {
 		case errors.Is(err, service.ErrURLConflict):
@@ -118,7 +219,14 @@ func (h *URLHandler) HandleJSONShortenURL(w http.ResponseWriter, r *http.Request
 		http.Error(w, "Отсутствует ссылка для сокращения", http.StatusBadRequest)
 		return
 	}
-	shortURL, err := h.service.ShortenURL(r.Context(), req.URL)
+	shortURL, err := h.service.ShortenURL(r.Context(), req.URL, service.ShortenOptions{
+		Alias:     req.Alias,
+		ExpiresAt: req.ExpiresAt,
+		TTL:       time.Duration(req.TTL) * time.Second,
+		MaxClicks: req.MaxClicks,
+		Password:  req.Password,
+		Tags:      req.Tags,
+	})
 	w.Header().Set("Content-Type", "application/json")
 	if err != nil {
 		switch {
@@ -127,6 +235,24 @@ func (h *URLHandler) HandleJSONShortenURL(w http.ResponseWriter, r *http.Request
 		case errors.Is(err, service.ErrInvalidURL):
 			http.Error(w, "Некорректная ссылка для сокращения", http.StatusBadRequest)
 			return
+		case errors.Is(err, service.ErrInvalidAlias):
+			http.Error(w, "Некорректный алиас сокращенной ссылки", http.StatusBadRequest)
+			return
+		case errors.Is(err, service.ErrAliasConflict):
+			http.Error(w, "Сокращенная ссылка с таким алиасом уже существует", http.StatusConflict)
+			return
+		case errors.Is(err, service.ErrInvalidExpiry):
+			http.Error(w, "Некорректный срок действия сокращенной ссылки", http.StatusBadRequest)
+			return
+		case errors.Is(err, service.ErrInvalidLimit):
+			http.Error(w, "Некорректный лимит переходов по сокращенной ссылке", http.StatusBadRequest)
+			return
+		case errors.Is(err, service.ErrInvalidPassword):
+			http.Error(w, "Некорректный пароль сокращенной ссылки", http.StatusBadRequest)
+			return
+		case errors.Is(err, service.ErrInvalidTags):
+			http.Error(w, "Некорректные теги сокращенной ссылки", http.StatusBadRequest)
+			return
 		default:
 			logger.Log.Errorw("Error while saving url for shorten", "err", err)
 			http.Error(w, "Internal server error", http.StatusInternalServerError)
@@ -166,6 +292,12 @@ func (h *URLHandler) HandleShortenBatchURL(w http.ResponseWriter, r *http.Reques
 		shortenURLs = append(shortenURLs, service.BatchURL{
 			OriginalURL:   reqURL.OriginalURL,
 			CorrelationID: reqURL.CorrelationID,
+			Alias:         reqURL.Alias,
+			ExpiresAt:     reqURL.ExpiresAt,
+			TTL:           time.Duration(reqURL.TTL) * time.Second,
+			MaxClicks:     reqURL.MaxClicks,
+			Password:      reqURL.Password,
+			Tags:          reqURL.Tags,
 		})
 	}
 	savedBatch, err := h.service.ShortenBatchURL(r.Context(), shortenURLs)
@@ -177,6 +309,24 @@ func (h *URLHandler) HandleShortenBatchURL(w http.ResponseWriter, r *http.Reques
 		case errors.Is(err, service.ErrNoData):
 			http.Error(w, "Отсутствуют данные для сокращения", http.StatusBadRequest)
 			return
+		case errors.Is(err, service.ErrInvalidAlias):
+			http.Error(w, "Некорректный алиас сокращенной ссылки", http.StatusBadRequest)
+			return
+		case errors.Is(err, service.ErrAliasConflict):
+			http.Error(w, "Сокращенная ссылка с таким алиасом уже существует", http.StatusConflict)
+			return
+		case errors.Is(err, service.ErrInvalidExpiry):
+			http.Error(w, "Некорректный срок действия сокращенной ссылки", http.StatusBadRequest)
+			return
+		case errors.Is(err, service.ErrInvalidLimit):
+			http.Error(w, "Некорректный лимит переходов по сокращенной ссылке", http.StatusBadRequest)
+			return
+		case errors.Is(err, service.ErrInvalidPassword):
+			http.Error(w, "Некорректный пароль сокращенной ссылки", http.StatusBadRequest)
+			return
+		case errors.Is(err, service.ErrInvalidTags):
+			http.Error(w, "Некорректные теги сокращенной ссылки", http.StatusBadRequest)
+			return
 		default:
 			logger.Log.Errorw("Error in saving batch of urls in store", "err", err)
 			http.Error(w, "Internal server error", http.StatusInternalServerError)
@@ -202,11 +352,35 @@ func (h *URLHandler) HandleShortenBatchURL(w http.ResponseWriter, r *http.Reques
 }
 
 // HandleGetUsersURLs обрабатывает запрос на получение ссылок, сокращенных пользователем.
+// Параметры запроса tag (можно передать несколько) оставляют только ссылки со всеми переданными тегами,
+// параметры q и domain - только ссылки, полная ссылка которых содержит строку q и ведет на домен domain.
+// Параметры limit и cursor задают размер и токен страницы. Токен следующей страницы
+// передается в заголовке ответа nextCursorHeader (если страница не последняя).
 func (h *URLHandler) HandleGetUsersURLs(w http.ResponseWriter, r *http.Request) {
-	userURLs, err := h.service.GetUserURLs(r.Context())
+	query := service.UserURLsQuery{
+		Tags:   r.URL.Query()["tag"],
+		Query:  r.URL.Query().Get("q"),
+		Domain: r.URL.Query().Get("domain"),
+		Cursor: r.URL.Query().Get("cursor"),
+	}
+	if limit := r.URL.Query().Get("limit"); limit != "" {
+		var err error
+		if query.Limit, err = strconv.Atoi(limit); err != nil || query.Limit <= 0 {
+			http.Error(w, "Некорректные параметры страницы", http.StatusBadRequest)
+			return
+		}
+	}
+	userURLs, nextCursor, err := h.service.GetUserURLs(r.Context(), query)
 	if err != nil {
-		logger.Log.Errorw("Error in getting user shorten urls", "err", err)
-		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		switch {
+		case errors.Is(err, service.ErrInvalidTags):
+			http.Error(w, "Некорректные теги сокращенной ссылки", http.StatusBadRequest)
+		case errors.Is(err, service.ErrInvalidPage):
+			http.Error(w, "Некорректные параметры страницы", http.StatusBadRequest)
+		default:
+			logger.Log.Errorw("Error in getting user shorten urls", "err", err)
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+		}
 		return
 	}
 	resp := []userURLResponse{}
@@ -214,6 +388,7 @@ func (h *URLHandler) HandleGetUsersURLs(w http.ResponseWriter, r *http.Request)
 		result := userURLResponse{
 			OriginalURL: url.OriginalURL,
 			ShortURL:    h.baseURL.JoinPath(url.ShortURL).String(),
+			Tags:        url.Tags,
 		}
 		resp = append(resp, result)
 	}
@@ -223,12 +398,318 @@ func (h *URLHandler) HandleGetUsersURLs(w http.ResponseWriter, r *http.Request)
 		w.WriteHeader(http.StatusNoContent)
 		return
 	}
+	if nextCursor != "" {
+		w.Header().Set(nextCursorHeader, nextCursor)
+	}
 	enc := json.NewEncoder(w)
 	if err = enc.Encode(resp); err != nil {
 		logger.Log.Errorw("Error in encoding user urls response to json", "err", err)
 	}
 }
 
+// HandleGetURLStats обрабатывает запрос на получение статистики переходов по ссылке пользователя.
+func (h *URLHandler) HandleGetURLStats(w http.ResponseWriter, r *http.Request) {
+	urlID := chi.URLParam(r, "urlID")
+	stats, err := h.service.GetURLStats(r.Context(), urlID)
+	if err != nil {
+		if errors.Is(err, service.ErrNotFound) {
+			http.Error(w, "Сокращенная ссылка не найдена", http.StatusNotFound)
+			return
+		}
+		logger.Log.Errorw("Error in getting url stats", "err", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	resp := urlStatsResponse{
+		TotalClicks:    stats.TotalClicks,
+		UniqueVisitors: stats.UniqueVisitors,
+		ClicksPerDay:   []dayClicksResponse{},
+		TopReferrers:   []topValueResponse{},
+		TopUserAgents:  []topValueResponse{},
+	}
+	for _, day := range stats.ClicksPerDay {
+		resp.ClicksPerDay = append(resp.ClicksPerDay, dayClicksResponse{Day: day.Day, Clicks: day.Clicks})
+	}
+	for _, referrer := range stats.TopReferrers {
+		resp.TopReferrers = append(resp.TopReferrers, topValueResponse{Value: referrer.Value, Count: referrer.Count})
+	}
+	for _, userAgent := range stats.TopUserAgents {
+		resp.TopUserAgents = append(resp.TopUserAgents, topValueResponse{Value: userAgent.Value, Count: userAgent.Count})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	enc := json.NewEncoder(w)
+	if err = enc.Encode(resp); err != nil {
+		logger.Log.Errorw("Error in encoding url stats response to json", "err", err)
+	}
+}
+
+// HandleGetDeletedUserURLs обрабатывает запрос на получение удаленных ссылок пользователя.
+func (h *URLHandler) HandleGetDeletedUserURLs(w http.ResponseWriter, r *http.Request) {
+	userURLs, err := h.service.GetDeletedUserURLs(r.Context())
+	if err != nil {
+		logger.Log.Errorw("Error in getting deleted user urls", "err", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	resp := []userURLResponse{}
+	for _, url := range userURLs {
+		resp = append(resp, userURLResponse{
+			OriginalURL: url.OriginalURL,
+			ShortURL:    h.baseURL.JoinPath(url.ShortURL).String(),
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if len(resp) == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	enc := json.NewEncoder(w)
+	if err = enc.Encode(resp); err != nil {
+		logger.Log.Errorw("Error in encoding deleted user urls response to json", "err", err)
+	}
+}
+
+// HandleRestoreUserURLs обрабатывает запрос на восстановление удаленных ссылок.
+func (h *URLHandler) HandleRestoreUserURLs(w http.ResponseWriter, r *http.Request) {
+	contentType := r.Header.Get("Content-Type")
+	if !strings.Contains(contentType, "application/json") {
+		http.Error(w, "Invalid content type", http.StatusBadRequest)
+		return
+	}
+
+	var req []string
+	dec := json.NewDecoder(r.Body)
+	if err := dec.Decode(&req); err != nil {
+		logger.Log.Errorw("Error in decoding request body", "err", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	if len(req) == 0 {
+		http.Error(w, "Отсутствуют данные для восстановления", http.StatusBadRequest)
+		return
+	}
+
+	if err := h.service.RestoreUserURLs(r.Context(), req); err != nil {
+		logger.Log.Errorw("Error in restoring user urls", "err", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// HandleUpdateURL обрабатывает запрос на изменение сокращенной ссылки пользователя:
+// полной ссылки, на которую она ведет, и (или) ее тегов.
+func (h *URLHandler) HandleUpdateURL(w http.ResponseWriter, r *http.Request) {
+	contentType := r.Header.Get("Content-Type")
+	if !strings.Contains(contentType, "application/json") {
+		http.Error(w, "Invalid content type", http.StatusBadRequest)
+		return
+	}
+
+	var req updateURLRequest
+	dec := json.NewDecoder(r.Body)
+	if err := dec.Decode(&req); err != nil {
+		logger.Log.Errorw("Error in decoding update url request body", "err", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	if req.URL == "" && req.Tags == nil {
+		http.Error(w, "Отсутствуют данные для изменения", http.StatusBadRequest)
+		return
+	}
+
+	urlID := chi.URLParam(r, "urlID")
+	update := service.URLUpdate{OriginalURL: req.URL, UpdateTags: req.Tags != nil}
+	if req.Tags != nil {
+		update.Tags = *req.Tags
+	}
+	updated, err := h.service.UpdateURL(r.Context(), urlID, update)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrNotFound):
+			http.Error(w, "Сокращенная ссылка не найдена", http.StatusNotFound)
+		case errors.Is(err, service.ErrInvalidURL):
+			http.Error(w, "Некорректная ссылка", http.StatusBadRequest)
+		case errors.Is(err, service.ErrInvalidTags):
+			http.Error(w, "Некорректные теги сокращенной ссылки", http.StatusBadRequest)
+		case errors.Is(err, service.ErrURLConflict):
+			http.Error(w, "Ссылка уже сохранена", http.StatusConflict)
+		default:
+			logger.Log.Errorw("Error in updating user url", "err", err)
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	resp := updateURLResponse{
+		ShortURL:    h.baseURL.JoinPath(updated.ShortURL).String(),
+		OriginalURL: updated.OriginalURL,
+		Tags:        updated.Tags,
+	}
+	enc := json.NewEncoder(w)
+	if err = enc.Encode(resp); err != nil {
+		logger.Log.Errorw("Error in encoding update url response to json", "err", err)
+	}
+}
+
+// HandleImportURLs обрабатывает запрос на импорт ссылок пользователя из CSV.
+// Строка CSV: original_url[,alias][,tags], теги разделяются символом importTagsSeparator.
+// Первая строка пропускается, если это заголовок (первое поле - original_url).
+// В ответе возвращается результат импорта каждой строки. Если импорт прерван ошибкой (некорректный CSV
+// или ошибка хранилища), в ответе возвращаются ошибка и результаты строк, обработанных и сохраненных до нее.
+func (h *URLHandler) HandleImportURLs(w http.ResponseWriter, r *http.Request) {
+	contentType := r.Header.Get("Content-Type")
+	if !strings.Contains(contentType, "text/csv") {
+		http.Error(w, "Invalid content type", http.StatusBadRequest)
+		return
+	}
+
+	reader := csv.NewReader(r.Body)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	reader.ReuseRecord = true
+	firstRow := true
+	next := func() (service.ImportURL, error) {
+		record, err := reader.Read()
+		if err != nil {
+			return service.ImportURL{}, err
+		}
+		if firstRow {
+			firstRow = false
+			if strings.EqualFold(strings.TrimSpace(record[0]), "original_url") {
+				if record, err = reader.Read(); err != nil {
+					return service.ImportURL{}, err
+				}
+			}
+		}
+		importURL := service.ImportURL{OriginalURL: strings.TrimSpace(record[0])}
+		if len(record) > 1 {
+			importURL.Alias = strings.TrimSpace(record[1])
+		}
+		if len(record) > 2 && record[2] != "" {
+			importURL.Tags = strings.Split(record[2], importTagsSeparator)
+		}
+		return importURL, nil
+	}
+
+	results, err := h.service.ImportURLs(r.Context(), next)
+	if errors.Is(err, service.ErrNoData) {
+		http.Error(w, "Отсутствуют данные для импорта", http.StatusBadRequest)
+		return
+	}
+
+	status := http.StatusOK
+	var resp any = importResultsResponse(results)
+	if err != nil {
+		// Ссылки, обработанные до ошибки, уже сохранены, поэтому их результаты возвращаются вместе с ошибкой
+		errResp := importErrorResponse{Error: "Internal server error", Results: importResultsResponse(results)}
+		status = http.StatusInternalServerError
+		var parseErr *csv.ParseError
+		if !errors.Is(err, service.ErrStorageError) && errors.As(err, &parseErr) {
+			errResp.Error = fmt.Sprintf("Некорректный формат CSV в строке %d (обработано строк до нее: %d)",
+				parseErr.Line, len(results))
+			status = http.StatusBadRequest
+		} else {
+			logger.Log.Errorw("Error in importing user urls", "err", err)
+		}
+		resp = errResp
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	enc := json.NewEncoder(w)
+	if err = enc.Encode(resp); err != nil {
+		logger.Log.Errorw("Error in encoding import response to json", "err", err)
+	}
+}
+
+// importResultsResponse переводит результаты импорта в формат ответа.
+func importResultsResponse(results []service.ImportResult) []importResultResponse {
+	resp := make([]importResultResponse, 0, len(results))
+	for _, result := range results {
+		resp = append(resp, importResultResponse{
+			Row:         result.Row,
+			OriginalURL: result.OriginalURL,
+			ShortURL:    result.ShortURL,
+			Status:      string(result.Status),
+		})
+	}
+	return resp
+}
+
+// HandleExportURLs обрабатывает запрос на выгрузку всех ссылок пользователя.
+// Формат выгрузки задается параметром format: csv (по умолчанию) или ndjson.
+// Ссылки записываются в ответ по мере чтения из хранилища.
+func (h *URLHandler) HandleExportURLs(w http.ResponseWriter, r *http.Request) {
+	var write func(url service.URLData) error
+	var flush func() error
+	switch format := r.URL.Query().Get("format"); format {
+	case "", "csv":
+		w.Header().Set("Content-Type", "text/csv")
+		w.Header().Set("Content-Disposition", `attachment; filename="urls.csv"`)
+		csvWriter := csv.NewWriter(w)
+		headerWritten := false
+		write = func(url service.URLData) error {
+			if !headerWritten {
+				headerWritten = true
+				if err := csvWriter.Write(exportCSVHeader); err != nil {
+					return err
+				}
+			}
+			expiresAt := ""
+			if !url.ExpiresAt.IsZero() {
+				expiresAt = url.ExpiresAt.UTC().Format(time.RFC3339)
+			}
+			return csvWriter.Write([]string{
+				url.OriginalURL, url.ShortURL, strings.Join(url.Tags, importTagsSeparator), expiresAt,
+			})
+		}
+		flush = func() error {
+			if !headerWritten {
+				if err := csvWriter.Write(exportCSVHeader); err != nil {
+					return err
+				}
+			}
+			csvWriter.Flush()
+			return csvWriter.Error()
+		}
+	case "ndjson":
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		w.Header().Set("Content-Disposition", `attachment; filename="urls.ndjson"`)
+		enc := json.NewEncoder(w)
+		write = func(url service.URLData) error {
+			resp := exportURLResponse{OriginalURL: url.OriginalURL, ShortURL: url.ShortURL, Tags: url.Tags}
+			if !url.ExpiresAt.IsZero() {
+				resp.ExpiresAt = &url.ExpiresAt
+			}
+			return enc.Encode(resp)
+		}
+		flush = func() error { return nil }
+	default:
+		http.Error(w, "Некорректный формат выгрузки", http.StatusBadRequest)
+		return
+	}
+
+	started := false
+	err := h.service.ExportUserURLs(r.Context(), func(url service.URLData) error {
+		started = true
+		return write(url)
+	})
+	if err == nil {
+		err = flush()
+	}
+	if err != nil {
+		logger.Log.Errorw("Error in exporting user urls", "err", err)
+		// После начала записи ответа изменить его статус уже нельзя
+		if !started {
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+		}
+	}
+}
+
 // HandleDeleteUserURLs обрабатывает запрос на удаление сокращенных ссылок.
 func (h *URLHandler) HandleDeleteUserURLs(w http.ResponseWriter, r *http.Request) {
 	contentType := r.Header.Get("Content-Type")
@@ -258,9 +739,14 @@ func (h *URLHandler) HandleDeleteUserURLs(w http.ResponseWriter, r *http.Request
 }
 
 // HandleRedirect обрабатывает запрос переход по сокращенной ссылке.
+// Для защищенных паролем ссылок возвращает форму ввода пароля, пароль передается POST запросом.
 func (h *URLHandler) HandleRedirect(w http.ResponseWriter, r *http.Request) {
 	urlID := chi.URLParam(r, "urlID")
-	url, err := h.service.GetURL(r.Context(), urlID)
+	var password string
+	if r.Method == http.MethodPost {
+		password = r.PostFormValue("password")
+	}
+	url, shortID, err := h.service.GetURL(r.Context(), urlID, password)
 	if err != nil {
 		switch {
 		case errors.Is(err, service.ErrInvalidURL):
@@ -269,6 +755,18 @@ func (h *URLHandler) HandleRedirect(w http.ResponseWriter, r *http.Request) {
 		case errors.Is(err, service.ErrIsDeleted):
 			w.WriteHeader(http.StatusGone)
 			return
+		case errors.Is(err, service.ErrExpired):
+			http.Error(w, "Срок действия сокращенной ссылки истек", http.StatusGone)
+			return
+		case errors.Is(err, service.ErrClickLimit):
+			http.Error(w, "Исчерпан лимит переходов по сокращенной ссылке", http.StatusGone)
+			return
+		case errors.Is(err, service.ErrPasswordRequired):
+			renderPasswordForm(w, http.StatusOK, "")
+			return
+		case errors.Is(err, service.ErrWrongPassword):
+			renderPasswordForm(w, http.StatusForbidden, "Неверный пароль")
+			return
 		case errors.Is(err, service.ErrNotFound):
 			http.Error(w, "Сокращенная ссылка не найдена", http.StatusNotFound)
 			return
@@ -278,9 +776,47 @@ func (h *URLHandler) HandleRedirect(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 	}
+	h.service.RecordClick(shortID, service.ClickData{
+		Referrer:  r.Referer(),
+		UserAgent: r.UserAgent(),
+		IP:        h.clientIP(r),
+	})
+	if r.Method == http.MethodPost {
+		// 307 повторил бы POST запрос с паролем на оригинальную ссылку
+		http.Redirect(w, r, url, http.StatusSeeOther)
+		return
+	}
 	http.Redirect(w, r, url, http.StatusTemporaryRedirect)
 }
 
+// clientIP возвращает IP адрес клиента: адрес соединения или, если соединение установлено прокси из доверенной
+// подсети, адрес из заголовка X-Real-IP. Заголовок остальных клиентов не учитывается, так как его можно подделать.
+func (h *URLHandler) clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	if h.trustedSubnet == nil {
+		return host
+	}
+	if remoteIP := net.ParseIP(host); remoteIP == nil || !h.trustedSubnet.Contains(remoteIP) {
+		return host
+	}
+	if ip := net.ParseIP(strings.TrimSpace(r.Header.Get("X-Real-IP"))); ip != nil {
+		return ip.String()
+	}
+	return host
+}
+
+// renderPasswordForm отправляет форму ввода пароля защищенной ссылки.
+func renderPasswordForm(w http.ResponseWriter, statusCode int, message string) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(statusCode)
+	if err := passwordFormTmpl.Execute(w, message); err != nil {
+		logger.Log.Errorw("Error in rendering password form", "err", err)
+	}
+}
+
 // HandlePing обрабатывает запрос на проверку соединения с хранилищем данных.
 func (h *URLHandler) HandlePing(w http.ResponseWriter, r *http.Request) {
 	if err := h.service.Ping(r.Context()); err != nil {
@@ -305,6 +841,12 @@ func (h *URLHandler) HandleGetStats(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
 		return
 	}
+	stats.PurgedURLs, err = h.service.GetPurgedURLsCount(r.Context())
+	if err != nil {
+		logger.Log.Errorw("Error trying to get purged urls count", "err", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
 
 	w.Header().Set("Content-Type", "application/json")
 	enc := json.NewEncoder(w)
@@ -3,9 +3,12 @@ package handlers
 import (
 	"bytes"
 	"context"
+	"encoding/base64"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
+	"net"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
@@ -41,13 +44,15 @@ func TestURLHandler_HandleShortenURL(t *testing.T) {
 	type urlStore struct {
 		urlStoreError error
 		urlID         string
+		canonical     string
 	}
 	tests := []struct {
-		urlStore *urlStore
-		request  request
-		name     string
-		baseURL  string
-		want     want
+		urlStore    *urlStore
+		request     request
+		name        string
+		baseURL     string
+		serviceOpts []service.Option
+		want        want
 	}{
 		{
 			name:    "Успешный запрос",
@@ -65,6 +70,23 @@ func TestURLHandler_HandleShortenURL(t *testing.T) {
 				resBody:    "http://localhost:8080/AbCd1234",
 			},
 		},
+		{
+			name:        "Сохранение канонической формы ссылки",
+			baseURL:     "http://localhost:8080/",
+			serviceOpts: []service.Option{service.WithCanonicalURLs(true)},
+			request: request{
+				url:         "HTTP://Some.Host.ru:80/path?utm_source=mail&b=2&a=1#top",
+				contentType: "text/plain",
+			},
+			urlStore: &urlStore{
+				urlID:     "AbCd1234",
+				canonical: "http://some.host.ru/path?a=1&b=2",
+			},
+			want: want{
+				statusCode: http.StatusCreated,
+				resBody:    "http://localhost:8080/AbCd1234",
+			},
+		},
 		{
 			name:    "Некорректный тип передаваемых данных",
 			baseURL: "http://localhost:8080/",
@@ -109,12 +131,12 @@ func TestURLHandler_HandleShortenURL(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			baseURL, err := url.Parse(tt.baseURL)
 			require.NoError(t, err)
-			service := service.NewService(mockStorage, *baseURL)
-			handler := NewURLHandler(&service, *baseURL)
+			service := service.NewService(mockStorage, *baseURL, tt.serviceOpts...)
+			handler := NewURLHandler(&service, *baseURL, nil)
 
 			if tt.urlStore != nil {
 				mockStorage.EXPECT().
-					SaveURL(gomock.Any(), tt.request.url, gomock.Any()).
+					SaveURL(gomock.Any(), storage.ShortenURL{Original: tt.request.url, Canonical: tt.urlStore.canonical}, gomock.Any()).
 					Times(1).
 					Return(tt.urlStore.urlID, tt.urlStore.urlStoreError)
 			} else {
@@ -153,7 +175,9 @@ func TestURLHandler_HandleJSONShortenURL(t *testing.T) {
 		statusCode int
 	}
 	type request struct {
+		expiresAt   time.Time
 		url         string
+		alias       string
 		contentType string
 	}
 	type urlStore struct {
@@ -207,6 +231,81 @@ func TestURLHandler_HandleJSONShortenURL(t *testing.T) {
 				statusCode: http.StatusBadRequest,
 			},
 		},
+		{
+			name:    "Успешный запрос с алиасом",
+			baseURL: "http://localhost:8080/",
+			request: request{
+				url:         "http://some.host.ru",
+				alias:       "spring-sale",
+				contentType: "application/json",
+			},
+			urlStore: &urlStore{
+				urlID: "spring-sale",
+			},
+			want: want{
+				statusCode: http.StatusCreated,
+				resBody:    `{"result":"http://localhost:8080/spring-sale"}`,
+			},
+		},
+		{
+			name:    "Алиас уже занят",
+			baseURL: "http://localhost:8080/",
+			request: request{
+				url:         "http://some.host.ru",
+				alias:       "spring-sale",
+				contentType: "application/json",
+			},
+			urlStore: &urlStore{
+				urlStoreError: storage.ErrAliasConflict,
+			},
+			want: want{
+				statusCode: http.StatusConflict,
+			},
+		},
+		{
+			name:    "Некорректный алиас",
+			baseURL: "http://localhost:8080/",
+			request: request{
+				url:         "http://some.host.ru",
+				alias:       "a!",
+				contentType: "application/json",
+			},
+			urlStore: &urlStore{
+				urlStoreError: storage.ErrInvalidAlias,
+			},
+			want: want{
+				statusCode: http.StatusBadRequest,
+			},
+		},
+		{
+			name:    "Успешный запрос со сроком действия",
+			baseURL: "http://localhost:8080/",
+			request: request{
+				url:         "http://some.host.ru",
+				expiresAt:   time.Date(2100, 1, 1, 0, 0, 0, 0, time.UTC),
+				contentType: "application/json",
+			},
+			urlStore: &urlStore{
+				urlID: "AbCd1234",
+			},
+			want: want{
+				statusCode: http.StatusCreated,
+				resBody:    `{"result":"http://localhost:8080/AbCd1234"}`,
+			},
+		},
+		{
+			name:    "Срок действия уже истек",
+			baseURL: "http://localhost:8080/",
+			request: request{
+				url:         "http://some.host.ru",
+				expiresAt:   time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC),
+				contentType: "application/json",
+			},
+			urlStore: nil,
+			want: want{
+				statusCode: http.StatusBadRequest,
+			},
+		},
 		{
 			name:    "Ошибка сохранения записи (ошибка store)",
 			baseURL: "http://localhost:8080/",
@@ -228,11 +327,15 @@ func TestURLHandler_HandleJSONShortenURL(t *testing.T) {
 			baseURL, err := url.Parse(tt.baseURL)
 			require.NoError(t, err)
 			service := service.NewService(mockStorage, *baseURL)
-			handler := NewURLHandler(&service, *baseURL)
+			handler := NewURLHandler(&service, *baseURL, nil)
 
 			if tt.urlStore != nil {
 				mockStorage.EXPECT().
-					SaveURL(gomock.Any(), tt.request.url, gomock.Any()).
+					SaveURL(gomock.Any(), storage.ShortenURL{
+						Original:  tt.request.url,
+						Shorten:   tt.request.alias,
+						ExpiresAt: tt.request.expiresAt,
+					}, gomock.Any()).
 					Times(1).
 					Return(tt.urlStore.urlID, tt.urlStore.urlStoreError)
 			} else {
@@ -240,7 +343,9 @@ func TestURLHandler_HandleJSONShortenURL(t *testing.T) {
 			}
 
 			req := shortenRequest{
-				URL: tt.request.url,
+				URL:       tt.request.url,
+				Alias:     tt.request.alias,
+				ExpiresAt: tt.request.expiresAt,
 			}
 			reqJSON, err := json.Marshal(req)
 			require.NoError(t, err)
@@ -275,12 +380,15 @@ func TestURLHandler_HandleRedirect(t *testing.T) {
 		statusCode int
 	}
 	type request struct {
-		reqURL string
-		urlID  string
+		method   string
+		reqURL   string
+		urlID    string
+		password string
 	}
 	type urlStore struct {
 		urlStoreError error
 		url           string
+		shortID       string // Сокращенная ссылка, под которой сохранена ссылка (пустая - совпадает с запрошенной)
 	}
 	tests := []struct {
 		urlStore  *urlStore
@@ -331,6 +439,97 @@ func TestURLHandler_HandleRedirect(t *testing.T) {
 			},
 			isValidID: true,
 		},
+		{
+			name: "Истек срок действия ссылки",
+			request: request{
+				reqURL: "/AbCd1234",
+				urlID:  "AbCd1234",
+			},
+			want: want{
+				statusCode: http.StatusGone,
+			},
+			urlStore: &urlStore{
+				urlStoreError: storage.ErrExpired,
+			},
+			isValidID: true,
+		},
+		{
+			name: "Исчерпан лимит переходов",
+			request: request{
+				reqURL: "/AbCd1234",
+				urlID:  "AbCd1234",
+			},
+			want: want{
+				statusCode: http.StatusGone,
+			},
+			urlStore: &urlStore{
+				urlStoreError: storage.ErrClickLimit,
+			},
+			isValidID: true,
+		},
+		{
+			name: "Форма ввода пароля",
+			request: request{
+				reqURL: "/AbCd1234",
+				urlID:  "AbCd1234",
+			},
+			want: want{
+				statusCode: http.StatusOK,
+			},
+			urlStore: &urlStore{
+				urlStoreError: storage.ErrPasswordRequired,
+			},
+			isValidID: true,
+		},
+		{
+			name: "Неверный пароль",
+			request: request{
+				method:   http.MethodPost,
+				reqURL:   "/AbCd1234",
+				urlID:    "AbCd1234",
+				password: "wrong",
+			},
+			want: want{
+				statusCode: http.StatusForbidden,
+			},
+			urlStore: &urlStore{
+				urlStoreError: storage.ErrWrongPassword,
+			},
+			isValidID: true,
+		},
+		{
+			name: "Верный пароль",
+			request: request{
+				method:   http.MethodPost,
+				reqURL:   "/AbCd1234",
+				urlID:    "AbCd1234",
+				password: "secret",
+			},
+			want: want{
+				statusCode: http.StatusSeeOther,
+				location:   "http://some.host.ru",
+			},
+			urlStore: &urlStore{
+				url: "http://some.host.ru",
+			},
+			isValidID: true,
+		},
+		{
+			name: "Сокращенная ссылка в другом регистре",
+			request: request{
+				reqURL: "/ABCD1234",
+				urlID:  "ABCD1234",
+			},
+			want: want{
+				statusCode: http.StatusTemporaryRedirect,
+				location:   "http://some.host.ru",
+			},
+			urlStore: &urlStore{
+				url:     "http://some.host.ru",
+				shortID: "abcd1234",
+			},
+			isValidID: true,
+		},
 		{
 			name: "Сокращенная ссылка не найдена",
 			request: request{
@@ -355,15 +554,37 @@ func TestURLHandler_HandleRedirect(t *testing.T) {
 				Host:   "localhost:8080",
 			}
 			service := service.NewService(mockStorage, baseURL)
-			handler := NewURLHandler(&service, baseURL)
+			handler := NewURLHandler(&service, baseURL, nil)
 
+			shortID := tt.request.urlID
+			if tt.urlStore != nil && tt.urlStore.shortID != "" {
+				shortID = tt.urlStore.shortID
+			}
 			if tt.urlStore != nil {
+				var storedID string
+				if tt.urlStore.url != "" {
+					storedID = shortID
+				}
+				mockStorage.EXPECT().
+					GetURL(gomock.Any(), tt.request.urlID, tt.request.password).
+					Times(1).
+					Return(tt.urlStore.url, storedID, tt.urlStore.urlStoreError)
+			} else {
+				mockStorage.EXPECT().GetURL(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+			}
+			if tt.want.location != "" {
+				// Переход записывается под сокращенной ссылкой, под которой сохранена ссылка
 				mockStorage.EXPECT().
-					GetURL(gomock.Any(), tt.request.urlID).
+					SaveClick(gomock.Any()).
 					Times(1).
-					Return(tt.urlStore.url, tt.urlStore.urlStoreError)
+					DoAndReturn(func(event storage.ClickEvent) error {
+						assert.Equal(t, shortID, event.ShortURL)
+						assert.Equal(t, "192.0.2.1", event.IP)
+						assert.False(t, event.Time.IsZero())
+						return nil
+					})
 			} else {
-				mockStorage.EXPECT().GetURL(gomock.Any(), gomock.Any()).Times(0)
+				mockStorage.EXPECT().SaveClick(gomock.Any()).Times(0)
 			}
 
 			mockStorage.EXPECT().
@@ -371,7 +592,14 @@ func TestURLHandler_HandleRedirect(t *testing.T) {
 				Times(1).
 				Return(tt.isValidID)
 
-			request := httptest.NewRequest(http.MethodGet, tt.request.reqURL, nil)
+			var request *http.Request
+			if tt.request.method == http.MethodPost {
+				form := url.Values{"password": {tt.request.password}}
+				request = httptest.NewRequest(http.MethodPost, tt.request.reqURL, strings.NewReader(form.Encode()))
+				request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			} else {
+				request = httptest.NewRequest(http.MethodGet, tt.request.reqURL, nil)
+			}
 			rctx := chi.NewRouteContext()
 			rctx.URLParams.Add("urlID", tt.request.urlID)
 			request = request.WithContext(context.WithValue(request.Context(), chi.RouteCtxKey, rctx))
@@ -387,6 +615,94 @@ func TestURLHandler_HandleRedirect(t *testing.T) {
 	}
 }
 
+func TestURLHandler_clientIP(t *testing.T) {
+	_, trustedSubnet, err := net.ParseCIDR("10.0.0.0/8")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name          string
+		trustedSubnet *net.IPNet
+		remoteAddr    string
+		realIP        string
+		want          string
+	}{
+		{
+			name:       "Адрес соединения",
+			remoteAddr: "192.0.2.1:1234",
+			want:       "192.0.2.1",
+		},
+		{
+			name:       "Заголовок без доверенной подсети не учитывается",
+			remoteAddr: "192.0.2.1:1234",
+			realIP:     "203.0.113.5",
+			want:       "192.0.2.1",
+		},
+		{
+			name:          "Заголовок клиента не из доверенной подсети не учитывается",
+			trustedSubnet: trustedSubnet,
+			remoteAddr:    "192.0.2.1:1234",
+			realIP:        "203.0.113.5",
+			want:          "192.0.2.1",
+		},
+		{
+			name:          "Заголовок прокси из доверенной подсети",
+			trustedSubnet: trustedSubnet,
+			remoteAddr:    "10.1.2.3:1234",
+			realIP:        "203.0.113.5",
+			want:          "203.0.113.5",
+		},
+		{
+			name:          "Некорректный заголовок прокси",
+			trustedSubnet: trustedSubnet,
+			remoteAddr:    "10.1.2.3:1234",
+			realIP:        "not an ip",
+			want:          "10.1.2.3",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := NewURLHandler(nil, url.URL{}, tt.trustedSubnet)
+			request := httptest.NewRequest(http.MethodGet, "/AbCd1234", nil)
+			request.RemoteAddr = tt.remoteAddr
+			if tt.realIP != "" {
+				request.Header.Set("X-Real-IP", tt.realIP)
+			}
+			assert.Equal(t, tt.want, handler.clientIP(request))
+		})
+	}
+}
+
+func TestURLHandler_HandleRedirectFoldedID(t *testing.T) {
+	ctx := context.Background()
+	store, err := storage.NewURLMapStore(storage.MapConfig{
+		IDFormat: storage.IDFormat{Alphabet: "abcdefghijklmnopqrstuvwxyz0123456789"},
+	})
+	require.NoError(t, err)
+	id, err := store.SaveURL(ctx, storage.ShortenURL{Original: "http://some.host.ru"}, 1)
+	require.NoError(t, err)
+
+	baseURL := url.URL{Scheme: "http", Host: "localhost:8080"}
+	service := service.NewService(store, baseURL)
+	handler := NewURLHandler(&service, baseURL, nil)
+
+	upper := strings.ToUpper(id)
+	request := httptest.NewRequest(http.MethodGet, "/"+upper, nil)
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("urlID", upper)
+	request = request.WithContext(context.WithValue(request.Context(), chi.RouteCtxKey, rctx))
+	w := httptest.NewRecorder()
+	handler.HandleRedirect(w, request)
+	res := w.Result()
+	defer res.Body.Close()
+	assert.Equal(t, http.StatusTemporaryRedirect, res.StatusCode)
+
+	// Очередь событий переходов сохраняется при закрытии хранилища
+	require.NoError(t, store.Close())
+	stats, err := store.GetURLStats(ctx, 1, id)
+	require.NoError(t, err)
+	assert.Equal(t, 1, stats.TotalClicks)
+}
+
 func TestURLHandler_HandleShortenBatchURL(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -496,7 +812,7 @@ func TestURLHandler_HandleShortenBatchURL(t *testing.T) {
 			baseURL, err := url.Parse(tt.baseURL)
 			require.NoError(t, err)
 			service := service.NewService(mockStorage, *baseURL)
-			handler := NewURLHandler(&service, *baseURL)
+			handler := NewURLHandler(&service, *baseURL, nil)
 
 			if tt.urlStore != nil {
 				if tt.urlStore.storeError != nil {
@@ -547,7 +863,7 @@ func TestURLHandler_HandleGetUsersURLs(t *testing.T) {
 
 	baseURL := url.URL{Scheme: "http", Host: "localhost:8080"}
 	service := service.NewService(mockStorage, baseURL)
-	urlHandler := NewURLHandler(&service, baseURL)
+	urlHandler := NewURLHandler(&service, baseURL, nil)
 	router := NewURLRouter(urlHandler, &service, nil)
 
 	user := &storage.User{ID: 1}
@@ -556,6 +872,7 @@ func TestURLHandler_HandleGetUsersURLs(t *testing.T) {
 
 	type want struct {
 		resBody    string
+		nextCursor string
 		statusCode int
 	}
 	type urlStore struct {
@@ -565,6 +882,8 @@ func TestURLHandler_HandleGetUsersURLs(t *testing.T) {
 	tests := []struct {
 		urlStore *urlStore
 		name     string
+		query    string
+		filter   storage.UserURLsFilter
 		want     want
 	}{
 		{
@@ -592,6 +911,93 @@ func TestURLHandler_HandleGetUsersURLs(t *testing.T) {
 				`,
 			},
 		},
+		{
+			name:   "Фильтр по тегам",
+			query:  "?tag=sale&tag=news",
+			filter: storage.UserURLsFilter{Tags: []string{"news", "sale"}},
+			urlStore: &urlStore{
+				userURLs: []storage.ShortenURL{
+					{Original: "http://some.host.ru/1", Shorten: "AbCd1234", Tags: []string{"news", "sale"}},
+				},
+			},
+			want: want{
+				statusCode: http.StatusOK,
+				resBody: `
+					[
+						{
+							"original_url": "http://some.host.ru/1",
+							"short_url": "http://localhost:8080/AbCd1234",
+							"tags": ["news", "sale"]
+						}
+					]
+				`,
+			},
+		},
+		{
+			name:   "Поиск по подстроке и домену",
+			query:  "?q=docs&domain=Some.Host.ru",
+			filter: storage.UserURLsFilter{Query: "docs", Domain: "some.host.ru"},
+			urlStore: &urlStore{
+				userURLs: []storage.ShortenURL{
+					{Original: "http://some.host.ru/docs", Shorten: "AbCd1234"},
+				},
+			},
+			want: want{
+				statusCode: http.StatusOK,
+				resBody:    `[{"original_url": "http://some.host.ru/docs", "short_url": "http://localhost:8080/AbCd1234"}]`,
+			},
+		},
+		{
+			name:   "Первая страница",
+			query:  "?limit=1",
+			filter: storage.UserURLsFilter{Limit: 2},
+			urlStore: &urlStore{
+				userURLs: []storage.ShortenURL{
+					{Original: "http://some.host.ru/1", Shorten: "AbCd1234"},
+					{Original: "http://some.host.ru/2", Shorten: "EfGh5678"},
+				},
+			},
+			want: want{
+				statusCode: http.StatusOK,
+				nextCursor: base64.RawURLEncoding.EncodeToString([]byte("AbCd1234")),
+				resBody:    `[{"original_url": "http://some.host.ru/1", "short_url": "http://localhost:8080/AbCd1234"}]`,
+			},
+		},
+		{
+			name:   "Последняя страница",
+			query:  "?limit=1&cursor=" + base64.RawURLEncoding.EncodeToString([]byte("AbCd1234")),
+			filter: storage.UserURLsFilter{After: "AbCd1234", Limit: 2},
+			urlStore: &urlStore{
+				userURLs: []storage.ShortenURL{
+					{Original: "http://some.host.ru/2", Shorten: "EfGh5678"},
+				},
+			},
+			want: want{
+				statusCode: http.StatusOK,
+				resBody:    `[{"original_url": "http://some.host.ru/2", "short_url": "http://localhost:8080/EfGh5678"}]`,
+			},
+		},
+		{
+			name:  "Некорректный размер страницы",
+			query: "?limit=abc",
+			want: want{
+				statusCode: http.StatusBadRequest,
+			},
+		},
+		{
+			name:  "Некорректный токен страницы",
+			query: "?cursor=!!!",
+			want: want{
+				statusCode: http.StatusBadRequest,
+			},
+		},
+		{
+			name:  "Некорректный тег",
+			query: "?tag=" + strings.Repeat("a", 65),
+			want: want{
+				statusCode: http.StatusBadRequest,
+			},
+		},
 		{
 			name: "Ошибка при получении",
 			urlStore: &urlStore{
@@ -606,17 +1012,19 @@ func TestURLHandler_HandleGetUsersURLs(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			mockStorage.EXPECT().
-				GetUserURLs(gomock.Any(), gomock.Any()).
-				Times(1).
-				Return(tt.urlStore.userURLs, tt.urlStore.storeError)
+			if tt.urlStore != nil {
+				mockStorage.EXPECT().
+					GetUserURLs(gomock.Any(), user.ID, tt.filter).
+					Times(1).
+					Return(tt.urlStore.userURLs, tt.urlStore.storeError)
+			}
 
 			mockStorage.EXPECT().
 				GetUser(gomock.Any(), user.ID).
 				Times(1).
 				Return(user, nil)
 
-			request := httptest.NewRequest(http.MethodGet, "/api/user/urls", nil)
+			request := httptest.NewRequest(http.MethodGet, "/api/user/urls"+tt.query, nil)
 			request.AddCookie(&http.Cookie{Name: middleware.JWTCookieName, Value: jwtString})
 			w := httptest.NewRecorder()
 
@@ -624,6 +1032,7 @@ func TestURLHandler_HandleGetUsersURLs(t *testing.T) {
 
 			res := w.Result()
 			assert.Equal(t, tt.want.statusCode, res.StatusCode)
+			assert.Equal(t, tt.want.nextCursor, res.Header.Get(nextCursorHeader))
 
 			if tt.want.resBody != "" {
 				defer res.Body.Close()
@@ -635,7 +1044,7 @@ func TestURLHandler_HandleGetUsersURLs(t *testing.T) {
 	}
 }
 
-func TestURLHandler_HandleDeleteUserURLs(t *testing.T) {
+func TestURLHandler_HandleGetURLStats(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
@@ -643,7 +1052,7 @@ func TestURLHandler_HandleDeleteUserURLs(t *testing.T) {
 
 	baseURL := url.URL{Scheme: "http", Host: "localhost:8080"}
 	service := service.NewService(mockStorage, baseURL)
-	urlHandler := NewURLHandler(&service, baseURL)
+	urlHandler := NewURLHandler(&service, baseURL, nil)
 	router := NewURLRouter(urlHandler, &service, nil)
 
 	user := &storage.User{ID: 1}
@@ -651,12 +1060,765 @@ func TestURLHandler_HandleDeleteUserURLs(t *testing.T) {
 	require.NoError(t, err)
 
 	type want struct {
+		resBody    string
 		statusCode int
 	}
+	type urlStore struct {
+		storeError error
+		stats      *storage.URLStats
+	}
 	tests := []struct {
-		name        string
-		contentType string
-		body        []string
+		urlStore *urlStore
+		name     string
+		want     want
+	}{
+		{
+			name: "Успешный запрос",
+			urlStore: &urlStore{
+				stats: &storage.URLStats{
+					TotalClicks:    3,
+					UniqueVisitors: 2,
+					ClicksPerDay:   []storage.DayClicks{{Day: "2024-05-01", Clicks: 1}, {Day: "2024-05-02", Clicks: 2}},
+					TopReferrers:   []storage.TopValue{{Value: "http://ref.ru", Count: 2}},
+					TopUserAgents:  []storage.TopValue{},
+				},
+			},
+			want: want{
+				statusCode: http.StatusOK,
+				resBody: `
+					{
+						"total_clicks": 3,
+						"unique_visitors": 2,
+						"clicks_per_day": [
+							{"day": "2024-05-01", "clicks": 1},
+							{"day": "2024-05-02", "clicks": 2}
+						],
+						"top_referrers": [{"value": "http://ref.ru", "count": 2}],
+						"top_user_agents": []
+					}
+				`,
+			},
+		},
+		{
+			name: "Ссылка другого пользователя",
+			urlStore: &urlStore{
+				storeError: storage.ErrNoData,
+			},
+			want: want{
+				statusCode: http.StatusNotFound,
+			},
+		},
+		{
+			name: "Ошибка при получении",
+			urlStore: &urlStore{
+				storeError: errors.New("URL store error"),
+			},
+			want: want{
+				statusCode: http.StatusInternalServerError,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockStorage.EXPECT().
+				IsValidID("AbCd1234").
+				Times(1).
+				Return(true)
+
+			mockStorage.EXPECT().
+				GetURLStats(gomock.Any(), user.ID, "AbCd1234").
+				Times(1).
+				Return(tt.urlStore.stats, tt.urlStore.storeError)
+
+			mockStorage.EXPECT().
+				GetUser(gomock.Any(), user.ID).
+				Times(1).
+				Return(user, nil)
+
+			request := httptest.NewRequest(http.MethodGet, "/api/user/urls/AbCd1234/stats", nil)
+			request.AddCookie(&http.Cookie{Name: middleware.JWTCookieName, Value: jwtString})
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, request)
+
+			res := w.Result()
+			defer res.Body.Close()
+			assert.Equal(t, tt.want.statusCode, res.StatusCode)
+
+			if tt.want.resBody != "" {
+				resBody, readErr := io.ReadAll(res.Body)
+				require.NoError(t, readErr)
+				assert.JSONEq(t, tt.want.resBody, string(resBody))
+			}
+		})
+	}
+}
+
+func TestURLHandler_HandleUpdateURL(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockStorage := mocks.NewMockURLStorage(ctrl)
+
+	baseURL := url.URL{Scheme: "http", Host: "localhost:8080"}
+	service := service.NewService(mockStorage, baseURL)
+	urlHandler := NewURLHandler(&service, baseURL, nil)
+	router := NewURLRouter(urlHandler, &service, nil)
+
+	user := &storage.User{ID: 1}
+	jwtString, err := middleware.BuildJWTString(user.ID)
+	require.NoError(t, err)
+
+	type want struct {
+		resBody    string
+		statusCode int
+	}
+	type urlStore struct {
+		storeError error
+		update     storage.URLUpdate   // Ожидаемое изменение ссылки
+		stored     *storage.ShortenURL // Сохраненная ссылка после изменения
+	}
+	tests := []struct {
+		urlStore    *urlStore
+		name        string
+		contentType string
+		body        string
+		want        want
+	}{
+		{
+			name:        "Успешный запрос",
+			contentType: "application/json",
+			body:        `{"url": "http://new.host.ru"}`,
+			urlStore: &urlStore{
+				update: storage.URLUpdate{Original: "http://new.host.ru"},
+				stored: &storage.ShortenURL{Original: "http://new.host.ru", Shorten: "AbCd1234"},
+			},
+			want: want{
+				statusCode: http.StatusOK,
+				resBody:    `{"original_url": "http://new.host.ru", "short_url": "http://localhost:8080/AbCd1234"}`,
+			},
+		},
+		{
+			name:        "Изменение тегов",
+			contentType: "application/json",
+			body:        `{"tags": ["sale", " news ", "sale"]}`,
+			urlStore: &urlStore{
+				update: storage.URLUpdate{Tags: []string{"news", "sale"}, UpdateTags: true},
+				stored: &storage.ShortenURL{Original: "http://old.host.ru", Shorten: "AbCd1234", Tags: []string{"news", "sale"}},
+			},
+			want: want{
+				statusCode: http.StatusOK,
+				resBody:    `{"original_url": "http://old.host.ru", "short_url": "http://localhost:8080/AbCd1234", "tags": ["news", "sale"]}`,
+			},
+		},
+		{
+			name:        "Удаление всех тегов",
+			contentType: "application/json",
+			body:        `{"tags": []}`,
+			urlStore: &urlStore{
+				update: storage.URLUpdate{UpdateTags: true},
+				stored: &storage.ShortenURL{Original: "http://old.host.ru", Shorten: "AbCd1234"},
+			},
+			want: want{
+				statusCode: http.StatusOK,
+				resBody:    `{"original_url": "http://old.host.ru", "short_url": "http://localhost:8080/AbCd1234"}`,
+			},
+		},
+		{
+			name:        "Отсутствуют данные для изменения",
+			contentType: "application/json",
+			body:        `{}`,
+			want: want{
+				statusCode: http.StatusBadRequest,
+			},
+		},
+		{
+			name:        "Некорректный тег",
+			contentType: "application/json",
+			body:        `{"tags": ["` + strings.Repeat("a", 65) + `"]}`,
+			want: want{
+				statusCode: http.StatusBadRequest,
+			},
+		},
+		{
+			name:        "Некорректный тип данных",
+			contentType: "text/plain",
+			body:        `{"url": "http://new.host.ru"}`,
+			want: want{
+				statusCode: http.StatusBadRequest,
+			},
+		},
+		{
+			name:        "Некорректная ссылка",
+			contentType: "application/json",
+			body:        `{"url": "new.host.ru"}`,
+			want: want{
+				statusCode: http.StatusBadRequest,
+			},
+		},
+		{
+			name:        "Ссылка другого пользователя",
+			contentType: "application/json",
+			body:        `{"url": "http://new.host.ru"}`,
+			urlStore:    &urlStore{update: storage.URLUpdate{Original: "http://new.host.ru"}, storeError: storage.ErrNoData},
+			want: want{
+				statusCode: http.StatusNotFound,
+			},
+		},
+		{
+			name:        "Такая ссылка уже сохранена",
+			contentType: "application/json",
+			body:        `{"url": "http://new.host.ru"}`,
+			urlStore:    &urlStore{update: storage.URLUpdate{Original: "http://new.host.ru"}, storeError: storage.ErrConflict},
+			want: want{
+				statusCode: http.StatusConflict,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.contentType == "application/json" && tt.body != `{}` {
+				mockStorage.EXPECT().
+					IsValidID("AbCd1234").
+					Times(1).
+					Return(true)
+			}
+			if tt.urlStore != nil {
+				mockStorage.EXPECT().
+					UpdateURL(gomock.Any(), user.ID, "AbCd1234", tt.urlStore.update).
+					Times(1).
+					Return(tt.urlStore.stored, tt.urlStore.storeError)
+			}
+			mockStorage.EXPECT().
+				GetUser(gomock.Any(), user.ID).
+				Times(1).
+				Return(user, nil)
+
+			request := httptest.NewRequest(http.MethodPatch, "/api/user/urls/AbCd1234", strings.NewReader(tt.body))
+			request.Header.Set("Content-Type", tt.contentType)
+			request.AddCookie(&http.Cookie{Name: middleware.JWTCookieName, Value: jwtString})
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, request)
+
+			res := w.Result()
+			defer res.Body.Close()
+			assert.Equal(t, tt.want.statusCode, res.StatusCode)
+
+			if tt.want.resBody != "" {
+				resBody, readErr := io.ReadAll(res.Body)
+				require.NoError(t, readErr)
+				assert.JSONEq(t, tt.want.resBody, string(resBody))
+			}
+		})
+	}
+}
+
+func TestURLHandler_HandleGetDeletedUserURLs(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockStorage := mocks.NewMockURLStorage(ctrl)
+
+	baseURL := url.URL{Scheme: "http", Host: "localhost:8080"}
+	service := service.NewService(mockStorage, baseURL)
+	urlHandler := NewURLHandler(&service, baseURL, nil)
+	router := NewURLRouter(urlHandler, &service, nil)
+
+	user := &storage.User{ID: 1}
+	jwtString, err := middleware.BuildJWTString(user.ID)
+	require.NoError(t, err)
+
+	type want struct {
+		resBody    string
+		statusCode int
+	}
+	type urlStore struct {
+		storeError error
+		userURLs   []storage.ShortenURL
+	}
+	tests := []struct {
+		urlStore *urlStore
+		name     string
+		want     want
+	}{
+		{
+			name: "Успешный запрос",
+			urlStore: &urlStore{
+				userURLs: []storage.ShortenURL{
+					{Original: "http://some.host.ru/1", Shorten: "AbCd1234"},
+				},
+			},
+			want: want{
+				statusCode: http.StatusOK,
+				resBody:    `[{"original_url": "http://some.host.ru/1", "short_url": "http://localhost:8080/AbCd1234"}]`,
+			},
+		},
+		{
+			name:     "Нет удаленных ссылок",
+			urlStore: &urlStore{},
+			want: want{
+				statusCode: http.StatusNoContent,
+			},
+		},
+		{
+			name: "Ошибка при получении",
+			urlStore: &urlStore{
+				storeError: errors.New("URL store error"),
+			},
+			want: want{
+				statusCode: http.StatusInternalServerError,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockStorage.EXPECT().
+				GetDeletedUserURLs(gomock.Any(), user.ID).
+				Times(1).
+				Return(tt.urlStore.userURLs, tt.urlStore.storeError)
+
+			mockStorage.EXPECT().
+				GetUser(gomock.Any(), user.ID).
+				Times(1).
+				Return(user, nil)
+
+			request := httptest.NewRequest(http.MethodGet, "/api/user/urls/deleted", nil)
+			request.AddCookie(&http.Cookie{Name: middleware.JWTCookieName, Value: jwtString})
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, request)
+
+			res := w.Result()
+			defer res.Body.Close()
+			assert.Equal(t, tt.want.statusCode, res.StatusCode)
+
+			if tt.want.resBody != "" {
+				resBody, readErr := io.ReadAll(res.Body)
+				require.NoError(t, readErr)
+				assert.JSONEq(t, tt.want.resBody, string(resBody))
+			}
+		})
+	}
+}
+
+func TestURLHandler_HandleRestoreUserURLs(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockStorage := mocks.NewMockURLStorage(ctrl)
+
+	baseURL := url.URL{Scheme: "http", Host: "localhost:8080"}
+	service := service.NewService(mockStorage, baseURL)
+	urlHandler := NewURLHandler(&service, baseURL, nil)
+	router := NewURLRouter(urlHandler, &service, nil)
+
+	user := &storage.User{ID: 1}
+	jwtString, err := middleware.BuildJWTString(user.ID)
+	require.NoError(t, err)
+
+	tests := []struct {
+		storeError  error
+		name        string
+		contentType string
+		body        []string
+		statusCode  int
+	}{
+		{
+			name:        "Успешный запрос",
+			contentType: "application/json",
+			body:        []string{"AbCd1234", "EfGh5678"},
+			statusCode:  http.StatusNoContent,
+		},
+		{
+			name:        "Некорректный тип данных",
+			contentType: "text/plain",
+			statusCode:  http.StatusBadRequest,
+		},
+		{
+			name:        "Отсутствуют данные",
+			contentType: "application/json",
+			body:        []string{},
+			statusCode:  http.StatusBadRequest,
+		},
+		{
+			name:        "Ошибка хранилища",
+			contentType: "application/json",
+			body:        []string{"AbCd1234"},
+			storeError:  errors.New("URL store error"),
+			statusCode:  http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reqBody, mErr := json.Marshal(tt.body)
+			require.NoError(t, mErr)
+			request := httptest.NewRequest(http.MethodPost, "/api/user/urls/restore", bytes.NewReader(reqBody))
+
+			if len(tt.body) > 0 {
+				mockStorage.EXPECT().
+					RestoreUserURLs(gomock.Any(), user.ID, tt.body).
+					Times(1).
+					Return(tt.storeError)
+			}
+			mockStorage.EXPECT().
+				GetUser(gomock.Any(), user.ID).
+				Times(1).
+				Return(user, nil)
+			request.AddCookie(&http.Cookie{Name: middleware.JWTCookieName, Value: jwtString})
+			request.Header.Set("Content-Type", tt.contentType)
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, request)
+
+			res := w.Result()
+			defer res.Body.Close()
+			assert.Equal(t, tt.statusCode, res.StatusCode)
+		})
+	}
+}
+
+func TestURLHandler_HandleImportURLs(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockStorage := mocks.NewMockURLStorage(ctrl)
+
+	baseURL := url.URL{Scheme: "http", Host: "localhost:8080"}
+	service := service.NewService(mockStorage, baseURL)
+	urlHandler := NewURLHandler(&service, baseURL, nil)
+	router := NewURLRouter(urlHandler, &service, nil)
+
+	user := &storage.User{ID: 1}
+	jwtString, err := middleware.BuildJWTString(user.ID)
+	require.NoError(t, err)
+
+	// saveBatch имитирует успешное сохранение пачки: ссылкам без алиаса выдаются id по номеру в пачке
+	saveBatch := func(_ context.Context, batch []storage.ShortenURL, _ int) error {
+		for i := range batch {
+			if batch[i].Shorten == "" {
+				batch[i].Shorten = fmt.Sprintf("id%d", i+1)
+			}
+		}
+		return nil
+	}
+	manyRows := strings.Repeat("http://some.host.ru\n", 101)
+
+	type want struct {
+		resBody    string
+		statusCode int
+	}
+	tests := []struct {
+		setup       func()
+		name        string
+		contentType string
+		body        string
+		want        want
+	}{
+		{
+			name:        "Успешный импорт",
+			contentType: "text/csv",
+			body:        "original_url,alias,tags\nhttp://one.ru\nnot a url\nhttp://two.ru,my-alias,sale|news\n",
+			setup: func() {
+				mockStorage.EXPECT().
+					SaveBatchURL(gomock.Any(), []storage.ShortenURL{
+						{Original: "http://one.ru"},
+						{Original: "http://two.ru", Shorten: "my-alias", Tags: []string{"news", "sale"}},
+					}, user.ID).
+					Times(1).
+					DoAndReturn(saveBatch)
+			},
+			want: want{
+				statusCode: http.StatusOK,
+				resBody: `[
+					{"row": 1, "original_url": "http://one.ru", "short_url": "http://localhost:8080/id1", "status": "created"},
+					{"row": 2, "original_url": "not a url", "status": "invalid_url"},
+					{"row": 3, "original_url": "http://two.ru", "short_url": "http://localhost:8080/my-alias", "status": "created"}
+				]`,
+			},
+		},
+		{
+			name:        "Конфликты в пачке",
+			contentType: "text/csv",
+			body:        "http://one.ru\nhttp://exists.ru\nhttp://three.ru,taken\n",
+			setup: func() {
+				gomock.InOrder(
+					mockStorage.EXPECT().SaveBatchURL(gomock.Any(), gomock.Any(), user.ID).
+						Times(1).Return(storage.ErrConflict),
+					mockStorage.EXPECT().SaveURL(gomock.Any(), storage.ShortenURL{Original: "http://one.ru"}, user.ID).
+						Times(1).Return("new1", nil),
+					mockStorage.EXPECT().SaveURL(gomock.Any(), storage.ShortenURL{Original: "http://exists.ru"}, user.ID).
+						Times(1).Return("old1", storage.ErrConflict),
+					mockStorage.EXPECT().
+						SaveURL(gomock.Any(), storage.ShortenURL{Original: "http://three.ru", Shorten: "taken"}, user.ID).
+						Times(1).Return("", storage.ErrAliasConflict),
+				)
+			},
+			want: want{
+				statusCode: http.StatusOK,
+				resBody: `[
+					{"row": 1, "original_url": "http://one.ru", "short_url": "http://localhost:8080/new1", "status": "created"},
+					{"row": 2, "original_url": "http://exists.ru", "short_url": "http://localhost:8080/old1", "status": "conflict"},
+					{"row": 3, "original_url": "http://three.ru", "status": "alias_conflict"}
+				]`,
+			},
+		},
+		{
+			name:        "Импорт пачками",
+			contentType: "text/csv",
+			body:        manyRows,
+			setup: func() {
+				gomock.InOrder(
+					mockStorage.EXPECT().SaveBatchURL(gomock.Any(), gomock.Len(100), user.ID).
+						Times(1).DoAndReturn(saveBatch),
+					mockStorage.EXPECT().SaveBatchURL(gomock.Any(), gomock.Len(1), user.ID).
+						Times(1).DoAndReturn(saveBatch),
+				)
+			},
+			want: want{
+				statusCode: http.StatusOK,
+			},
+		},
+		{
+			name:        "Некорректный тип данных",
+			contentType: "application/json",
+			body:        "http://one.ru\n",
+			want: want{
+				statusCode: http.StatusBadRequest,
+			},
+		},
+		{
+			name:        "Отсутствуют данные",
+			contentType: "text/csv",
+			body:        "original_url\n",
+			want: want{
+				statusCode: http.StatusBadRequest,
+			},
+		},
+		{
+			name:        "Некорректный CSV",
+			contentType: "text/csv",
+			body:        "http://one.ru\n\"http://two.ru\n",
+			setup: func() {
+				mockStorage.EXPECT().SaveBatchURL(gomock.Any(), gomock.Len(1), user.ID).
+					Times(1).DoAndReturn(saveBatch)
+			},
+			want: want{
+				statusCode: http.StatusBadRequest,
+				resBody: `{
+					"error": "Некорректный формат CSV в строке 2 (обработано строк до нее: 1)",
+					"results": [
+						{"row": 1, "original_url": "http://one.ru", "short_url": "http://localhost:8080/id1", "status": "created"}
+					]
+				}`,
+			},
+		},
+		{
+			name:        "Ошибка хранилища после сохранения первой пачки",
+			contentType: "text/csv",
+			body:        manyRows,
+			setup: func() {
+				gomock.InOrder(
+					mockStorage.EXPECT().SaveBatchURL(gomock.Any(), gomock.Len(100), user.ID).
+						Times(1).DoAndReturn(saveBatch),
+					mockStorage.EXPECT().SaveBatchURL(gomock.Any(), gomock.Len(1), user.ID).
+						Times(1).Return(errors.New("db error")),
+				)
+			},
+			want: want{
+				statusCode: http.StatusInternalServerError,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.setup != nil {
+				tt.setup()
+			}
+			mockStorage.EXPECT().
+				GetUser(gomock.Any(), user.ID).
+				Times(1).
+				Return(user, nil)
+
+			request := httptest.NewRequest(http.MethodPost, "/api/user/urls/import", strings.NewReader(tt.body))
+			request.Header.Set("Content-Type", tt.contentType)
+			request.AddCookie(&http.Cookie{Name: middleware.JWTCookieName, Value: jwtString})
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, request)
+
+			res := w.Result()
+			defer res.Body.Close()
+			assert.Equal(t, tt.want.statusCode, res.StatusCode)
+
+			if tt.want.resBody != "" {
+				resBody, readErr := io.ReadAll(res.Body)
+				require.NoError(t, readErr)
+				assert.JSONEq(t, tt.want.resBody, string(resBody))
+			}
+			// Результаты сохраненной пачки возвращаются вместе с ошибкой хранилища
+			if tt.want.statusCode == http.StatusInternalServerError {
+				var resp importErrorResponse
+				require.NoError(t, json.NewDecoder(res.Body).Decode(&resp))
+				assert.Len(t, resp.Results, 100)
+			}
+		})
+	}
+}
+
+func TestURLHandler_HandleExportURLs(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockStorage := mocks.NewMockURLStorage(ctrl)
+
+	baseURL := url.URL{Scheme: "http", Host: "localhost:8080"}
+	service := service.NewService(mockStorage, baseURL)
+	urlHandler := NewURLHandler(&service, baseURL, nil)
+	router := NewURLRouter(urlHandler, &service, nil)
+
+	user := &storage.User{ID: 1}
+	jwtString, err := middleware.BuildJWTString(user.ID)
+	require.NoError(t, err)
+
+	expiresAt := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	userURLs := []storage.ShortenURL{
+		{Original: "http://some.host.ru/1", Shorten: "AbCd1234", Tags: []string{"news", "sale"}},
+		{Original: "http://some.host.ru/2", Shorten: "EfGh5678", ExpiresAt: expiresAt},
+	}
+
+	type want struct {
+		contentType string
+		resBody     string
+		statusCode  int
+	}
+	type urlStore struct {
+		storeError error
+		userURLs   []storage.ShortenURL
+	}
+	tests := []struct {
+		urlStore *urlStore
+		name     string
+		format   string
+		want     want
+	}{
+		{
+			name:     "Выгрузка в CSV",
+			urlStore: &urlStore{userURLs: userURLs},
+			want: want{
+				statusCode:  http.StatusOK,
+				contentType: "text/csv",
+				resBody: "original_url,short_url,tags,expires_at\n" +
+					"http://some.host.ru/1,http://localhost:8080/AbCd1234,news|sale,\n" +
+					"http://some.host.ru/2,http://localhost:8080/EfGh5678,,2030-01-01T00:00:00Z\n",
+			},
+		},
+		{
+			name:     "Выгрузка в NDJSON",
+			format:   "ndjson",
+			urlStore: &urlStore{userURLs: userURLs},
+			want: want{
+				statusCode:  http.StatusOK,
+				contentType: "application/x-ndjson",
+				resBody: `{"original_url":"http://some.host.ru/1","short_url":"http://localhost:8080/AbCd1234","tags":["news","sale"]}` +
+					"\n" +
+					`{"original_url":"http://some.host.ru/2","short_url":"http://localhost:8080/EfGh5678","expires_at":"2030-01-01T00:00:00Z"}` +
+					"\n",
+			},
+		},
+		{
+			name:     "Нет ссылок",
+			format:   "csv",
+			urlStore: &urlStore{},
+			want: want{
+				statusCode:  http.StatusOK,
+				contentType: "text/csv",
+				resBody:     "original_url,short_url,tags,expires_at\n",
+			},
+		},
+		{
+			name:   "Некорректный формат",
+			format: "xml",
+			want: want{
+				statusCode: http.StatusBadRequest,
+			},
+		},
+		{
+			name:     "Ошибка хранилища",
+			urlStore: &urlStore{storeError: errors.New("URL store error")},
+			want: want{
+				statusCode: http.StatusInternalServerError,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.urlStore != nil {
+				mockStorage.EXPECT().
+					ExportUserURLs(gomock.Any(), user.ID, gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, _ int, fn func(url storage.ShortenURL) error) error {
+						for _, url := range tt.urlStore.userURLs {
+							if fnErr := fn(url); fnErr != nil {
+								return fnErr
+							}
+						}
+						return tt.urlStore.storeError
+					})
+			}
+			mockStorage.EXPECT().
+				GetUser(gomock.Any(), user.ID).
+				Times(1).
+				Return(user, nil)
+
+			request := httptest.NewRequest(http.MethodGet, "/api/user/urls/export?format="+tt.format, nil)
+			request.AddCookie(&http.Cookie{Name: middleware.JWTCookieName, Value: jwtString})
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, request)
+
+			res := w.Result()
+			defer res.Body.Close()
+			assert.Equal(t, tt.want.statusCode, res.StatusCode)
+
+			if tt.want.resBody != "" {
+				assert.Equal(t, tt.want.contentType, res.Header.Get("Content-Type"))
+				resBody, readErr := io.ReadAll(res.Body)
+				require.NoError(t, readErr)
+				assert.Equal(t, tt.want.resBody, string(resBody))
+			}
+		})
+	}
+}
+
+func TestURLHandler_HandleDeleteUserURLs(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockStorage := mocks.NewMockURLStorage(ctrl)
+
+	baseURL := url.URL{Scheme: "http", Host: "localhost:8080"}
+	service := service.NewService(mockStorage, baseURL)
+	urlHandler := NewURLHandler(&service, baseURL, nil)
+	router := NewURLRouter(urlHandler, &service, nil)
+
+	user := &storage.User{ID: 1}
+	jwtString, err := middleware.BuildJWTString(user.ID)
+	require.NoError(t, err)
+
+	type want struct {
+		statusCode int
+	}
+	tests := []struct {
+		name        string
+		contentType string
+		body        []string
 		want        want
 		isAuth      bool
 	}{
@@ -769,7 +1931,7 @@ func TestURLHandler_HandlePing(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			service := service.NewService(mockStorage, url.URL{})
-			handler := NewURLHandler(&service, url.URL{})
+			handler := NewURLHandler(&service, url.URL{}, nil)
 
 			mockStorage.EXPECT().
 				Ping(gomock.Any()).
@@ -804,8 +1966,9 @@ func TestURLHandler_HandleGetStats(t *testing.T) {
 		count int
 	}
 	type urlStore struct {
-		users *stat
-		urls  *stat
+		users  *stat
+		urls   *stat
+		purged *stat
 	}
 
 	tests := []struct {
@@ -822,10 +1985,13 @@ func TestURLHandler_HandleGetStats(t *testing.T) {
 				urls: &stat{
 					count: 20,
 				},
+				purged: &stat{
+					count: 5,
+				},
 			},
 			want: want{
 				statusCode: http.StatusOK,
-				resBody:    `{"users":10,"urls":20}`,
+				resBody:    `{"users":10,"urls":20,"purged_urls":5}`,
 			},
 		},
 		{
@@ -868,12 +2034,18 @@ func TestURLHandler_HandleGetStats(t *testing.T) {
 			} else {
 				mockStorage.EXPECT().GetUsersCount(gomock.Any()).Times(0)
 			}
+			if tt.urlStore.purged != nil {
+				mockStorage.EXPECT().GetPurgedURLsCount(gomock.Any()).
+					Times(1).Return(tt.urlStore.purged.count, tt.urlStore.purged.err)
+			} else {
+				mockStorage.EXPECT().GetPurgedURLsCount(gomock.Any()).Times(0)
+			}
 			baseURL := url.URL{
 				Scheme: "http",
 				Host:   "localhost:8080",
 			}
 			service := service.NewService(mockStorage, baseURL)
-			handler := NewURLHandler(&service, baseURL)
+			handler := NewURLHandler(&service, baseURL, nil)
 
 			request := httptest.NewRequest(http.MethodGet, "/api/internal/stats", nil)
 			w := httptest.NewRecorder()
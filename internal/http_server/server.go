@@ -24,7 +24,7 @@ type URLShortenerServer struct {
 func NewHTTPServer(service *service.Service, serverConf config.ServerConf) *URLShortenerServer {
 	server := &URLShortenerServer{
 		isHTTPS:    serverConf.EnableHTTPS,
-		urlHandler: handlers.NewURLHandler(service, serverConf.BaseURL),
+		urlHandler: handlers.NewURLHandler(service, serverConf.BaseURL, serverConf.TrustedSubnet),
 	}
 	urlRouter := handlers.NewURLRouter(server.urlHandler, service, serverConf.TrustedSubnet)
 	if serverConf.EnableHTTPS {
@@ -0,0 +1,99 @@
+// Package migrate переносит пользователей и ссылки из одного хранилища приложения в другое.
+package migrate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/pinbrain/urlshortener/internal/logger"
+	"github.com/pinbrain/urlshortener/internal/storage"
+)
+
+// batchSize - количество пользователей или ссылок, сохраняемых в хранилище за один раз.
+const batchSize = 100
+
+// fileStoragePrefix - префикс адреса хранилища в json файле.
+const fileStoragePrefix = "file:"
+
+// Stats описывает результат переноса данных.
+type Stats struct {
+	Users      int // Количество прочитанных пользователей
+	URLs       int // Количество прочитанных ссылок
+	LoadedURLs int // Количество сохраненных ссылок (остальные были перенесены ранее)
+}
+
+// ParseStorage разбирает адрес хранилища: file:<имя json файла> или строка подключения к БД postgres://...
+func ParseStorage(addr string) (storage.URLStorageConfig, error) {
+	switch {
+	case strings.HasPrefix(addr, fileStoragePrefix):
+		file := strings.TrimPrefix(addr, fileStoragePrefix)
+		if file == "" {
+			return storage.URLStorageConfig{}, errors.New("не задано имя файла хранилища")
+		}
+		return storage.URLStorageConfig{StorageFile: file}, nil
+	case strings.HasPrefix(addr, "postgres://"), strings.HasPrefix(addr, "postgresql://"):
+		return storage.URLStorageConfig{DSN: addr}, nil
+	}
+	return storage.URLStorageConfig{}, fmt.Errorf("неизвестный адрес хранилища: %q", addr)
+}
+
+// Migrate переносит всех пользователей и все ссылки (включая удаленные) из хранилища from в хранилище to,
+// сохраняя ID пользователей и сокращенные ссылки. Уже перенесенные данные пропускаются,
+// поэтому прерванный перенос можно безопасно запустить повторно. Если сокращенная ссылка в хранилище to
+// занята другой ссылкой, перенос прерывается с ошибкой storage.ErrConflict.
+func Migrate(ctx context.Context, from, to storage.URLStorage) (Stats, error) {
+	var stats Stats
+
+	// Пользователи переносятся первыми, так как ссылки ссылаются на них
+	users := make([]storage.User, 0, batchSize)
+	err := from.DumpUsers(ctx, func(user storage.User) error {
+		users = append(users, user)
+		if len(users) < batchSize {
+			return nil
+		}
+		if err := to.LoadUsers(ctx, users); err != nil {
+			return err
+		}
+		stats.Users += len(users)
+		users = users[:0]
+		return nil
+	})
+	if err == nil {
+		if err = to.LoadUsers(ctx, users); err == nil {
+			stats.Users += len(users)
+		}
+	}
+	if err != nil {
+		return stats, fmt.Errorf("failed to migrate users: %w", err)
+	}
+	logger.Log.Infow("Users migrated", "count", stats.Users)
+
+	urls := make([]storage.URLRecord, 0, batchSize)
+	loadURLs := func() error {
+		loaded, err := to.LoadURLs(ctx, urls)
+		if err != nil {
+			return err
+		}
+		stats.URLs += len(urls)
+		stats.LoadedURLs += loaded
+		logger.Log.Infow("Urls migrated", "read", stats.URLs, "loaded", stats.LoadedURLs)
+		urls = urls[:0]
+		return nil
+	}
+	err = from.DumpURLs(ctx, func(url storage.URLRecord) error {
+		urls = append(urls, url)
+		if len(urls) < batchSize {
+			return nil
+		}
+		return loadURLs()
+	})
+	if err == nil && len(urls) > 0 {
+		err = loadURLs()
+	}
+	if err != nil {
+		return stats, fmt.Errorf("failed to migrate urls: %w", err)
+	}
+	return stats, nil
+}
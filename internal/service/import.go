@@ -0,0 +1,163 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"io"
+	"slices"
+
+	appCtx "github.com/pinbrain/urlshortener/internal/context"
+	"github.com/pinbrain/urlshortener/internal/logger"
+	"github.com/pinbrain/urlshortener/internal/storage"
+	"github.com/pinbrain/urlshortener/internal/utils"
+)
+
+// importBatchSize - количество ссылок, сохраняемых в хранилище за один раз при импорте.
+const importBatchSize = 100
+
+// ImportURL описывает импортируемую ссылку.
+type ImportURL struct {
+	OriginalURL string
+	Alias       string   // Пользовательский id сокращенной ссылки (необязательный)
+	Tags        []string // Теги ссылки (необязательные)
+}
+
+// ImportStatus описывает результат импорта одной ссылки.
+type ImportStatus string
+
+// Результаты импорта ссылки.
+const (
+	ImportCreated       ImportStatus = "created"        // Ссылка сокращена
+	ImportConflict      ImportStatus = "conflict"       // Ссылка уже была сокращена ранее
+	ImportInvalidURL    ImportStatus = "invalid_url"    // Некорректная ссылка
+	ImportInvalidAlias  ImportStatus = "invalid_alias"  // Некорректный алиас
+	ImportAliasConflict ImportStatus = "alias_conflict" // Алиас уже занят
+	ImportInvalidTags   ImportStatus = "invalid_tags"   // Некорректные теги
+)
+
+// ImportResult описывает результат импорта одной ссылки.
+type ImportResult struct {
+	Row         int    // Номер импортируемой ссылки (начиная с 1)
+	OriginalURL string // Исходная ссылка
+	ShortURL    string // Сокращенная ссылка (созданная или уже существующая)
+	Status      ImportStatus
+}
+
+// ImportURLs импортирует ссылки пользователя, получаемые по одной функцией next
+// (next возвращает io.EOF, когда ссылки закончились). Ссылки сохраняются пачками,
+// для каждой ссылки возвращается результат импорта. Ошибка чтения или сохранения ссылок прерывает импорт,
+// при этом ранее сохраненные пачки остаются в хранилище: вместе с ошибкой всегда возвращаются результаты
+// ссылок, обработанных до нее.
+func (s *Service) ImportURLs(ctx context.Context, next func() (ImportURL, error)) ([]ImportResult, error) {
+	user := appCtx.GetCtxUser(ctx)
+	userID := 0
+	if user != nil {
+		userID = user.ID
+	}
+
+	var results []ImportResult
+	var batch []storage.ShortenURL
+	var batchResults []int // Индексы результатов ссылок пачки
+	for {
+		importURL, err := next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			if saveErr := s.saveImportBatch(ctx, userID, batch, results, batchResults); saveErr != nil {
+				return processedResults(results), errors.Join(saveErr, err)
+			}
+			return results, err
+		}
+		result := ImportResult{Row: len(results) + 1, OriginalURL: importURL.OriginalURL}
+		tags, tagsErr := normalizeTags(importURL.Tags)
+		canonical, canonicalErr := s.canonicalURL(importURL.OriginalURL)
+		switch {
+		case !utils.IsValidURLString(importURL.OriginalURL) || canonicalErr != nil:
+			result.Status = ImportInvalidURL
+		case tagsErr != nil:
+			result.Status = ImportInvalidTags
+		default:
+			batch = append(batch, storage.ShortenURL{
+				Original:  importURL.OriginalURL,
+				Canonical: canonical,
+				Shorten:   importURL.Alias,
+				Tags:      tags,
+			})
+			batchResults = append(batchResults, len(results))
+		}
+		results = append(results, result)
+
+		if len(batch) == importBatchSize {
+			if err = s.saveImportBatch(ctx, userID, batch, results, batchResults); err != nil {
+				return processedResults(results), err
+			}
+			batch, batchResults = nil, nil
+		}
+	}
+	if len(results) == 0 {
+		return nil, ErrNoData
+	}
+	if err := s.saveImportBatch(ctx, userID, batch, results, batchResults); err != nil {
+		return processedResults(results), err
+	}
+	return results, nil
+}
+
+// processedResults возвращает результаты ссылок, обработанных до ошибки сохранения пачки:
+// результаты отбрасываются начиная с первой ссылки, сохранение которой не завершилось.
+func processedResults(results []ImportResult) []ImportResult {
+	for i, result := range results {
+		if result.Status == "" {
+			return results[:i]
+		}
+	}
+	return results
+}
+
+// saveImportBatch сохраняет пачку импортируемых ссылок и заполняет их результаты.
+// Если пачку нельзя сохранить целиком (конфликт ссылок или алиасов), ссылки сохраняются по одной.
+func (s *Service) saveImportBatch(
+	ctx context.Context, userID int, batch []storage.ShortenURL, results []ImportResult, batchResults []int,
+) error {
+	if len(batch) == 0 {
+		return nil
+	}
+	// Хранилище заполняет сокращенные ссылки пачки, а при сохранении по одной нужны исходные алиасы
+	saved := slices.Clone(batch)
+	err := s.urlStore.SaveBatchURL(ctx, saved, userID)
+	if err == nil {
+		for i, url := range saved {
+			results[batchResults[i]].ShortURL = s.baseURL.JoinPath(url.Shorten).String()
+			results[batchResults[i]].Status = ImportCreated
+		}
+		return nil
+	}
+	if !errors.Is(err, storage.ErrConflict) &&
+		!errors.Is(err, storage.ErrAliasConflict) &&
+		!errors.Is(err, storage.ErrInvalidAlias) {
+		logger.Log.Errorw("Error while saving batch of imported urls", "err", err)
+		return errors.Join(ErrStorageError, err)
+	}
+
+	for i, url := range batch {
+		result := &results[batchResults[i]]
+		urlID, saveErr := s.urlStore.SaveURL(ctx, url, userID)
+		switch {
+		case saveErr == nil:
+			result.ShortURL = s.baseURL.JoinPath(urlID).String()
+			result.Status = ImportCreated
+		case errors.Is(saveErr, storage.ErrConflict):
+			result.ShortURL = s.baseURL.JoinPath(urlID).String()
+			result.Status = ImportConflict
+		case errors.Is(saveErr, storage.ErrAliasConflict):
+			result.Status = ImportAliasConflict
+		case errors.Is(saveErr, storage.ErrInvalidAlias):
+			result.Status = ImportInvalidAlias
+		default:
+			logger.Log.Errorw("Error while saving imported url", "err", saveErr)
+			return errors.Join(ErrStorageError, saveErr)
+		}
+	}
+	return nil
+}
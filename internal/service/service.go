@@ -3,8 +3,15 @@ package service
 
 import (
 	"context"
+	"encoding/base64"
 	"errors"
 	"net/url"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"golang.org/x/crypto/bcrypt"
 
 	appCtx "github.com/pinbrain/urlshortener/internal/context"
 	"github.com/pinbrain/urlshortener/internal/logger"
@@ -14,19 +21,45 @@ import (
 
 // Ошибки сервиса.
 var (
-	ErrStorageError  = errors.New("storage error")
-	ErrInvalidURL    = errors.New("invalid url string")
-	ErrURLConflict   = errors.New("url already exists")
-	ErrNoData        = errors.New("no data")
-	ErrIsDeleted     = errors.New("data is deleted")
-	ErrNotFound      = errors.New("data not found")
-	ErrInvalidUserID = errors.New("invalid user id")
+	ErrStorageError     = errors.New("storage error")
+	ErrInvalidURL       = errors.New("invalid url string")
+	ErrURLConflict      = errors.New("url already exists")
+	ErrNoData           = errors.New("no data")
+	ErrIsDeleted        = errors.New("data is deleted")
+	ErrNotFound         = errors.New("data not found")
+	ErrInvalidUserID    = errors.New("invalid user id")
+	ErrInvalidAlias     = errors.New("invalid alias")
+	ErrAliasConflict    = errors.New("alias already taken")
+	ErrExpired          = errors.New("data is expired")
+	ErrInvalidExpiry    = errors.New("invalid expiration")
+	ErrClickLimit       = errors.New("click limit reached")
+	ErrInvalidLimit     = errors.New("invalid click limit")
+	ErrInvalidPassword  = errors.New("invalid password")
+	ErrPasswordRequired = errors.New("password required")
+	ErrWrongPassword    = errors.New("wrong password")
+	ErrInvalidTags      = errors.New("invalid tags")
+	ErrInvalidPage      = errors.New("invalid page parameters")
 )
 
+// maxPasswordLength - максимальная длина пароля ссылки в байтах (ограничение bcrypt).
+const maxPasswordLength = 72
+
+const (
+	// maxTagLength - максимальная длина тега ссылки в символах.
+	maxTagLength = 64
+	// maxTagsCount - максимальное количество тегов у одной ссылки.
+	maxTagsCount = 20
+)
+
+// maxPageSize - максимальный размер страницы при постраничном получении ссылок.
+const maxPageSize = 1000
+
 // URLData описывает структуру данных ссылки (сокращенная и полная).
 type URLData struct {
 	OriginalURL string
 	ShortURL    string
+	Tags        []string
+	ExpiresAt   time.Time // Время истечения срока действия ссылки (нулевое значение - бессрочная ссылка)
 }
 
 // BatchURL описывает структуру данных ссылок при batch запросах.
@@ -34,40 +67,194 @@ type BatchURL struct {
 	OriginalURL   string
 	ShortURL      string
 	CorrelationID string
+	Alias         string        // Пользовательский id сокращенной ссылки (необязательный)
+	ExpiresAt     time.Time     // Время истечения срока действия ссылки (необязательное)
+	TTL           time.Duration // Срок действия ссылки с момента сокращения (необязательный)
+	MaxClicks     int           // Максимальное количество переходов по ссылке (необязательное)
+	Password      string        // Пароль для перехода по ссылке (необязательный)
+	Tags          []string      // Теги ссылки (необязательные)
+}
+
+// ShortenOptions описывает дополнительные параметры сокращения ссылки.
+type ShortenOptions struct {
+	Alias     string        // Пользовательский id сокращенной ссылки (необязательный)
+	ExpiresAt time.Time     // Время истечения срока действия ссылки (необязательное)
+	TTL       time.Duration // Срок действия ссылки с момента сокращения (необязательный)
+	MaxClicks int           // Максимальное количество переходов по ссылке (необязательное)
+	Password  string        // Пароль для перехода по ссылке (необязательный)
+	Tags      []string      // Теги ссылки (необязательные)
+}
+
+// UserURLsQuery описывает параметры запроса ссылок пользователя.
+type UserURLsQuery struct {
+	Tags   []string // Теги, которые должны быть у ссылки (все перечисленные)
+	Query  string   // Подстрока полной ссылки (без учета регистра)
+	Domain string   // Хост полной ссылки (без учета регистра)
+	Limit  int      // Размер страницы (0 - все ссылки без разбиения на страницы)
+	Cursor string   // Токен страницы из предыдущего ответа (пустая строка - первая страница)
+}
+
+// URLUpdate описывает изменения сокращенной ссылки пользователя.
+type URLUpdate struct {
+	OriginalURL string   // Новая полная ссылка (пустая строка - не изменять)
+	Tags        []string // Новые теги ссылки (заменяют текущие)
+	UpdateTags  bool     // Признак изменения тегов (позволяет удалить все теги пустым Tags)
+}
+
+// expirationTime возвращает время истечения срока действия ссылки по переданным параметрам.
+// Можно задать либо время истечения, либо срок действия. Нулевой результат означает бессрочную ссылку.
+func expirationTime(expiresAt time.Time, ttl time.Duration) (time.Time, error) {
+	switch {
+	case ttl < 0:
+		return time.Time{}, ErrInvalidExpiry
+	case ttl > 0 && !expiresAt.IsZero():
+		return time.Time{}, ErrInvalidExpiry
+	case ttl > 0:
+		return time.Now().Add(ttl), nil
+	case !expiresAt.IsZero() && !expiresAt.After(time.Now()):
+		return time.Time{}, ErrInvalidExpiry
+	}
+	return expiresAt, nil
+}
+
+// hashPassword возвращает bcrypt хэш пароля ссылки (пустую строку, если пароль не задан).
+func hashPassword(password string) (string, error) {
+	if password == "" {
+		return "", nil
+	}
+	if len(password) > maxPasswordLength {
+		return "", ErrInvalidPassword
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", err
+	}
+	return string(hash), nil
+}
+
+// normalizeTags проверяет теги ссылки и приводит их к каноничному виду:
+// без пробелов по краям, без пустых значений и повторов, в отсортированном порядке.
+func normalizeTags(tags []string) ([]string, error) {
+	if len(tags) == 0 {
+		return nil, nil
+	}
+	result := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		tag = strings.TrimSpace(tag)
+		if tag == "" {
+			continue
+		}
+		if utf8.RuneCountInString(tag) > maxTagLength {
+			return nil, ErrInvalidTags
+		}
+		result = append(result, tag)
+	}
+	slices.Sort(result)
+	result = slices.Compact(result)
+	if len(result) > maxTagsCount {
+		return nil, ErrInvalidTags
+	}
+	return result, nil
 }
 
 // Service описывает структуру сервиса с бизнес логикой.
 type Service struct {
-	urlStore storage.URLStorage // Хранилище приложения
-	baseURL  *url.URL           // Базовый url сокращаемых ссылок
+	urlStore      storage.URLStorage // Хранилище приложения
+	baseURL       *url.URL           // Базовый url сокращаемых ссылок
+	canonicalize  bool               // Приводить полные ссылки к канонической форме перед сохранением
+	stripTracking bool               // Удалять из канонической формы параметры отслеживания
+}
+
+// Option описывает дополнительную настройку сервиса.
+type Option func(s *Service)
+
+// WithCanonicalURLs включает приведение полных ссылок к канонической форме перед сохранением:
+// уникальность проверяется по канонической форме, а пользователю возвращается переданная им ссылка.
+// stripTracking - удалять из канонической формы параметры отслеживания (utm_*, fbclid и т.п.).
+func WithCanonicalURLs(stripTracking bool) Option {
+	return func(s *Service) {
+		s.canonicalize = true
+		s.stripTracking = stripTracking
+	}
 }
 
 // NewService создает и возвращает новый сервис.
-func NewService(urlStore storage.URLStorage, baseURL url.URL) Service {
-	return Service{
+func NewService(urlStore storage.URLStorage, baseURL url.URL, opts ...Option) Service {
+	service := Service{
 		urlStore: urlStore,
 		baseURL:  &baseURL,
 	}
+	for _, opt := range opts {
+		opt(&service)
+	}
+	return service
+}
+
+// canonicalURL возвращает каноническую форму полной ссылки (пустая строка, если приведение отключено).
+func (s *Service) canonicalURL(original string) (string, error) {
+	if !s.canonicalize {
+		return "", nil
+	}
+	canonical, err := utils.CanonicalURL(original, s.stripTracking)
+	if err != nil {
+		return "", ErrInvalidURL
+	}
+	return canonical, nil
 }
 
 // ShortenURL сокращает и сохраняет ссылку.
-func (s *Service) ShortenURL(ctx context.Context, url string) (string, error) {
+// Если ссылка уже сокращена в пределах области уникальности хранилища (всеми пользователями или текущим),
+// возвращается существующая сокращенная ссылка и ErrURLConflict.
+func (s *Service) ShortenURL(ctx context.Context, url string, opts ShortenOptions) (string, error) {
 	isValidURL := utils.IsValidURLString(url)
 	if !isValidURL {
 		return "", ErrInvalidURL
 	}
+	canonical, err := s.canonicalURL(url)
+	if err != nil {
+		return "", err
+	}
+	expiresAt, err := expirationTime(opts.ExpiresAt, opts.TTL)
+	if err != nil {
+		return "", err
+	}
+	if opts.MaxClicks < 0 {
+		return "", ErrInvalidLimit
+	}
+	passwordHash, err := hashPassword(opts.Password)
+	if err != nil {
+		return "", err
+	}
+	tags, err := normalizeTags(opts.Tags)
+	if err != nil {
+		return "", err
+	}
 	user := appCtx.GetCtxUser(ctx)
 	userID := 0
 	if user != nil {
 		userID = user.ID
 	}
-	urlID, err := s.urlStore.SaveURL(ctx, url, userID)
+	urlID, err := s.urlStore.SaveURL(ctx, storage.ShortenURL{
+		Original:     url,
+		Canonical:    canonical,
+		Shorten:      opts.Alias,
+		ExpiresAt:    expiresAt,
+		MaxClicks:    opts.MaxClicks,
+		PasswordHash: passwordHash,
+		Tags:         tags,
+	}, userID)
 	if err != nil {
-		if !errors.Is(err, storage.ErrConflict) {
+		switch {
+		case errors.Is(err, storage.ErrConflict):
+			return s.baseURL.JoinPath(urlID).String(), ErrURLConflict
+		case errors.Is(err, storage.ErrInvalidAlias):
+			return "", ErrInvalidAlias
+		case errors.Is(err, storage.ErrAliasConflict):
+			return "", ErrAliasConflict
+		default:
 			logger.Log.Errorw("Error while saving url for shorten", "err", err)
 			return "", errors.Join(ErrStorageError, err)
 		}
-		return s.baseURL.JoinPath(urlID).String(), ErrURLConflict
 	}
 	shortURL := s.baseURL.JoinPath(urlID).String()
 	return shortURL, nil
@@ -86,15 +273,48 @@ func (s *Service) ShortenBatchURL(ctx context.Context, urls []BatchURL) ([]Batch
 
 	shortenURLs := []storage.ShortenURL{}
 	for _, url := range urls {
-		shortenURLs = append(shortenURLs, storage.ShortenURL{Original: url.OriginalURL})
+		canonical, err := s.canonicalURL(url.OriginalURL)
+		if err != nil {
+			return nil, err
+		}
+		expiresAt, err := expirationTime(url.ExpiresAt, url.TTL)
+		if err != nil {
+			return nil, err
+		}
+		if url.MaxClicks < 0 {
+			return nil, ErrInvalidLimit
+		}
+		passwordHash, err := hashPassword(url.Password)
+		if err != nil {
+			return nil, err
+		}
+		tags, err := normalizeTags(url.Tags)
+		if err != nil {
+			return nil, err
+		}
+		shortenURLs = append(shortenURLs, storage.ShortenURL{
+			Original:     url.OriginalURL,
+			Canonical:    canonical,
+			Shorten:      url.Alias,
+			ExpiresAt:    expiresAt,
+			MaxClicks:    url.MaxClicks,
+			PasswordHash: passwordHash,
+			Tags:         tags,
+		})
 	}
 	err := s.urlStore.SaveBatchURL(ctx, shortenURLs, userID)
 	if err != nil {
-		if !errors.Is(err, storage.ErrConflict) {
+		switch {
+		case errors.Is(err, storage.ErrConflict):
+			return nil, ErrURLConflict
+		case errors.Is(err, storage.ErrInvalidAlias):
+			return nil, ErrInvalidAlias
+		case errors.Is(err, storage.ErrAliasConflict):
+			return nil, ErrAliasConflict
+		default:
 			logger.Log.Errorw("Error while saving batch url for shorten", "err", err)
 			return nil, errors.Join(ErrStorageError, err)
 		}
-		return nil, ErrURLConflict
 	}
 	for i := range urls {
 		urls[i].ShortURL = s.baseURL.JoinPath(shortenURLs[i].Shorten).String()
@@ -102,34 +322,180 @@ func (s *Service) ShortenBatchURL(ctx context.Context, urls []BatchURL) ([]Batch
 	return urls, nil
 }
 
-// GetURL возвращает полную ссылку по id сокращенной.
-func (s *Service) GetURL(ctx context.Context, urlID string) (string, error) {
+// GetURL возвращает полную ссылку по id сокращенной и id, под которым ссылка сохранена
+// (по нему записываются переходы, см. RecordClick).
+// password необходим только для перехода по защищенным паролем ссылкам.
+func (s *Service) GetURL(ctx context.Context, urlID string, password string) (string, string, error) {
 	if !s.urlStore.IsValidID(urlID) {
-		return "", ErrInvalidURL
+		return "", "", ErrInvalidURL
 	}
-	url, err := s.urlStore.GetURL(ctx, urlID)
+	url, shortID, err := s.urlStore.GetURL(ctx, urlID, password)
 	if err != nil {
 		if errors.Is(err, storage.ErrIsDeleted) {
-			return "", ErrIsDeleted
+			return "", "", ErrIsDeleted
+		}
+		if errors.Is(err, storage.ErrExpired) {
+			return "", "", ErrExpired
+		}
+		if errors.Is(err, storage.ErrClickLimit) {
+			return "", "", ErrClickLimit
+		}
+		if errors.Is(err, storage.ErrPasswordRequired) {
+			return "", "", ErrPasswordRequired
+		}
+		if errors.Is(err, storage.ErrWrongPassword) {
+			return "", "", ErrWrongPassword
 		}
 		logger.Log.Errorw("Error getting shorten url", "err", err)
-		return "", errors.Join(ErrStorageError, err)
+		return "", "", errors.Join(ErrStorageError, err)
 	}
 	if url == "" {
-		return "", ErrNotFound
+		return "", "", ErrNotFound
+	}
+	return url, shortID, nil
+}
+
+// ClickData описывает данные о переходе по сокращенной ссылке.
+type ClickData struct {
+	Referrer  string // Источник перехода
+	UserAgent string // User-Agent клиента
+	IP        string // IP адрес клиента
+}
+
+// RecordClick сохраняет событие перехода по сокращенной ссылке.
+// Сохранение происходит асинхронно и не задерживает переход.
+func (s *Service) RecordClick(urlID string, click ClickData) {
+	err := s.urlStore.SaveClick(storage.ClickEvent{
+		ShortURL:  urlID,
+		Time:      time.Now(),
+		Referrer:  click.Referrer,
+		UserAgent: click.UserAgent,
+		IP:        click.IP,
+	})
+	if err != nil {
+		logger.Log.Errorw("Error in saving click event", "err", err)
 	}
-	return url, nil
 }
 
-// GetUserURLs возвращает сокращенные ссылки пользователя.
-func (s *Service) GetUserURLs(ctx context.Context) ([]URLData, error) {
+// GetURLStats возвращает статистику переходов по сокращенной ссылке пользователя.
+// Для чужих и несуществующих ссылок возвращается ErrNotFound.
+func (s *Service) GetURLStats(ctx context.Context, urlID string) (*storage.URLStats, error) {
+	user := appCtx.GetCtxUser(ctx)
+	if user == nil || !s.urlStore.IsValidID(urlID) {
+		return nil, ErrNotFound
+	}
+	stats, err := s.urlStore.GetURLStats(ctx, user.ID, urlID)
+	if err != nil {
+		if errors.Is(err, storage.ErrNoData) {
+			return nil, ErrNotFound
+		}
+		logger.Log.Errorw("Error in getting url stats", "err", err)
+		return nil, errors.Join(ErrStorageError, err)
+	}
+	return stats, nil
+}
+
+// GetUserURLs возвращает страницу сокращенных ссылок пользователя и токен следующей страницы
+// (пустая строка, если страница последняя). Ссылки упорядочены по сокращенной ссылке.
+// Если переданы теги, возвращаются только ссылки, у которых есть все эти теги.
+// Если переданы строка поиска и (или) домен, возвращаются только ссылки, полная ссылка которых
+// содержит строку поиска и ведет на этот домен.
+func (s *Service) GetUserURLs(ctx context.Context, query UserURLsQuery) ([]URLData, string, error) {
 	user := appCtx.GetCtxUser(ctx)
 	if user == nil {
-		return nil, nil
+		return nil, "", nil
+	}
+	tags, err := normalizeTags(query.Tags)
+	if err != nil {
+		return nil, "", err
+	}
+	if query.Limit < 0 || query.Limit > maxPageSize {
+		return nil, "", ErrInvalidPage
+	}
+	after, err := decodeCursor(query.Cursor)
+	if err != nil {
+		return nil, "", ErrInvalidPage
 	}
-	userURLs, err := s.urlStore.GetUserURLs(ctx, user.ID)
+	filter := storage.UserURLsFilter{
+		Tags:   tags,
+		Query:  strings.TrimSpace(query.Query),
+		Domain: strings.ToLower(strings.TrimSpace(query.Domain)),
+		After:  after,
+	}
+	if query.Limit > 0 {
+		// Лишняя ссылка нужна только для того, чтобы понять, есть ли следующая страница
+		filter.Limit = query.Limit + 1
+	}
+	userURLs, err := s.urlStore.GetUserURLs(ctx, user.ID, filter)
 	if err != nil {
 		logger.Log.Errorw("Error in getting user shorten urls", "err", err)
+		return nil, "", errors.Join(ErrStorageError, err)
+	}
+	nextCursor := ""
+	if query.Limit > 0 && len(userURLs) > query.Limit {
+		userURLs = userURLs[:query.Limit]
+		nextCursor = encodeCursor(userURLs[query.Limit-1].Shorten)
+	}
+	var result []URLData
+	for _, url := range userURLs {
+		result = append(result, URLData{OriginalURL: url.Original, ShortURL: url.Shorten, Tags: url.Tags})
+	}
+	return result, nextCursor, nil
+}
+
+// ExportUserURLs передает функции fn по очереди все сокращенные ссылки пользователя
+// (с полной сокращенной ссылкой). Ошибка fn прерывает выгрузку и возвращается как есть.
+func (s *Service) ExportUserURLs(ctx context.Context, fn func(url URLData) error) error {
+	user := appCtx.GetCtxUser(ctx)
+	if user == nil {
+		return nil
+	}
+	var fnErr error
+	err := s.urlStore.ExportUserURLs(ctx, user.ID, func(url storage.ShortenURL) error {
+		fnErr = fn(URLData{
+			OriginalURL: url.Original,
+			ShortURL:    s.baseURL.JoinPath(url.Shorten).String(),
+			Tags:        url.Tags,
+			ExpiresAt:   url.ExpiresAt,
+		})
+		return fnErr
+	})
+	if err != nil {
+		if fnErr != nil {
+			return fnErr
+		}
+		logger.Log.Errorw("Error in exporting user urls", "err", err)
+		return errors.Join(ErrStorageError, err)
+	}
+	return nil
+}
+
+// encodeCursor возвращает токен страницы, начинающейся после сокращенной ссылки id.
+func encodeCursor(id string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(id))
+}
+
+// decodeCursor возвращает сокращенную ссылку, после которой начинается страница с токеном cursor.
+func decodeCursor(cursor string) (string, error) {
+	if cursor == "" {
+		return "", nil
+	}
+	id, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil || len(id) == 0 {
+		return "", ErrInvalidPage
+	}
+	return string(id), nil
+}
+
+// GetDeletedUserURLs возвращает удаленные сокращенные ссылки пользователя.
+func (s *Service) GetDeletedUserURLs(ctx context.Context) ([]URLData, error) {
+	user := appCtx.GetCtxUser(ctx)
+	if user == nil {
+		return nil, nil
+	}
+	userURLs, err := s.urlStore.GetDeletedUserURLs(ctx, user.ID)
+	if err != nil {
+		logger.Log.Errorw("Error in getting deleted user urls", "err", err)
 		return nil, errors.Join(ErrStorageError, err)
 	}
 	var result []URLData
@@ -139,6 +505,68 @@ func (s *Service) GetUserURLs(ctx context.Context) ([]URLData, error) {
 	return result, nil
 }
 
+// RestoreUserURLs восстанавливает удаленные сокращенные ссылки пользователя.
+func (s *Service) RestoreUserURLs(ctx context.Context, urls []string) error {
+	user := appCtx.GetCtxUser(ctx)
+	if user == nil {
+		return nil
+	}
+	if len(urls) == 0 {
+		return ErrNoData
+	}
+	err := s.urlStore.RestoreUserURLs(ctx, user.ID, urls)
+	if err != nil {
+		logger.Log.Errorw("Error in restoring user urls", "err", err)
+		return errors.Join(ErrStorageError, err)
+	}
+	return nil
+}
+
+// UpdateURL изменяет полную ссылку и (или) теги сокращенной ссылки пользователя.
+// Полная ссылка и теги изменяются одной операцией хранилища. Возвращает сохраненные данные ссылки после изменения.
+// Для чужих и несуществующих ссылок возвращается ErrNotFound.
+func (s *Service) UpdateURL(ctx context.Context, urlID string, update URLUpdate) (*URLData, error) {
+	user := appCtx.GetCtxUser(ctx)
+	if user == nil || !s.urlStore.IsValidID(urlID) {
+		return nil, ErrNotFound
+	}
+	if update.OriginalURL == "" && !update.UpdateTags {
+		return nil, ErrNoData
+	}
+	if update.OriginalURL != "" && !utils.IsValidURLString(update.OriginalURL) {
+		return nil, ErrInvalidURL
+	}
+	var canonical string
+	if update.OriginalURL != "" {
+		var err error
+		if canonical, err = s.canonicalURL(update.OriginalURL); err != nil {
+			return nil, err
+		}
+	}
+	tags, err := normalizeTags(update.Tags)
+	if err != nil {
+		return nil, err
+	}
+	updated, err := s.urlStore.UpdateURL(ctx, user.ID, urlID, storage.URLUpdate{
+		Original:   update.OriginalURL,
+		Canonical:  canonical,
+		Tags:       tags,
+		UpdateTags: update.UpdateTags,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, storage.ErrNoData):
+			return nil, ErrNotFound
+		case errors.Is(err, storage.ErrConflict):
+			return nil, ErrURLConflict
+		default:
+			logger.Log.Errorw("Error in updating user url", "err", err)
+			return nil, errors.Join(ErrStorageError, err)
+		}
+	}
+	return &URLData{OriginalURL: updated.Original, ShortURL: urlID, Tags: updated.Tags}, nil
+}
+
 // DeleteUserURLs удаляет сокращенные ссылки пользователя.
 func (s *Service) DeleteUserURLs(ctx context.Context, urls []string) error {
 	user := appCtx.GetCtxUser(ctx)
@@ -192,6 +620,11 @@ func (s *Service) GetURLsCount(ctx context.Context) (int, error) {
 	return s.urlStore.GetURLsCount(ctx)
 }
 
+// GetPurgedURLsCount возвращает количество окончательно удаленных ссылок.
+func (s *Service) GetPurgedURLsCount(ctx context.Context) (int, error) {
+	return s.urlStore.GetPurgedURLsCount(ctx)
+}
+
 // GetUsersCount возвращает количество пользователей в хранилище.
 func (s *Service) GetUsersCount(ctx context.Context) (int, error) {
 	return s.urlStore.GetUsersCount(ctx)
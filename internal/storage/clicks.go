@@ -0,0 +1,194 @@
+package storage
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/pinbrain/urlshortener/internal/logger"
+)
+
+const (
+	// Количество событий переходов в пачке. При достижении пачка будет сохранена в хранилище.
+	clickBatchSize = 100
+	// Интервал (в секундах) между сохранением событий переходов (даже если не было достигнуто количество clickBatchSize).
+	clickBatchInterval = 10
+	// Размер очереди событий переходов. При заполненной очереди новые события отбрасываются.
+	clickQueueSize = clickBatchSize * 10
+	// Максимальное время сохранения одной пачки событий переходов.
+	clickFlushTimeout = 5 * time.Second
+)
+
+// ClickEvent описывает событие перехода по сокращенной ссылке.
+type ClickEvent struct {
+	ShortURL  string    `json:"short_url"`  // Сокращенная ссылка
+	Time      time.Time `json:"time"`       // Время перехода
+	Referrer  string    `json:"referrer"`   // Источник перехода (заголовок Referer)
+	UserAgent string    `json:"user_agent"` // User-Agent клиента
+	IP        string    `json:"ip"`         // IP адрес клиента
+}
+
+// clickQueue описывает асинхронную очередь событий переходов, сохраняемых в хранилище пачками.
+type clickQueue struct {
+	events chan ClickEvent
+	save   func(ctx context.Context, batch []ClickEvent) error
+	mutex  sync.RWMutex // Блокировка закрытия очереди: событие не отправляется в уже закрытый канал
+	closed bool
+}
+
+// newClickQueue создает очередь событий переходов, сохраняемых функцией save.
+func newClickQueue(save func(ctx context.Context, batch []ClickEvent) error) *clickQueue {
+	return &clickQueue{
+		events: make(chan ClickEvent, clickQueueSize),
+		save:   save,
+	}
+}
+
+// push добавляет событие в очередь без ожидания. Если очередь заполнена или уже закрыта
+// (переход завершился после закрытия хранилища), событие отбрасывается.
+func (q *clickQueue) push(event ClickEvent) {
+	q.mutex.RLock()
+	defer q.mutex.RUnlock()
+	if q.closed {
+		logger.Log.Warnw("Click events queue is closed, dropping event", "short_url", event.ShortURL)
+		return
+	}
+	select {
+	case q.events <- event:
+	default:
+		logger.Log.Warnw("Click events queue is full, dropping event", "short_url", event.ShortURL)
+	}
+}
+
+// run - go рутина, которая собирает события переходов в пачки и сохраняет их.
+// Сохранение происходит либо при достижении clickBatchSize событий, либо каждые clickBatchInterval секунд.
+// Завершается после закрытия очереди, предварительно сохранив оставшиеся события.
+func (q *clickQueue) run(wg *sync.WaitGroup) {
+	ticker := time.NewTicker(clickBatchInterval * time.Second)
+	defer ticker.Stop()
+	defer wg.Done()
+
+	batch := make([]ClickEvent, 0, clickBatchSize)
+	for {
+		select {
+		case event, ok := <-q.events:
+			if !ok {
+				if len(batch) > 0 {
+					logger.Log.Debug("Saving click events while closing store...")
+					q.flush(batch)
+				}
+				return
+			}
+			batch = append(batch, event)
+			if len(batch) >= clickBatchSize {
+				q.flush(batch)
+				batch = batch[:0]
+			}
+		case <-ticker.C:
+			if len(batch) > 0 {
+				q.flush(batch)
+				batch = batch[:0]
+			}
+		}
+	}
+}
+
+// flush сохраняет пачку событий переходов.
+func (q *clickQueue) flush(batch []ClickEvent) {
+	ctx, cancel := context.WithTimeout(context.Background(), clickFlushTimeout)
+	defer cancel()
+	if err := q.save(ctx, batch); err != nil {
+		logger.Log.Errorw("Error in saving click events", "err", err, "count", len(batch))
+	}
+}
+
+// close закрывает очередь. События, добавленные после закрытия, отбрасываются.
+func (q *clickQueue) close() {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+	if q.closed {
+		return
+	}
+	q.closed = true
+	close(q.events)
+}
+
+// urlStatsTopSize - количество значений в топах источников и User-Agent статистики ссылки.
+const urlStatsTopSize = 10
+
+// statsDayLayout - формат дня в статистике переходов по дням (дни считаются в UTC).
+const statsDayLayout = "2006-01-02"
+
+// URLStats описывает статистику переходов по сокращенной ссылке.
+type URLStats struct {
+	TotalClicks    int         // Общее количество переходов
+	UniqueVisitors int         // Количество уникальных посетителей (по IP адресу)
+	ClicksPerDay   []DayClicks // Количество переходов по дням (по возрастанию дня)
+	TopReferrers   []TopValue  // Самые частые источники переходов
+	TopUserAgents  []TopValue  // Самые частые User-Agent клиентов
+}
+
+// DayClicks описывает количество переходов по ссылке за день.
+type DayClicks struct {
+	Day    string // День в формате 2006-01-02
+	Clicks int    // Количество переходов
+}
+
+// TopValue описывает значение из топа статистики и количество переходов с ним.
+type TopValue struct {
+	Value string
+	Count int
+}
+
+// aggregateClicks рассчитывает статистику переходов по списку событий.
+func aggregateClicks(events []ClickEvent) *URLStats {
+	stats := &URLStats{
+		TotalClicks:   len(events),
+		ClicksPerDay:  []DayClicks{},
+		TopReferrers:  []TopValue{},
+		TopUserAgents: []TopValue{},
+	}
+	visitors := make(map[string]struct{})
+	days := make(map[string]int)
+	referrers := make(map[string]int)
+	userAgents := make(map[string]int)
+	for _, event := range events {
+		visitors[event.IP] = struct{}{}
+		days[event.Time.UTC().Format(statsDayLayout)]++
+		if event.Referrer != "" {
+			referrers[event.Referrer]++
+		}
+		if event.UserAgent != "" {
+			userAgents[event.UserAgent]++
+		}
+	}
+	stats.UniqueVisitors = len(visitors)
+	for day, clicks := range days {
+		stats.ClicksPerDay = append(stats.ClicksPerDay, DayClicks{Day: day, Clicks: clicks})
+	}
+	sort.Slice(stats.ClicksPerDay, func(i, j int) bool {
+		return stats.ClicksPerDay[i].Day < stats.ClicksPerDay[j].Day
+	})
+	stats.TopReferrers = topValues(referrers)
+	stats.TopUserAgents = topValues(userAgents)
+	return stats
+}
+
+// topValues возвращает urlStatsTopSize самых частых значений (при равенстве - по алфавиту).
+func topValues(counts map[string]int) []TopValue {
+	top := make([]TopValue, 0, len(counts))
+	for value, count := range counts {
+		top = append(top, TopValue{Value: value, Count: count})
+	}
+	sort.Slice(top, func(i, j int) bool {
+		if top[i].Count != top[j].Count {
+			return top[i].Count > top[j].Count
+		}
+		return top[i].Value < top[j].Value
+	})
+	if len(top) > urlStatsTopSize {
+		top = top[:urlStatsTopSize]
+	}
+	return top
+}
@@ -0,0 +1,102 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClickQueue(t *testing.T) {
+	var saved [][]ClickEvent
+	queue := newClickQueue(func(_ context.Context, batch []ClickEvent) error {
+		saved = append(saved, append([]ClickEvent(nil), batch...))
+		return nil
+	})
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go queue.run(&wg)
+	for range clickBatchSize + 1 {
+		queue.push(ClickEvent{ShortURL: "short"})
+	}
+	queue.close()
+	wg.Wait()
+
+	// Полная пачка сохраняется сразу, остаток - при закрытии очереди
+	if assert.Len(t, saved, 2) {
+		assert.Len(t, saved[0], clickBatchSize)
+		assert.Len(t, saved[1], 1)
+	}
+}
+
+func TestClickQueueFull(t *testing.T) {
+	queue := newClickQueue(func(_ context.Context, _ []ClickEvent) error {
+		return errors.New("storage error")
+	})
+
+	// Без обработчика очереди лишние события отбрасываются без блокировки
+	for range clickQueueSize + 10 {
+		queue.push(ClickEvent{ShortURL: "short"})
+	}
+	assert.Len(t, queue.events, clickQueueSize)
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go queue.run(&wg)
+	queue.close()
+	wg.Wait()
+	assert.Empty(t, queue.events)
+}
+
+func TestClickQueueClosed(t *testing.T) {
+	var saved int
+	queue := newClickQueue(func(_ context.Context, batch []ClickEvent) error {
+		saved += len(batch)
+		return nil
+	})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go queue.run(&wg)
+
+	// Переходы, завершившиеся одновременно с закрытием и после него, не приводят к панике
+	var pushWG sync.WaitGroup
+	for range 10 {
+		pushWG.Add(1)
+		go func() {
+			defer pushWG.Done()
+			for range 10 {
+				queue.push(ClickEvent{ShortURL: "short"})
+			}
+		}()
+	}
+	queue.close()
+	pushWG.Wait()
+	wg.Wait()
+	queue.close()
+	assert.NotPanics(t, func() { queue.push(ClickEvent{ShortURL: "late"}) })
+	assert.LessOrEqual(t, saved, 100)
+}
+
+func TestTopValues(t *testing.T) {
+	counts := map[string]int{"b": 2, "a": 2, "c": 5}
+	for i := range urlStatsTopSize {
+		counts[fmt.Sprintf("z%02d", i)] = 1
+	}
+	top := topValues(counts)
+	require.Len(t, top, urlStatsTopSize)
+	assert.Equal(t, []TopValue{{Value: "c", Count: 5}, {Value: "a", Count: 2}, {Value: "b", Count: 2}}, top[:3])
+	assert.Equal(t, TopValue{Value: "z00", Count: 1}, top[3])
+}
+
+func TestAggregateClicksEmpty(t *testing.T) {
+	assert.Equal(t, &URLStats{
+		ClicksPerDay:  []DayClicks{},
+		TopReferrers:  []TopValue{},
+		TopUserAgents: []TopValue{},
+	}, aggregateClicks(nil))
+}
@@ -0,0 +1,203 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testDatabaseDSNEnv - переменная окружения с адресом тестовой БД.
+// Если не задана, тесты хранилища в БД на реальной базе пропускаются.
+const testDatabaseDSNEnv = "TEST_DATABASE_DSN"
+
+// testUserURLsSearch проверяет поиск ссылок пользователя по подстроке полной ссылки и по домену.
+// Общий для всех реализаций хранилища.
+func testUserURLsSearch(t *testing.T, store URLStorage) {
+	ctx := context.Background()
+	// Полные ссылки уникальны в рамках хранилища, поэтому делаем их уникальными для каждого запуска
+	nonce := fmt.Sprintf("%d", time.Now().UnixNano())
+
+	user, err := store.CreateUser(ctx)
+	require.NoError(t, err)
+	otherUser, err := store.CreateUser(ctx)
+	require.NoError(t, err)
+
+	docsGuide := "https://Example.com/Docs/guide?v=" + nonce
+	blog := "https://www.example.com/blog/" + nonce
+	otherDocs := "http://other.org/docs/" + nonce
+	percent := "https://user@example.com:8080/100%_done/" + nonce
+	for _, original := range []string{docsGuide, blog, otherDocs, percent} {
+		_, err = store.SaveURL(ctx, ShortenURL{Original: original}, user.ID)
+		require.NoError(t, err)
+	}
+	_, err = store.SaveURL(ctx, ShortenURL{Original: "https://example.com/docs/foreign/" + nonce}, otherUser.ID)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name   string
+		filter UserURLsFilter
+		want   []string
+	}{
+		{
+			name:   "Подстрока без учета регистра",
+			filter: UserURLsFilter{Query: "docs"},
+			want:   []string{docsGuide, otherDocs},
+		},
+		{
+			name:   "Домен без учета регистра",
+			filter: UserURLsFilter{Domain: "EXAMPLE.com"},
+			want:   []string{docsGuide, percent},
+		},
+		{
+			name:   "Поддомен не совпадает с доменом",
+			filter: UserURLsFilter{Domain: "www.example.com"},
+			want:   []string{blog},
+		},
+		{
+			name:   "Подстрока и домен",
+			filter: UserURLsFilter{Query: "docs", Domain: "example.com"},
+			want:   []string{docsGuide},
+		},
+		{
+			name:   "Спецсимволы шаблона ищутся как есть",
+			filter: UserURLsFilter{Query: "%_"},
+			want:   []string{percent},
+		},
+		{
+			name:   "Ничего не найдено",
+			filter: UserURLsFilter{Query: "missing"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			urls, getErr := store.GetUserURLs(ctx, user.ID, tt.filter)
+			require.NoError(t, getErr)
+			var originals []string
+			for _, url := range urls {
+				originals = append(originals, url.Original)
+			}
+			assert.ElementsMatch(t, tt.want, originals)
+		})
+	}
+}
+
+func TestMapUserURLsSearch(t *testing.T) {
+	store, err := NewURLMapStore(MapConfig{})
+	require.NoError(t, err)
+	defer store.Close()
+
+	testUserURLsSearch(t, store)
+}
+
+func TestPgUserURLsSearch(t *testing.T) {
+	dsn := os.Getenv(testDatabaseDSNEnv)
+	if dsn == "" {
+		t.Skipf("%s is not set", testDatabaseDSNEnv)
+	}
+	store, err := NewURLPgStore(PgConfig{DSN: dsn})
+	require.NoError(t, err)
+	defer store.Close()
+
+	testUserURLsSearch(t, store)
+}
+
+// testURLUniqueness проверяет сохранение одной полной ссылки разными пользователями в области уникальности scope.
+// Общий для всех реализаций хранилища. nonce делает полные ссылки уникальными для каждого запуска.
+func testURLUniqueness(t *testing.T, store URLStorage, scope UniquenessScope, nonce string) {
+	ctx := context.Background()
+	user, err := store.CreateUser(ctx)
+	require.NoError(t, err)
+	otherUser, err := store.CreateUser(ctx)
+	require.NoError(t, err)
+	original := "https://example.com/unique/" + nonce
+
+	id, err := store.SaveURL(ctx, ShortenURL{Original: original}, user.ID)
+	require.NoError(t, err)
+
+	// Повторное сокращение тем же пользователем
+	againID, err := store.SaveURL(ctx, ShortenURL{Original: original}, user.ID)
+	if scope == UniqueNone {
+		require.NoError(t, err)
+		assert.NotEqual(t, id, againID)
+	} else {
+		assert.ErrorIs(t, err, ErrConflict)
+		assert.Equal(t, id, againID)
+	}
+
+	// Другая запись той же полной ссылки уникальна по канонической форме, но сохраняется как передана
+	variant := "https://Example.com:443/unique/" + nonce
+	variantID, err := store.SaveURL(ctx, ShortenURL{Original: variant, Canonical: original}, user.ID)
+	if scope == UniqueNone {
+		require.NoError(t, err)
+		variantURL, _, err := store.GetURL(ctx, variantID, "")
+		require.NoError(t, err)
+		assert.Equal(t, variant, variantURL)
+	} else {
+		assert.ErrorIs(t, err, ErrConflict)
+		assert.Equal(t, id, variantID)
+	}
+
+	// Сокращение той же ссылки другим пользователем
+	otherID, err := store.SaveURL(ctx, ShortenURL{Original: original}, otherUser.ID)
+	if scope == UniqueGlobal {
+		assert.ErrorIs(t, err, ErrConflict)
+		assert.Equal(t, id, otherID)
+		return
+	}
+	require.NoError(t, err)
+	assert.NotEqual(t, id, otherID)
+	urls, err := store.GetUserURLs(ctx, otherUser.ID, UserURLsFilter{})
+	require.NoError(t, err)
+	require.Len(t, urls, 1)
+	assert.Equal(t, otherID, urls[0].Shorten)
+
+	// Пачка с уже сохраненной пользователем ссылкой
+	err = store.SaveBatchURL(ctx, []ShortenURL{{Original: original}}, otherUser.ID)
+	if scope == UniqueNone {
+		assert.NoError(t, err)
+	} else {
+		assert.ErrorIs(t, err, ErrConflict)
+	}
+}
+
+// uniquenessScopes - все области уникальности полных ссылок.
+var uniquenessScopes = []UniquenessScope{UniqueGlobal, UniquePerUser, UniqueNone}
+
+func TestMapURLUniqueness(t *testing.T) {
+	for _, scope := range uniquenessScopes {
+		t.Run(string(scope), func(t *testing.T) {
+			store, err := NewURLMapStore(MapConfig{Uniqueness: scope})
+			require.NoError(t, err)
+			defer store.Close()
+
+			testURLUniqueness(t, store, scope, "map")
+		})
+	}
+}
+
+func TestPgURLUniqueness(t *testing.T) {
+	dsn := os.Getenv(testDatabaseDSNEnv)
+	if dsn == "" {
+		t.Skipf("%s is not set", testDatabaseDSNEnv)
+	}
+	for _, scope := range uniquenessScopes {
+		t.Run(string(scope), func(t *testing.T) {
+			store, err := NewURLPgStore(PgConfig{DSN: dsn, Uniqueness: scope})
+			require.NoError(t, err)
+			defer store.Close()
+
+			nonce := fmt.Sprintf("%d", time.Now().UnixNano())
+			// Ссылки удаляются, чтобы повторы не мешали запуску хранилища с более строгой областью уникальности
+			defer func() {
+				_, err = store.pool.Exec(context.Background(), `DELETE FROM shorten_urls WHERE original LIKE $1`, "%"+nonce)
+				require.NoError(t, err)
+			}()
+			testURLUniqueness(t, store, scope, nonce)
+		})
+	}
+}
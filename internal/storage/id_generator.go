@@ -0,0 +1,271 @@
+package storage
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Виды генераторов сокращенных ссылок.
+const (
+	IDGeneratorRandom     = "random"     // Криптографически случайная строка
+	IDGeneratorSequential = "sequential" // Последовательный счетчик
+	IDGeneratorHash       = "hash"       // Хэш от оригинальной ссылки
+)
+
+// Формат сокращенных ссылок по умолчанию.
+const (
+	DefaultIDLength   = 8                                                                // Длина сокращенной ссылки
+	DefaultIDAlphabet = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz" // Набор символов сокращенной ссылки
+)
+
+// Допустимая длина сокращенных ссылок.
+const (
+	minIDLength = 4
+	maxIDLength = 32
+)
+
+// Максимальное количество попыток сгенерировать уникальную сокращенную ссылку.
+const maxIDAttempts = 10
+
+// ErrIDGeneration - ошибка, указывающая на то, что не удалось сгенерировать уникальную сокращенную ссылку.
+var ErrIDGeneration = errors.New("failed to generate unique short url id")
+
+// ErrIDSpaceExhausted - ошибка, указывающая на то, что значение счетчика не помещается в сокращенную ссылку
+// заданной длины и все последовательные ссылки уже выданы. Повторные попытки генерации в этом случае бессмысленны.
+var ErrIDSpaceExhausted = errors.New("short url id space exhausted")
+
+// IDFormat описывает формат генерируемых сокращенных ссылок.
+// Нулевые значения полей заменяются значениями по умолчанию.
+type IDFormat struct {
+	Length        int    // Длина сокращенной ссылки
+	Alphabet      string // Набор символов сокращенной ссылки
+	LegacyLengths []int  // Длины ссылок прежних форматов (из символов DefaultIDAlphabet), которые остаются валидными
+}
+
+// length возвращает длину сокращенной ссылки.
+func (f IDFormat) length() int {
+	if f.Length == 0 {
+		return DefaultIDLength
+	}
+	return f.Length
+}
+
+// alphabet возвращает набор символов сокращенной ссылки.
+func (f IDFormat) alphabet() string {
+	if f.Alphabet == "" {
+		return DefaultIDAlphabet
+	}
+	return f.Alphabet
+}
+
+// validate проверяет корректность формата.
+func (f IDFormat) validate() error {
+	if f.length() < minIDLength || f.length() > maxIDLength {
+		return fmt.Errorf("id length must be between %d and %d", minIDLength, maxIDLength)
+	}
+	for _, length := range f.LegacyLengths {
+		if length <= 0 {
+			return errors.New("legacy id lengths must be positive")
+		}
+	}
+	alphabet := f.alphabet()
+	if len(alphabet) < 2 {
+		return errors.New("id alphabet must contain at least 2 characters")
+	}
+	for i, ch := range alphabet {
+		if !strings.ContainsRune(DefaultIDAlphabet, ch) {
+			return fmt.Errorf("id alphabet contains invalid character %q", ch)
+		}
+		if strings.IndexRune(alphabet, ch) != i {
+			return fmt.Errorf("id alphabet contains duplicate character %q", ch)
+		}
+	}
+	return nil
+}
+
+// fold приводит id к регистру алфавита: если в алфавите нет заглавных букв, сокращенные ссылки
+// не зависят от регистра и приводятся к нижнему.
+func (f IDFormat) fold(id string) string {
+	alphabet := f.alphabet()
+	if strings.ToLower(alphabet) != alphabet {
+		return id
+	}
+	return strings.ToLower(id)
+}
+
+// isValid проверяет, что id соответствует текущему (без учета регистра, см. fold) или одному из прежних форматов.
+func (f IDFormat) isValid(id string) bool {
+	if len(id) == f.length() && consistsOf(f.fold(id), f.alphabet()) {
+		return true
+	}
+	return slices.Contains(f.LegacyLengths, len(id)) && consistsOf(id, DefaultIDAlphabet)
+}
+
+// consistsOf проверяет, что строка состоит только из символов alphabet.
+func consistsOf(s, alphabet string) bool {
+	for _, ch := range s {
+		if !strings.ContainsRune(alphabet, ch) {
+			return false
+		}
+	}
+	return true
+}
+
+// IDGenerator описывает интерфейс генератора сокращенных ссылок.
+type IDGenerator interface {
+	// Generate возвращает новую сокращенную ссылку для url.
+	// attempt - номер попытки (начиная с 0), увеличивается при повторной генерации после коллизии.
+	Generate(url string, attempt int) (string, error)
+}
+
+// idSeeder описывает генераторы, состояние которых восстанавливается по количеству уже сохраненных ссылок.
+type idSeeder interface {
+	Seed(count uint64)
+}
+
+// NewIDGenerator создает генератор сокращенных ссылок формата format по его названию.
+// Если название не задано, используется IDGeneratorRandom.
+func NewIDGenerator(kind string, format IDFormat) (IDGenerator, error) {
+	if err := format.validate(); err != nil {
+		return nil, err
+	}
+	switch kind {
+	case "", IDGeneratorRandom:
+		return NewRandomIDGenerator(format), nil
+	case IDGeneratorSequential:
+		return NewSequentialIDGenerator(format, 0), nil
+	case IDGeneratorHash:
+		return NewHashIDGenerator(format), nil
+	default:
+		return nil, fmt.Errorf("unknown id generator: %s", kind)
+	}
+}
+
+// RandomIDGenerator генерирует криптографически случайные сокращенные ссылки.
+type RandomIDGenerator struct {
+	format IDFormat
+}
+
+// NewRandomIDGenerator создает генератор случайных сокращенных ссылок.
+func NewRandomIDGenerator(format IDFormat) *RandomIDGenerator {
+	return &RandomIDGenerator{format: format}
+}
+
+// Generate возвращает случайную сокращенную ссылку.
+func (g *RandomIDGenerator) Generate(_ string, _ int) (string, error) {
+	length, alphabet := g.format.length(), g.format.alphabet()
+	// Отбрасываем байты, не кратные длине алфавита, чтобы все символы были равновероятны
+	maxByte := 256 - 256%len(alphabet)
+	id := make([]byte, 0, length)
+	buf := make([]byte, length*2)
+	for len(id) < length {
+		if _, err := rand.Read(buf); err != nil {
+			return "", fmt.Errorf("failed to read random bytes: %w", err)
+		}
+		for _, b := range buf {
+			if int(b) >= maxByte {
+				continue
+			}
+			id = append(id, alphabet[int(b)%len(alphabet)])
+			if len(id) == length {
+				break
+			}
+		}
+	}
+	return string(id), nil
+}
+
+// SequentialIDGenerator генерирует сокращенные ссылки из последовательного счетчика,
+// записанного в системе счисления с основанием, равным длине алфавита. Счетчик хранится в памяти процесса,
+// хранилище в БД заменяет его последовательностью БД (см. pgSequenceIDGenerator).
+type SequentialIDGenerator struct {
+	format  IDFormat
+	mutex   sync.Mutex
+	counter uint64
+}
+
+// NewSequentialIDGenerator создает последовательный генератор, начинающий счет со start.
+func NewSequentialIDGenerator(format IDFormat, start uint64) *SequentialIDGenerator {
+	return &SequentialIDGenerator{format: format, counter: start}
+}
+
+// Seed сдвигает счетчик так, чтобы он был не меньше количества уже сохраненных ссылок.
+func (g *SequentialIDGenerator) Seed(count uint64) {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	if g.counter < count {
+		g.counter = count
+	}
+}
+
+// Generate возвращает следующее значение счетчика в виде сокращенной ссылки.
+func (g *SequentialIDGenerator) Generate(_ string, _ int) (string, error) {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+	id, err := encodeID(g.counter, g.format.length(), g.format.alphabet())
+	if err != nil {
+		return "", err
+	}
+	g.counter++
+	return id, nil
+}
+
+// HashIDGenerator генерирует сокращенные ссылки из хэша оригинальной ссылки.
+// Одна и та же ссылка при первой попытке всегда получает один и тот же id.
+type HashIDGenerator struct {
+	format IDFormat
+}
+
+// NewHashIDGenerator создает генератор сокращенных ссылок по хэшу.
+func NewHashIDGenerator(format IDFormat) *HashIDGenerator {
+	return &HashIDGenerator{format: format}
+}
+
+// Generate возвращает сокращенную ссылку из хэша url (при повторных попытках к url добавляется номер попытки).
+func (g *HashIDGenerator) Generate(url string, attempt int) (string, error) {
+	data := url
+	if attempt > 0 {
+		data += "#" + strconv.Itoa(attempt)
+	}
+	alphabet := g.format.alphabet()
+	// Отбрасываем байты, не кратные длине алфавита, чтобы все символы были равновероятны
+	maxByte := 256 - 256%len(alphabet)
+	length := g.format.length()
+	id := make([]byte, 0, length)
+	sum := sha256.Sum256([]byte(data))
+	for len(id) < length {
+		for _, b := range sum {
+			if int(b) >= maxByte {
+				continue
+			}
+			id = append(id, alphabet[int(b)%len(alphabet)])
+			if len(id) == length {
+				break
+			}
+		}
+		// Если байтов хэша не хватило, продолжаем хэшем от хэша
+		sum = sha256.Sum256(sum[:])
+	}
+	return string(id), nil
+}
+
+// encodeID кодирует число в строку фиксированной длины из символов alphabet.
+// Если число не помещается в length символов, возвращается ErrIDSpaceExhausted.
+func encodeID(value uint64, length int, alphabet string) (string, error) {
+	base := uint64(len(alphabet))
+	id := make([]byte, length)
+	for i := length - 1; i >= 0; i-- {
+		id[i] = alphabet[value%base]
+		value /= base
+	}
+	if value != 0 {
+		return "", ErrIDSpaceExhausted
+	}
+	return string(id), nil
+}
@@ -0,0 +1,200 @@
+package storage
+
+import (
+	"regexp"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stubIDGenerator возвращает заранее заданные id по порядку (для тестов коллизий).
+type stubIDGenerator struct {
+	ids []string
+	i   int
+}
+
+func (g *stubIDGenerator) Generate(_ string, _ int) (string, error) {
+	id := g.ids[g.i%len(g.ids)]
+	g.i++
+	return id, nil
+}
+
+func TestNewIDGenerator(t *testing.T) {
+	tests := []struct {
+		name    string
+		kind    string
+		want    IDGenerator
+		wantErr bool
+	}{
+		{name: "По умолчанию", kind: "", want: &RandomIDGenerator{}},
+		{name: "Случайный", kind: IDGeneratorRandom, want: &RandomIDGenerator{}},
+		{name: "Последовательный", kind: IDGeneratorSequential, want: &SequentialIDGenerator{}},
+		{name: "Хэш", kind: IDGeneratorHash, want: &HashIDGenerator{}},
+		{name: "Неизвестный", kind: "uuid", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gen, err := NewIDGenerator(tt.kind, IDFormat{})
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.IsType(t, tt.want, gen)
+		})
+	}
+}
+
+func TestRandomIDGenerator(t *testing.T) {
+	gen := NewRandomIDGenerator(IDFormat{})
+	idReg := regexp.MustCompile(`^[a-zA-Z0-9]{8}$`)
+	ids := make(map[string]struct{})
+	for i := 0; i < 100; i++ {
+		id, err := gen.Generate("http://some.ru", 0)
+		require.NoError(t, err)
+		assert.Regexp(t, idReg, id)
+		ids[id] = struct{}{}
+	}
+	assert.Len(t, ids, 100)
+}
+
+func TestSequentialIDGenerator(t *testing.T) {
+	gen := NewSequentialIDGenerator(IDFormat{}, 0)
+	id, err := gen.Generate("", 0)
+	require.NoError(t, err)
+	assert.Equal(t, "00000000", id)
+	id, err = gen.Generate("", 0)
+	require.NoError(t, err)
+	assert.Equal(t, "00000001", id)
+
+	gen.Seed(62)
+	id, err = gen.Generate("", 0)
+	require.NoError(t, err)
+	assert.Equal(t, "00000010", id)
+
+	// Seed не отматывает счетчик назад
+	gen.Seed(1)
+	id, err = gen.Generate("", 0)
+	require.NoError(t, err)
+	assert.Equal(t, "00000011", id)
+
+	// Счетчик, не помещающийся в длину ссылки, не заворачивается на уже выданные ссылки
+	gen = NewSequentialIDGenerator(IDFormat{Length: 4, Alphabet: "ab"}, 15)
+	id, err = gen.Generate("", 0)
+	require.NoError(t, err)
+	assert.Equal(t, "bbbb", id)
+	for attempt := 0; attempt < 2; attempt++ {
+		_, err = gen.Generate("", attempt)
+		require.ErrorIs(t, err, ErrIDSpaceExhausted)
+	}
+}
+
+func TestHashIDGenerator(t *testing.T) {
+	gen := NewHashIDGenerator(IDFormat{})
+	id1, err := gen.Generate("http://some.ru", 0)
+	require.NoError(t, err)
+	id2, err := gen.Generate("http://some.ru", 0)
+	require.NoError(t, err)
+	assert.Equal(t, id1, id2)
+	assert.Len(t, id1, DefaultIDLength)
+
+	retryID, err := gen.Generate("http://some.ru", 1)
+	require.NoError(t, err)
+	assert.NotEqual(t, id1, retryID)
+
+	otherID, err := gen.Generate("http://other.ru", 0)
+	require.NoError(t, err)
+	assert.NotEqual(t, id1, otherID)
+
+	// Символы алфавита, длина которого не делит 256, встречаются равновероятно
+	format := IDFormat{Length: 32, Alphabet: "abcdefghijklmnopqrstuvwxyz0123456789"}
+	gen = NewHashIDGenerator(format)
+	counts := make(map[byte]int)
+	const samples = 2000
+	for i := 0; i < samples; i++ {
+		id, genErr := gen.Generate("http://some.ru/"+strconv.Itoa(i), 0)
+		require.NoError(t, genErr)
+		require.True(t, format.isValid(id), id)
+		for j := 0; j < len(id); j++ {
+			counts[id[j]]++
+		}
+	}
+	// Без отбрасывания первые 256 % 36 = 4 символа встречались бы на 1/7 чаще остальных
+	expected := float64(samples*format.Length) / float64(len(format.Alphabet))
+	for ch, count := range counts {
+		assert.InDelta(t, expected, count, expected*0.08, string(ch))
+	}
+}
+
+func TestIDFormat(t *testing.T) {
+	tests := []struct {
+		name    string
+		format  IDFormat
+		wantErr bool
+		valid   []string
+		invalid []string
+	}{
+		{
+			name:    "Формат по умолчанию",
+			format:  IDFormat{},
+			valid:   []string{"abcd5678", "ABCD5678"},
+			invalid: []string{"abcd567", "abcd-678"},
+		},
+		{
+			name:    "Без похожих символов с прежней длиной",
+			format:  IDFormat{Length: 6, Alphabet: "23456789abcdefghjkmnpqrstuvwxyz", LegacyLengths: []int{8}},
+			valid:   []string{"abc234", "abcd5678", "ABCD1OlI"},
+			invalid: []string{"abc1O0", "abcd567"},
+		},
+		{
+			name:    "Только строчные символы",
+			format:  IDFormat{Length: 10, Alphabet: "abcdefghijklmnopqrstuvwxyz0123456789"},
+			valid:   []string{"abcdefgh12", "ABCDEFGH12"},
+			invalid: []string{"abcd5678", "ABCD-FGH12"},
+		},
+		{
+			name:    "Слишком короткая длина",
+			format:  IDFormat{Length: 2},
+			wantErr: true,
+		},
+		{
+			name:    "Недопустимые символы",
+			format:  IDFormat{Alphabet: "abc/"},
+			wantErr: true,
+		},
+		{
+			name:    "Повторяющиеся символы",
+			format:  IDFormat{Alphabet: "abca"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.format.validate()
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			for _, id := range tt.valid {
+				assert.True(t, tt.format.isValid(id), id)
+			}
+			for _, id := range tt.invalid {
+				assert.False(t, tt.format.isValid(id), id)
+			}
+		})
+	}
+}
+
+func TestIDGeneratorsFormat(t *testing.T) {
+	format := IDFormat{Length: 12, Alphabet: "abcdefghijkmnpqrstuvwxyz"}
+	for _, kind := range []string{IDGeneratorRandom, IDGeneratorSequential, IDGeneratorHash} {
+		gen, err := NewIDGenerator(kind, format)
+		require.NoError(t, err)
+		id, err := gen.Generate("http://some.ru", 0)
+		require.NoError(t, err)
+		assert.True(t, format.isValid(id), "%s: %s", kind, id)
+	}
+}
@@ -0,0 +1,59 @@
+package storage
+
+import (
+	"hash/maphash"
+	"sync"
+)
+
+// defaultMapShards - количество шардов ссылок хранилища в памяти по умолчанию.
+const defaultMapShards = 64
+
+// urlShard описывает шард ссылок хранилища в памяти. Ссылка попадает в шард по хэшу сокращенной ссылки,
+// поэтому операции с разными ссылками (например, переходы) не блокируют друг друга.
+type urlShard struct {
+	mutex sync.RWMutex
+	urls  map[string]URLMapData
+}
+
+// newURLShards создает count пустых шардов ссылок.
+func newURLShards(count int) []urlShard {
+	if count <= 0 {
+		count = defaultMapShards
+	}
+	shards := make([]urlShard, count)
+	for i := range shards {
+		shards[i].urls = make(map[string]URLMapData)
+	}
+	return shards
+}
+
+// shard возвращает шард, в котором хранится ссылка id.
+func (s *URLMapStore) shard(id string) *urlShard {
+	return &s.shards[maphash.String(s.shardSeed, id)%uint64(len(s.shards))]
+}
+
+// getURL возвращает данные ссылки id.
+func (s *URLMapStore) getURL(id string) (URLMapData, bool) {
+	shard := s.shard(id)
+	shard.mutex.RLock()
+	defer shard.mutex.RUnlock()
+	urlData, ok := shard.urls[id]
+	return urlData, ok
+}
+
+// hasURL проверяет, сохранена ли ссылка id.
+func (s *URLMapStore) hasURL(id string) bool {
+	_, ok := s.getURL(id)
+	return ok
+}
+
+// urlsCount возвращает количество сохраненных ссылок (включая удаленные).
+func (s *URLMapStore) urlsCount() int {
+	var count int
+	for i := range s.shards {
+		s.shards[i].mutex.RLock()
+		count += len(s.shards[i].urls)
+		s.shards[i].mutex.RUnlock()
+	}
+	return count
+}
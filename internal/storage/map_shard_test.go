@@ -0,0 +1,122 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestShardedConcurrentAccess(t *testing.T) {
+	ctx := context.Background()
+	tmpFile, err := os.CreateTemp(".", "jsonDB_*.json")
+	require.NoError(t, err)
+	require.NoError(t, tmpFile.Close())
+	defer os.Remove(tmpFile.Name())
+	defer os.Remove(tmpFile.Name() + clicksFileSuffix)
+	defer os.Remove(tmpFile.Name() + walFileSuffix)
+
+	store, err := NewURLMapStore(MapConfig{StorageFile: tmpFile.Name(), Shards: 4, FileSync: FileSyncNever})
+	require.NoError(t, err)
+	assert.Len(t, store.shards, 4)
+
+	const workers, urlsPerWorker = 8, 50
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			user, err := store.CreateUser(ctx)
+			if !assert.NoError(t, err) {
+				return
+			}
+			for i := 0; i < urlsPerWorker; i++ {
+				id, err := store.SaveURL(ctx, ShortenURL{Original: fmt.Sprintf("http://example.com/%d/%d", w, i)}, user.ID)
+				if !assert.NoError(t, err) {
+					return
+				}
+				_, _, err = store.GetURL(ctx, id, "")
+				assert.NoError(t, err)
+				_, err = store.UpdateURL(ctx, user.ID, id, URLUpdate{Tags: []string{"tag" + strconv.Itoa(i%3)}, UpdateTags: true})
+				assert.NoError(t, err)
+				if i%5 == 0 {
+					assert.NoError(t, store.DeleteUserURLs(user.ID, []string{id}))
+				}
+				_, err = store.GetUserURLs(ctx, user.ID, UserURLsFilter{Tags: []string{"tag0"}})
+				assert.NoError(t, err)
+			}
+		}(w)
+	}
+	// Журнал сжимается одновременно с изменениями
+	for i := 0; i < 5; i++ {
+		require.NoError(t, store.compact())
+	}
+	wg.Wait()
+	require.NoError(t, store.Close())
+
+	store, err = NewURLMapStore(MapConfig{StorageFile: tmpFile.Name(), Shards: 16})
+	require.NoError(t, err)
+	defer store.Close()
+	assert.Equal(t, workers*urlsPerWorker, store.urlsCount())
+	count, err := store.GetURLsCount(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, workers*urlsPerWorker*4/5, count)
+	users, err := store.GetUsersCount(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, workers, users)
+	for userID := 1; userID <= workers; userID++ {
+		urls, err := store.GetUserURLs(ctx, userID, UserURLsFilter{Tags: []string{"tag1"}})
+		require.NoError(t, err)
+		assert.Len(t, urls, 14)
+	}
+}
+
+// benchMapURLs - количество ссылок в хранилище при замере параллельной нагрузки.
+const benchMapURLs = 10_000
+
+// BenchmarkMapStoreParallel замеряет смешанную параллельную нагрузку на хранилище в памяти
+// (9 переходов по ссылкам на одно сохранение) с одним шардом и с шардами по умолчанию.
+func BenchmarkMapStoreParallel(b *testing.B) {
+	for _, shards := range []int{1, defaultMapShards} {
+		b.Run(fmt.Sprintf("shards=%d", shards), func(b *testing.B) {
+			ctx := context.Background()
+			store, err := NewURLMapStore(MapConfig{Shards: shards})
+			require.NoError(b, err)
+			defer store.Close()
+			ids := make([]string, benchMapURLs)
+			for i := range ids {
+				ids[i], err = store.SaveURL(ctx, ShortenURL{Original: fmt.Sprintf("https://bench.example/%d", i)}, i%100+1)
+				require.NoError(b, err)
+			}
+
+			var workers, saved atomic.Int64
+			b.ResetTimer()
+			b.RunParallel(func(pb *testing.PB) {
+				// Каждая go рутина переходит по ссылкам со своего места
+				i := int(workers.Add(1)) * 7919
+				for pb.Next() {
+					i++
+					if i%10 == 0 {
+						n := saved.Add(1)
+						original := "https://bench.example/new/" + strconv.FormatInt(n, 10)
+						if _, err := store.SaveURL(ctx, ShortenURL{Original: original}, int(n%100)+1); err != nil {
+							b.Error(err)
+							return
+						}
+						continue
+					}
+					if _, _, err := store.GetURL(ctx, ids[i%benchMapURLs], ""); err != nil {
+						b.Error(err)
+						return
+					}
+				}
+			})
+		})
+	}
+}
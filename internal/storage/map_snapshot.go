@@ -0,0 +1,333 @@
+package storage
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/gob"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+)
+
+// FileFormat - формат снимка данных файлового хранилища.
+type FileFormat string
+
+// Форматы снимка данных. При чтении формат определяется по содержимому файла,
+// поэтому смена формата в настройках применяется при следующем сжатии журнала.
+const (
+	FileFormatJSON   FileFormat = "json"   // json, по записи в строке (по умолчанию)
+	FileFormatBinary FileFormat = "binary" // Двоичный формат (gob) с заголовком и контрольной суммой
+)
+
+// ParseFileFormat разбирает формат снимка данных (пустая строка - FileFormatJSON).
+func ParseFileFormat(format string) (FileFormat, error) {
+	switch FileFormat(format) {
+	case "", FileFormatJSON:
+		return FileFormatJSON, nil
+	case FileFormatBinary:
+		return FileFormatBinary, nil
+	}
+	return "", fmt.Errorf("unknown file format %q", format)
+}
+
+// binarySnapshotMagic - сигнатура в начале двоичного снимка (json снимок начинается с '{').
+var binarySnapshotMagic = []byte("URLS")
+
+// binarySnapshotVersion - версия двоичного формата снимка, записываемая в заголовок.
+const binarySnapshotVersion uint32 = 1
+
+// binarySnapshotHeaderSize - размер заголовка двоичного снимка: сигнатура и версия.
+const binarySnapshotHeaderSize = 8
+
+// binarySnapshotChecksumSize - размер контрольной суммы (crc32) заголовка и данных в конце двоичного снимка.
+const binarySnapshotChecksumSize = 4
+
+// snapshotChecksumTable - таблица crc32 (Castagnoli) для контрольной суммы двоичного снимка.
+var snapshotChecksumTable = crc32.MakeTable(crc32.Castagnoli)
+
+// binarySnapshotInfo описывает первое значение данных двоичного снимка. За ним следуют URLs ссылок.
+type binarySnapshotInfo struct {
+	Users []int // ID пользователей
+	URLs  int   // Количество ссылок
+}
+
+// UserFileRecord описывает структуру хранимого пользователя в json файле.
+type UserFileRecord struct {
+	ID int `json:"id"`
+}
+
+// snapshotLine описывает строку снимка в json файле: пользователя (заполнено поле User) или ссылку.
+// Снимки предыдущих версий приложения содержат только ссылки.
+type snapshotLine struct {
+	URLMapFileRecord
+	User *UserFileRecord `json:"user,omitempty"`
+}
+
+// userSnapshotLine описывает строку снимка с пользователем при записи (без пустых полей ссылки).
+type userSnapshotLine struct {
+	User UserFileRecord `json:"user"`
+}
+
+// detectFileFormat определяет формат снимка в файле fileName по его началу.
+// Отсутствующий или пустой файл считается json снимком.
+func detectFileFormat(fileName string) (FileFormat, error) {
+	file, err := os.Open(fileName)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return FileFormatJSON, nil
+		}
+		return "", err
+	}
+	defer file.Close()
+	magic := make([]byte, len(binarySnapshotMagic))
+	if _, err = io.ReadFull(file, magic); err != nil {
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			return FileFormatJSON, nil
+		}
+		return "", err
+	}
+	if bytes.Equal(magic, binarySnapshotMagic) {
+		return FileFormatBinary, nil
+	}
+	return FileFormatJSON, nil
+}
+
+// readSnapshot читает снимок из файла fileName (в любом формате) и передает функциям onUser и onURL
+// сохраненных пользователей и ссылки. Пользователи передаются первыми. Отсутствующий файл считается пустым.
+// Если readOnly, файл не изменяется (см. readJSONLines).
+func readSnapshot(
+	fileName string, readOnly bool, onUser func(userID int), onURL func(record URLMapFileRecord),
+) error {
+	format, err := detectFileFormat(fileName)
+	if err != nil {
+		return err
+	}
+	if format == FileFormatBinary {
+		return readBinarySnapshot(fileName, onUser, onURL)
+	}
+	_, err = readJSONLines(fileName, readOnly, func(line snapshotLine) error {
+		if line.User != nil {
+			onUser(line.User.ID)
+			return nil
+		}
+		onURL(line.URLMapFileRecord)
+		return nil
+	})
+	return err
+}
+
+// readBinarySnapshot читает двоичный снимок из файла fileName. Контрольная сумма проверяется после чтения
+// всех данных, поэтому при ошибке часть данных уже может быть передана функциям onUser и onURL.
+func readBinarySnapshot(fileName string, onUser func(userID int), onURL func(record URLMapFileRecord)) error {
+	file, err := os.Open(fileName)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	stat, err := file.Stat()
+	if err != nil {
+		return err
+	}
+	bodySize := stat.Size() - binarySnapshotHeaderSize - binarySnapshotChecksumSize
+	if bodySize < 0 {
+		return errors.New("binary snapshot is truncated")
+	}
+
+	reader := bufio.NewReader(file)
+	checksum := crc32.New(snapshotChecksumTable)
+	header := make([]byte, binarySnapshotHeaderSize)
+	if _, err = io.ReadFull(reader, header); err != nil {
+		return fmt.Errorf("failed to read binary snapshot header: %w", err)
+	}
+	checksum.Write(header)
+	if version := binary.BigEndian.Uint32(header[len(binarySnapshotMagic):]); version != binarySnapshotVersion {
+		return fmt.Errorf("unsupported binary snapshot version %d", version)
+	}
+
+	body := io.TeeReader(io.LimitReader(reader, bodySize), checksum)
+	decoder := gob.NewDecoder(body)
+	var info binarySnapshotInfo
+	if err = decoder.Decode(&info); err != nil {
+		return fmt.Errorf("failed to decode binary snapshot info: %w", err)
+	}
+	for _, userID := range info.Users {
+		onUser(userID)
+	}
+	for i := 0; i < info.URLs; i++ {
+		// gob не заполняет поля с нулевыми значениями, поэтому каждая ссылка декодируется в новую переменную
+		var record URLMapFileRecord
+		if err = decoder.Decode(&record); err != nil {
+			return fmt.Errorf("failed to decode binary snapshot url %d: %w", i, err)
+		}
+		onURL(record)
+	}
+	// Декодер мог прочитать не все данные до контрольной суммы
+	if _, err = io.Copy(io.Discard, body); err != nil {
+		return fmt.Errorf("failed to read binary snapshot: %w", err)
+	}
+	sum := make([]byte, binarySnapshotChecksumSize)
+	if _, err = io.ReadFull(reader, sum); err != nil {
+		return fmt.Errorf("failed to read binary snapshot checksum: %w", err)
+	}
+	if binary.BigEndian.Uint32(sum) != checksum.Sum32() {
+		return errors.New("binary snapshot checksum mismatch")
+	}
+	return nil
+}
+
+// writeSnapshot атомарно заменяет файл fileName снимком пользователей users и ссылок records в формате format.
+func writeSnapshot(fileName string, format FileFormat, users []int, records []URLMapFileRecord) error {
+	return replaceFile(fileName, func(w io.Writer) error {
+		if format == FileFormatBinary {
+			return encodeBinarySnapshot(w, users, records)
+		}
+		return encodeJSONSnapshot(w, users, records)
+	})
+}
+
+// replaceFile атомарно заменяет файл fileName данными, записанными функцией write: данные записываются
+// во временный файл в том же каталоге, сбрасываются на диск, после чего временный файл переименовывается в fileName.
+func replaceFile(fileName string, write func(w io.Writer) error) (err error) {
+	tmpFile, err := os.CreateTemp(filepath.Dir(fileName), filepath.Base(fileName)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			tmpFile.Close()
+			os.Remove(tmpFile.Name())
+		}
+	}()
+
+	writer := bufio.NewWriter(tmpFile)
+	if err = write(writer); err != nil {
+		return err
+	}
+	if err = writer.Flush(); err != nil {
+		return fmt.Errorf("failed to write temporary file: %w", err)
+	}
+	if err = tmpFile.Sync(); err != nil {
+		return fmt.Errorf("failed to sync temporary file: %w", err)
+	}
+	if err = tmpFile.Close(); err != nil {
+		return fmt.Errorf("failed to close temporary file: %w", err)
+	}
+	if err = os.Rename(tmpFile.Name(), fileName); err != nil {
+		return fmt.Errorf("failed to replace the file %s: %w", fileName, err)
+	}
+	return nil
+}
+
+// encodeJSONSnapshot записывает снимок в json, по записи в строке. Пользователи записываются первыми.
+func encodeJSONSnapshot(w io.Writer, users []int, records []URLMapFileRecord) error {
+	encoder := json.NewEncoder(w)
+	for _, userID := range users {
+		if err := encoder.Encode(userSnapshotLine{User: UserFileRecord{ID: userID}}); err != nil {
+			return fmt.Errorf("failed to encode user to temporary file: %w", err)
+		}
+	}
+	for i := range records {
+		if err := encoder.Encode(&records[i]); err != nil {
+			return fmt.Errorf("failed to encode record to temporary file: %w", err)
+		}
+	}
+	return nil
+}
+
+// encodeBinarySnapshot записывает двоичный снимок: заголовок (сигнатура и версия), данные в gob
+// (binarySnapshotInfo, затем ссылки) и контрольную сумму заголовка и данных.
+func encodeBinarySnapshot(w io.Writer, users []int, records []URLMapFileRecord) error {
+	checksum := crc32.New(snapshotChecksumTable)
+	body := io.MultiWriter(w, checksum)
+	header := binary.BigEndian.AppendUint32(slices.Clone(binarySnapshotMagic), binarySnapshotVersion)
+	if _, err := body.Write(header); err != nil {
+		return fmt.Errorf("failed to write binary snapshot header: %w", err)
+	}
+	encoder := gob.NewEncoder(body)
+	if err := encoder.Encode(binarySnapshotInfo{Users: users, URLs: len(records)}); err != nil {
+		return fmt.Errorf("failed to encode binary snapshot info: %w", err)
+	}
+	for i := range records {
+		if err := encoder.Encode(&records[i]); err != nil {
+			return fmt.Errorf("failed to encode record to temporary file: %w", err)
+		}
+	}
+	if _, err := w.Write(binary.BigEndian.AppendUint32(nil, checksum.Sum32())); err != nil {
+		return fmt.Errorf("failed to write binary snapshot checksum: %w", err)
+	}
+	return nil
+}
+
+// ConvertFile записывает данные файлового хранилища from (снимок в любом формате вместе с журналом операций)
+// в снимок формата format в файле to. Приложение, работающее с файлами, должно быть остановлено.
+// Если from и to совпадают, журнал операций после записи снимка удаляется. Иначе у файла to
+// не должно быть журнала операций, так как он был бы применен поверх нового снимка, а файлы служебных данных
+// и событий переходов копируются из from в to (файлы to, которых нет у from, удаляются вместе со старым снимком).
+func ConvertFile(from, to string, format FileFormat) error {
+	if _, err := os.Stat(from); err != nil {
+		return fmt.Errorf("failed to open source file: %w", err)
+	}
+	toWAL := to + walFileSuffix
+	if from != to {
+		for _, name := range []string{toWAL, toWAL + walOldFileSuffix} {
+			if _, err := os.Stat(name); err == nil {
+				return fmt.Errorf("target file has an operation log %s", name)
+			}
+		}
+	}
+
+	// Исходный файл читается без изменений, прерванная сбоем последняя запись только отбрасывается
+	store, err := NewURLMapStore(MapConfig{ReadOnly: true})
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+	if _, err = store.loadFile(from); err != nil {
+		return err
+	}
+	users, records := store.snapshotData()
+	if err = writeSnapshot(to, format, users, records); err != nil {
+		return err
+	}
+	if from == to {
+		for _, name := range []string{toWAL + walOldFileSuffix, toWAL} {
+			if err = os.Remove(name); err != nil && !errors.Is(err, os.ErrNotExist) {
+				return fmt.Errorf("failed to remove converted wal: %w", err)
+			}
+		}
+		return nil
+	}
+	for _, suffix := range []string{metaFileSuffix, clicksFileSuffix} {
+		if err = copyFile(from+suffix, to+suffix); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// copyFile атомарно заменяет файл to копией файла from. Если файла from нет, файл to удаляется.
+func copyFile(from, to string) error {
+	src, err := os.Open(from)
+	if errors.Is(err, os.ErrNotExist) {
+		if err = os.Remove(to); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("failed to remove the file %s: %w", to, err)
+		}
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to open the file %s: %w", from, err)
+	}
+	defer src.Close()
+	return replaceFile(to, func(w io.Writer) error {
+		if _, err := io.Copy(w, src); err != nil {
+			return fmt.Errorf("failed to copy the file %s: %w", from, err)
+		}
+		return nil
+	})
+}
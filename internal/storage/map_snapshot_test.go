@@ -0,0 +1,251 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseFileFormat(t *testing.T) {
+	tests := []struct {
+		name    string
+		format  string
+		want    FileFormat
+		wantErr bool
+	}{
+		{name: "По умолчанию", format: "", want: FileFormatJSON},
+		{name: "json", format: "json", want: FileFormatJSON},
+		{name: "Двоичный формат", format: "binary", want: FileFormatBinary},
+		{name: "Неизвестный формат", format: "xml", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			format, err := ParseFileFormat(tt.format)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, format)
+		})
+	}
+}
+
+// snapshotRecords возвращает count ссылок для записи в снимок.
+func snapshotRecords(count int) []URLMapFileRecord {
+	deletedAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	records := make([]URLMapFileRecord, 0, count)
+	for i := 0; i < count; i++ {
+		record := URLMapFileRecord{
+			OriginalURL: fmt.Sprintf("https://example.com/articles/%d?utm_source=news", i),
+			ShortURL:    fmt.Sprintf("id%06d", i),
+			UserID:      i%100 + 1,
+			Clicks:      i % 7,
+		}
+		if i%10 == 0 {
+			record.IsDeleted = true
+			record.DeletedAt = &deletedAt
+			record.Tags = []string{"news", "archive"}
+		}
+		records = append(records, record)
+	}
+	return records
+}
+
+// readSnapshotFile читает снимок из файла fileName в срезы пользователей и ссылок.
+func readSnapshotFile(fileName string) ([]int, []URLMapFileRecord, error) {
+	var users []int
+	var records []URLMapFileRecord
+	err := readSnapshot(fileName, true, func(userID int) {
+		users = append(users, userID)
+	}, func(record URLMapFileRecord) {
+		records = append(records, record)
+	})
+	return users, records, err
+}
+
+func TestSnapshotFormats(t *testing.T) {
+	users := []int{1, 2, 5}
+	records := snapshotRecords(20)
+	for _, format := range []FileFormat{FileFormatJSON, FileFormatBinary} {
+		t.Run(string(format), func(t *testing.T) {
+			fileName := "snapshot_" + string(format) + ".db"
+			defer os.Remove(fileName)
+			require.NoError(t, writeSnapshot(fileName, format, users, records))
+
+			detected, err := detectFileFormat(fileName)
+			require.NoError(t, err)
+			assert.Equal(t, format, detected)
+			gotUsers, gotRecords, err := readSnapshotFile(fileName)
+			require.NoError(t, err)
+			assert.Equal(t, users, gotUsers)
+			assert.Equal(t, records, gotRecords)
+		})
+	}
+
+	// Отсутствующий файл считается пустым json снимком
+	detected, err := detectFileFormat("not_exists.db")
+	require.NoError(t, err)
+	assert.Equal(t, FileFormatJSON, detected)
+}
+
+func TestReadBinarySnapshotErrors(t *testing.T) {
+	fileName := "snapshot_broken.db"
+	defer os.Remove(fileName)
+	require.NoError(t, writeSnapshot(fileName, FileFormatBinary, []int{1}, snapshotRecords(5)))
+	data, err := os.ReadFile(fileName)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		corrupt func(data []byte) []byte
+		wantErr string
+	}{
+		{
+			name: "Контрольная сумма не совпадает",
+			corrupt: func(data []byte) []byte {
+				data[len(data)-1] ^= 0xff
+				return data
+			},
+			wantErr: "checksum mismatch",
+		},
+		{
+			name: "Неподдерживаемая версия",
+			corrupt: func(data []byte) []byte {
+				data[binarySnapshotHeaderSize-1] = 2
+				return data
+			},
+			wantErr: "unsupported binary snapshot version 2",
+		},
+		{
+			name: "Обрезанный файл",
+			corrupt: func(data []byte) []byte {
+				return data[:binarySnapshotHeaderSize]
+			},
+			wantErr: "truncated",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			broken := tt.corrupt(append([]byte(nil), data...))
+			require.NoError(t, os.WriteFile(fileName, broken, 0666))
+			_, _, err := readSnapshotFile(fileName)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+func TestConvertFile(t *testing.T) {
+	ctx := context.Background()
+	tmpFile, err := os.CreateTemp(".", "jsonDB_*.json")
+	require.NoError(t, err)
+	require.NoError(t, tmpFile.Close())
+	binName := tmpFile.Name() + ".bin"
+	defer os.Remove(tmpFile.Name())
+	defer os.Remove(tmpFile.Name() + clicksFileSuffix)
+	defer os.Remove(tmpFile.Name() + walFileSuffix)
+	defer os.Remove(tmpFile.Name() + metaFileSuffix)
+	defer os.Remove(binName)
+	defer os.Remove(binName + clicksFileSuffix)
+	defer os.Remove(binName + walFileSuffix)
+	defer os.Remove(binName + metaFileSuffix)
+
+	// Снимок пуст, данные есть только в журнале операций
+	user := User{ID: 3}
+	id := "short1"
+	writeWALRecords(t, tmpFile.Name()+walFileSuffix,
+		walRecord{Op: walOpUser, UserID: user.ID},
+		walRecord{Op: walOpCreate, URL: &URLMapFileRecord{OriginalURL: "http://example.com", ShortURL: id, UserID: user.ID, Tags: []string{"news"}}},
+	)
+	// Служебные данные и события переходов хранятся в отдельных файлах
+	require.NoError(t, os.WriteFile(tmpFile.Name()+metaFileSuffix, []byte(`{"purged_urls":2}`), 0666))
+	require.NoError(t, os.WriteFile(tmpFile.Name()+clicksFileSuffix,
+		[]byte(`{"short_url":"short1","time":"2024-05-01T12:00:00Z","ip":"10.0.0.1"}`+"\n"), 0666))
+
+	// Прерванная сбоем запись журнала отбрасывается, исходный журнал не изменяется
+	walFile, err := os.OpenFile(tmpFile.Name()+walFileSuffix, os.O_WRONLY|os.O_APPEND, 0666)
+	require.NoError(t, err)
+	_, err = walFile.WriteString(`{"op":"create","url":{"orig`)
+	require.NoError(t, err)
+	require.NoError(t, walFile.Close())
+	walData, err := os.ReadFile(tmpFile.Name() + walFileSuffix)
+	require.NoError(t, err)
+
+	require.NoError(t, ConvertFile(tmpFile.Name(), binName, FileFormatBinary))
+	data, err := os.ReadFile(tmpFile.Name() + walFileSuffix)
+	require.NoError(t, err)
+	assert.Equal(t, walData, data)
+	format, err := detectFileFormat(binName)
+	require.NoError(t, err)
+	assert.Equal(t, FileFormatBinary, format)
+
+	// Новое хранилище читает двоичный снимок и продолжает записывать его в заданном формате
+	binStore, err := NewURLMapStore(MapConfig{StorageFile: binName, FileFormat: FileFormatBinary})
+	require.NoError(t, err)
+	_, err = binStore.GetUser(ctx, user.ID)
+	require.NoError(t, err)
+	original, _, err := binStore.GetURL(ctx, id, "")
+	require.NoError(t, err)
+	assert.Equal(t, "http://example.com", original)
+	assert.Contains(t, binStore.tagIndex["news"], id)
+	purged, err := binStore.GetPurgedURLsCount(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 2, purged)
+	stats, err := binStore.GetURLStats(ctx, user.ID, id)
+	require.NoError(t, err)
+	assert.Equal(t, 1, stats.TotalClicks)
+	require.NoError(t, binStore.Close())
+	format, err = detectFileFormat(binName)
+	require.NoError(t, err)
+	assert.Equal(t, FileFormatBinary, format)
+
+	// У файла с журналом операций снимок не перезаписывается
+	assert.Error(t, ConvertFile(tmpFile.Name(), binName, FileFormatJSON))
+
+	// Перевод на месте удаляет примененный журнал операций
+	require.NoError(t, ConvertFile(binName, binName, FileFormatJSON))
+	assert.NoFileExists(t, binName+walFileSuffix)
+	users, records, err := readSnapshotFile(binName)
+	require.NoError(t, err)
+	assert.Equal(t, []int{user.ID}, users)
+	require.Len(t, records, 1)
+	assert.Equal(t, id, records[0].ShortURL)
+}
+
+// benchSnapshotURLs - количество ссылок в снимке при замере времени загрузки.
+const benchSnapshotURLs = 100_000
+
+// BenchmarkLoadSnapshot замеряет время загрузки снимка в разных форматах и размер файла снимка (bytes/file).
+func BenchmarkLoadSnapshot(b *testing.B) {
+	users := make([]int, 100)
+	for i := range users {
+		users[i] = i + 1
+	}
+	records := snapshotRecords(benchSnapshotURLs)
+	for _, format := range []FileFormat{FileFormatJSON, FileFormatBinary} {
+		b.Run(string(format), func(b *testing.B) {
+			fileName := "bench_snapshot_" + string(format) + ".db"
+			defer os.Remove(fileName)
+			require.NoError(b, writeSnapshot(fileName, format, users, records))
+			stat, err := os.Stat(fileName)
+			require.NoError(b, err)
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				store, err := NewURLMapStore(MapConfig{})
+				require.NoError(b, err)
+				if _, err = store.loadFile(fileName); err != nil {
+					b.Fatal(err)
+				}
+				store.Close()
+			}
+			b.ReportMetric(float64(stat.Size()), "bytes/file")
+		})
+	}
+}
@@ -5,124 +5,231 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"hash/maphash"
+	"io"
 	"os"
 	"regexp"
+	"slices"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/pinbrain/urlshortener/internal/logger"
-	"github.com/pinbrain/urlshortener/internal/utils"
 )
 
 // URLMapStore описывает структуру хранилища в памяти и в json файле (поддерживает оба вида).
+// Ссылки разделены на шарды со своими блокировками, пользователи и индексы ссылок защищены отдельной
+// блокировкой indexMutex. Если нужны обе блокировки, indexMutex берется первой.
 type URLMapStore struct {
-	store     map[string]URLMapData
-	userStore map[int][]string
-	jsonDB    jsonDB
-	mutex     sync.RWMutex
-	userMaxID int
+	shards     []urlShard       // Шарды ссылок
+	shardSeed  maphash.Seed     // Затравка хэша, по которому ссылка распределяется в шард
+	indexMutex sync.RWMutex     // Блокировка пользователей, индексов и служебных данных
+	userStore  map[int][]string // Сокращенные ссылки пользователей (отсортированы по возрастанию)
+	fileName   string           // Основной файл хранилища (снимок данных)
+	format     FileFormat       // Формат, в котором записывается снимок данных
+	wal        *walLog          // Журнал операций (nil - данные хранятся только в памяти)
+	userMaxID  int
+	aliasReg   *regexp.Regexp
+	idGen      IDGenerator
+	idFormat   IDFormat
+
+	tagIndex    map[string]map[string]struct{} // Индекс тегов: тег -> сокращенные ссылки с этим тегом
+	uniqueness  UniquenessScope                // Область уникальности полных ссылок
+	uniqueIndex map[string]string              // Индекс уникальности: ключ уникальности полной ссылки -> сокращенная ссылка
+	// События переходов по сокращенным ссылкам. Хранятся в памяти и в файле целиком, пока ссылка не удалена
+	// окончательно (см. MapConfig.DeletedRetention), поэтому растут с каждым переходом: для ссылок с большим
+	// количеством переходов следует использовать хранилище в БД
+	clicks      map[string][]ClickEvent
+	clicksDB    jsonDB     // Файл событий переходов
+	clicksMutex sync.Mutex // Блокировка событий переходов и их файла
+	clickQueue  *clickQueue
+
+	deletedRetention time.Duration // Срок хранения удаленных ссылок
+	purgedCount      int           // Количество окончательно удаленных ссылок
+	metaFileName     string        // Файл служебных данных хранилища
+	readOnly         bool          // Хранилище только для чтения (файлы данных не изменяются)
 
 	wg        sync.WaitGroup
 	ctx       context.Context
 	ctxCancel context.CancelFunc
 }
 
+// MapConfig описывает структуру конфигурации хранилища в памяти.
+type MapConfig struct {
+	StorageFile  string      // Полное имя json файла с данными (если не задано, данные хранятся только в памяти)
+	AliasPattern string      // Шаблон пользовательских сокращенных ссылок
+	IDGenerator  IDGenerator // Генератор сокращенных ссылок (по умолчанию - случайные ссылки формата IDFormat)
+	IDFormat     IDFormat    // Формат сокращенных ссылок
+
+	DeletedRetention time.Duration   // Срок хранения удаленных ссылок (0 - хранятся бессрочно)
+	Uniqueness       UniquenessScope // Область уникальности полных ссылок
+	FileSync         FileSyncPolicy  // Политика сброса журнала операций на диск
+	FileFormat       FileFormat      // Формат снимка данных (по умолчанию json)
+	Shards           int             // Количество шардов ссылок (по умолчанию defaultMapShards)
+	// Только для чтения: данные загружаются из файла, но изменения в него не записываются,
+	// фоновые задачи не запускаются
+	ReadOnly bool
+}
+
 // jsonDB описывает структуру для записи и чтения данных из json файла.
 type jsonDB struct {
-	file         *os.File
-	encoder      *json.Encoder
-	decoder      *json.Decoder
-	needSyncFile bool
+	file    *os.File
+	encoder *json.Encoder
+	decoder *json.Decoder
 }
 
-// URLMapFileRecord описывает структуру хранимых данных в json файле.
+// URLMapFileRecord описывает структуру хранимых данных в json файле (снимке и журнале операций).
 type URLMapFileRecord struct {
-	OriginalURL string `json:"original_url"`
-	ShortURL    string `json:"short_url"`
-	UserID      int    `json:"user_id"`
-	IsDeleted   bool   `json:"is_deleted"`
+	OriginalURL  string     `json:"original_url"`
+	CanonicalURL string     `json:"canonical_url,omitempty"`
+	ShortURL     string     `json:"short_url"`
+	UserID       int        `json:"user_id"`
+	IsDeleted    bool       `json:"is_deleted"`
+	DeletedAt    *time.Time `json:"deleted_at,omitempty"`
+	ExpiresAt    *time.Time `json:"expires_at,omitempty"`
+	IsExpired    bool       `json:"is_expired,omitempty"`
+	MaxClicks    int        `json:"max_clicks,omitempty"`
+	Clicks       int        `json:"clicks,omitempty"`
+	PasswordHash string     `json:"password_hash,omitempty"`
+	Tags         []string   `json:"tags,omitempty"`
 }
 
 // URLMapData описывает структуру хранимых ссылок в памяти.
 type URLMapData struct {
-	OriginalURL string
-	UserID      int
-	IsDeleted   bool
+	OriginalURL  string
+	CanonicalURL string // Каноническая форма полной ссылки (пустая строка - совпадает с OriginalURL)
+	UserID       int
+	IsDeleted    bool
+	DeletedAt    time.Time // Время удаления ссылки
+	ExpiresAt    time.Time // Время истечения срока действия (нулевое значение - бессрочная ссылка)
+	IsExpired    bool      // Ссылка помечена как просроченная
+	MaxClicks    int       // Максимальное количество переходов (0 - без ограничений)
+	Clicks       int       // Количество совершенных переходов
+	PasswordHash string    // bcrypt хэш пароля ссылки (пустая строка - ссылка без пароля)
+	Tags         []string  // Теги ссылки
+}
+
+// canonicalURL возвращает полную ссылку, по которой проверяется уникальность.
+func (d URLMapData) canonicalURL() string {
+	if d.CanonicalURL != "" {
+		return d.CanonicalURL
+	}
+	return d.OriginalURL
+}
+
+const walCompactInterval = 30 // Интервал (в секундах) проверки необходимости сжатия журнала операций.
+
+// metaFileSuffix - суффикс имени файла служебных данных хранилища (файл хранится рядом с основным файлом хранилища).
+const metaFileSuffix = ".meta"
+
+// storeMeta описывает служебные данные хранилища, сохраняемые в отдельном файле.
+type storeMeta struct {
+	PurgedURLs int `json:"purged_urls"` // Количество окончательно удаленных ссылок
 }
 
-const syncFileInterval = 30 // Интервал синхронизации данных в памяти и файле.
+// clicksFileSuffix - суффикс имени файла событий переходов (файл хранится рядом с основным файлом хранилища).
+const clicksFileSuffix = ".clicks"
 
 // NewURLMapStore создает новое хранилище в памяти согласно конфигурации.
 // При соответствующих настройках так же будет добавлена поддержка данных в json файле.
-func NewURLMapStore(storageFile string) (*URLMapStore, error) {
+func NewURLMapStore(cfg MapConfig) (*URLMapStore, error) {
+	aliasReg, err := compileAliasPattern(cfg.AliasPattern)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compile alias pattern: %w", err)
+	}
+	if err = cfg.IDFormat.validate(); err != nil {
+		return nil, fmt.Errorf("invalid id format: %w", err)
+	}
 	urlMapStore := &URLMapStore{
-		store:     make(map[string]URLMapData),
-		userStore: make(map[int][]string),
-		wg:        sync.WaitGroup{},
+		shards:      newURLShards(cfg.Shards),
+		shardSeed:   maphash.MakeSeed(),
+		userStore:   make(map[int][]string),
+		tagIndex:    make(map[string]map[string]struct{}),
+		uniqueIndex: make(map[string]string),
+		clicks:      make(map[string][]ClickEvent),
+		aliasReg:    aliasReg,
+		idGen:       cfg.IDGenerator,
+		idFormat:    cfg.IDFormat,
+		wg:          sync.WaitGroup{},
+
+		deletedRetention: cfg.DeletedRetention,
+		uniqueness:       cfg.Uniqueness,
+		readOnly:         cfg.ReadOnly,
+	}
+	if urlMapStore.idGen == nil {
+		urlMapStore.idGen = NewRandomIDGenerator(cfg.IDFormat)
 	}
 
 	urlMapStore.ctx, urlMapStore.ctxCancel = context.WithCancel(context.Background())
 
-	if storageFile != "" {
-		jsonDBFile, err := os.OpenFile(storageFile, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0666)
-		if err != nil {
-			return nil, err
+	urlMapStore.clickQueue = newClickQueue(urlMapStore.saveClicks)
+	if cfg.ReadOnly {
+		if cfg.StorageFile != "" {
+			if _, err = urlMapStore.loadFile(cfg.StorageFile); err != nil {
+				return nil, err
+			}
 		}
-		urlMapStore.jsonDB = jsonDB{
-			file:    jsonDBFile,
-			encoder: json.NewEncoder(jsonDBFile),
-			decoder: json.NewDecoder(jsonDBFile),
+		return urlMapStore, nil
+	}
+
+	if cfg.StorageFile != "" {
+		if err = urlMapStore.openFile(cfg.StorageFile, cfg.FileSync, cfg.FileFormat); err != nil {
+			return nil, err
 		}
 
-		record := &URLMapFileRecord{}
-		for {
-			if err = urlMapStore.jsonDB.decoder.Decode(record); err != nil {
-				if err.Error() == "EOF" {
-					break
-				}
-				return nil, err
-			}
-			urlMapStore.store[record.ShortURL] = URLMapData{
-				OriginalURL: record.OriginalURL,
-				IsDeleted:   record.IsDeleted,
-				UserID:      record.UserID,
-			}
-			userURLs := urlMapStore.userStore[record.UserID]
-			urlMapStore.userStore[record.UserID] = append(userURLs, record.ShortURL)
-			if urlMapStore.userMaxID < record.UserID {
-				urlMapStore.userMaxID = record.UserID
-			}
+		if err = urlMapStore.loadClicks(cfg.StorageFile + clicksFileSuffix); err != nil {
+			return nil, fmt.Errorf("failed to load click events: %w", err)
+		}
+		if err = urlMapStore.loadMeta(cfg.StorageFile + metaFileSuffix); err != nil {
+			return nil, fmt.Errorf("failed to load store meta: %w", err)
 		}
 
 		urlMapStore.wg.Add(1)
 		go urlMapStore.syncFileData()
 	}
 
+	urlMapStore.wg.Add(2)
+	go urlMapStore.expireURLs()
+	go urlMapStore.clickQueue.run(&urlMapStore.wg)
+	if urlMapStore.deletedRetention > 0 {
+		urlMapStore.wg.Add(1)
+		go urlMapStore.purgeURLs()
+	}
+
+	if seeder, ok := urlMapStore.idGen.(idSeeder); ok {
+		// Окончательно удаленные ссылки тоже учитываются, чтобы не выдавать повторно еще занятые id
+		seeder.Seed(uint64(urlMapStore.urlsCount() + urlMapStore.purgedCount))
+	}
+
 	return urlMapStore, nil
 }
 
 // SaveURL сохраняет сокращенную ссылку.
-func (s *URLMapStore) SaveURL(_ context.Context, url string, userID int) (string, error) {
-	s.mutex.Lock()
-	defer s.mutex.Unlock()
-	if userID > s.userMaxID {
-		s.userMaxID = userID
+// Если задан url.Shorten, то он используется в качестве сокращенной ссылки (алиаса).
+func (s *URLMapStore) SaveURL(_ context.Context, url ShortenURL, userID int) (string, error) {
+	s.indexMutex.Lock()
+	defer s.indexMutex.Unlock()
+	if err := s.checkAliases([]ShortenURL{url}); err != nil {
+		return "", err
 	}
-	id := utils.NewRandomString(urlIDLength)
-	if s.jsonDB.file != nil {
-		if err := s.jsonDB.encoder.Encode(URLMapFileRecord{OriginalURL: url, ShortURL: id, UserID: userID}); err != nil {
-			return "", err
-		}
+	if id, ok := s.uniqueIndex[s.uniqueness.uniqueKey(url.canonicalURL(), userID)]; ok {
+		return id, ErrConflict
 	}
-	s.store[id] = URLMapData{OriginalURL: url, IsDeleted: false, UserID: userID}
-	s.userStore[userID] = append(s.userStore[userID], id)
-	return id, nil
+	return s.saveURL(url, userID)
 }
 
 // SaveBatchURL сохраняет массив сокращенных ссылок.
-func (s *URLMapStore) SaveBatchURL(ctx context.Context, urls []ShortenURL, userID int) error {
+func (s *URLMapStore) SaveBatchURL(_ context.Context, urls []ShortenURL, userID int) error {
+	s.indexMutex.Lock()
+	defer s.indexMutex.Unlock()
+	if err := s.checkAliases(urls); err != nil {
+		return err
+	}
+	if err := s.checkUnique(urls, userID); err != nil {
+		return err
+	}
 	for i, url := range urls {
-		urlID, err := s.SaveURL(ctx, url.Original, userID)
+		urlID, err := s.saveURL(url, userID)
 		if err != nil {
 			return fmt.Errorf("failed to save batch of urls: %w", err)
 		}
@@ -131,35 +238,250 @@ func (s *URLMapStore) SaveBatchURL(ctx context.Context, urls []ShortenURL, userI
 	return nil
 }
 
-// GetURL возвращает полную ссылку по сокращенной.
-func (s *URLMapStore) GetURL(_ context.Context, id string) (string, error) {
-	s.mutex.RLock()
-	defer s.mutex.RUnlock()
-	urlData, ok := s.store[id]
+// checkAliases проверяет формат и уникальность алиасов сохраняемых ссылок.
+// Должна вызываться под блокировкой indexMutex.
+func (s *URLMapStore) checkAliases(urls []ShortenURL) error {
+	aliases := make(map[string]struct{})
+	for _, url := range urls {
+		if url.Shorten == "" {
+			continue
+		}
+		if !isValidAlias(s.aliasReg, url.Shorten) {
+			return ErrInvalidAlias
+		}
+		if s.hasURL(url.Shorten) {
+			return ErrAliasConflict
+		}
+		if _, ok := aliases[url.Shorten]; ok {
+			return ErrAliasConflict
+		}
+		aliases[url.Shorten] = struct{}{}
+	}
+	return nil
+}
+
+// checkUnique проверяет, что полные ссылки сохраняемых ссылок еще не сохранены в пределах области уникальности.
+// Должна вызываться под блокировкой indexMutex.
+func (s *URLMapStore) checkUnique(urls []ShortenURL, userID int) error {
+	keys := make(map[string]struct{})
+	for _, url := range urls {
+		key := s.uniqueness.uniqueKey(url.canonicalURL(), userID)
+		if key == "" {
+			continue
+		}
+		if _, ok := s.uniqueIndex[key]; ok {
+			return ErrConflict
+		}
+		if _, ok := keys[key]; ok {
+			return ErrConflict
+		}
+		keys[key] = struct{}{}
+	}
+	return nil
+}
+
+// indexUnique добавляет ссылку в индекс уникальности полных ссылок.
+// Должна вызываться под блокировкой indexMutex.
+func (s *URLMapStore) indexUnique(id string, canonical string, userID int) {
+	if key := s.uniqueness.uniqueKey(canonical, userID); key != "" {
+		// Если в файле оказались повторяющиеся ссылки (сохраненные в другой области уникальности), индексируется первая
+		if _, ok := s.uniqueIndex[key]; !ok {
+			s.uniqueIndex[key] = id
+		}
+	}
+}
+
+// unindexUnique удаляет ссылку из индекса уникальности полных ссылок.
+// Должна вызываться под блокировкой indexMutex.
+func (s *URLMapStore) unindexUnique(id string, canonical string, userID int) {
+	key := s.uniqueness.uniqueKey(canonical, userID)
+	if key != "" && s.uniqueIndex[key] == id {
+		delete(s.uniqueIndex, key)
+	}
+}
+
+// saveURL сохраняет ссылку в памяти и в файле. Должна вызываться под блокировкой indexMutex:
+// ссылки добавляются только под ней, поэтому сгенерированная сокращенная ссылка не будет занята до сохранения.
+func (s *URLMapStore) saveURL(url ShortenURL, userID int) (string, error) {
+	id := url.Shorten
+	if id == "" {
+		var err error
+		if id, err = s.generateID(url.Original); err != nil {
+			return "", err
+		}
+	}
+	urlData := URLMapData{
+		OriginalURL:  url.Original,
+		CanonicalURL: canonicalData(url.Original, url.Canonical),
+		IsDeleted:    false,
+		UserID:       userID,
+		ExpiresAt:    url.ExpiresAt,
+		MaxClicks:    url.MaxClicks,
+		PasswordHash: url.PasswordHash,
+		Tags:         url.Tags,
+	}
+	if err := s.logURL(walOpCreate, id, urlData); err != nil {
+		return "", err
+	}
+	s.putURL(id, urlData)
+	return id, nil
+}
+
+// addUserURL добавляет сокращенную ссылку в список ссылок пользователя, сохраняя порядок сортировки.
+// Должна вызываться под блокировкой indexMutex.
+func (s *URLMapStore) addUserURL(userID int, id string) {
+	userURLs := s.userStore[userID]
+	i, found := slices.BinarySearch(userURLs, id)
+	if !found {
+		s.userStore[userID] = slices.Insert(userURLs, i, id)
+	}
+}
+
+// generateID генерирует сокращенную ссылку, не совпадающую с уже сохраненными.
+// Должна вызываться под блокировкой indexMutex.
+func (s *URLMapStore) generateID(url string) (string, error) {
+	for attempt := 0; attempt < maxIDAttempts; attempt++ {
+		id, err := s.idGen.Generate(url, attempt)
+		if err != nil {
+			return "", err
+		}
+		if !s.hasURL(id) {
+			return id, nil
+		}
+		logger.Log.Debugw("Generated short url id already exists, retrying", "id", id, "attempt", attempt)
+	}
+	return "", ErrIDGeneration
+}
+
+// GetURL возвращает полную ссылку по сокращенной (если ссылка не найдена, то без учета регистра, см. IDFormat.fold)
+// и сокращенную ссылку, под которой она сохранена. Для защищенных ссылок предварительно проверяется пароль.
+// Проверка bcrypt хэша медленная, поэтому выполняется без блокировки шарда. Счетчик переходов ведется
+// только для ссылок с лимитом переходов: для них шард блокируется на запись и лимит проверяется повторно.
+func (s *URLMapStore) GetURL(_ context.Context, id string, password string) (string, string, error) {
+	urlData, ok := s.getURL(id)
+	if folded := s.idFormat.fold(id); !ok && folded != id {
+		id = folded
+		urlData, ok = s.getURL(id)
+	}
 	if !ok {
-		return "", nil
+		return "", "", nil
+	}
+	if err := checkURLAvailable(urlData, time.Now()); err != nil {
+		return "", "", err
+	}
+	if urlData.PasswordHash != "" {
+		if err := checkPassword(urlData.PasswordHash, password); err != nil {
+			return "", "", err
+		}
+	}
+	if urlData.MaxClicks == 0 {
+		return urlData.OriginalURL, id, nil
+	}
+
+	shard := s.shard(id)
+	shard.mutex.Lock()
+	defer shard.mutex.Unlock()
+	urlData, ok = shard.urls[id]
+	if !ok {
+		return "", "", nil
+	}
+	if err := checkURLAvailable(urlData, time.Now()); err != nil {
+		return "", "", err
+	}
+	if urlData.MaxClicks > 0 && urlData.Clicks >= urlData.MaxClicks {
+		return "", "", ErrClickLimit
+	}
+	urlData.Clicks++
+	shard.urls[id] = urlData
+	if err := s.logURL(walOpUpdate, id, urlData); err != nil {
+		logger.Log.Errorw("Failed to log url click", "id", id, "err", err)
 	}
+	return urlData.OriginalURL, id, nil
+}
+
+// checkURLAvailable проверяет, что по ссылке можно перейти на момент now: она не удалена и не истекла.
+func checkURLAvailable(urlData URLMapData, now time.Time) error {
 	if urlData.IsDeleted {
-		return "", ErrIsDeleted
+		return ErrIsDeleted
+	}
+	if urlData.IsExpired || isExpired(urlData.ExpiresAt, now) {
+		return ErrExpired
+	}
+	return nil
+}
+
+// loadClicks открывает файл событий переходов и загружает из него сохраненные события.
+func (s *URLMapStore) loadClicks(fileName string) error {
+	file, err := os.OpenFile(fileName, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0666)
+	if err != nil {
+		return err
+	}
+	s.clicksDB = jsonDB{
+		file:    file,
+		encoder: json.NewEncoder(file),
+		decoder: json.NewDecoder(file),
+	}
+	for {
+		var event ClickEvent
+		if err = s.clicksDB.decoder.Decode(&event); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		}
+		s.clicks[event.ShortURL] = append(s.clicks[event.ShortURL], event)
 	}
-	return urlData.OriginalURL, nil
+}
+
+// SaveClick добавляет событие перехода по ссылке в очередь на сохранение.
+func (s *URLMapStore) SaveClick(event ClickEvent) error {
+	s.clickQueue.push(event)
+	return nil
+}
+
+// GetURLStats возвращает статистику переходов по ссылке пользователя.
+// События, еще не сохраненные из очереди, в статистике не учитываются.
+func (s *URLMapStore) GetURLStats(_ context.Context, userID int, id string) (*URLStats, error) {
+	urlData, ok := s.getURL(id)
+	if !ok || urlData.IsDeleted || urlData.UserID != userID {
+		return nil, ErrNoData
+	}
+	s.clicksMutex.Lock()
+	defer s.clicksMutex.Unlock()
+	return aggregateClicks(s.clicks[id]), nil
+}
+
+// saveClicks сохраняет пачку событий переходов в памяти и в файле.
+func (s *URLMapStore) saveClicks(_ context.Context, batch []ClickEvent) error {
+	s.clicksMutex.Lock()
+	defer s.clicksMutex.Unlock()
+	for _, event := range batch {
+		s.clicks[event.ShortURL] = append(s.clicks[event.ShortURL], event)
+		if s.clicksDB.file != nil {
+			if err := s.clicksDB.encoder.Encode(event); err != nil {
+				return fmt.Errorf("failed to write click event to file: %w", err)
+			}
+		}
+	}
+	return nil
 }
 
 // IsValidID проверяет валидность сокращенной ссылки (проверка формата).
+// Валидными считаются сгенерированные ссылки текущего и прежних форматов, а также пользовательские алиасы.
 func (s *URLMapStore) IsValidID(id string) bool {
-	regStr := fmt.Sprintf(`^[a-zA-Z0-9]{%d}$`, urlIDLength)
-	validIDReg := regexp.MustCompile(regStr)
-	return validIDReg.MatchString(id)
+	return s.idFormat.isValid(id) || isValidAlias(s.aliasReg, id)
 }
 
 // CreateUser сохраняет нового пользователя.
 func (s *URLMapStore) CreateUser(_ context.Context) (*User, error) {
-	s.mutex.Lock()
-	defer s.mutex.Unlock()
-	s.userMaxID++
-	userID := s.userMaxID
-	s.userStore[userID] = []string{}
-	return &User{ID: s.userMaxID}, nil
+	s.indexMutex.Lock()
+	defer s.indexMutex.Unlock()
+	userID := s.userMaxID + 1
+	if err := s.logOp(walRecord{Op: walOpUser, UserID: userID}); err != nil {
+		return nil, err
+	}
+	s.addUser(userID)
+	return &User{ID: userID}, nil
 }
 
 // GetUser возвращает данные пользователя по id.
@@ -167,152 +489,897 @@ func (s *URLMapStore) GetUser(_ context.Context, id int) (*User, error) {
 	if id <= 0 {
 		return nil, errors.New("invalid user id")
 	}
+	s.indexMutex.RLock()
 	_, ok := s.userStore[id]
+	s.indexMutex.RUnlock()
 	if !ok {
 		return nil, ErrNoData
 	}
 	return &User{ID: id}, nil
 }
 
-// GetUserURLs возвращает все сохраненные ссылки пользователя.
-func (s *URLMapStore) GetUserURLs(_ context.Context, userID int) ([]ShortenURL, error) {
+// GetUserURLs возвращает сохраненные ссылки пользователя, подходящие под фильтр.
+func (s *URLMapStore) GetUserURLs(_ context.Context, userID int, filter UserURLsFilter) ([]ShortenURL, error) {
 	if userID <= 0 {
 		return nil, errors.New("invalid user id")
 	}
-	s.mutex.RLock()
-	defer s.mutex.RUnlock()
+	s.indexMutex.RLock()
+	defer s.indexMutex.RUnlock()
 
 	var userURLs []ShortenURL
 	userStore := s.userStore[userID]
-	for _, url := range userStore {
-		if s.store[url].IsDeleted {
+	start := 0
+	if filter.After != "" {
+		start, _ = slices.BinarySearch(userStore, filter.After)
+		if start < len(userStore) && userStore[start] == filter.After {
+			start++
+		}
+	}
+	query := strings.ToLower(filter.Query)
+	domain := strings.ToLower(filter.Domain)
+	for _, url := range userStore[start:] {
+		if filter.Limit > 0 && len(userURLs) == filter.Limit {
+			break
+		}
+		urlData, _ := s.getURL(url)
+		if urlData.IsDeleted || !s.hasIndexedTags(url, filter.Tags) {
+			continue
+		}
+		if query != "" && !strings.Contains(strings.ToLower(urlData.OriginalURL), query) {
+			continue
+		}
+		if domain != "" && urlDomain(urlData.OriginalURL) != domain {
+			continue
+		}
+		userURLs = append(userURLs, ShortenURL{
+			Shorten:   url,
+			Original:  urlData.OriginalURL,
+			ExpiresAt: urlData.ExpiresAt,
+			Tags:      urlData.Tags,
+		})
+	}
+	return userURLs, nil
+}
+
+// ExportUserURLs передает функции fn по очереди все сохраненные ссылки пользователя.
+// Блокировка хранилища берется только на чтение очередной ссылки, а не на все время выгрузки.
+func (s *URLMapStore) ExportUserURLs(ctx context.Context, userID int, fn func(url ShortenURL) error) error {
+	if userID <= 0 {
+		return errors.New("invalid user id")
+	}
+	s.indexMutex.RLock()
+	ids := slices.Clone(s.userStore[userID])
+	s.indexMutex.RUnlock()
+
+	for _, id := range ids {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		urlData, ok := s.getURL(id)
+		// Ссылка могла быть удалена во время выгрузки
+		if !ok || urlData.IsDeleted || urlData.UserID != userID {
+			continue
+		}
+		err := fn(ShortenURL{
+			Shorten:   id,
+			Original:  urlData.OriginalURL,
+			ExpiresAt: urlData.ExpiresAt,
+			Tags:      urlData.Tags,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// hasIndexedTags проверяет по индексу тегов, что у ссылки есть все теги tags.
+// Должна вызываться под блокировкой indexMutex.
+func (s *URLMapStore) hasIndexedTags(id string, tags []string) bool {
+	for _, tag := range tags {
+		if _, ok := s.tagIndex[tag][id]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// indexTags добавляет теги ссылки в индекс тегов. Должна вызываться под блокировкой indexMutex.
+func (s *URLMapStore) indexTags(id string, tags []string) {
+	for _, tag := range tags {
+		if s.tagIndex[tag] == nil {
+			s.tagIndex[tag] = make(map[string]struct{})
+		}
+		s.tagIndex[tag][id] = struct{}{}
+	}
+}
+
+// unindexTags удаляет теги ссылки из индекса тегов. Должна вызываться под блокировкой indexMutex.
+func (s *URLMapStore) unindexTags(id string, tags []string) {
+	for _, tag := range tags {
+		delete(s.tagIndex[tag], id)
+		if len(s.tagIndex[tag]) == 0 {
+			delete(s.tagIndex, tag)
+		}
+	}
+}
+
+// GetDeletedUserURLs возвращает удаленные ссылки пользователя.
+func (s *URLMapStore) GetDeletedUserURLs(_ context.Context, userID int) ([]ShortenURL, error) {
+	if userID <= 0 {
+		return nil, errors.New("invalid user id")
+	}
+	s.indexMutex.RLock()
+	defer s.indexMutex.RUnlock()
+
+	var userURLs []ShortenURL
+	for _, url := range s.userStore[userID] {
+		urlData, _ := s.getURL(url)
+		if !urlData.IsDeleted {
 			continue
 		}
 		userURLs = append(userURLs, ShortenURL{
-			Shorten:  url,
-			Original: s.store[url].OriginalURL,
+			Shorten:   url,
+			Original:  urlData.OriginalURL,
+			ExpiresAt: urlData.ExpiresAt,
 		})
 	}
 	return userURLs, nil
 }
 
+// RestoreUserURLs восстанавливает удаленные ссылки пользователя.
+// Чужие и не удаленные ссылки пропускаются.
+func (s *URLMapStore) RestoreUserURLs(_ context.Context, userID int, urls []string) error {
+	for _, url := range urls {
+		if err := s.restoreURL(userID, url); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// restoreURL восстанавливает удаленную ссылку пользователя под блокировкой ее шарда.
+func (s *URLMapStore) restoreURL(userID int, id string) error {
+	shard := s.shard(id)
+	shard.mutex.Lock()
+	defer shard.mutex.Unlock()
+	urlData, ok := shard.urls[id]
+	if !ok || !urlData.IsDeleted || urlData.UserID != userID {
+		return nil
+	}
+	urlData.IsDeleted = false
+	urlData.DeletedAt = time.Time{}
+	if err := s.logURL(walOpUpdate, id, urlData); err != nil {
+		return err
+	}
+	shard.urls[id] = urlData
+	return nil
+}
+
+// UpdateURL изменяет полную ссылку и (или) теги сокращенной ссылки пользователя одной записью в журнал операций.
+// Возвращает сохраненную ссылку после изменения.
+func (s *URLMapStore) UpdateURL(_ context.Context, userID int, id string, update URLUpdate) (*ShortenURL, error) {
+	s.indexMutex.Lock()
+	defer s.indexMutex.Unlock()
+	shard := s.shard(id)
+	shard.mutex.Lock()
+	defer shard.mutex.Unlock()
+	urlData, ok := shard.urls[id]
+	if !ok || urlData.IsDeleted || urlData.UserID != userID {
+		return nil, ErrNoData
+	}
+	updatedData := urlData
+	if update.Original != "" {
+		updated := ShortenURL{Original: update.Original, Canonical: update.Canonical}
+		if existingID, ok := s.uniqueIndex[s.uniqueness.uniqueKey(updated.canonicalURL(), userID)]; ok && existingID != id {
+			return nil, ErrConflict
+		}
+		updatedData.OriginalURL = update.Original
+		updatedData.CanonicalURL = canonicalData(update.Original, update.Canonical)
+	}
+	if update.UpdateTags {
+		updatedData.Tags = update.Tags
+	}
+	if err := s.logURL(walOpUpdate, id, updatedData); err != nil {
+		return nil, err
+	}
+	if update.Original != "" {
+		s.unindexUnique(id, urlData.canonicalURL(), userID)
+		s.indexUnique(id, updatedData.canonicalURL(), userID)
+	}
+	if update.UpdateTags {
+		s.unindexTags(id, urlData.Tags)
+		s.indexTags(id, updatedData.Tags)
+	}
+	shard.urls[id] = updatedData
+	return &ShortenURL{Original: updatedData.OriginalURL, Shorten: id, Tags: updatedData.Tags}, nil
+}
+
 // DeleteUserURLs удаляет сокращенные ссылки пользователя.
 func (s *URLMapStore) DeleteUserURLs(userID int, urls []string) error {
-	s.mutex.Lock()
-	defer s.mutex.Unlock()
 	for _, url := range urls {
-		urlData, ok := s.store[url]
-		if !ok || urlData.IsDeleted || urlData.UserID != userID {
-			continue
+		if err := s.deleteURL(userID, url); err != nil {
+			return err
 		}
-		s.store[url] = URLMapData{OriginalURL: s.store[url].OriginalURL, IsDeleted: true}
-		s.jsonDB.needSyncFile = true
 	}
 	return nil
 }
 
-// processSyncFileData реализует синхронизацию данных в памяти и в json файле.
-func (s *URLMapStore) processSyncFileData() error {
-	s.mutex.Lock()
-	defer s.mutex.Unlock()
+// deleteURL помечает ссылку пользователя удаленной под блокировкой ее шарда.
+func (s *URLMapStore) deleteURL(userID int, id string) error {
+	shard := s.shard(id)
+	shard.mutex.Lock()
+	defer shard.mutex.Unlock()
+	urlData, ok := shard.urls[id]
+	if !ok || urlData.IsDeleted || urlData.UserID != userID {
+		return nil
+	}
+	urlData.IsDeleted = true
+	urlData.DeletedAt = time.Now()
+	if err := s.logOp(walRecord{Op: walOpDelete, ShortURL: id, DeletedAt: &urlData.DeletedAt}); err != nil {
+		return err
+	}
+	shard.urls[id] = urlData
+	return nil
+}
 
-	if s.jsonDB.file != nil {
-		logger.Log.Debugln("starting file sync")
-		// Временный файл для копирования в него всех данных
-		tmpFile, err := os.CreateTemp(".", "jsondb-*.tmp")
-		tmpIsClosed := false
-		defer func() {
-			if err = tmpFile.Close(); err != nil && !tmpIsClosed {
-				logger.Log.Errorf("failed to close temporary file: %w", err)
-			}
-		}()
+// newFileRecord формирует запись json файла по данным ссылки в памяти.
+func newFileRecord(id string, data URLMapData) URLMapFileRecord {
+	record := URLMapFileRecord{
+		OriginalURL:  data.OriginalURL,
+		CanonicalURL: data.CanonicalURL,
+		ShortURL:     id,
+		UserID:       data.UserID,
+		IsDeleted:    data.IsDeleted,
+		IsExpired:    data.IsExpired,
+		MaxClicks:    data.MaxClicks,
+		Clicks:       data.Clicks,
+		PasswordHash: data.PasswordHash,
+		Tags:         data.Tags,
+	}
+	if !data.ExpiresAt.IsZero() {
+		record.ExpiresAt = &data.ExpiresAt
+	}
+	if data.IsDeleted {
+		record.DeletedAt = &data.DeletedAt
+	}
+	return record
+}
+
+// newMapData формирует данные ссылки в памяти по записи json файла.
+func newMapData(record URLMapFileRecord) URLMapData {
+	urlData := URLMapData{
+		OriginalURL:  record.OriginalURL,
+		CanonicalURL: record.CanonicalURL,
+		IsDeleted:    record.IsDeleted,
+		UserID:       record.UserID,
+		IsExpired:    record.IsExpired,
+		MaxClicks:    record.MaxClicks,
+		Clicks:       record.Clicks,
+		PasswordHash: record.PasswordHash,
+		Tags:         record.Tags,
+	}
+	if record.ExpiresAt != nil {
+		urlData.ExpiresAt = *record.ExpiresAt
+	}
+	if record.DeletedAt != nil {
+		urlData.DeletedAt = *record.DeletedAt
+	} else if record.IsDeleted {
+		// Ссылки, удаленные предыдущими версиями приложения, хранятся с момента запуска
+		urlData.DeletedAt = time.Now()
+	}
+	return urlData
+}
+
+// putURL сохраняет данные ссылки в шарде и обновляет индексы. Должна вызываться под блокировкой indexMutex.
+func (s *URLMapStore) putURL(id string, urlData URLMapData) {
+	shard := s.shard(id)
+	shard.mutex.Lock()
+	oldData, ok := shard.urls[id]
+	shard.urls[id] = urlData
+	shard.mutex.Unlock()
+	if ok {
+		s.unindexURL(id, oldData)
+	}
+	s.indexTags(id, urlData.Tags)
+	s.indexUnique(id, urlData.canonicalURL(), urlData.UserID)
+	s.addUserURL(urlData.UserID, id)
+	if s.userMaxID < urlData.UserID {
+		s.userMaxID = urlData.UserID
+	}
+}
+
+// addUser добавляет пользователя в память (если его еще нет). Должна вызываться под блокировкой indexMutex.
+func (s *URLMapStore) addUser(userID int) {
+	if _, ok := s.userStore[userID]; !ok {
+		s.userStore[userID] = []string{}
+	}
+	if s.userMaxID < userID {
+		s.userMaxID = userID
+	}
+}
+
+// removeURL удаляет ссылку из шарда и из индексов. Должна вызываться под блокировкой indexMutex.
+func (s *URLMapStore) removeURL(id string) {
+	shard := s.shard(id)
+	shard.mutex.Lock()
+	urlData, ok := shard.urls[id]
+	delete(shard.urls, id)
+	shard.mutex.Unlock()
+	if ok {
+		s.unindexURL(id, urlData)
+	}
+}
+
+// unindexURL удаляет ссылку из индексов. Должна вызываться под блокировкой indexMutex.
+func (s *URLMapStore) unindexURL(id string, urlData URLMapData) {
+	s.unindexTags(id, urlData.Tags)
+	s.unindexUnique(id, urlData.canonicalURL(), urlData.UserID)
+	s.userStore[urlData.UserID] = slices.DeleteFunc(s.userStore[urlData.UserID], func(url string) bool {
+		return url == id
+	})
+}
+
+// openFile загружает данные хранилища из снимка в основном файле и журнала операций
+// и открывает журнал для записи новых операций. Снимок записывается в формате format.
+func (s *URLMapStore) openFile(fileName string, policy FileSyncPolicy, format FileFormat) error {
+	if policy == "" {
+		policy = FileSyncInterval
+	}
+	if format == "" {
+		format = FileFormatJSON
+	}
+	s.fileName = fileName
+	s.format = format
+	records, err := s.loadFile(fileName)
+	if err != nil {
+		return err
+	}
+	if s.wal, err = openWAL(fileName+walFileSuffix, policy, records); err != nil {
+		return fmt.Errorf("failed to open wal: %w", err)
+	}
+	return nil
+}
+
+// loadFile загружает в память данные из снимка в файле fileName (в любом формате) и применяет к ним журнал операций.
+// Возвращает количество примененных записей журнала.
+func (s *URLMapStore) loadFile(fileName string) (int, error) {
+	s.indexMutex.Lock()
+	defer s.indexMutex.Unlock()
+	err := readSnapshot(fileName, s.readOnly, s.addUser, func(record URLMapFileRecord) {
+		s.putURL(record.ShortURL, newMapData(record))
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to load snapshot: %w", err)
+	}
+	// Журнал, сжатие которого не завершилось, содержит более ранние операции
+	walName := fileName + walFileSuffix
+	var records int
+	for _, name := range []string{walName + walOldFileSuffix, walName} {
+		count, err := readJSONLines(name, s.readOnly, s.applyWAL)
 		if err != nil {
-			return fmt.Errorf("failed to create temporary file: %w", err)
+			return 0, fmt.Errorf("failed to replay wal %s: %w", name, err)
+		}
+		records += count
+	}
+	return records, nil
+}
+
+// applyWAL применяет к данным в памяти операцию из журнала. Должна вызываться под блокировкой indexMutex.
+// Операции содержат итоговое состояние, поэтому их повторное применение поверх снимка не меняет данные.
+func (s *URLMapStore) applyWAL(record walRecord) error {
+	switch record.Op {
+	case walOpCreate, walOpUpdate:
+		if record.URL == nil {
+			return fmt.Errorf("wal operation %q without url data", record.Op)
+		}
+		s.putURL(record.URL.ShortURL, newMapData(*record.URL))
+	case walOpDelete:
+		shard := s.shard(record.ShortURL)
+		shard.mutex.Lock()
+		defer shard.mutex.Unlock()
+		urlData, ok := shard.urls[record.ShortURL]
+		if !ok {
+			return nil
+		}
+		urlData.IsDeleted = true
+		urlData.DeletedAt = time.Now()
+		if record.DeletedAt != nil {
+			urlData.DeletedAt = *record.DeletedAt
+		}
+		shard.urls[record.ShortURL] = urlData
+	case walOpPurge:
+		s.removeURL(record.ShortURL)
+	case walOpUser:
+		s.addUser(record.UserID)
+	default:
+		return fmt.Errorf("unknown wal operation %q", record.Op)
+	}
+	return nil
+}
+
+// logOp записывает операцию в журнал (если данные хранятся в файле). Должна вызываться под блокировкой
+// изменяемых данных (шарда ссылки или indexMutex при добавлении ссылок и пользователей) до их изменения в памяти,
+// чтобы порядок операций в журнале совпадал с порядком изменений и данные не изменялись при ошибке записи.
+func (s *URLMapStore) logOp(record walRecord) error {
+	if s.wal == nil {
+		return nil
+	}
+	return s.wal.append(record)
+}
+
+// logURL записывает в журнал операцию op с данными ссылки. Должна вызываться под блокировкой изменяемой ссылки.
+func (s *URLMapStore) logURL(op walOp, id string, urlData URLMapData) error {
+	record := newFileRecord(id, urlData)
+	return s.logOp(walRecord{Op: op, URL: &record})
+}
+
+// needCompact проверяет, разросся ли журнал операций настолько, что его нужно сжать в снимок.
+func (s *URLMapStore) needCompact() bool {
+	return s.wal.size() >= max(walCompactMinRecords, s.urlsCount())
+}
+
+// compact сжимает журнал операций в снимок. Журнал переключается до копирования данных: операции,
+// записанные после переключения, попадают в новый журнал, а их повторное применение поверх снимка не меняет данные.
+// Шарды копируются по очереди, а снимок записывается в файл уже без блокировки.
+func (s *URLMapStore) compact() error {
+	if err := s.wal.rotate(); err != nil {
+		return err
+	}
+	users, records := s.snapshotData()
+	if err := writeSnapshot(s.fileName, s.format, users, records); err != nil {
+		return err
+	}
+	return s.wal.removeOld()
+}
+
+// snapshotData копирует данные для снимка: ID пользователей (по возрастанию) и ссылки.
+// Блокировка indexMutex удерживается на все время копирования, чтобы ссылки не добавлялись
+// между копированием шардов, а шарды блокируются по очереди.
+func (s *URLMapStore) snapshotData() ([]int, []URLMapFileRecord) {
+	s.indexMutex.RLock()
+	defer s.indexMutex.RUnlock()
+	users := make([]int, 0, len(s.userStore))
+	for userID := range s.userStore {
+		// Ссылки неавторизованных пользователей хранятся под ID 0
+		if userID > 0 {
+			users = append(users, userID)
+		}
+	}
+	slices.Sort(users)
+	records := make([]URLMapFileRecord, 0, s.urlsCount())
+	for i := range s.shards {
+		shard := &s.shards[i]
+		shard.mutex.RLock()
+		for id, urlData := range shard.urls {
+			records = append(records, newFileRecord(id, urlData))
 		}
+		shard.mutex.RUnlock()
+	}
+	return users, records
+}
 
-		tmpEncoder := json.NewEncoder(tmpFile)
+// syncFileData - go рутина, сбрасывающая журнал операций на диск каждые walSyncInterval (при политике
+// FileSyncInterval) и сжимающая его в снимок, если при проверке раз в walCompactInterval секунд он разросся.
+// При закрытии хранилища журнал сжимается.
+func (s *URLMapStore) syncFileData() {
+	syncTicker := time.NewTicker(walSyncInterval)
+	defer syncTicker.Stop()
+	compactTicker := time.NewTicker(walCompactInterval * time.Second)
+	defer compactTicker.Stop()
+	defer s.wg.Done()
 
-		// Все записи из памяти переносим во временный файл
-		for shortURL, data := range s.store {
-			record := URLMapFileRecord{
-				OriginalURL: data.OriginalURL,
-				ShortURL:    shortURL,
-				UserID:      data.UserID,
-				IsDeleted:   data.IsDeleted,
+	for {
+		select {
+		case <-syncTicker.C:
+			if s.wal.policy != FileSyncInterval {
+				continue
 			}
-			if err = tmpEncoder.Encode(&record); err != nil {
-				return fmt.Errorf("failed to encode record to temporary file: %w", err)
+			if err := s.wal.sync(); err != nil {
+				logger.Log.Errorw("failed to sync wal", "err", err)
 			}
+		case <-compactTicker.C:
+			if !s.needCompact() {
+				continue
+			}
+			if err := s.compact(); err != nil {
+				logger.Log.Errorw("failed to compact wal", "err", err)
+			}
+		case <-s.ctx.Done():
+			if s.wal.size() == 0 {
+				return
+			}
+			logger.Log.Debug("Compacting wal while closing url map store...")
+			if err := s.compact(); err != nil {
+				logger.Log.Errorw("failed to compact wal", "err", err)
+			}
+			return
 		}
+	}
+}
+
+// markExpiredURLs помечает ссылки, срок действия которых истек к моменту now.
+// Шарды блокируются по очереди. Возвращает количество помеченных ссылок.
+func (s *URLMapStore) markExpiredURLs(now time.Time) int {
+	var count int
+	for i := range s.shards {
+		count += s.markExpiredShardURLs(&s.shards[i], now)
+	}
+	return count
+}
 
-		// Закрываем файлы для замены старого на новый
-		if err = tmpFile.Close(); err != nil {
-			return fmt.Errorf("failed to close temporary file: %w", err)
+// markExpiredShardURLs помечает ссылки шарда, срок действия которых истек к моменту now.
+func (s *URLMapStore) markExpiredShardURLs(shard *urlShard, now time.Time) int {
+	shard.mutex.Lock()
+	defer shard.mutex.Unlock()
+	var count int
+	for id, urlData := range shard.urls {
+		if urlData.IsDeleted || urlData.IsExpired || !isExpired(urlData.ExpiresAt, now) {
+			continue
+		}
+		urlData.IsExpired = true
+		// Ссылка помечается и без записи в журнал: после перезапуска срок действия будет проверен заново
+		if err := s.logURL(walOpUpdate, id, urlData); err != nil {
+			logger.Log.Errorw("Failed to log expired url", "id", id, "err", err)
+		}
+		shard.urls[id] = urlData
+		count++
+	}
+	return count
+}
+
+// expireURLs - go рутина, помечающая ссылки с истекшим сроком действия.
+// Запускает проверку каждые expireURLsInterval секунд.
+func (s *URLMapStore) expireURLs() {
+	ticker := time.NewTicker(expireURLsInterval * time.Second)
+	defer ticker.Stop()
+	defer s.wg.Done()
+
+	for {
+		select {
+		case now := <-ticker.C:
+			if count := s.markExpiredURLs(now); count > 0 {
+				logger.Log.Debugw("Marked expired urls", "count", count)
+			}
+		case <-s.ctx.Done():
+			return
 		}
-		tmpIsClosed = true
-		if err = s.jsonDB.file.Close(); err != nil {
-			os.Remove(tmpFile.Name())
-			return fmt.Errorf("failed to close the original file: %w", err)
+	}
+}
+
+// purgeDeletedURLs окончательно удаляет ссылки, удаленные до момента deletedBefore, вместе с их переходами.
+// Возвращает количество удаленных ссылок.
+func (s *URLMapStore) purgeDeletedURLs(deletedBefore time.Time) (int, error) {
+	s.indexMutex.Lock()
+	defer s.indexMutex.Unlock()
+	var purged []string
+	var err error
+	for i := range s.shards {
+		if purged, err = s.purgeShardURLs(&s.shards[i], deletedBefore, purged); err != nil {
+			break
 		}
-		if err = os.Rename(tmpFile.Name(), s.jsonDB.file.Name()); err != nil {
-			os.Remove(tmpFile.Name())
-			return fmt.Errorf("failed to replace the original file: %w", err)
+	}
+	if len(purged) == 0 {
+		return 0, err
+	}
+	s.purgedCount += len(purged)
+	if clicksErr := s.purgeClicks(purged); clicksErr != nil && err == nil {
+		err = clicksErr
+	}
+	if s.metaFileName != "" {
+		if metaErr := s.saveMeta(); metaErr != nil && err == nil {
+			err = metaErr
 		}
+	}
+	return len(purged), err
+}
 
-		// Переоткрываем новый файл для использования хранилищем
-		s.jsonDB.file, err = os.OpenFile(s.jsonDB.file.Name(), os.O_RDWR|os.O_CREATE|os.O_APPEND, 0666)
-		if err != nil {
-			return fmt.Errorf("failed to reopen the original file: %w", err)
+// purgeShardURLs окончательно удаляет ссылки шарда, удаленные до момента deletedBefore, и добавляет их к purged.
+// Должна вызываться под блокировкой indexMutex.
+func (s *URLMapStore) purgeShardURLs(shard *urlShard, deletedBefore time.Time, purged []string) ([]string, error) {
+	shard.mutex.Lock()
+	defer shard.mutex.Unlock()
+	for id, urlData := range shard.urls {
+		if !urlData.IsDeleted || urlData.DeletedAt.After(deletedBefore) {
+			continue
+		}
+		if err := s.logOp(walRecord{Op: walOpPurge, ShortURL: id}); err != nil {
+			return purged, err
 		}
-		s.jsonDB.encoder = json.NewEncoder(s.jsonDB.file)
-		s.jsonDB.decoder = json.NewDecoder(s.jsonDB.file)
-		s.jsonDB.needSyncFile = false
+		delete(shard.urls, id)
+		s.unindexURL(id, urlData)
+		purged = append(purged, id)
 	}
+	return purged, nil
+}
 
+// purgeClicks удаляет события переходов по окончательно удаленным ссылкам.
+func (s *URLMapStore) purgeClicks(ids []string) error {
+	s.clicksMutex.Lock()
+	defer s.clicksMutex.Unlock()
+	var hasClicks bool
+	for _, id := range ids {
+		if _, ok := s.clicks[id]; ok {
+			delete(s.clicks, id)
+			hasClicks = true
+		}
+	}
+	if s.clicksDB.file != nil && hasClicks {
+		return s.rewriteClicksFile()
+	}
 	return nil
 }
 
-// syncFileData - go рутина запускающая синхронизацию данных в памяти в json файле.
-// Запускает синхронизацию каждые syncFileInterval секунд.
-func (s *URLMapStore) syncFileData() {
-	ticker := time.NewTicker(syncFileInterval * time.Second)
+// purgeURLs - go рутина, окончательно удаляющая ссылки с истекшим сроком хранения после удаления.
+// Запускает удаление каждые purgeURLsInterval секунд.
+func (s *URLMapStore) purgeURLs() {
+	ticker := time.NewTicker(purgeURLsInterval * time.Second)
 	defer ticker.Stop()
 	defer s.wg.Done()
 
 	for {
 		select {
-		case <-ticker.C:
-			if s.jsonDB.needSyncFile {
-				if err := s.processSyncFileData(); err != nil {
-					logger.Log.Errorw("failed to sync file data", "err", err)
-				}
+		case now := <-ticker.C:
+			count, err := s.purgeDeletedURLs(now.Add(-s.deletedRetention))
+			if err != nil {
+				logger.Log.Errorw("Error in purging deleted urls", "err", err)
 			}
-		case <-s.ctx.Done():
-			logger.Log.Debug("Sync file data while closing url map store...")
-			if err := s.processSyncFileData(); err != nil {
-				logger.Log.Errorw("failed to sync file data", "err", err)
+			if count > 0 {
+				logger.Log.Infow("Purged deleted urls", "count", count)
 			}
+		case <-s.ctx.Done():
 			return
 		}
 	}
 }
 
+// rewriteClicksFile атомарно заменяет файл событий переходов данными из памяти и открывает новый файл для дописывания.
+// Должна вызываться под блокировкой clicksMutex.
+func (s *URLMapStore) rewriteClicksFile() error {
+	fileName := s.clicksDB.file.Name()
+	err := replaceFile(fileName, func(w io.Writer) error {
+		encoder := json.NewEncoder(w)
+		for _, events := range s.clicks {
+			for _, event := range events {
+				if err := encoder.Encode(event); err != nil {
+					return fmt.Errorf("failed to write click event to file: %w", err)
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to rewrite click events file: %w", err)
+	}
+	file, err := os.OpenFile(fileName, os.O_RDWR|os.O_APPEND, 0666)
+	if err != nil {
+		return fmt.Errorf("failed to reopen click events file: %w", err)
+	}
+	// Прежний файл уже заменен новым, ошибка его закрытия не влияет на данные
+	s.clicksDB.file.Close()
+	s.clicksDB = jsonDB{
+		file:    file,
+		encoder: json.NewEncoder(file),
+		decoder: json.NewDecoder(file),
+	}
+	return nil
+}
+
+// loadMeta загружает служебные данные хранилища из файла (если он существует).
+func (s *URLMapStore) loadMeta(fileName string) error {
+	s.metaFileName = fileName
+	data, err := os.ReadFile(fileName)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+	var meta storeMeta
+	if err = json.Unmarshal(data, &meta); err != nil {
+		return err
+	}
+	s.purgedCount = meta.PurgedURLs
+	return nil
+}
+
+// saveMeta сохраняет служебные данные хранилища в файл. Должна вызываться под блокировкой indexMutex.
+func (s *URLMapStore) saveMeta() error {
+	data, err := json.Marshal(storeMeta{PurgedURLs: s.purgedCount})
+	if err != nil {
+		return err
+	}
+	if err = os.WriteFile(s.metaFileName, data, 0666); err != nil {
+		return fmt.Errorf("failed to write store meta file: %w", err)
+	}
+	return nil
+}
+
+// GetPurgedURLsCount возвращает количество окончательно удаленных ссылок.
+func (s *URLMapStore) GetPurgedURLsCount(_ context.Context) (int, error) {
+	s.indexMutex.RLock()
+	defer s.indexMutex.RUnlock()
+	return s.purgedCount, nil
+}
+
 // GetURLsCount возвращает количество сокращенных ссылок в БД.
 func (s *URLMapStore) GetURLsCount(_ context.Context) (int, error) {
 	var count int
-	for _, v := range s.store {
-		if !v.IsDeleted {
-			count++
+	for i := range s.shards {
+		shard := &s.shards[i]
+		shard.mutex.RLock()
+		for _, v := range shard.urls {
+			if !v.IsDeleted && !v.IsExpired {
+				count++
+			}
 		}
+		shard.mutex.RUnlock()
 	}
 	return count, nil
 }
 
 // GetURLsCount возвращает количество пользователей в БД.
 func (s *URLMapStore) GetUsersCount(_ context.Context) (int, error) {
+	s.indexMutex.RLock()
+	defer s.indexMutex.RUnlock()
 	return len(s.userStore), nil
 }
 
+// DumpUsers передает функции fn по очереди всех пользователей хранилища (по возрастанию ID).
+func (s *URLMapStore) DumpUsers(ctx context.Context, fn func(user User) error) error {
+	s.indexMutex.RLock()
+	ids := make([]int, 0, len(s.userStore))
+	for id := range s.userStore {
+		// Ссылки неавторизованных пользователей хранятся под ID 0
+		if id > 0 {
+			ids = append(ids, id)
+		}
+	}
+	s.indexMutex.RUnlock()
+	slices.Sort(ids)
+
+	for _, id := range ids {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := fn(User{ID: id}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DumpURLs передает функции fn по очереди все ссылки хранилища, включая удаленные (по возрастанию сокращенной ссылки).
+// Блокировка хранилища берется только на чтение очередной ссылки, а не на все время выгрузки.
+func (s *URLMapStore) DumpURLs(ctx context.Context, fn func(url URLRecord) error) error {
+	var ids []string
+	for i := range s.shards {
+		shard := &s.shards[i]
+		shard.mutex.RLock()
+		for id := range shard.urls {
+			ids = append(ids, id)
+		}
+		shard.mutex.RUnlock()
+	}
+	slices.Sort(ids)
+
+	for _, id := range ids {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		urlData, ok := s.getURL(id)
+		// Ссылка могла быть окончательно удалена во время выгрузки
+		if !ok {
+			continue
+		}
+		err := fn(URLRecord{
+			ShortenURL: ShortenURL{
+				Original:     urlData.OriginalURL,
+				Canonical:    urlData.CanonicalURL,
+				Shorten:      id,
+				ExpiresAt:    urlData.ExpiresAt,
+				MaxClicks:    urlData.MaxClicks,
+				PasswordHash: urlData.PasswordHash,
+				Tags:         urlData.Tags,
+			},
+			UserID:    urlData.UserID,
+			IsDeleted: urlData.IsDeleted,
+			DeletedAt: urlData.DeletedAt,
+			IsExpired: urlData.IsExpired,
+			Clicks:    urlData.Clicks,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// LoadUsers сохраняет пользователей с их ID. Уже существующие пользователи пропускаются.
+func (s *URLMapStore) LoadUsers(_ context.Context, users []User) error {
+	s.indexMutex.Lock()
+	defer s.indexMutex.Unlock()
+	for _, user := range users {
+		if user.ID <= 0 {
+			return errors.New("invalid user id")
+		}
+		if _, ok := s.userStore[user.ID]; !ok {
+			if err := s.logOp(walRecord{Op: walOpUser, UserID: user.ID}); err != nil {
+				return err
+			}
+		}
+		s.addUser(user.ID)
+	}
+	return nil
+}
+
+// LoadURLs сохраняет ссылки с их сокращенными ссылками в памяти и в файле.
+// Уже сохраненные ссылки пропускаются. Возвращает количество сохраненных ссылок.
+// Если сокращенная ссылка занята другой ссылкой или полная ссылка уже сохранена в пределах области уникальности,
+// не сохраняется вся пачка (ErrConflict).
+func (s *URLMapStore) LoadURLs(_ context.Context, urls []URLRecord) (int, error) {
+	s.indexMutex.Lock()
+	defer s.indexMutex.Unlock()
+	// Ссылки проверяются заранее, чтобы при конфликте не сохранять часть пачки
+	keys := make(map[string]struct{})
+	ids := make(map[string]loadedURL)
+	for _, url := range urls {
+		saved, ok := ids[url.Shorten]
+		if !ok {
+			var urlData URLMapData
+			if urlData, ok = s.getURL(url.Shorten); ok {
+				saved = loadedURL{original: urlData.OriginalURL, userID: urlData.UserID}
+			}
+		}
+		if ok {
+			if saved != (loadedURL{original: url.Original, userID: url.UserID}) {
+				return 0, errShortenTaken(url.Shorten)
+			}
+			continue
+		}
+		ids[url.Shorten] = loadedURL{original: url.Original, userID: url.UserID}
+		key := s.uniqueness.uniqueKey(url.canonicalURL(), url.UserID)
+		if key == "" {
+			continue
+		}
+		_, keySaved := s.uniqueIndex[key]
+		_, keyInBatch := keys[key]
+		if keySaved || keyInBatch {
+			return 0, fmt.Errorf("failed to load url %s: %w", url.Shorten, ErrConflict)
+		}
+		keys[key] = struct{}{}
+	}
+
+	var loaded int
+	for _, url := range urls {
+		if s.hasURL(url.Shorten) {
+			continue
+		}
+		urlData := URLMapData{
+			OriginalURL:  url.Original,
+			CanonicalURL: canonicalData(url.Original, url.Canonical),
+			UserID:       url.UserID,
+			IsDeleted:    url.IsDeleted,
+			DeletedAt:    url.DeletedAt,
+			ExpiresAt:    url.ExpiresAt,
+			IsExpired:    url.IsExpired,
+			MaxClicks:    url.MaxClicks,
+			Clicks:       url.Clicks,
+			PasswordHash: url.PasswordHash,
+			Tags:         url.Tags,
+		}
+		if urlData.IsDeleted && urlData.DeletedAt.IsZero() {
+			urlData.DeletedAt = time.Now()
+		}
+		if err := s.logURL(walOpCreate, url.Shorten, urlData); err != nil {
+			return loaded, err
+		}
+		s.putURL(url.Shorten, urlData)
+		loaded++
+	}
+	return loaded, nil
+}
+
 // Ping проверяет связь с хранилищем. В данном случае ничего не делает.
 func (s *URLMapStore) Ping(_ context.Context) error {
 	return nil
@@ -321,10 +1388,16 @@ func (s *URLMapStore) Ping(_ context.Context) error {
 // Close закрывает контекст и файл.
 func (s *URLMapStore) Close() error {
 	logger.Log.Debug("Closing url map store...")
+	s.clickQueue.close()
 	s.ctxCancel()
 	s.wg.Wait()
-	if s.jsonDB.file != nil {
-		return s.jsonDB.file.Close()
+	if s.clicksDB.file != nil {
+		if err := s.clicksDB.file.Close(); err != nil {
+			return err
+		}
+	}
+	if s.wal != nil {
+		return s.wal.close()
 	}
 	return nil
 }
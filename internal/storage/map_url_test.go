@@ -5,16 +5,26 @@ import (
 	"encoding/json"
 	"errors"
 	"os"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
 )
 
+// storedURL возвращает данные ссылки id из шарда хранилища (нулевое значение, если ссылки нет).
+func storedURL(store *URLMapStore, id string) URLMapData {
+	urlData, _ := store.getURL(id)
+	return urlData
+}
+
 func TestGetURL(t *testing.T) {
 	ctx := context.Background()
-	store, err := NewURLMapStore("")
+	store, err := NewURLMapStore(MapConfig{})
 	require.NoError(t, err)
 	defer store.Close()
 
@@ -33,7 +43,7 @@ func TestGetURL(t *testing.T) {
 		},
 		{
 			name:      "ссылка удалена",
-			url:       "http://some.ru",
+			url:       "http://deleted.ru",
 			isDeleted: true,
 			err:       ErrIsDeleted,
 		},
@@ -49,14 +59,14 @@ func TestGetURL(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			short := tt.short
 			if tt.url != "" {
-				short, err = store.SaveURL(ctx, tt.url, 1)
+				short, err = store.SaveURL(ctx, ShortenURL{Original: tt.url}, 1)
 				require.NoError(t, err)
 			}
 			if tt.isDeleted {
 				err = store.DeleteUserURLs(1, []string{short})
 				require.NoError(t, err)
 			}
-			full, err := store.GetURL(ctx, short)
+			full, _, err := store.GetURL(ctx, short, "")
 			if tt.err == nil {
 				require.NoError(t, err)
 				assert.Equal(t, tt.url, full)
@@ -67,9 +77,25 @@ func TestGetURL(t *testing.T) {
 	}
 }
 
+func TestGetURLCaseInsensitive(t *testing.T) {
+	ctx := context.Background()
+	store, err := NewURLMapStore(MapConfig{IDFormat: IDFormat{Alphabet: "abcdefghijklmnopqrstuvwxyz0123456789"}})
+	require.NoError(t, err)
+	defer store.Close()
+
+	id, err := store.SaveURL(ctx, ShortenURL{Original: "http://some.ru"}, 1)
+	require.NoError(t, err)
+	upper := strings.ToUpper(id)
+	assert.True(t, store.IsValidID(upper))
+	full, shortID, err := store.GetURL(ctx, upper, "")
+	require.NoError(t, err)
+	assert.Equal(t, "http://some.ru", full)
+	assert.Equal(t, id, shortID)
+}
+
 func TestGetUser(t *testing.T) {
 	ctx := context.Background()
-	store, err := NewURLMapStore("")
+	store, err := NewURLMapStore(MapConfig{})
 	require.NoError(t, err)
 	defer store.Close()
 
@@ -118,7 +144,7 @@ func TestGetUser(t *testing.T) {
 
 func TestGetUserURLs(t *testing.T) {
 	ctx := context.Background()
-	store, err := NewURLMapStore("")
+	store, err := NewURLMapStore(MapConfig{})
 	require.NoError(t, err)
 	defer store.Close()
 
@@ -133,40 +159,627 @@ func TestGetUserURLs(t *testing.T) {
 
 	err = store.SaveBatchURL(ctx, urls, 1)
 	require.NoError(t, err)
-	userURLs, err := store.GetUserURLs(ctx, 1)
+	userURLs, err := store.GetUserURLs(ctx, 1, UserURLsFilter{})
+	require.NoError(t, err)
+	assert.ElementsMatch(t, urls, userURLs)
+}
+
+func TestGetUserURLsPages(t *testing.T) {
+	ctx := context.Background()
+	store, err := NewURLMapStore(MapConfig{})
+	require.NoError(t, err)
+	defer store.Close()
+
+	for _, alias := range []string{"link-c", "link-a", "link-e", "link-b", "link-d"} {
+		_, err = store.SaveURL(ctx, ShortenURL{Original: "http://" + alias + ".ru", Shorten: alias}, 1)
+		require.NoError(t, err)
+	}
+	require.NoError(t, store.DeleteUserURLs(1, []string{"link-b"}))
+
+	tests := []struct {
+		name   string
+		filter UserURLsFilter
+		want   []string
+	}{
+		{
+			name: "Все ссылки",
+			want: []string{"link-a", "link-c", "link-d", "link-e"},
+		},
+		{
+			name:   "Первая страница",
+			filter: UserURLsFilter{Limit: 2},
+			want:   []string{"link-a", "link-c"},
+		},
+		{
+			name:   "Следующая страница",
+			filter: UserURLsFilter{After: "link-c", Limit: 2},
+			want:   []string{"link-d", "link-e"},
+		},
+		{
+			name:   "Курсор на удаленной ссылке",
+			filter: UserURLsFilter{After: "link-b", Limit: 2},
+			want:   []string{"link-c", "link-d"},
+		},
+		{
+			name:   "После последней ссылки",
+			filter: UserURLsFilter{After: "link-e", Limit: 2},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			urls, getErr := store.GetUserURLs(ctx, 1, tt.filter)
+			require.NoError(t, getErr)
+			var ids []string
+			for _, url := range urls {
+				ids = append(ids, url.Shorten)
+			}
+			assert.Equal(t, tt.want, ids)
+		})
+	}
+}
+
+func TestSaveURLAlias(t *testing.T) {
+	ctx := context.Background()
+	store, err := NewURLMapStore(MapConfig{})
+	require.NoError(t, err)
+	defer store.Close()
+
+	id, err := store.SaveURL(ctx, ShortenURL{Original: "http://some.ru", Shorten: "spring-sale"}, 1)
+	require.NoError(t, err)
+	assert.Equal(t, "spring-sale", id)
+	assert.True(t, store.IsValidID(id))
+
+	full, _, err := store.GetURL(ctx, id, "")
+	require.NoError(t, err)
+	assert.Equal(t, "http://some.ru", full)
+
+	_, err = store.SaveURL(ctx, ShortenURL{Original: "http://other.ru", Shorten: "spring-sale"}, 2)
+	assert.ErrorIs(t, err, ErrAliasConflict)
+
+	_, err = store.SaveURL(ctx, ShortenURL{Original: "http://other.ru", Shorten: "a!"}, 2)
+	assert.ErrorIs(t, err, ErrInvalidAlias)
+
+	urls := []ShortenURL{
+		{Original: "http://batch1.ru", Shorten: "batch-alias"},
+		{Original: "http://batch2.ru", Shorten: "batch-alias"},
+	}
+	err = store.SaveBatchURL(ctx, urls, 1)
+	assert.ErrorIs(t, err, ErrAliasConflict)
+	full, _, err = store.GetURL(ctx, "batch-alias", "")
+	require.NoError(t, err)
+	assert.Empty(t, full)
+}
+
+func TestSaveURLIDCollision(t *testing.T) {
+	ctx := context.Background()
+	idGen := &stubIDGenerator{ids: []string{"AAAAAAAA", "AAAAAAAA", "BBBBBBBB"}}
+	store, err := NewURLMapStore(MapConfig{IDGenerator: idGen})
+	require.NoError(t, err)
+	defer store.Close()
+
+	id, err := store.SaveURL(ctx, ShortenURL{Original: "http://some1.ru"}, 1)
+	require.NoError(t, err)
+	assert.Equal(t, "AAAAAAAA", id)
+
+	// Повторно сгенерированный id занят - берется следующий
+	id, err = store.SaveURL(ctx, ShortenURL{Original: "http://some2.ru"}, 1)
+	require.NoError(t, err)
+	assert.Equal(t, "BBBBBBBB", id)
+
+	full, _, err := store.GetURL(ctx, "AAAAAAAA", "")
 	require.NoError(t, err)
-	assert.Equal(t, urls, userURLs)
+	assert.Equal(t, "http://some1.ru", full)
+
+	// Все попытки генерации дают занятые id
+	store.idGen = &stubIDGenerator{ids: []string{"AAAAAAAA", "BBBBBBBB"}}
+	_, err = store.SaveURL(ctx, ShortenURL{Original: "http://some3.ru"}, 1)
+	assert.ErrorIs(t, err, ErrIDGeneration)
+
+	// Последовательные ссылки закончились - ошибка возвращается сразу, без повторных попыток
+	store.idGen = NewSequentialIDGenerator(IDFormat{Length: 4, Alphabet: "AB"}, 16)
+	_, err = store.SaveURL(ctx, ShortenURL{Original: "http://some3.ru"}, 1)
+	assert.ErrorIs(t, err, ErrIDSpaceExhausted)
+	assert.NotErrorIs(t, err, ErrIDGeneration)
 }
 
-func TestProcessSyncFileData(t *testing.T) {
+func TestExpireURLs(t *testing.T) {
+	ctx := context.Background()
+	store, err := NewURLMapStore(MapConfig{})
+	require.NoError(t, err)
+	defer store.Close()
+
+	now := time.Now()
+	expiredID, err := store.SaveURL(ctx, ShortenURL{Original: "http://expired.ru", ExpiresAt: now.Add(-time.Minute)}, 1)
+	require.NoError(t, err)
+	activeID, err := store.SaveURL(ctx, ShortenURL{Original: "http://active.ru", ExpiresAt: now.Add(time.Hour)}, 1)
+	require.NoError(t, err)
+	_, err = store.SaveURL(ctx, ShortenURL{Original: "http://forever.ru"}, 1)
+	require.NoError(t, err)
+
+	// Просроченная ссылка недоступна еще до пометки
+	_, _, err = store.GetURL(ctx, expiredID, "")
+	assert.ErrorIs(t, err, ErrExpired)
+	full, _, err := store.GetURL(ctx, activeID, "")
+	require.NoError(t, err)
+	assert.Equal(t, "http://active.ru", full)
+
+	count, err := store.GetURLsCount(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 3, count)
+
+	assert.Equal(t, 1, store.markExpiredURLs(now))
+	assert.Equal(t, 0, store.markExpiredURLs(now))
+
+	count, err = store.GetURLsCount(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
+
+	// Срок действия второй ссылки истекает позже
+	assert.Equal(t, 1, store.markExpiredURLs(now.Add(2*time.Hour)))
+	_, _, err = store.GetURL(ctx, activeID, "")
+	assert.ErrorIs(t, err, ErrExpired)
+}
+
+func TestGetURLClickLimit(t *testing.T) {
+	ctx := context.Background()
 	tmpFile, err := os.CreateTemp(".", "jsonDB_*.json")
 	require.NoError(t, err)
+	require.NoError(t, tmpFile.Close())
+	defer os.Remove(tmpFile.Name())
+	defer os.Remove(tmpFile.Name() + clicksFileSuffix)
+	defer os.Remove(tmpFile.Name() + walFileSuffix)
+
+	store, err := NewURLMapStore(MapConfig{StorageFile: tmpFile.Name()})
+	require.NoError(t, err)
+
+	id, err := store.SaveURL(ctx, ShortenURL{Original: "http://invite.ru", MaxClicks: 2}, 1)
+	require.NoError(t, err)
+	for range 2 {
+		full, _, getErr := store.GetURL(ctx, id, "")
+		require.NoError(t, getErr)
+		assert.Equal(t, "http://invite.ru", full)
+	}
+	_, _, err = store.GetURL(ctx, id, "")
+	assert.ErrorIs(t, err, ErrClickLimit)
+
+	// Переходы по ссылке без лимита не изменяют ее данные
+	unlimitedID, err := store.SaveURL(ctx, ShortenURL{Original: "http://unlimited.ru"}, 1)
+	require.NoError(t, err)
+	_, _, err = store.GetURL(ctx, unlimitedID, "")
+	require.NoError(t, err)
+	assert.Equal(t, 0, storedURL(store, unlimitedID).Clicks)
+	require.NoError(t, store.Close())
+
+	// Счетчик переходов сохраняется в файле
+	store, err = NewURLMapStore(MapConfig{StorageFile: tmpFile.Name()})
+	require.NoError(t, err)
+	defer store.Close()
+	assert.Equal(t, 2, storedURL(store, id).MaxClicks)
+	assert.Equal(t, 2, storedURL(store, id).Clicks)
+	_, _, err = store.GetURL(ctx, id, "")
+	assert.ErrorIs(t, err, ErrClickLimit)
+}
+
+func TestGetURLPassword(t *testing.T) {
+	ctx := context.Background()
+	store, err := NewURLMapStore(MapConfig{})
+	require.NoError(t, err)
+	defer store.Close()
+
+	passwordHash, err := bcrypt.GenerateFromPassword([]byte("secret"), bcrypt.MinCost)
+	require.NoError(t, err)
+	id, err := store.SaveURL(ctx, ShortenURL{Original: "http://docs.ru", PasswordHash: string(passwordHash), MaxClicks: 1}, 1)
+	require.NoError(t, err)
+
+	_, _, err = store.GetURL(ctx, id, "")
+	assert.ErrorIs(t, err, ErrPasswordRequired)
+	_, _, err = store.GetURL(ctx, id, "wrong")
+	assert.ErrorIs(t, err, ErrWrongPassword)
+
+	// Попытки без верного пароля не учитываются как переходы
+	full, _, err := store.GetURL(ctx, id, "secret")
+	require.NoError(t, err)
+	assert.Equal(t, "http://docs.ru", full)
+	_, _, err = store.GetURL(ctx, id, "secret")
+	assert.ErrorIs(t, err, ErrClickLimit)
+
+	// Пароль проверяется без блокировки, но лимит переходов не превышается при одновременных переходах
+	id, err = store.SaveURL(ctx, ShortenURL{Original: "http://docs.ru/private", PasswordHash: string(passwordHash), MaxClicks: 1}, 1)
+	require.NoError(t, err)
+	var succeeded atomic.Int32
+	var wg sync.WaitGroup
+	for range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, _, getErr := store.GetURL(ctx, id, "secret"); getErr == nil {
+				succeeded.Add(1)
+			} else {
+				assert.ErrorIs(t, getErr, ErrClickLimit)
+			}
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, int32(1), succeeded.Load())
+	assert.Equal(t, 1, storedURL(store, id).Clicks)
+}
+
+func TestSaveClicks(t *testing.T) {
+	tmpFile, err := os.CreateTemp(".", "jsonDB_*.json")
+	require.NoError(t, err)
+	require.NoError(t, tmpFile.Close())
+	defer os.Remove(tmpFile.Name())
+	defer os.Remove(tmpFile.Name() + clicksFileSuffix)
+	defer os.Remove(tmpFile.Name() + walFileSuffix)
+
+	store, err := NewURLMapStore(MapConfig{StorageFile: tmpFile.Name()})
+	require.NoError(t, err)
+
+	clickTime := time.Now().UTC().Truncate(time.Second)
+	events := []ClickEvent{
+		{ShortURL: "short1", Time: clickTime, Referrer: "http://ref.ru", UserAgent: "agent", IP: "127.0.0.1"},
+		{ShortURL: "short1", Time: clickTime.Add(time.Second)},
+		{ShortURL: "short2", Time: clickTime},
+	}
+	for _, event := range events {
+		require.NoError(t, store.SaveClick(event))
+	}
+	// При закрытии хранилища оставшиеся в очереди события сохраняются
+	require.NoError(t, store.Close())
+	assert.Equal(t, events[:2], store.clicks["short1"])
+	assert.Equal(t, events[2:], store.clicks["short2"])
+
+	// События переходов загружаются из файла
+	store, err = NewURLMapStore(MapConfig{StorageFile: tmpFile.Name()})
+	require.NoError(t, err)
+	defer store.Close()
+	assert.Equal(t, events[:2], store.clicks["short1"])
+	assert.Equal(t, events[2:], store.clicks["short2"])
+}
 
-	store := &URLMapStore{
-		store: map[string]URLMapData{
-			"short1": {OriginalURL: "http://example1.com", UserID: 1, IsDeleted: false},
-			"short2": {OriginalURL: "http://example2.com", UserID: 2, IsDeleted: false},
+func TestGetURLStatsMap(t *testing.T) {
+	ctx := context.Background()
+	store, err := NewURLMapStore(MapConfig{})
+	require.NoError(t, err)
+	defer store.Close()
+
+	id, err := store.SaveURL(ctx, ShortenURL{Original: "http://stats.ru"}, 1)
+	require.NoError(t, err)
+	day := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	require.NoError(t, store.saveClicks(ctx, []ClickEvent{
+		{ShortURL: id, Time: day, Referrer: "http://ref.ru", UserAgent: "agent", IP: "1.1.1.1"},
+		{ShortURL: id, Time: day.Add(time.Hour), Referrer: "http://ref.ru", IP: "1.1.1.1"},
+		{ShortURL: id, Time: day.Add(24 * time.Hour), UserAgent: "agent", IP: "2.2.2.2"},
+		{ShortURL: "other", Time: day, IP: "3.3.3.3"},
+	}))
+
+	stats, err := store.GetURLStats(ctx, 1, id)
+	require.NoError(t, err)
+	assert.Equal(t, &URLStats{
+		TotalClicks:    3,
+		UniqueVisitors: 2,
+		ClicksPerDay:   []DayClicks{{Day: "2024-05-01", Clicks: 2}, {Day: "2024-05-02", Clicks: 1}},
+		TopReferrers:   []TopValue{{Value: "http://ref.ru", Count: 2}},
+		TopUserAgents:  []TopValue{{Value: "agent", Count: 2}},
+	}, stats)
+
+	// Статистика чужих и несуществующих ссылок недоступна
+	_, err = store.GetURLStats(ctx, 2, id)
+	assert.ErrorIs(t, err, ErrNoData)
+	_, err = store.GetURLStats(ctx, 1, "unknown")
+	assert.ErrorIs(t, err, ErrNoData)
+}
+
+func TestUpdateURL(t *testing.T) {
+	ctx := context.Background()
+	tmpFile, err := os.CreateTemp(".", "jsonDB_*.json")
+	require.NoError(t, err)
+	require.NoError(t, tmpFile.Close())
+	defer os.Remove(tmpFile.Name())
+	defer os.Remove(tmpFile.Name() + clicksFileSuffix)
+	defer os.Remove(tmpFile.Name() + walFileSuffix)
+
+	store, err := NewURLMapStore(MapConfig{StorageFile: tmpFile.Name()})
+	require.NoError(t, err)
+
+	id, err := store.SaveURL(ctx, ShortenURL{Original: "http://old.ru"}, 1)
+	require.NoError(t, err)
+
+	_, err = store.UpdateURL(ctx, 2, id, URLUpdate{Original: "http://new.ru"})
+	assert.ErrorIs(t, err, ErrNoData)
+	_, err = store.UpdateURL(ctx, 1, "unknown", URLUpdate{Original: "http://new.ru"})
+	assert.ErrorIs(t, err, ErrNoData)
+	updated, err := store.UpdateURL(ctx, 1, id, URLUpdate{Original: "http://New.ru", Canonical: "http://new.ru/"})
+	require.NoError(t, err)
+	assert.Equal(t, &ShortenURL{Original: "http://New.ru", Shorten: id}, updated)
+	full, _, err := store.GetURL(ctx, id, "")
+	require.NoError(t, err)
+	assert.Equal(t, "http://New.ru", full)
+
+	// Полная ссылка и теги изменяются вместе, при конфликте не изменяется ничего
+	otherID, err := store.SaveURL(ctx, ShortenURL{Original: "http://other.ru"}, 1)
+	require.NoError(t, err)
+	_, err = store.UpdateURL(ctx, 1, otherID, URLUpdate{Original: "http://new.ru/", Tags: []string{"news"}, UpdateTags: true})
+	assert.ErrorIs(t, err, ErrConflict)
+	assert.Empty(t, storedURL(store, otherID).Tags)
+	updated, err = store.UpdateURL(ctx, 1, otherID, URLUpdate{Original: "http://other.ru/page", Tags: []string{"news"}, UpdateTags: true})
+	require.NoError(t, err)
+	assert.Equal(t, &ShortenURL{Original: "http://other.ru/page", Shorten: otherID, Tags: []string{"news"}}, updated)
+	require.NoError(t, store.Close())
+
+	// Измененная ссылка и ее каноническая форма сохраняются в файле
+	store, err = NewURLMapStore(MapConfig{StorageFile: tmpFile.Name()})
+	require.NoError(t, err)
+	defer store.Close()
+	assert.Equal(t, "http://New.ru", storedURL(store, id).OriginalURL)
+	assert.Equal(t, "http://new.ru/", storedURL(store, id).CanonicalURL)
+	assert.Equal(t, 1, storedURL(store, id).UserID)
+	existingID, err := store.SaveURL(ctx, ShortenURL{Original: "http://new.ru/"}, 2)
+	assert.ErrorIs(t, err, ErrConflict)
+	assert.Equal(t, id, existingID)
+}
+
+func TestURLTags(t *testing.T) {
+	ctx := context.Background()
+	tmpFile, err := os.CreateTemp(".", "jsonDB_*.json")
+	require.NoError(t, err)
+	require.NoError(t, tmpFile.Close())
+	defer os.Remove(tmpFile.Name())
+	defer os.Remove(tmpFile.Name() + clicksFileSuffix)
+	defer os.Remove(tmpFile.Name() + walFileSuffix)
+
+	store, err := NewURLMapStore(MapConfig{StorageFile: tmpFile.Name()})
+	require.NoError(t, err)
+
+	id1, err := store.SaveURL(ctx, ShortenURL{Original: "http://one.ru", Tags: []string{"news", "sale"}}, 1)
+	require.NoError(t, err)
+	id2, err := store.SaveURL(ctx, ShortenURL{Original: "http://two.ru", Tags: []string{"news"}}, 1)
+	require.NoError(t, err)
+	_, err = store.SaveURL(ctx, ShortenURL{Original: "http://three.ru"}, 1)
+	require.NoError(t, err)
+
+	urls, err := store.GetUserURLs(ctx, 1, UserURLsFilter{Tags: []string{"news"}})
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []ShortenURL{
+		{Original: "http://one.ru", Shorten: id1, Tags: []string{"news", "sale"}},
+		{Original: "http://two.ru", Shorten: id2, Tags: []string{"news"}},
+	}, urls)
+
+	urls, err = store.GetUserURLs(ctx, 1, UserURLsFilter{Tags: []string{"news", "sale"}})
+	require.NoError(t, err)
+	assert.Equal(t, []ShortenURL{{Original: "http://one.ru", Shorten: id1, Tags: []string{"news", "sale"}}}, urls)
+
+	_, err = store.UpdateURL(ctx, 2, id2, URLUpdate{Tags: []string{"sale"}, UpdateTags: true})
+	assert.ErrorIs(t, err, ErrNoData)
+	// При изменении только тегов возвращается сохраненная полная ссылка
+	updated, err := store.UpdateURL(ctx, 1, id2, URLUpdate{Tags: []string{"sale"}, UpdateTags: true})
+	require.NoError(t, err)
+	assert.Equal(t, &ShortenURL{Original: "http://two.ru", Shorten: id2, Tags: []string{"sale"}}, updated)
+	urls, err = store.GetUserURLs(ctx, 1, UserURLsFilter{Tags: []string{"news"}})
+	require.NoError(t, err)
+	assert.Equal(t, []ShortenURL{{Original: "http://one.ru", Shorten: id1, Tags: []string{"news", "sale"}}}, urls)
+	require.NoError(t, store.Close())
+
+	// Теги сохраняются в файле
+	store, err = NewURLMapStore(MapConfig{StorageFile: tmpFile.Name()})
+	require.NoError(t, err)
+	defer store.Close()
+	urls, err = store.GetUserURLs(ctx, 1, UserURLsFilter{Tags: []string{"sale"}})
+	require.NoError(t, err)
+	assert.Len(t, urls, 2)
+}
+
+func TestExportUserURLs(t *testing.T) {
+	ctx := context.Background()
+	store, err := NewURLMapStore(MapConfig{})
+	require.NoError(t, err)
+	defer store.Close()
+
+	for _, alias := range []string{"link-b", "link-a", "link-c"} {
+		_, err = store.SaveURL(ctx, ShortenURL{Original: "http://" + alias + ".ru", Shorten: alias}, 1)
+		require.NoError(t, err)
+	}
+	_, err = store.SaveURL(ctx, ShortenURL{Original: "http://other.ru", Shorten: "other"}, 2)
+	require.NoError(t, err)
+	require.NoError(t, store.DeleteUserURLs(1, []string{"link-c"}))
+
+	var ids []string
+	err = store.ExportUserURLs(ctx, 1, func(url ShortenURL) error {
+		ids = append(ids, url.Shorten)
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"link-a", "link-b"}, ids)
+
+	// Ошибка обработки ссылки прерывает выгрузку
+	fnErr := errors.New("write error")
+	calls := 0
+	err = store.ExportUserURLs(ctx, 1, func(_ ShortenURL) error {
+		calls++
+		return fnErr
+	})
+	assert.ErrorIs(t, err, fnErr)
+	assert.Equal(t, 1, calls)
+}
+
+func TestLoadURLs(t *testing.T) {
+	ctx := context.Background()
+	tmpFile, err := os.CreateTemp(".", "jsonDB_*.json")
+	require.NoError(t, err)
+	require.NoError(t, tmpFile.Close())
+	defer os.Remove(tmpFile.Name())
+	defer os.Remove(tmpFile.Name() + clicksFileSuffix)
+	defer os.Remove(tmpFile.Name() + walFileSuffix)
+
+	deletedAt := time.Now().Add(-time.Hour).Round(time.Second)
+	records := []URLRecord{
+		{
+			ShortenURL: ShortenURL{Original: "http://one.ru", Shorten: "id-one", MaxClicks: 5, Tags: []string{"news"}},
+			UserID:     3,
+			Clicks:     2,
+		},
+		{
+			ShortenURL: ShortenURL{Original: "http://two.ru", Shorten: "id-two"},
+			UserID:     3,
+			IsDeleted:  true,
+			DeletedAt:  deletedAt,
 		},
-		jsonDB: jsonDB{
-			file:    tmpFile,
-			encoder: json.NewEncoder(tmpFile),
-			decoder: json.NewDecoder(tmpFile),
+		{
+			ShortenURL: ShortenURL{Original: "http://anon.ru", Shorten: "id-anon"},
 		},
-		mutex: sync.RWMutex{},
 	}
 
-	err = store.processSyncFileData()
+	store, err := NewURLMapStore(MapConfig{StorageFile: tmpFile.Name()})
 	require.NoError(t, err)
+	require.NoError(t, store.LoadUsers(ctx, []User{{ID: 3}, {ID: 7}}))
+	loaded, err := store.LoadURLs(ctx, records)
+	require.NoError(t, err)
+	assert.Equal(t, 3, loaded)
 
-	fileData, err := os.ReadFile(tmpFile.Name())
+	// Повторная загрузка пропускает уже сохраненные ссылки
+	loaded, err = store.LoadURLs(ctx, records)
+	require.NoError(t, err)
+	assert.Equal(t, 0, loaded)
+
+	// Сокращенная ссылка, занятая другой полной ссылкой или другим пользователем, - конфликт
+	for _, taken := range []URLRecord{
+		{ShortenURL: ShortenURL{Original: "http://other.ru", Shorten: "id-one"}, UserID: 3},
+		{ShortenURL: ShortenURL{Original: "http://one.ru", Shorten: "id-one"}, UserID: 7},
+	} {
+		_, err = store.LoadURLs(ctx, []URLRecord{taken})
+		assert.ErrorIs(t, err, ErrConflict)
+	}
+	_, err = store.LoadURLs(ctx, []URLRecord{
+		{ShortenURL: ShortenURL{Original: "http://new.ru", Shorten: "id-new"}},
+		{ShortenURL: ShortenURL{Original: "http://other-new.ru", Shorten: "id-new"}},
+	})
+	assert.ErrorIs(t, err, ErrConflict)
+	assert.False(t, store.hasURL("id-new"))
+
+	// Новые пользователи получают ID после перенесенных
+	user, err := store.CreateUser(ctx)
 	require.NoError(t, err)
-	require.Contains(t, string(fileData), `"original_url":"http://example1.com","short_url":"short1"`)
-	require.Contains(t, string(fileData), `"original_url":"http://example2.com","short_url":"short2"`)
+	assert.Equal(t, 8, user.ID)
+	require.NoError(t, store.Close())
 
-	store.jsonDB.file.Close()
-	require.NoError(t, os.Remove(tmpFile.Name()))
-	err = store.processSyncFileData()
-	require.Error(t, err)
+	// Ссылки сохраняются в файл со всеми данными
+	store, err = NewURLMapStore(MapConfig{StorageFile: tmpFile.Name()})
+	require.NoError(t, err)
+	defer store.Close()
+	var dumped []URLRecord
+	err = store.DumpURLs(ctx, func(url URLRecord) error {
+		dumped = append(dumped, url)
+		return nil
+	})
+	require.NoError(t, err)
+	require.Len(t, dumped, 3)
+	assert.Equal(t, "id-anon", dumped[0].Shorten)
+	assert.Equal(t, records[0], dumped[1])
+	assert.Equal(t, "id-two", dumped[2].Shorten)
+	assert.True(t, dumped[2].IsDeleted)
+	assert.True(t, deletedAt.Equal(dumped[2].DeletedAt))
+
+	var users []User
+	err = store.DumpUsers(ctx, func(user User) error {
+		users = append(users, user)
+		return nil
+	})
+	require.NoError(t, err)
+	// Пользователи без ссылок также сохраняются
+	assert.Equal(t, []User{{ID: 3}, {ID: 7}, {ID: 8}}, users)
+}
+
+func TestRestoreUserURLs(t *testing.T) {
+	ctx := context.Background()
+	tmpFile, err := os.CreateTemp(".", "jsonDB_*.json")
+	require.NoError(t, err)
+	require.NoError(t, tmpFile.Close())
+	defer os.Remove(tmpFile.Name())
+	defer os.Remove(tmpFile.Name() + clicksFileSuffix)
+	defer os.Remove(tmpFile.Name() + walFileSuffix)
+
+	store, err := NewURLMapStore(MapConfig{StorageFile: tmpFile.Name()})
+	require.NoError(t, err)
+
+	id1, err := store.SaveURL(ctx, ShortenURL{Original: "http://one.ru"}, 1)
+	require.NoError(t, err)
+	id2, err := store.SaveURL(ctx, ShortenURL{Original: "http://two.ru"}, 1)
+	require.NoError(t, err)
+	require.NoError(t, store.DeleteUserURLs(1, []string{id1, id2}))
+	require.NoError(t, store.Close())
+
+	// Удаленные ссылки сохраняют владельца после перезапуска
+	store, err = NewURLMapStore(MapConfig{StorageFile: tmpFile.Name()})
+	require.NoError(t, err)
+	defer store.Close()
+	deleted, err := store.GetDeletedUserURLs(ctx, 1)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []ShortenURL{
+		{Original: "http://one.ru", Shorten: id1},
+		{Original: "http://two.ru", Shorten: id2},
+	}, deleted)
+
+	// Чужие ссылки не восстанавливаются
+	require.NoError(t, store.RestoreUserURLs(ctx, 2, []string{id1}))
+	_, _, err = store.GetURL(ctx, id1, "")
+	assert.ErrorIs(t, err, ErrIsDeleted)
+
+	require.NoError(t, store.RestoreUserURLs(ctx, 1, []string{id1, "unknown"}))
+	full, _, err := store.GetURL(ctx, id1, "")
+	require.NoError(t, err)
+	assert.Equal(t, "http://one.ru", full)
+	deleted, err = store.GetDeletedUserURLs(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, []ShortenURL{{Original: "http://two.ru", Shorten: id2}}, deleted)
+}
+
+func TestPurgeDeletedURLs(t *testing.T) {
+	ctx := context.Background()
+	tmpFile, err := os.CreateTemp(".", "jsonDB_*.json")
+	require.NoError(t, err)
+	require.NoError(t, tmpFile.Close())
+	defer os.Remove(tmpFile.Name())
+	defer os.Remove(tmpFile.Name() + clicksFileSuffix)
+	defer os.Remove(tmpFile.Name() + walFileSuffix)
+	defer os.Remove(tmpFile.Name() + metaFileSuffix)
+
+	store, err := NewURLMapStore(MapConfig{StorageFile: tmpFile.Name(), DeletedRetention: time.Hour})
+	require.NoError(t, err)
+
+	oldID, err := store.SaveURL(ctx, ShortenURL{Original: "http://old.ru"}, 1)
+	require.NoError(t, err)
+	newID, err := store.SaveURL(ctx, ShortenURL{Original: "http://new.ru"}, 1)
+	require.NoError(t, err)
+	keptID, err := store.SaveURL(ctx, ShortenURL{Original: "http://kept.ru"}, 1)
+	require.NoError(t, err)
+	require.NoError(t, store.saveClicks(ctx, []ClickEvent{{ShortURL: oldID}, {ShortURL: keptID}}))
+	require.NoError(t, store.DeleteUserURLs(1, []string{oldID, newID}))
+	shard := store.shard(oldID)
+	oldData := shard.urls[oldID]
+	oldData.DeletedAt = time.Now().Add(-2 * time.Hour)
+	shard.urls[oldID] = oldData
+
+	count, err := store.purgeDeletedURLs(time.Now().Add(-time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+	assert.False(t, store.hasURL(oldID))
+	assert.NotContains(t, store.clicks, oldID)
+	assert.ElementsMatch(t, []string{newID, keptID}, store.userStore[1])
+	// Файл событий переходов заменен, новые события дописываются в новый файл
+	require.NoError(t, store.saveClicks(ctx, []ClickEvent{{ShortURL: keptID}}))
+	require.NoError(t, store.Close())
+
+	// Удаление сохраняется в файлах хранилища
+	store, err = NewURLMapStore(MapConfig{StorageFile: tmpFile.Name()})
+	require.NoError(t, err)
+	defer store.Close()
+	assert.False(t, store.hasURL(oldID))
+	assert.True(t, storedURL(store, newID).IsDeleted)
+	assert.NotContains(t, store.clicks, oldID)
+	assert.Len(t, store.clicks[keptID], 2)
+	purged, err := store.GetPurgedURLsCount(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 1, purged)
 }
 
 func TestNewURLMapStore(t *testing.T) {
@@ -194,12 +807,13 @@ func TestNewURLMapStore(t *testing.T) {
 
 	require.NoError(t, tmpFile.Close())
 
-	store, err := NewURLMapStore(tmpFile.Name())
+	idGen := NewSequentialIDGenerator(IDFormat{}, 0)
+	store, err := NewURLMapStore(MapConfig{StorageFile: tmpFile.Name(), IDGenerator: idGen})
 	require.NoError(t, err)
 
-	require.Len(t, store.store, 2)
-	require.Equal(t, "http://example1.com", store.store["short1"].OriginalURL)
-	require.Equal(t, "http://example2.com", store.store["short2"].OriginalURL)
+	require.Equal(t, 2, store.urlsCount())
+	require.Equal(t, "http://example1.com", storedURL(store, "short1").OriginalURL)
+	require.Equal(t, "http://example2.com", storedURL(store, "short2").OriginalURL)
 
 	require.Len(t, store.userStore[1], 1)
 	require.Len(t, store.userStore[2], 1)
@@ -207,8 +821,16 @@ func TestNewURLMapStore(t *testing.T) {
 	require.Contains(t, store.userStore[2], "short2")
 
 	require.Equal(t, 2, store.userMaxID)
+
+	// Последовательный генератор продолжает счет после загруженных ссылок
+	nextID, err := idGen.Generate("", 0)
+	require.NoError(t, err)
+	require.Equal(t, "00000002", nextID)
+
 	require.NoError(t, store.Close())
 	require.NoError(t, os.Remove(tmpFile.Name()))
+	os.Remove(tmpFile.Name() + clicksFileSuffix)
+	os.Remove(tmpFile.Name() + walFileSuffix)
 }
 
 func BenchmarkSaveURL(b *testing.B) {
@@ -219,8 +841,10 @@ func BenchmarkSaveURL(b *testing.B) {
 		b.Fatalf("failed to create temporary file: %v", err)
 	}
 	defer os.Remove(tmpFile.Name())
+	defer os.Remove(tmpFile.Name() + clicksFileSuffix)
+	defer os.Remove(tmpFile.Name() + walFileSuffix)
 
-	store, err := NewURLMapStore(tmpFile.Name())
+	store, err := NewURLMapStore(MapConfig{StorageFile: tmpFile.Name()})
 	if err != nil {
 		b.Fatalf("failed to create store: %v", err)
 	}
@@ -230,7 +854,7 @@ func BenchmarkSaveURL(b *testing.B) {
 
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
-		_, err = store.SaveURL(ctx, url, userID)
+		_, err = store.SaveURL(ctx, ShortenURL{Original: url}, userID)
 		if err != nil {
 			b.Fatalf("failed to save URL: %v", err)
 		}
@@ -245,21 +869,23 @@ func BenchmarkSaveBatchURL(b *testing.B) {
 		b.Fatalf("failed to create temporary file: %v", err)
 	}
 	defer os.Remove(tmpFile.Name())
+	defer os.Remove(tmpFile.Name() + clicksFileSuffix)
+	defer os.Remove(tmpFile.Name() + walFileSuffix)
 
-	store, err := NewURLMapStore(tmpFile.Name())
+	store, err := NewURLMapStore(MapConfig{StorageFile: tmpFile.Name()})
 	if err != nil {
 		b.Fatalf("failed to create store: %v", err)
 	}
 
 	userID := 1
-	urls := []ShortenURL{
-		{Original: "https://example1.com"},
-		{Original: "https://example2.com"},
-		{Original: "https://example3.com"},
-	}
 
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
+		urls := []ShortenURL{
+			{Original: "https://example1.com"},
+			{Original: "https://example2.com"},
+			{Original: "https://example3.com"},
+		}
 		err = store.SaveBatchURL(ctx, urls, userID)
 		if err != nil {
 			b.Fatalf("failed to save batch URLs: %v", err)
@@ -275,22 +901,24 @@ func BenchmarkGetURL(b *testing.B) {
 		b.Fatalf("failed to create temporary file: %v", err)
 	}
 	defer os.Remove(tmpFile.Name())
+	defer os.Remove(tmpFile.Name() + clicksFileSuffix)
+	defer os.Remove(tmpFile.Name() + walFileSuffix)
 
-	store, err := NewURLMapStore(tmpFile.Name())
+	store, err := NewURLMapStore(MapConfig{StorageFile: tmpFile.Name()})
 	if err != nil {
 		b.Fatalf("failed to create store: %v", err)
 	}
 
 	url := "https://example.com"
 	userID := 1
-	shortURL, err := store.SaveURL(ctx, url, userID)
+	shortURL, err := store.SaveURL(ctx, ShortenURL{Original: url}, userID)
 	if err != nil {
 		b.Fatalf("failed to save URL: %v", err)
 	}
 
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
-		_, err = store.GetURL(ctx, shortURL)
+		_, _, err = store.GetURL(ctx, shortURL, "")
 		if err != nil || url == "" {
 			b.Fatalf("failed to get URL: %v", err)
 		}
@@ -307,8 +935,10 @@ func BenchmarkDeleteUserURLs(b *testing.B) {
 		b.Fatalf("failed to create temporary file: %v", err)
 	}
 	defer os.Remove(tmpFile.Name())
+	defer os.Remove(tmpFile.Name() + clicksFileSuffix)
+	defer os.Remove(tmpFile.Name() + walFileSuffix)
 
-	store, err := NewURLMapStore(tmpFile.Name())
+	store, err := NewURLMapStore(MapConfig{StorageFile: tmpFile.Name()})
 	if err != nil {
 		b.Fatalf("failed to create store: %v", err)
 	}
@@ -320,7 +950,7 @@ func BenchmarkDeleteUserURLs(b *testing.B) {
 	for i := 0; i < b.N; i++ {
 		b.StopTimer()
 		for j := 0; j < urlsCount; j++ {
-			shortURL, saveErr := store.SaveURL(ctx, "https://example.com", userID)
+			shortURL, saveErr := store.SaveURL(ctx, ShortenURL{Original: "https://example.com"}, userID)
 			if saveErr != nil {
 				b.Fatalf("failed to save URL: %v", err)
 			}
@@ -337,7 +967,7 @@ func BenchmarkDeleteUserURLs(b *testing.B) {
 
 func TestGetStats(t *testing.T) {
 	ctx := context.Background()
-	store, err := NewURLMapStore("")
+	store, err := NewURLMapStore(MapConfig{})
 	require.NoError(t, err)
 	defer store.Close()
 
@@ -349,7 +979,7 @@ func TestGetStats(t *testing.T) {
 	urlsCount, err := store.GetURLsCount(ctx)
 	require.NoError(t, err)
 	assert.Equal(t, 0, urlsCount)
-	_, err = store.SaveURL(ctx, "some", user.ID)
+	_, err = store.SaveURL(ctx, ShortenURL{Original: "some"}, user.ID)
 	require.NoError(t, err)
 	urlsCount, err = store.GetURLsCount(ctx)
 	require.NoError(t, err)
@@ -0,0 +1,257 @@
+package storage
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/pinbrain/urlshortener/internal/logger"
+)
+
+// walFileSuffix - суффикс имени файла журнала операций (файл хранится рядом с основным файлом хранилища).
+const walFileSuffix = ".wal"
+
+// walOldFileSuffix - суффикс имени файла журнала, который сжимается в снимок (добавляется к имени журнала).
+const walOldFileSuffix = ".old"
+
+// walSyncInterval - интервал сброса журнала на диск при политике FileSyncInterval.
+const walSyncInterval = time.Second
+
+// walCompactMinRecords - минимальное количество записей журнала, при котором он сжимается в снимок
+// (журнал также не сжимается, пока в нем меньше записей, чем ссылок в хранилище).
+const walCompactMinRecords = 1000
+
+// FileSyncPolicy определяет, когда записи журнала операций сбрасываются на диск (fsync).
+type FileSyncPolicy string
+
+// Политики сброса журнала операций на диск.
+const (
+	FileSyncAlways   FileSyncPolicy = "always"   // После каждой операции
+	FileSyncInterval FileSyncPolicy = "interval" // Раз в walSyncInterval (по умолчанию)
+	FileSyncNever    FileSyncPolicy = "never"    // Сброс на диск остается операционной системе
+)
+
+// ParseFileSyncPolicy разбирает политику сброса журнала на диск (пустая строка - FileSyncInterval).
+func ParseFileSyncPolicy(policy string) (FileSyncPolicy, error) {
+	switch FileSyncPolicy(policy) {
+	case "", FileSyncInterval:
+		return FileSyncInterval, nil
+	case FileSyncAlways, FileSyncNever:
+		return FileSyncPolicy(policy), nil
+	}
+	return "", fmt.Errorf("unknown file sync policy %q", policy)
+}
+
+// walOp - тип операции журнала.
+type walOp string
+
+// Операции журнала.
+const (
+	walOpCreate walOp = "create" // Сохранение ссылки (запись содержит все данные ссылки)
+	walOpUpdate walOp = "update" // Изменение ссылки (запись содержит все данные ссылки после изменения)
+	walOpDelete walOp = "delete" // Удаление ссылки пользователем
+	walOpPurge  walOp = "purge"  // Окончательное удаление ссылки
+	walOpUser   walOp = "user"   // Создание пользователя
+)
+
+// walRecord описывает запись журнала операций.
+type walRecord struct {
+	Op        walOp             `json:"op"`
+	URL       *URLMapFileRecord `json:"url,omitempty"`        // Данные ссылки (create, update)
+	ShortURL  string            `json:"short_url,omitempty"`  // Сокращенная ссылка (delete, purge)
+	DeletedAt *time.Time        `json:"deleted_at,omitempty"` // Время удаления ссылки (delete)
+	UserID    int               `json:"user_id,omitempty"`    // Пользователь (user)
+}
+
+// walLog описывает журнал операций хранилища в файле. Записи только добавляются в конец файла.
+type walLog struct {
+	mutex   sync.Mutex
+	file    *os.File
+	encoder *json.Encoder
+	policy  FileSyncPolicy
+	dirty   bool // В журнале есть записи, еще не сброшенные на диск
+	records int  // Количество записей в журнале (включая сжимаемый журнал)
+}
+
+// openWAL открывает файл журнала для добавления записей. records - количество уже сохраненных записей.
+func openWAL(fileName string, policy FileSyncPolicy, records int) (*walLog, error) {
+	file, err := os.OpenFile(fileName, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0666)
+	if err != nil {
+		return nil, err
+	}
+	return &walLog{
+		file:    file,
+		encoder: json.NewEncoder(file),
+		policy:  policy,
+		records: records,
+	}, nil
+}
+
+// append добавляет запись в журнал и при политике FileSyncAlways сразу сбрасывает ее на диск.
+func (w *walLog) append(record walRecord) error {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	if err := w.encoder.Encode(record); err != nil {
+		return fmt.Errorf("failed to write wal record: %w", err)
+	}
+	w.records++
+	if w.policy == FileSyncAlways {
+		if err := w.file.Sync(); err != nil {
+			return fmt.Errorf("failed to sync wal: %w", err)
+		}
+		return nil
+	}
+	w.dirty = true
+	return nil
+}
+
+// sync сбрасывает на диск записи журнала, добавленные после предыдущего сброса.
+func (w *walLog) sync() error {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	if !w.dirty {
+		return nil
+	}
+	if err := w.file.Sync(); err != nil {
+		return fmt.Errorf("failed to sync wal: %w", err)
+	}
+	w.dirty = false
+	return nil
+}
+
+// size возвращает количество записей в журнале.
+func (w *walLog) size() int {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	return w.records
+}
+
+// rotate переносит записи журнала в сжимаемый журнал и начинает новый пустой журнал.
+// Если предыдущее сжатие не завершилось, записи дописываются к оставшемуся сжимаемому журналу.
+func (w *walLog) rotate() error {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	fileName := w.file.Name()
+	if err := w.file.Sync(); err != nil {
+		return fmt.Errorf("failed to sync wal: %w", err)
+	}
+	if err := w.file.Close(); err != nil {
+		return fmt.Errorf("failed to close wal: %w", err)
+	}
+	if err := moveWAL(fileName, fileName+walOldFileSuffix); err != nil {
+		return err
+	}
+	file, err := os.OpenFile(fileName, os.O_WRONLY|os.O_CREATE|os.O_TRUNC|os.O_APPEND, 0666)
+	if err != nil {
+		return fmt.Errorf("failed to reopen wal: %w", err)
+	}
+	w.file = file
+	w.encoder = json.NewEncoder(file)
+	w.dirty = false
+	w.records = 0
+	return nil
+}
+
+// moveWAL переносит журнал fileName в сжимаемый журнал oldName.
+func moveWAL(fileName, oldName string) error {
+	if _, err := os.Stat(oldName); errors.Is(err, os.ErrNotExist) {
+		if err = os.Rename(fileName, oldName); err != nil {
+			return fmt.Errorf("failed to rename wal: %w", err)
+		}
+		return nil
+	}
+	oldFile, err := os.OpenFile(oldName, os.O_WRONLY|os.O_APPEND, 0666)
+	if err != nil {
+		return fmt.Errorf("failed to open compacting wal: %w", err)
+	}
+	defer oldFile.Close()
+	file, err := os.Open(fileName)
+	if err != nil {
+		return fmt.Errorf("failed to open wal: %w", err)
+	}
+	defer file.Close()
+	if _, err = io.Copy(oldFile, file); err != nil {
+		return fmt.Errorf("failed to copy wal to compacting wal: %w", err)
+	}
+	if err = oldFile.Sync(); err != nil {
+		return fmt.Errorf("failed to sync compacting wal: %w", err)
+	}
+	return nil
+}
+
+// removeOld удаляет сжимаемый журнал после того, как его записи попали в снимок.
+func (w *walLog) removeOld() error {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	if err := os.Remove(w.file.Name() + walOldFileSuffix); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to remove compacted wal: %w", err)
+	}
+	return nil
+}
+
+// close сбрасывает журнал на диск и закрывает его файл.
+func (w *walLog) close() error {
+	if err := w.sync(); err != nil {
+		return err
+	}
+	return w.file.Close()
+}
+
+// readJSONLines читает из файла fileName значения json, записанные по одному в строке, и передает их функции fn.
+// Отсутствующий файл считается пустым. Последняя строка, которую не удалось разобрать
+// (запись, прерванная сбоем), отбрасывается и обрезается в файле. Если readOnly, файл открывается только
+// для чтения и не обрезается. Возвращает количество прочитанных значений.
+func readJSONLines[T any](fileName string, readOnly bool, fn func(value T) error) (int, error) {
+	flag := os.O_RDWR
+	if readOnly {
+		flag = os.O_RDONLY
+	}
+	file, err := os.OpenFile(fileName, flag, 0666)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return 0, nil
+		}
+		return 0, err
+	}
+	defer file.Close()
+
+	reader := bufio.NewReader(file)
+	var count int
+	var offset int64
+	for {
+		line, readErr := reader.ReadBytes('\n')
+		if readErr != nil && !errors.Is(readErr, io.EOF) {
+			return count, readErr
+		}
+		if len(bytes.TrimSpace(line)) > 0 {
+			var value T
+			if err = json.Unmarshal(line, &value); err != nil {
+				if _, peekErr := reader.Peek(1); !errors.Is(peekErr, io.EOF) {
+					return count, fmt.Errorf("failed to decode line at offset %d: %w", offset, err)
+				}
+				if readOnly {
+					logger.Log.Warnw("Skipping incomplete last line", "file", fileName, "offset", offset, "err", err)
+					return count, nil
+				}
+				if err = file.Truncate(offset); err != nil {
+					return count, fmt.Errorf("failed to truncate incomplete line: %w", err)
+				}
+				return count, nil
+			}
+			if err = fn(value); err != nil {
+				return count, err
+			}
+			count++
+		}
+		offset += int64(len(line))
+		if readErr != nil {
+			return count, nil
+		}
+	}
+}
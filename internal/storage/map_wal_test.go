@@ -0,0 +1,312 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseFileSyncPolicy(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  string
+		want    FileSyncPolicy
+		wantErr bool
+	}{
+		{name: "По умолчанию", policy: "", want: FileSyncInterval},
+		{name: "После каждой операции", policy: "always", want: FileSyncAlways},
+		{name: "По интервалу", policy: "interval", want: FileSyncInterval},
+		{name: "Без сброса", policy: "never", want: FileSyncNever},
+		{name: "Неизвестная политика", policy: "sometimes", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy, err := ParseFileSyncPolicy(tt.policy)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, policy)
+		})
+	}
+}
+
+func TestReadJSONLines(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     string
+		readOnly bool
+		want     []int
+		wantData string
+		wantErr  bool
+	}{
+		{
+			name:     "Все строки целые",
+			data:     "1\n2\n3\n",
+			want:     []int{1, 2, 3},
+			wantData: "1\n2\n3\n",
+		},
+		{
+			name:     "Последняя строка без перевода строки",
+			data:     "1\n2",
+			want:     []int{1, 2},
+			wantData: "1\n2",
+		},
+		{
+			name:     "Прерванная последняя строка обрезается",
+			data:     "1\n2\n\"tru",
+			want:     []int{1, 2},
+			wantData: "1\n2\n",
+		},
+		{
+			name:     "Прерванная последняя строка только для чтения не обрезается",
+			data:     "1\n2\n\"tru",
+			readOnly: true,
+			want:     []int{1, 2},
+			wantData: "1\n2\n\"tru",
+		},
+		{
+			name:    "Поврежденная строка в середине",
+			data:    "1\n\"tru\n3\n",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpFile, err := os.CreateTemp(".", "lines_*.json")
+			require.NoError(t, err)
+			defer os.Remove(tmpFile.Name())
+			_, err = tmpFile.WriteString(tt.data)
+			require.NoError(t, err)
+			require.NoError(t, tmpFile.Close())
+
+			var values []int
+			count, err := readJSONLines(tmpFile.Name(), tt.readOnly, func(value int) error {
+				values = append(values, value)
+				return nil
+			})
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, len(tt.want), count)
+			assert.Equal(t, tt.want, values)
+			data, err := os.ReadFile(tmpFile.Name())
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantData, string(data))
+		})
+	}
+
+	// Отсутствующий файл считается пустым
+	count, err := readJSONLines("not_exists.json", false, func(_ int) error { return nil })
+	require.NoError(t, err)
+	assert.Equal(t, 0, count)
+}
+
+// writeWALRecords записывает в файл журнала fileName записи records.
+func writeWALRecords(t *testing.T, fileName string, records ...walRecord) {
+	file, err := os.Create(fileName)
+	require.NoError(t, err)
+	defer file.Close()
+	encoder := json.NewEncoder(file)
+	for _, record := range records {
+		require.NoError(t, encoder.Encode(record))
+	}
+}
+
+func TestReplayWAL(t *testing.T) {
+	ctx := context.Background()
+	tmpFile, err := os.CreateTemp(".", "jsonDB_*.json")
+	require.NoError(t, err)
+	walName := tmpFile.Name() + walFileSuffix
+	defer os.Remove(tmpFile.Name())
+	defer os.Remove(tmpFile.Name() + clicksFileSuffix)
+	defer os.Remove(walName)
+	defer os.Remove(walName + walOldFileSuffix)
+
+	// Снимок, журнал незавершенного сжатия и текущий журнал с прерванной последней записью
+	require.NoError(t, json.NewEncoder(tmpFile).Encode(URLMapFileRecord{OriginalURL: "http://one.ru", ShortURL: "short1", UserID: 1}))
+	require.NoError(t, tmpFile.Close())
+	writeWALRecords(t, walName+walOldFileSuffix,
+		walRecord{Op: walOpCreate, URL: &URLMapFileRecord{OriginalURL: "http://two.ru", ShortURL: "short2", UserID: 1}},
+		walRecord{Op: walOpCreate, URL: &URLMapFileRecord{OriginalURL: "http://three.ru", ShortURL: "short3", UserID: 1}},
+	)
+	deletedAt := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	writeWALRecords(t, walName,
+		walRecord{Op: walOpDelete, ShortURL: "short1", DeletedAt: &deletedAt},
+		walRecord{Op: walOpUpdate, URL: &URLMapFileRecord{OriginalURL: "http://two.ru", ShortURL: "short2", UserID: 1, Tags: []string{"news"}}},
+		walRecord{Op: walOpPurge, ShortURL: "short3"},
+		walRecord{Op: walOpUser, UserID: 5},
+	)
+	walFile, err := os.OpenFile(walName, os.O_WRONLY|os.O_APPEND, 0666)
+	require.NoError(t, err)
+	_, err = walFile.WriteString(`{"op":"create","url":{"origi`)
+	require.NoError(t, err)
+	require.NoError(t, walFile.Close())
+
+	store, err := NewURLMapStore(MapConfig{StorageFile: tmpFile.Name()})
+	require.NoError(t, err)
+	assert.True(t, storedURL(store, "short1").IsDeleted)
+	assert.True(t, deletedAt.Equal(storedURL(store, "short1").DeletedAt))
+	assert.Equal(t, []string{"news"}, storedURL(store, "short2").Tags)
+	assert.Contains(t, store.tagIndex["news"], "short2")
+	assert.False(t, store.hasURL("short3"))
+	assert.Equal(t, []string{"short1", "short2"}, store.userStore[1])
+	_, err = store.GetUser(ctx, 5)
+	require.NoError(t, err)
+	assert.Equal(t, 5, store.userMaxID)
+	assert.Equal(t, 6, store.wal.size())
+
+	// Прерванная запись обрезана, новые операции дописываются после целых записей
+	walData, err := os.ReadFile(walName)
+	require.NoError(t, err)
+	assert.Equal(t, 4, strings.Count(string(walData), "\n"))
+	assert.True(t, strings.HasSuffix(string(walData), "\n"))
+	require.NoError(t, store.Close())
+
+	// При закрытии журналы сжимаются в снимок
+	assert.NoFileExists(t, walName+walOldFileSuffix)
+	store, err = NewURLMapStore(MapConfig{StorageFile: tmpFile.Name()})
+	require.NoError(t, err)
+	defer store.Close()
+	assert.Equal(t, 0, store.wal.size())
+	assert.True(t, storedURL(store, "short1").IsDeleted)
+	assert.Equal(t, []string{"news"}, storedURL(store, "short2").Tags)
+	assert.False(t, store.hasURL("short3"))
+	// Пользователь без ссылок сохранен в снимке
+	_, err = store.GetUser(ctx, 5)
+	require.NoError(t, err)
+	assert.Equal(t, 5, store.userMaxID)
+}
+
+func TestReadOnlyMapStore(t *testing.T) {
+	ctx := context.Background()
+	tmpFile, err := os.CreateTemp(".", "jsonDB_*.json")
+	require.NoError(t, err)
+	require.NoError(t, tmpFile.Close())
+	walName := tmpFile.Name() + walFileSuffix
+	defer os.Remove(tmpFile.Name())
+	defer os.Remove(tmpFile.Name() + clicksFileSuffix)
+	defer os.Remove(walName)
+
+	writeWALRecords(t, walName,
+		walRecord{Op: walOpUser, UserID: 2},
+		walRecord{Op: walOpCreate, URL: &URLMapFileRecord{OriginalURL: "http://one.ru", ShortURL: "short1", UserID: 2}},
+	)
+	// Запись, прерванная сбоем, отбрасывается, но не обрезается в файле
+	walFile, err := os.OpenFile(walName, os.O_WRONLY|os.O_APPEND, 0666)
+	require.NoError(t, err)
+	_, err = walFile.WriteString(`{"op":"create","url":{"orig`)
+	require.NoError(t, err)
+	require.NoError(t, walFile.Close())
+	walData, err := os.ReadFile(walName)
+	require.NoError(t, err)
+
+	// Данные загружаются из снимка и журнала, но файлы не изменяются ни при работе, ни при закрытии
+	store, err := NewURLMapStore(MapConfig{StorageFile: tmpFile.Name(), ReadOnly: true})
+	require.NoError(t, err)
+	assert.Nil(t, store.wal)
+	full, _, err := store.GetURL(ctx, "short1", "")
+	require.NoError(t, err)
+	assert.Equal(t, "http://one.ru", full)
+	_, err = store.SaveURL(ctx, ShortenURL{Original: "http://two.ru"}, 2)
+	require.NoError(t, err)
+	require.NoError(t, store.Close())
+
+	assert.NoFileExists(t, tmpFile.Name()+clicksFileSuffix)
+	data, err := os.ReadFile(walName)
+	require.NoError(t, err)
+	assert.Equal(t, walData, data)
+	data, err = os.ReadFile(tmpFile.Name())
+	require.NoError(t, err)
+	assert.Empty(t, data)
+}
+
+func TestCompactWAL(t *testing.T) {
+	ctx := context.Background()
+	tmpFile, err := os.CreateTemp(".", "jsonDB_*.json")
+	require.NoError(t, err)
+	require.NoError(t, tmpFile.Close())
+	defer os.Remove(tmpFile.Name())
+	defer os.Remove(tmpFile.Name() + clicksFileSuffix)
+	defer os.Remove(tmpFile.Name() + walFileSuffix)
+
+	store, err := NewURLMapStore(MapConfig{StorageFile: tmpFile.Name(), FileSync: FileSyncAlways})
+	require.NoError(t, err)
+	defer store.Close()
+
+	id1, err := store.SaveURL(ctx, ShortenURL{Original: "http://example1.com"}, 1)
+	require.NoError(t, err)
+	id2, err := store.SaveURL(ctx, ShortenURL{Original: "http://example2.com"}, 2)
+	require.NoError(t, err)
+	require.NoError(t, store.DeleteUserURLs(2, []string{id2}))
+	assert.Equal(t, 3, store.wal.size())
+	assert.False(t, store.needCompact())
+
+	require.NoError(t, store.compact())
+	assert.Equal(t, 0, store.wal.size())
+	assert.NoFileExists(t, tmpFile.Name()+walFileSuffix+walOldFileSuffix)
+	fileData, err := os.ReadFile(tmpFile.Name())
+	require.NoError(t, err)
+	assert.Contains(t, string(fileData), `"original_url":"http://example1.com","short_url":"`+id1+`"`)
+	assert.Contains(t, string(fileData), `"short_url":"`+id2+`","user_id":2,"is_deleted":true`)
+	walData, err := os.ReadFile(tmpFile.Name() + walFileSuffix)
+	require.NoError(t, err)
+	assert.Empty(t, walData)
+
+	// Операции после сжатия попадают в новый журнал
+	require.NoError(t, store.RestoreUserURLs(ctx, 2, []string{id2}))
+	walData, err = os.ReadFile(tmpFile.Name() + walFileSuffix)
+	require.NoError(t, err)
+	assert.Contains(t, string(walData), `"op":"update"`)
+	assert.Equal(t, 1, store.wal.size())
+}
+
+func TestPersistUsers(t *testing.T) {
+	ctx := context.Background()
+	tmpFile, err := os.CreateTemp(".", "jsonDB_*.json")
+	require.NoError(t, err)
+	require.NoError(t, tmpFile.Close())
+	defer os.Remove(tmpFile.Name())
+	defer os.Remove(tmpFile.Name() + clicksFileSuffix)
+	defer os.Remove(tmpFile.Name() + walFileSuffix)
+
+	store, err := NewURLMapStore(MapConfig{StorageFile: tmpFile.Name()})
+	require.NoError(t, err)
+	user1, err := store.CreateUser(ctx)
+	require.NoError(t, err)
+	user2, err := store.CreateUser(ctx)
+	require.NoError(t, err)
+	_, err = store.SaveURL(ctx, ShortenURL{Original: "http://example.com"}, user1.ID)
+	require.NoError(t, err)
+	require.NoError(t, store.compact())
+	// Пользователи сохраняются в снимке первыми, в том числе пользователь без ссылок
+	fileData, err := os.ReadFile(tmpFile.Name())
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(string(fileData), "{\"user\":{\"id\":1}}\n{\"user\":{\"id\":2}}\n"))
+	require.NoError(t, store.Close())
+
+	store, err = NewURLMapStore(MapConfig{StorageFile: tmpFile.Name()})
+	require.NoError(t, err)
+	defer store.Close()
+	for _, userID := range []int{user1.ID, user2.ID} {
+		_, err = store.GetUser(ctx, userID)
+		require.NoError(t, err)
+	}
+	count, err := store.GetUsersCount(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
+
+	// ID пользователей не используются повторно
+	user3, err := store.CreateUser(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, user2.ID+1, user3.ID)
+}
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS shorten_urls;
+DROP TABLE IF EXISTS users;
@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS users (
+	id SERIAL PRIMARY KEY
+);
+CREATE TABLE IF NOT EXISTS shorten_urls (
+	original VARCHAR(65536) NOT NULL UNIQUE,
+	shorten VARCHAR(256) NOT NULL,
+	user_id INT REFERENCES users (id),
+	is_deleted BOOLEAN NOT NULL DEFAULT FALSE
+);
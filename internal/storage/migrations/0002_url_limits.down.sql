@@ -0,0 +1,6 @@
+ALTER TABLE shorten_urls
+	DROP COLUMN IF EXISTS expires_at,
+	DROP COLUMN IF EXISTS is_expired,
+	DROP COLUMN IF EXISTS max_clicks,
+	DROP COLUMN IF EXISTS clicks,
+	DROP COLUMN IF EXISTS password_hash;
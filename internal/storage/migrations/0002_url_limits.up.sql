@@ -0,0 +1,6 @@
+ALTER TABLE shorten_urls
+	ADD COLUMN IF NOT EXISTS expires_at TIMESTAMPTZ,
+	ADD COLUMN IF NOT EXISTS is_expired BOOLEAN NOT NULL DEFAULT FALSE,
+	ADD COLUMN IF NOT EXISTS max_clicks INT NOT NULL DEFAULT 0,
+	ADD COLUMN IF NOT EXISTS clicks INT NOT NULL DEFAULT 0,
+	ADD COLUMN IF NOT EXISTS password_hash VARCHAR(256) NOT NULL DEFAULT '';
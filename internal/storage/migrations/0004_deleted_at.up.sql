@@ -0,0 +1,3 @@
+ALTER TABLE shorten_urls ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ;
+-- Ссылки, удаленные до появления столбца, хранятся с момента миграции
+UPDATE shorten_urls SET deleted_at = now() WHERE is_deleted = TRUE AND deleted_at IS NULL;
@@ -0,0 +1,4 @@
+CREATE TABLE IF NOT EXISTS store_counters (
+	name VARCHAR(64) PRIMARY KEY,
+	value BIGINT NOT NULL DEFAULT 0
+);
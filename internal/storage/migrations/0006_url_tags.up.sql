@@ -0,0 +1,6 @@
+CREATE TABLE IF NOT EXISTS url_tags (
+	shorten VARCHAR(256) NOT NULL,
+	tag VARCHAR(64) NOT NULL,
+	PRIMARY KEY (shorten, tag)
+);
+CREATE INDEX IF NOT EXISTS url_tags_tag_idx ON url_tags (tag);
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS shorten_urls_domain_idx;
+DROP INDEX IF EXISTS shorten_urls_original_trgm_idx;
+ALTER TABLE shorten_urls DROP COLUMN IF EXISTS domain;
+DROP INDEX IF EXISTS shorten_urls_user_idx;
@@ -0,0 +1,17 @@
+-- Постраничный вывод ссылок пользователя
+CREATE INDEX IF NOT EXISTS shorten_urls_user_idx ON shorten_urls (user_id, shorten);
+-- Хост полной ссылки в нижнем регистре (шаблон совпадает с urlDomainPattern)
+ALTER TABLE shorten_urls ADD COLUMN IF NOT EXISTS domain VARCHAR(65536)
+	GENERATED ALWAYS AS (lower(substring(original from '^[a-zA-Z][a-zA-Z0-9+.-]*://(?:[^/?#@]*@)?([^/?#:]+)'))) STORED;
+-- Поиск ссылок пользователя по подстроке полной ссылки и по хосту
+CREATE INDEX IF NOT EXISTS shorten_urls_domain_idx ON shorten_urls (user_id, domain);
+-- Индекс для поиска по подстроке необязателен: расширение pg_trgm создается, только если оно установлено на сервере
+-- и у пользователя БД есть право на его создание (CREATE в БД или superuser). Иначе поиск выполняется без индекса.
+DO $$
+BEGIN
+	CREATE EXTENSION IF NOT EXISTS pg_trgm;
+	CREATE INDEX IF NOT EXISTS shorten_urls_original_trgm_idx ON shorten_urls USING gin (original gin_trgm_ops);
+EXCEPTION WHEN insufficient_privilege OR undefined_file THEN
+	RAISE WARNING 'pg_trgm is not available, url search is not indexed: %', SQLERRM;
+END
+$$;
@@ -0,0 +1 @@
+ALTER TABLE shorten_urls DROP CONSTRAINT IF EXISTS shorten_urls_pkey;
@@ -0,0 +1,9 @@
+-- Удаляем дубли сокращенных ссылок: остается одна запись, предпочтительно не удаленная
+DELETE FROM shorten_urls WHERE ctid IN (
+	SELECT ctid FROM (
+		SELECT ctid, row_number() OVER (PARTITION BY shorten ORDER BY is_deleted, ctid) AS n
+		FROM shorten_urls
+	) duplicates
+	WHERE n > 1
+);
+ALTER TABLE shorten_urls ADD CONSTRAINT shorten_urls_pkey PRIMARY KEY (shorten);
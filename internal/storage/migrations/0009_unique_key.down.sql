@@ -0,0 +1,12 @@
+-- В областях уникальности user и none одна полная ссылка может быть сохранена несколько раз:
+-- откат не удаляет такие ссылки сам, а прерывается, пока повторы не будут удалены
+DO $$
+BEGIN
+	IF EXISTS (SELECT 1 FROM shorten_urls GROUP BY original HAVING count(*) > 1) THEN
+		RAISE EXCEPTION 'cannot roll back unique_key: shorten_urls has duplicate original urls, remove them first';
+	END IF;
+END
+$$;
+DROP INDEX IF EXISTS shorten_urls_unique_key_idx;
+ALTER TABLE shorten_urls DROP COLUMN IF EXISTS unique_key;
+ALTER TABLE shorten_urls ADD CONSTRAINT shorten_urls_original_key UNIQUE (original);
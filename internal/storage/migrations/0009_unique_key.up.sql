@@ -0,0 +1,8 @@
+-- Ключ уникальности полной ссылки вычисляется приложением согласно области уникальности
+-- (NULL - ссылка не проверяется на уникальность). До появления области полные ссылки были уникальны глобально,
+-- поэтому ключ заполняется для глобальной области: ключи заданной области приложение пересчитывает
+-- в той же транзакции сразу после применения миграций (см. syncUniqueKeys).
+ALTER TABLE shorten_urls ADD COLUMN IF NOT EXISTS unique_key VARCHAR(65536);
+UPDATE shorten_urls SET unique_key = original;
+ALTER TABLE shorten_urls DROP CONSTRAINT IF EXISTS shorten_urls_original_key;
+CREATE UNIQUE INDEX IF NOT EXISTS shorten_urls_unique_key_idx ON shorten_urls (unique_key);
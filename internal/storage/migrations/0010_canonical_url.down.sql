@@ -0,0 +1 @@
+ALTER TABLE shorten_urls DROP COLUMN IF EXISTS canonical;
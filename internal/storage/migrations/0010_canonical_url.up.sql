@@ -0,0 +1,2 @@
+-- Каноническая форма полной ссылки, по которой проверяется уникальность (NULL - совпадает с original).
+ALTER TABLE shorten_urls ADD COLUMN IF NOT EXISTS canonical VARCHAR(65536);
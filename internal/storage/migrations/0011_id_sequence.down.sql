@@ -0,0 +1 @@
+DROP SEQUENCE IF EXISTS shorten_id_seq;
@@ -0,0 +1,6 @@
+-- Последовательность для генератора последовательных сокращенных ссылок. Она общая для всех экземпляров сервиса,
+-- поэтому они не выдают одинаковые id. Начальное значение учитывает сохраненные и окончательно удаленные ссылки.
+CREATE SEQUENCE IF NOT EXISTS shorten_id_seq MINVALUE 0 START WITH 0;
+SELECT setval('shorten_id_seq',
+	(SELECT count(*) FROM shorten_urls) + COALESCE((SELECT value FROM store_counters WHERE name = 'purged_urls'), 0),
+	false);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS shorten_urls_user_idx;
+CREATE INDEX IF NOT EXISTS shorten_urls_user_idx ON shorten_urls (user_id, shorten);
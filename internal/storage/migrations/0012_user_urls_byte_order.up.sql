@@ -0,0 +1,3 @@
+-- Постраничный вывод ссылок пользователя упорядочен по сокращенной ссылке побайтово (COLLATE "C")
+DROP INDEX IF EXISTS shorten_urls_user_idx;
+CREATE INDEX IF NOT EXISTS shorten_urls_user_idx ON shorten_urls (user_id, shorten COLLATE "C");
@@ -78,19 +78,107 @@ func (mr *MockURLStorageMockRecorder) DeleteUserURLs(userID, urls interface{}) *
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteUserURLs", reflect.TypeOf((*MockURLStorage)(nil).DeleteUserURLs), userID, urls)
 }
 
+// DumpURLs mocks base method.
+func (m *MockURLStorage) DumpURLs(ctx context.Context, fn func(storage.URLRecord) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DumpURLs", ctx, fn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DumpURLs indicates an expected call of DumpURLs.
+func (mr *MockURLStorageMockRecorder) DumpURLs(ctx, fn interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DumpURLs", reflect.TypeOf((*MockURLStorage)(nil).DumpURLs), ctx, fn)
+}
+
+// DumpUsers mocks base method.
+func (m *MockURLStorage) DumpUsers(ctx context.Context, fn func(storage.User) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DumpUsers", ctx, fn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DumpUsers indicates an expected call of DumpUsers.
+func (mr *MockURLStorageMockRecorder) DumpUsers(ctx, fn interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DumpUsers", reflect.TypeOf((*MockURLStorage)(nil).DumpUsers), ctx, fn)
+}
+
+// ExportUserURLs mocks base method.
+func (m *MockURLStorage) ExportUserURLs(ctx context.Context, userID int, fn func(storage.ShortenURL) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExportUserURLs", ctx, userID, fn)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ExportUserURLs indicates an expected call of ExportUserURLs.
+func (mr *MockURLStorageMockRecorder) ExportUserURLs(ctx, userID, fn interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExportUserURLs", reflect.TypeOf((*MockURLStorage)(nil).ExportUserURLs), ctx, userID, fn)
+}
+
+// GetDeletedUserURLs mocks base method.
+func (m *MockURLStorage) GetDeletedUserURLs(ctx context.Context, id int) ([]storage.ShortenURL, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDeletedUserURLs", ctx, id)
+	ret0, _ := ret[0].([]storage.ShortenURL)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDeletedUserURLs indicates an expected call of GetDeletedUserURLs.
+func (mr *MockURLStorageMockRecorder) GetDeletedUserURLs(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDeletedUserURLs", reflect.TypeOf((*MockURLStorage)(nil).GetDeletedUserURLs), ctx, id)
+}
+
+// GetPurgedURLsCount mocks base method.
+func (m *MockURLStorage) GetPurgedURLsCount(ctx context.Context) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPurgedURLsCount", ctx)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPurgedURLsCount indicates an expected call of GetPurgedURLsCount.
+func (mr *MockURLStorageMockRecorder) GetPurgedURLsCount(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPurgedURLsCount", reflect.TypeOf((*MockURLStorage)(nil).GetPurgedURLsCount), ctx)
+}
+
 // GetURL mocks base method.
-func (m *MockURLStorage) GetURL(ctx context.Context, id string) (string, error) {
+func (m *MockURLStorage) GetURL(ctx context.Context, id, password string) (string, string, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetURL", ctx, id)
+	ret := m.ctrl.Call(m, "GetURL", ctx, id, password)
 	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(string)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetURL indicates an expected call of GetURL.
+func (mr *MockURLStorageMockRecorder) GetURL(ctx, id, password interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetURL", reflect.TypeOf((*MockURLStorage)(nil).GetURL), ctx, id, password)
+}
+
+// GetURLStats mocks base method.
+func (m *MockURLStorage) GetURLStats(ctx context.Context, userID int, id string) (*storage.URLStats, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetURLStats", ctx, userID, id)
+	ret0, _ := ret[0].(*storage.URLStats)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetURL indicates an expected call of GetURL.
-func (mr *MockURLStorageMockRecorder) GetURL(ctx, id interface{}) *gomock.Call {
+// GetURLStats indicates an expected call of GetURLStats.
+func (mr *MockURLStorageMockRecorder) GetURLStats(ctx, userID, id interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetURL", reflect.TypeOf((*MockURLStorage)(nil).GetURL), ctx, id)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetURLStats", reflect.TypeOf((*MockURLStorage)(nil).GetURLStats), ctx, userID, id)
 }
 
 // GetURLsCount mocks base method.
@@ -124,18 +212,18 @@ func (mr *MockURLStorageMockRecorder) GetUser(ctx, id interface{}) *gomock.Call
 }
 
 // GetUserURLs mocks base method.
-func (m *MockURLStorage) GetUserURLs(ctx context.Context, id int) ([]storage.ShortenURL, error) {
+func (m *MockURLStorage) GetUserURLs(ctx context.Context, id int, filter storage.UserURLsFilter) ([]storage.ShortenURL, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetUserURLs", ctx, id)
+	ret := m.ctrl.Call(m, "GetUserURLs", ctx, id, filter)
 	ret0, _ := ret[0].([]storage.ShortenURL)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetUserURLs indicates an expected call of GetUserURLs.
-func (mr *MockURLStorageMockRecorder) GetUserURLs(ctx, id interface{}) *gomock.Call {
+func (mr *MockURLStorageMockRecorder) GetUserURLs(ctx, id, filter interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserURLs", reflect.TypeOf((*MockURLStorage)(nil).GetUserURLs), ctx, id)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserURLs", reflect.TypeOf((*MockURLStorage)(nil).GetUserURLs), ctx, id, filter)
 }
 
 // GetUsersCount mocks base method.
@@ -167,6 +255,35 @@ func (mr *MockURLStorageMockRecorder) IsValidID(id interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsValidID", reflect.TypeOf((*MockURLStorage)(nil).IsValidID), id)
 }
 
+// LoadURLs mocks base method.
+func (m *MockURLStorage) LoadURLs(ctx context.Context, urls []storage.URLRecord) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LoadURLs", ctx, urls)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LoadURLs indicates an expected call of LoadURLs.
+func (mr *MockURLStorageMockRecorder) LoadURLs(ctx, urls interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LoadURLs", reflect.TypeOf((*MockURLStorage)(nil).LoadURLs), ctx, urls)
+}
+
+// LoadUsers mocks base method.
+func (m *MockURLStorage) LoadUsers(ctx context.Context, users []storage.User) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LoadUsers", ctx, users)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// LoadUsers indicates an expected call of LoadUsers.
+func (mr *MockURLStorageMockRecorder) LoadUsers(ctx, users interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LoadUsers", reflect.TypeOf((*MockURLStorage)(nil).LoadUsers), ctx, users)
+}
+
 // Ping mocks base method.
 func (m *MockURLStorage) Ping(ctx context.Context) error {
 	m.ctrl.T.Helper()
@@ -181,6 +298,20 @@ func (mr *MockURLStorageMockRecorder) Ping(ctx interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Ping", reflect.TypeOf((*MockURLStorage)(nil).Ping), ctx)
 }
 
+// RestoreUserURLs mocks base method.
+func (m *MockURLStorage) RestoreUserURLs(ctx context.Context, userID int, urls []string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RestoreUserURLs", ctx, userID, urls)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RestoreUserURLs indicates an expected call of RestoreUserURLs.
+func (mr *MockURLStorageMockRecorder) RestoreUserURLs(ctx, userID, urls interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreUserURLs", reflect.TypeOf((*MockURLStorage)(nil).RestoreUserURLs), ctx, userID, urls)
+}
+
 // SaveBatchURL mocks base method.
 func (m *MockURLStorage) SaveBatchURL(ctx context.Context, urls []storage.ShortenURL, userID int) error {
 	m.ctrl.T.Helper()
@@ -195,8 +326,22 @@ func (mr *MockURLStorageMockRecorder) SaveBatchURL(ctx, urls, userID interface{}
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveBatchURL", reflect.TypeOf((*MockURLStorage)(nil).SaveBatchURL), ctx, urls, userID)
 }
 
+// SaveClick mocks base method.
+func (m *MockURLStorage) SaveClick(event storage.ClickEvent) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveClick", event)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveClick indicates an expected call of SaveClick.
+func (mr *MockURLStorageMockRecorder) SaveClick(event interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveClick", reflect.TypeOf((*MockURLStorage)(nil).SaveClick), event)
+}
+
 // SaveURL mocks base method.
-func (m *MockURLStorage) SaveURL(ctx context.Context, url string, userID int) (string, error) {
+func (m *MockURLStorage) SaveURL(ctx context.Context, url storage.ShortenURL, userID int) (string, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "SaveURL", ctx, url, userID)
 	ret0, _ := ret[0].(string)
@@ -209,3 +354,18 @@ func (mr *MockURLStorageMockRecorder) SaveURL(ctx, url, userID interface{}) *gom
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveURL", reflect.TypeOf((*MockURLStorage)(nil).SaveURL), ctx, url, userID)
 }
+
+// UpdateURL mocks base method.
+func (m *MockURLStorage) UpdateURL(ctx context.Context, userID int, id string, update storage.URLUpdate) (*storage.ShortenURL, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateURL", ctx, userID, id, update)
+	ret0, _ := ret[0].(*storage.ShortenURL)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateURL indicates an expected call of UpdateURL.
+func (mr *MockURLStorageMockRecorder) UpdateURL(ctx, userID, id, update interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateURL", reflect.TypeOf((*MockURLStorage)(nil).UpdateURL), ctx, userID, id, update)
+}
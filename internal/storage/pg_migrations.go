@@ -0,0 +1,239 @@
+package storage
+
+import (
+	"context"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/pinbrain/urlshortener/internal/logger"
+)
+
+// migrationsFS содержит sql файлы миграций схемы БД.
+// Имя файла: <версия>_<название>.up.sql (применение) или <версия>_<название>.down.sql (откат).
+// Миграции 0001-0007 воспроизводят схему, которую до появления версий создавало при запуске само хранилище
+// (ограничения ссылок, переходы, удаление, счетчики, теги и поиск), поэтому они идемпотентны: в БД со старой
+// схемой они ничего не меняют и только отмечаются примененными. Новые изменения схемы добавляются только миграциями.
+//
+//go:embed migrations/*.sql
+var migrationsFS embed.FS
+
+// migrationsLockID - ключ advisory блокировки, под которой выполняются миграции
+// (несколько экземпляров приложения, запущенных одновременно, применяют миграции по очереди).
+const migrationsLockID = 7_319_452_001
+
+// migration описывает одну миграцию схемы БД.
+type migration struct {
+	version int
+	name    string
+	up      string
+	down    string
+}
+
+// loadMigrations читает миграции из fsys и возвращает их упорядоченными по версии.
+// У каждой миграции должны быть и применение, и откат.
+func loadMigrations(fsys fs.FS) ([]migration, error) {
+	files, err := fs.Glob(fsys, "migrations/*.sql")
+	if err != nil {
+		return nil, err
+	}
+	byVersion := make(map[int]*migration)
+	for _, file := range files {
+		base := strings.TrimPrefix(file, "migrations/")
+		name, direction, ok := strings.Cut(strings.TrimSuffix(base, ".sql"), ".")
+		if !ok || (direction != "up" && direction != "down") {
+			return nil, fmt.Errorf("invalid migration file name %q", base)
+		}
+		versionStr, title, ok := strings.Cut(name, "_")
+		version, convErr := strconv.Atoi(versionStr)
+		if !ok || convErr != nil || version <= 0 {
+			return nil, fmt.Errorf("invalid migration file name %q", base)
+		}
+		data, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			return nil, err
+		}
+		m, ok := byVersion[version]
+		if !ok {
+			m = &migration{version: version, name: title}
+			byVersion[version] = m
+		}
+		if m.name != title {
+			return nil, fmt.Errorf("migration version %d has different names: %q and %q", version, m.name, title)
+		}
+		if direction == "up" {
+			m.up = string(data)
+		} else {
+			m.down = string(data)
+		}
+	}
+
+	migrations := make([]migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.up == "" || m.down == "" {
+			return nil, fmt.Errorf("migration %d_%s must have both up and down files", m.version, m.name)
+		}
+		migrations = append(migrations, *m)
+	}
+	slices.SortFunc(migrations, func(a, b migration) int { return a.version - b.version })
+	return migrations, nil
+}
+
+// lockMigrations начинает транзакцию миграций: берет advisory блокировку (до конца транзакции),
+// создает при необходимости таблицу примененных миграций и возвращает версии примененных миграций.
+func lockMigrations(ctx context.Context, tx pgx.Tx) ([]int, error) {
+	if _, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock($1);`, migrationsLockID); err != nil {
+		return nil, fmt.Errorf("failed to acquire migrations lock: %w", err)
+	}
+	_, err := tx.Exec(ctx,
+		`CREATE TABLE IF NOT EXISTS schema_migrations (
+			version INT PRIMARY KEY,
+			name VARCHAR(256) NOT NULL,
+			applied_at TIMESTAMPTZ NOT NULL DEFAULT now()
+		);`,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create schema migrations table: %w", err)
+	}
+	rows, err := tx.Query(ctx, `SELECT version FROM schema_migrations ORDER BY version`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to select applied migrations: %w", err)
+	}
+	applied, err := pgx.CollectRows(rows, pgx.RowTo[int])
+	if err != nil {
+		return nil, fmt.Errorf("failed to select applied migrations: %w", err)
+	}
+	return applied, nil
+}
+
+// migrateUp применяет не примененные ранее миграции в одной транзакции.
+// Миграции схемы, созданной до появления версий, идемпотентны и просто отмечаются примененными.
+// В той же транзакции под блокировкой миграций ключи уникальности ссылок приводятся к области uniqueness.
+func migrateUp(ctx context.Context, pool PgxPoolI, migrations []migration, uniqueness UniquenessScope) error {
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	applied, err := lockMigrations(ctx, tx)
+	if err != nil {
+		return err
+	}
+	for _, m := range migrations {
+		if slices.Contains(applied, m.version) {
+			continue
+		}
+		logger.Log.Infow("Applying schema migration", "version", m.version, "name", m.name)
+		if _, err = tx.Exec(ctx, m.up); err != nil {
+			return fmt.Errorf("failed to apply migration %d_%s: %w", m.version, m.name, err)
+		}
+		_, err = tx.Exec(ctx, `INSERT INTO schema_migrations (version, name) VALUES ($1, $2);`, m.version, m.name)
+		if err != nil {
+			return fmt.Errorf("failed to save migration %d_%s: %w", m.version, m.name, err)
+		}
+	}
+	if err = syncUniqueKeys(ctx, tx, uniqueness); err != nil {
+		return fmt.Errorf("failed to apply url uniqueness scope: %w", err)
+	}
+	if err = tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit migrations: %w", err)
+	}
+	return nil
+}
+
+// checkMigrations проверяет, что все миграции применены к схеме БД (для хранилища только для чтения).
+func checkMigrations(ctx context.Context, pool PgxPoolI, migrations []migration) error {
+	rows, err := pool.Query(ctx, `SELECT version FROM schema_migrations ORDER BY version`)
+	if err != nil {
+		return fmt.Errorf("failed to select applied migrations: %w", err)
+	}
+	applied, err := pgx.CollectRows(rows, pgx.RowTo[int])
+	if err != nil {
+		return fmt.Errorf("failed to select applied migrations: %w", err)
+	}
+	for _, m := range migrations {
+		if !slices.Contains(applied, m.version) {
+			return fmt.Errorf("migration %d_%s is not applied", m.version, m.name)
+		}
+	}
+	return nil
+}
+
+// migrateDown откатывает steps последних примененных миграций в одной транзакции.
+func migrateDown(ctx context.Context, pool PgxPoolI, migrations []migration, steps int) error {
+	tx, err := pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	applied, err := lockMigrations(ctx, tx)
+	if err != nil {
+		return err
+	}
+	if steps > len(applied) {
+		return fmt.Errorf("cannot roll back %d migrations: only %d applied", steps, len(applied))
+	}
+	for j := len(applied) - 1; j >= len(applied)-steps; j-- {
+		version := applied[j]
+		i := slices.IndexFunc(migrations, func(m migration) bool { return m.version == version })
+		if i < 0 {
+			return fmt.Errorf("unknown applied migration %d", version)
+		}
+		m := migrations[i]
+		logger.Log.Infow("Rolling back schema migration", "version", m.version, "name", m.name)
+		if _, err = tx.Exec(ctx, m.down); err != nil {
+			return fmt.Errorf("failed to roll back migration %d_%s: %w", m.version, m.name, err)
+		}
+		if _, err = tx.Exec(ctx, `DELETE FROM schema_migrations WHERE version = $1;`, m.version); err != nil {
+			return fmt.Errorf("failed to delete migration %d_%s: %w", m.version, m.name, err)
+		}
+	}
+	if err = tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit migrations: %w", err)
+	}
+	return nil
+}
+
+// MigratePgSchema применяет к БД все миграции схемы, не запуская хранилище,
+// и приводит ключи уникальности ссылок к области uniqueness (global, user, none).
+func MigratePgSchema(ctx context.Context, dsn string, uniqueness string) error {
+	scope, err := ParseUniquenessScope(uniqueness)
+	if err != nil {
+		return err
+	}
+	return withMigrations(ctx, dsn, func(pool PgxPoolI, migrations []migration) error {
+		return migrateUp(ctx, pool, migrations, scope)
+	})
+}
+
+// RollbackPgSchema откатывает steps последних примененных к БД миграций схемы.
+func RollbackPgSchema(ctx context.Context, dsn string, steps int) error {
+	if steps <= 0 {
+		return errors.New("number of migrations to roll back must be positive")
+	}
+	return withMigrations(ctx, dsn, func(pool PgxPoolI, migrations []migration) error {
+		return migrateDown(ctx, pool, migrations, steps)
+	})
+}
+
+// withMigrations открывает соединение с БД и передает его функции fn вместе со списком миграций.
+func withMigrations(ctx context.Context, dsn string, fn func(pool PgxPoolI, migrations []migration) error) error {
+	migrations, err := loadMigrations(migrationsFS)
+	if err != nil {
+		return fmt.Errorf("failed to load migrations: %w", err)
+	}
+	pool, err := initPool(ctx, PgConfig{DSN: dsn})
+	if err != nil {
+		return fmt.Errorf("failed to initialize a db connection: %w", err)
+	}
+	defer pool.Close()
+	return fn(pool, migrations)
+}
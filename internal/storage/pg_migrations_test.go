@@ -0,0 +1,221 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/jackc/pgerrcode"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/pashagolub/pgxmock/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadMigrations(t *testing.T) {
+	migrations, err := loadMigrations(migrationsFS)
+	require.NoError(t, err)
+	require.NotEmpty(t, migrations)
+	for i, m := range migrations {
+		assert.Equal(t, i+1, m.version, "версии миграций должны идти подряд")
+		assert.NotEmpty(t, m.up)
+		assert.NotEmpty(t, m.down)
+	}
+	// Вычисляемый столбец domain должен определять хост так же, как хранилище в памяти
+	require.GreaterOrEqual(t, len(migrations), 7)
+	assert.Equal(t, "url_search", migrations[6].name)
+	assert.Contains(t, migrations[6].up, urlDomainPattern)
+
+	tests := []struct {
+		name string
+		fsys fstest.MapFS
+	}{
+		{
+			name: "Нет файла отката",
+			fsys: fstest.MapFS{"migrations/0001_init.up.sql": {Data: []byte("CREATE TABLE a;")}},
+		},
+		{
+			name: "Некорректная версия",
+			fsys: fstest.MapFS{
+				"migrations/init.up.sql":   {Data: []byte("CREATE TABLE a;")},
+				"migrations/init.down.sql": {Data: []byte("DROP TABLE a;")},
+			},
+		},
+		{
+			name: "Некорректное направление",
+			fsys: fstest.MapFS{"migrations/0001_init.sideways.sql": {Data: []byte("CREATE TABLE a;")}},
+		},
+		{
+			name: "Разные названия одной версии",
+			fsys: fstest.MapFS{
+				"migrations/0001_init.up.sql":    {Data: []byte("CREATE TABLE a;")},
+				"migrations/0001_other.down.sql": {Data: []byte("DROP TABLE a;")},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, loadErr := loadMigrations(tt.fsys)
+			assert.Error(t, loadErr)
+		})
+	}
+}
+
+// testMigrations - миграции для проверки применения и отката.
+var testMigrations = []migration{
+	{version: 1, name: "first", up: "CREATE TABLE first", down: "DROP TABLE first"},
+	{version: 2, name: "second", up: "CREATE TABLE second", down: "DROP TABLE second"},
+	{version: 3, name: "third", up: "CREATE TABLE third", down: "DROP TABLE third"},
+}
+
+// expectLockMigrations добавляет ожидания блокировки миграций с уже примененными версиями applied.
+func expectLockMigrations(mock pgxmock.PgxPoolIface, applied ...int) {
+	mock.ExpectBegin()
+	mock.ExpectExec("SELECT pg_advisory_xact_lock").
+		WithArgs(migrationsLockID).
+		WillReturnResult(pgxmock.NewResult("SELECT", 1))
+	mock.ExpectExec("CREATE TABLE IF NOT EXISTS schema_migrations").
+		WillReturnResult(pgxmock.NewResult("CREATE TABLE", 0))
+	rows := mock.NewRows([]string{"version"})
+	for _, version := range applied {
+		rows.AddRow(version)
+	}
+	mock.ExpectQuery("SELECT version FROM schema_migrations").WillReturnRows(rows)
+}
+
+func TestPgMigrateUp(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+
+	// Применяются только новые миграции
+	expectLockMigrations(mock, 1)
+	for _, m := range testMigrations[1:] {
+		mock.ExpectExec(m.up).WillReturnResult(pgxmock.NewResult("CREATE TABLE", 0))
+		mock.ExpectExec("INSERT INTO schema_migrations").
+			WithArgs(m.version, m.name).
+			WillReturnResult(pgxmock.NewResult("INSERT", 1))
+	}
+	// Ключи уникальности пересчитываются в той же транзакции
+	mock.ExpectExec("UPDATE shorten_urls SET unique_key = COALESCE\\(canonical, original\\)").
+		WillReturnResult(pgxmock.NewResult("UPDATE", 0))
+	mock.ExpectCommit()
+	require.NoError(t, migrateUp(context.TODO(), mock, testMigrations, UniqueGlobal))
+
+	// Все миграции уже применены
+	expectLockMigrations(mock, 1, 2, 3)
+	mock.ExpectExec("UPDATE shorten_urls SET unique_key = NULL").
+		WillReturnResult(pgxmock.NewResult("UPDATE", 2))
+	mock.ExpectCommit()
+	require.NoError(t, migrateUp(context.TODO(), mock, testMigrations, UniqueNone))
+
+	// Ошибка миграции откатывает всю транзакцию
+	migrationErr := errors.New("syntax error")
+	expectLockMigrations(mock, 1)
+	mock.ExpectExec(testMigrations[1].up).WillReturnError(migrationErr)
+	mock.ExpectRollback()
+	assert.ErrorIs(t, migrateUp(context.TODO(), mock, testMigrations, UniqueGlobal), migrationErr)
+
+	// Нарушение уникальности в новой области откатывает и миграции
+	expectLockMigrations(mock, 1, 2)
+	mock.ExpectExec(testMigrations[2].up).WillReturnResult(pgxmock.NewResult("CREATE TABLE", 0))
+	mock.ExpectExec("INSERT INTO schema_migrations").
+		WithArgs(testMigrations[2].version, testMigrations[2].name).
+		WillReturnResult(pgxmock.NewResult("INSERT", 1))
+	mock.ExpectExec("UPDATE shorten_urls SET unique_key").
+		WillReturnError(&pgconn.PgError{Code: pgerrcode.UniqueViolation})
+	mock.ExpectRollback()
+	assert.ErrorIs(t, migrateUp(context.TODO(), mock, testMigrations, UniqueGlobal), ErrConflict)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPgMigrateDown(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+
+	// Откатываются последние миграции в обратном порядке
+	expectLockMigrations(mock, 1, 2, 3)
+	for _, m := range []migration{testMigrations[2], testMigrations[1]} {
+		mock.ExpectExec(m.down).WillReturnResult(pgxmock.NewResult("DROP TABLE", 0))
+		mock.ExpectExec("DELETE FROM schema_migrations").
+			WithArgs(m.version).
+			WillReturnResult(pgxmock.NewResult("DELETE", 1))
+	}
+	mock.ExpectCommit()
+	require.NoError(t, migrateDown(context.TODO(), mock, testMigrations, 2))
+
+	// Нельзя откатить больше миграций, чем применено
+	expectLockMigrations(mock, 1)
+	mock.ExpectRollback()
+	assert.Error(t, migrateDown(context.TODO(), mock, testMigrations, 2))
+
+	// Примененная миграция неизвестна текущей версии приложения
+	expectLockMigrations(mock, 1, 2, 3, 4)
+	mock.ExpectRollback()
+	assert.Error(t, migrateDown(context.TODO(), mock, testMigrations, 1))
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPgRollbackDuplicateOriginals(t *testing.T) {
+	migrations, err := loadMigrations(migrationsFS)
+	require.NoError(t, err)
+	uniqueKey := slices.IndexFunc(migrations, func(m migration) bool { return m.name == "unique_key" })
+	require.GreaterOrEqual(t, uniqueKey, 0)
+	// Откат до миграции unique_key прерывается ошибкой, если полные ссылки повторяются
+	assert.Contains(t, migrations[uniqueKey].down, "duplicate original urls")
+
+	dsn := os.Getenv(testDatabaseDSNEnv)
+	if dsn == "" {
+		t.Skipf("%s is not set", testDatabaseDSNEnv)
+	}
+	ctx := context.Background()
+	require.NoError(t, MigratePgSchema(ctx, dsn, string(UniqueNone)))
+	store, err := NewURLPgStore(PgConfig{DSN: dsn, Uniqueness: UniqueNone})
+	require.NoError(t, err)
+	defer store.Close()
+
+	nonce := fmt.Sprintf("%d", time.Now().UnixNano())
+	original := "https://example.com/rollback/" + nonce
+	defer func() {
+		_, err = store.pool.Exec(ctx, `DELETE FROM shorten_urls WHERE original = $1`, original)
+		require.NoError(t, err)
+		require.NoError(t, MigratePgSchema(ctx, dsn, string(UniqueGlobal)))
+	}()
+	for i := 0; i < 2; i++ {
+		_, err = store.SaveURL(ctx, ShortenURL{Original: original}, 0)
+		require.NoError(t, err)
+	}
+
+	steps := len(migrations) - uniqueKey
+	assert.ErrorContains(t, RollbackPgSchema(ctx, dsn, steps), "duplicate original urls")
+	// Откат выполняется в одной транзакции, поэтому схема остается актуальной
+	require.NoError(t, checkMigrations(ctx, store.pool, migrations))
+}
+
+func TestPgCheckMigrations(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+
+	mock.ExpectQuery("SELECT version FROM schema_migrations").
+		WillReturnRows(mock.NewRows([]string{"version"}).AddRow(1).AddRow(2).AddRow(3))
+	require.NoError(t, checkMigrations(context.TODO(), mock, testMigrations))
+
+	// Схема БД не актуальна
+	mock.ExpectQuery("SELECT version FROM schema_migrations").
+		WillReturnRows(mock.NewRows([]string{"version"}).AddRow(1).AddRow(2))
+	assert.ErrorContains(t, checkMigrations(context.TODO(), mock, testMigrations), "3_third")
+	require.NoError(t, mock.ExpectationsWereMet())
+}
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"regexp"
+	"strings"
 	"sync"
 	"time"
 
@@ -14,12 +15,20 @@ import (
 	"github.com/jackc/pgx/v5/pgxpool"
 
 	"github.com/pinbrain/urlshortener/internal/logger"
-	"github.com/pinbrain/urlshortener/internal/utils"
 )
 
 // PgConfig описывает структуру конфигурации БД.
 type PgConfig struct {
-	DSN string
+	DSN          string
+	AliasPattern string      // Шаблон пользовательских сокращенных ссылок
+	IDGenerator  IDGenerator // Генератор сокращенных ссылок (по умолчанию - случайные ссылки формата IDFormat)
+	IDFormat     IDFormat    // Формат сокращенных ссылок
+
+	DeletedRetention time.Duration   // Срок хранения удаленных ссылок (0 - хранятся бессрочно)
+	Uniqueness       UniquenessScope // Область уникальности полных ссылок
+	// Только для чтения: схема БД не мигрирует (должна быть актуальной), фоновые задачи не запускаются,
+	// транзакции соединений открываются только для чтения
+	ReadOnly bool
 }
 
 // PgxPoolI описывает интерфейс Pool postgresql. Совместим с моком для тестов.
@@ -33,10 +42,22 @@ type PgxPoolI interface {
 	Ping(ctx context.Context) error
 }
 
+// pgExecer описывает выполнение запросов без результата (пул соединений или транзакция).
+type pgExecer interface {
+	Exec(ctx context.Context, sql string, arguments ...any) (pgconn.CommandTag, error)
+}
+
 // URLPgStore описывает структуру хранилища БД.
 type URLPgStore struct {
 	pool     PgxPoolI
 	urlDelCh chan urlDelBatchData
+	aliasReg *regexp.Regexp
+	idGen    IDGenerator
+	idFormat IDFormat
+
+	clickQueue       *clickQueue
+	deletedRetention time.Duration
+	uniqueness       UniquenessScope
 
 	ctx       context.Context
 	ctxCancel context.CancelFunc
@@ -56,24 +77,83 @@ const (
 	delURLBatchInterval = 10
 )
 
+// purgedURLsCounter - название счетчика окончательно удаленных ссылок в таблице store_counters.
+const purgedURLsCounter = "purged_urls"
+
+// insertURLStmt - запрос на сохранение ссылки в транзакции. Ошибка прерывает транзакцию,
+// поэтому запись с занятой сокращенной ссылкой не добавляется без ошибки. Уже сохраненная полная ссылка
+// нарушает уникальность ключа unique_key.
+const insertURLStmt = `INSERT INTO shorten_urls(original, shorten, user_id, expires_at, max_clicks, password_hash,
+		unique_key, canonical)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8) ON CONFLICT (shorten) DO NOTHING;`
+
+// getURLStmt - запрос на чтение ссылки при переходе по ней.
+const getURLStmt = `SELECT original, is_deleted, is_expired OR COALESCE(expires_at <= now(), FALSE),
+		password_hash, max_clicks
+	FROM shorten_urls WHERE shorten = $1`
+
+// clickURLStmt - запрос на переход по ссылке с лимитом переходов: увеличивает счетчик переходов
+// доступной ссылки, если лимит не исчерпан, и возвращает ее.
+const clickURLStmt = `UPDATE shorten_urls SET clicks = clicks + 1
+	WHERE shorten = $1 AND is_deleted = FALSE AND is_expired = FALSE
+		AND (expires_at IS NULL OR expires_at > now())
+		AND clicks < max_clicks
+	RETURNING original;`
+
 // NewURLPgStore создает новое хранилище типа БД (postgresql).
 func NewURLPgStore(cfg PgConfig) (*URLPgStore, error) {
-	var err error
+	aliasReg, err := compileAliasPattern(cfg.AliasPattern)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compile alias pattern: %w", err)
+	}
+	if err = cfg.IDFormat.validate(); err != nil {
+		return nil, fmt.Errorf("invalid id format: %w", err)
+	}
 	store := &URLPgStore{
 		urlDelCh: make(chan urlDelBatchData, delURLsBatchSize),
+		aliasReg: aliasReg,
+		idGen:    cfg.IDGenerator,
+		idFormat: cfg.IDFormat,
 		wg:       sync.WaitGroup{},
+
+		deletedRetention: cfg.DeletedRetention,
+		uniqueness:       cfg.Uniqueness,
+	}
+	if store.idGen == nil {
+		store.idGen = NewRandomIDGenerator(cfg.IDFormat)
+	}
+	// Счетчик последовательного генератора хранится в БД, чтобы его разделяли все экземпляры сервиса
+	if seqGen, ok := store.idGen.(*SequentialIDGenerator); ok {
+		store.idGen = &pgSequenceIDGenerator{store: store, format: seqGen.format}
 	}
 	store.ctx, store.ctxCancel = context.WithCancel(context.Background())
 	store.pool, err = initPool(store.ctx, cfg)
 	if err != nil {
 		return nil, fmt.Errorf("failed to initialize a db connection: %w", err)
 	}
-	if err = initSchema(store.ctx, store.pool); err != nil {
-		return nil, fmt.Errorf("failed to initialize a db scheme: %w", err)
+	migrations, err := loadMigrations(migrationsFS)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load migrations: %w", err)
+	}
+	store.clickQueue = newClickQueue(store.saveClicks)
+	if cfg.ReadOnly {
+		if err = checkMigrations(store.ctx, store.pool, migrations); err != nil {
+			return nil, fmt.Errorf("failed to check a db scheme: %w", err)
+		}
+		return store, nil
+	}
+	if err = migrateUp(store.ctx, store.pool, migrations, store.uniqueness); err != nil {
+		return nil, fmt.Errorf("failed to migrate a db scheme: %w", err)
 	}
 
-	store.wg.Add(1)
+	store.wg.Add(3)
 	go store.flushDelURLs()
+	go store.expireURLs()
+	go store.clickQueue.run(&store.wg)
+	if store.deletedRetention > 0 {
+		store.wg.Add(1)
+		go store.purgeURLs()
+	}
 
 	return store, nil
 }
@@ -84,6 +164,9 @@ func initPool(ctx context.Context, cfg PgConfig) (*pgxpool.Pool, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse the DNS: %w", err)
 	}
+	if cfg.ReadOnly {
+		poolCfg.ConnConfig.RuntimeParams["default_transaction_read_only"] = "on"
+	}
 	pool, err := pgxpool.NewWithConfig(ctx, poolCfg)
 	if err != nil {
 		return nil, fmt.Errorf("failed to initialize a connection pool: %w", err)
@@ -94,33 +177,57 @@ func initPool(ctx context.Context, cfg PgConfig) (*pgxpool.Pool, error) {
 	return pool, nil
 }
 
-// initSchema выполняет миграцию (создание таблиц).
-func initSchema(ctx context.Context, pool PgxPoolI) error {
-	tx, err := pool.Begin(ctx)
-	if err != nil {
-		return err
-	}
-	defer tx.Rollback(ctx)
-	_, err = tx.Exec(ctx,
-		`CREATE TABLE IF NOT EXISTS users (
-			id SERIAL PRIMARY KEY
-		);`,
+// syncUniqueKeys пересчитывает ключи уникальности ссылок, сохраненных в другой области уникальности.
+// Если сохраненные ссылки нарушают уникальность в области scope, возвращается ошибка.
+func syncUniqueKeys(ctx context.Context, db pgExecer, scope UniquenessScope) error {
+	expr := scope.uniqueKeyExpr()
+	tag, err := db.Exec(ctx,
+		`UPDATE shorten_urls SET unique_key = `+expr+` WHERE unique_key IS DISTINCT FROM `+expr+`;`,
 	)
 	if err != nil {
-		return err
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == pgerrcode.UniqueViolation {
+			return fmt.Errorf("saved urls are not unique within scope %q: %w", scope, ErrConflict)
+		}
+		return fmt.Errorf("failed to update url unique keys: %w", err)
 	}
-	_, err = tx.Exec(ctx,
-		`CREATE TABLE IF NOT EXISTS shorten_urls (
-			original VARCHAR(65536) NOT NULL UNIQUE,
-			shorten VARCHAR(256) NOT NULL,
-			user_id INT REFERENCES users (id),
-			is_deleted BOOLEAN NOT NULL DEFAULT FALSE
-		);`,
-	)
-	if err != nil {
-		return err
+	if tag.RowsAffected() > 0 {
+		logger.Log.Infow("Url unique keys updated", "scope", scope, "count", tag.RowsAffected())
+	}
+	return nil
+}
+
+// uniqueKeyValue возвращает ключ уникальности ссылки для сохранения в БД (NULL, если ссылка не проверяется на уникальность).
+func (db *URLPgStore) uniqueKeyValue(canonical string, userID int) interface{} {
+	key := db.uniqueness.uniqueKey(canonical, userID)
+	if key == "" {
+		return nil
+	}
+	return key
+}
+
+// canonicalValue возвращает каноническую форму полной ссылки для сохранения в БД (NULL, если она совпадает с полной ссылкой).
+func canonicalValue(url ShortenURL) interface{} {
+	canonical := canonicalData(url.Original, url.Canonical)
+	if canonical == "" {
+		return nil
+	}
+	return canonical
+}
+
+// pgSequenceIDGenerator генерирует последовательные сокращенные ссылки из последовательности БД shorten_id_seq.
+type pgSequenceIDGenerator struct {
+	store  *URLPgStore
+	format IDFormat
+}
+
+// Generate возвращает следующее значение последовательности в виде сокращенной ссылки.
+func (g *pgSequenceIDGenerator) Generate(_ string, _ int) (string, error) {
+	var value uint64
+	if err := g.store.pool.QueryRow(g.store.ctx, `SELECT nextval('shorten_id_seq');`).Scan(&value); err != nil {
+		return "", fmt.Errorf("failed to get next id from sequence: %w", err)
 	}
-	return tx.Commit(ctx)
+	return encodeID(value, g.format.length(), g.format.alphabet())
 }
 
 // flushDelURLs go рутина, которая собирает ссылки на удаления и запускает функцию удаления из БД.
@@ -165,7 +272,8 @@ func (db *URLPgStore) flushDelURLs() {
 // executeDelBatch реализует удаление из БД один батч запросом.
 func (db *URLPgStore) executeDelBatch(ctx context.Context, delBatch []urlDelBatchData) {
 	batch := &pgx.Batch{}
-	stmt := "UPDATE shorten_urls SET is_deleted = TRUE WHERE shorten = @shorten AND user_id = @user_id;"
+	stmt := `UPDATE shorten_urls SET is_deleted = TRUE, deleted_at = now()
+		WHERE shorten = @shorten AND user_id = @user_id AND is_deleted = FALSE;`
 	for _, del := range delBatch {
 		for _, url := range del.urls {
 			args := pgx.NamedArgs{
@@ -181,9 +289,119 @@ func (db *URLPgStore) executeDelBatch(ctx context.Context, delBatch []urlDelBatc
 	}
 }
 
+// expireURLs - go рутина, помечающая в БД ссылки с истекшим сроком действия.
+// Запускает пометку каждые expireURLsInterval секунд.
+func (db *URLPgStore) expireURLs() {
+	ticker := time.NewTicker(expireURLsInterval * time.Second)
+	defer ticker.Stop()
+	defer db.wg.Done()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := db.markExpiredURLs(db.ctx); err != nil {
+				logger.Log.Errorw("Error in marking expired urls", "err", err)
+			}
+		case <-db.ctx.Done():
+			return
+		}
+	}
+}
+
+// markExpiredURLs помечает ссылки, срок действия которых истек.
+func (db *URLPgStore) markExpiredURLs(ctx context.Context) error {
+	tag, err := db.pool.Exec(ctx,
+		`UPDATE shorten_urls SET is_expired = TRUE
+			WHERE is_expired = FALSE AND is_deleted = FALSE AND expires_at <= now();`,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to mark expired urls: %w", err)
+	}
+	if tag.RowsAffected() > 0 {
+		logger.Log.Debugw("Marked expired urls", "count", tag.RowsAffected())
+	}
+	return nil
+}
+
+// purgeURLs - go рутина, окончательно удаляющая из БД ссылки с истекшим сроком хранения после удаления.
+// Запускает удаление каждые purgeURLsInterval секунд.
+func (db *URLPgStore) purgeURLs() {
+	ticker := time.NewTicker(purgeURLsInterval * time.Second)
+	defer ticker.Stop()
+	defer db.wg.Done()
+
+	for {
+		select {
+		case <-ticker.C:
+			count, err := db.purgeDeletedURLs(db.ctx, time.Now().Add(-db.deletedRetention))
+			if err != nil {
+				logger.Log.Errorw("Error in purging deleted urls", "err", err)
+				continue
+			}
+			if count > 0 {
+				logger.Log.Infow("Purged deleted urls", "count", count)
+			}
+		case <-db.ctx.Done():
+			return
+		}
+	}
+}
+
+// purgeDeletedURLs окончательно удаляет ссылки, удаленные до момента deletedBefore, вместе с их переходами.
+// Возвращает количество удаленных ссылок.
+func (db *URLPgStore) purgeDeletedURLs(ctx context.Context, deletedBefore time.Time) (int, error) {
+	tx, err := db.pool.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var count int
+	row := tx.QueryRow(ctx,
+		`WITH purged AS (
+			DELETE FROM shorten_urls WHERE is_deleted = TRUE AND deleted_at <= $1 RETURNING shorten
+		), purged_clicks AS (
+			DELETE FROM clicks WHERE shorten IN (SELECT shorten FROM purged)
+		), purged_tags AS (
+			DELETE FROM url_tags WHERE shorten IN (SELECT shorten FROM purged)
+		)
+		SELECT count(*) FROM purged;`,
+		deletedBefore,
+	)
+	if err = row.Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to purge deleted urls: %w", err)
+	}
+	if count == 0 {
+		return 0, nil
+	}
+	_, err = tx.Exec(ctx,
+		`INSERT INTO store_counters(name, value) VALUES ($1, $2)
+			ON CONFLICT (name) DO UPDATE SET value = store_counters.value + EXCLUDED.value;`,
+		purgedURLsCounter, count,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to update purged urls counter: %w", err)
+	}
+	if err = tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("failed to commit purge of deleted urls: %w", err)
+	}
+	return count, nil
+}
+
+// expiresAtValue возвращает значение срока действия ссылки для сохранения в БД (NULL для бессрочных ссылок).
+func expiresAtValue(expiresAt time.Time) interface{} {
+	if expiresAt.IsZero() {
+		return nil
+	}
+	return expiresAt
+}
+
 // SaveURL сохраняет сокращенную ссылку.
-func (db *URLPgStore) SaveURL(ctx context.Context, url string, userID int) (string, error) {
-	id := utils.NewRandomString(urlIDLength)
+// Если задан url.Shorten, то он используется в качестве сокращенной ссылки (алиаса).
+func (db *URLPgStore) SaveURL(ctx context.Context, url ShortenURL, userID int) (string, error) {
+	if url.Shorten != "" && !isValidAlias(db.aliasReg, url.Shorten) {
+		return "", ErrInvalidAlias
+	}
 
 	// оставляем возможность сохранять url неавторизованными пользователями
 	var userIDValue interface{}
@@ -192,26 +410,55 @@ func (db *URLPgStore) SaveURL(ctx context.Context, url string, userID int) (stri
 	} else {
 		userIDValue = userID
 	}
+	uniqueKey := db.uniqueKeyValue(url.canonicalURL(), userID)
 
-	_, err := db.pool.Exec(ctx,
-		"INSERT INTO shorten_urls(original, shorten, user_id) VALUES($1, $2, $3);",
-		url, id, userIDValue,
-	)
+	// Ссылка и ее теги сохраняются в одной транзакции
+	tx, err := db.pool.Begin(ctx)
 	if err != nil {
-		var pgErr *pgconn.PgError
-		if errors.As(err, &pgErr) && pgErr.Code == pgerrcode.UniqueViolation {
+		return "", fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	for attempt := 0; attempt < maxIDAttempts; attempt++ {
+		id := url.Shorten
+		if id == "" {
+			if id, err = db.idGen.Generate(url.Original, attempt); err != nil {
+				return "", err
+			}
+		}
+		tag, err := tx.Exec(ctx, insertURLStmt, url.Original, id, userIDValue, expiresAtValue(url.ExpiresAt),
+			url.MaxClicks, url.PasswordHash, uniqueKey, canonicalValue(url))
+		if err != nil {
+			var pgErr *pgconn.PgError
+			if !errors.As(err, &pgErr) || pgErr.Code != pgerrcode.UniqueViolation {
+				return "", fmt.Errorf("failed to insert record to db: %w", err)
+			}
+			// Транзакция прервана ошибкой, уже сохраненная ссылка читается вне ее
+			tx.Rollback(ctx)
 			row := db.pool.QueryRow(ctx,
-				`SELECT shorten FROM shorten_urls WHERE original = $1`,
-				url,
+				`SELECT shorten FROM shorten_urls WHERE unique_key = $1`,
+				uniqueKey,
 			)
 			if err = row.Scan(&id); err != nil {
 				return "", fmt.Errorf("failed to select existing url from db after unique conflict: %w", err)
 			}
 			return id, ErrConflict
 		}
-		return "", fmt.Errorf("failed to insert record to db: %w", err)
+		if tag.RowsAffected() > 0 {
+			if err = insertTags(ctx, tx, id, url.Tags); err != nil {
+				return "", err
+			}
+			if err = tx.Commit(ctx); err != nil {
+				return "", fmt.Errorf("failed to commit url: %w", err)
+			}
+			return id, nil
+		}
+		if url.Shorten != "" {
+			return "", ErrAliasConflict
+		}
+		logger.Log.Debugw("Generated short url id already exists, retrying", "id", id, "attempt", attempt)
 	}
-	return id, nil
+	return "", ErrIDGeneration
 }
 
 // SaveBatchURL сохраняет массив сокращенных ссылок.
@@ -223,49 +470,148 @@ func (db *URLPgStore) SaveBatchURL(ctx context.Context, urls []ShortenURL, userI
 	} else {
 		userIDValue = userID
 	}
+	for _, url := range urls {
+		if url.Shorten != "" && !isValidAlias(db.aliasReg, url.Shorten) {
+			return ErrInvalidAlias
+		}
+	}
 	batch := &pgx.Batch{}
-	stmt := "INSERT INTO shorten_urls(original, shorten, user_id) VALUES(@original, @shorten, @user_id);"
+	isAlias := make([]bool, len(urls))
 	for i, url := range urls {
-		id := utils.NewRandomString(urlIDLength)
-		urls[i].Shorten = id
-		args := pgx.NamedArgs{
-			"original": url.Original,
-			"shorten":  id,
-			"user_id":  userIDValue,
+		isAlias[i] = url.Shorten != ""
+		if !isAlias[i] {
+			id, err := db.idGen.Generate(url.Original, 0)
+			if err != nil {
+				return err
+			}
+			urls[i].Shorten = id
 		}
-		batch.Queue(stmt, args)
+		batch.Queue(insertURLStmt, url.Original, urls[i].Shorten, userIDValue, expiresAtValue(url.ExpiresAt),
+			url.MaxClicks, url.PasswordHash, db.uniqueKeyValue(url.canonicalURL(), userID), canonicalValue(url))
 	}
-	err := db.pool.SendBatch(ctx, batch).Close()
+
+	// Сохраняем в транзакции, так как при занятой сокращенной ссылке запрос не вернет ошибку
+	tx, err := db.pool.Begin(ctx)
 	if err != nil {
-		var pgErr *pgconn.PgError
-		if errors.As(err, &pgErr) && pgErr.Code == pgerrcode.UniqueViolation {
-			return ErrConflict
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var collisions []int
+	results := tx.SendBatch(ctx, batch)
+	for i := range urls {
+		tag, execErr := results.Exec()
+		if execErr != nil {
+			results.Close()
+			var pgErr *pgconn.PgError
+			if errors.As(execErr, &pgErr) && pgErr.Code == pgerrcode.UniqueViolation {
+				return ErrConflict
+			}
+			return fmt.Errorf("failed to save batch of urls: %w", execErr)
+		}
+		if tag.RowsAffected() == 0 {
+			if isAlias[i] {
+				results.Close()
+				return ErrAliasConflict
+			}
+			logger.Log.Debugw("Generated short url id already exists, retrying", "id", urls[i].Shorten, "attempt", 0)
+			collisions = append(collisions, i)
 		}
+	}
+	if err = results.Close(); err != nil {
 		return fmt.Errorf("failed to save batch of urls: %w", err)
 	}
 
+	// Для ссылок, сгенерированный id которых оказался занят, повторяем генерацию
+	for _, i := range collisions {
+		if err = db.retryInsertURL(ctx, tx, &urls[i], userID); err != nil {
+			return err
+		}
+	}
+	for _, url := range urls {
+		if err = insertTags(ctx, tx, url.Shorten, url.Tags); err != nil {
+			return err
+		}
+	}
+	if err = tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to commit batch of urls: %w", err)
+	}
 	return nil
 }
 
-// GetURL возвращает полную ссылку по сокращенной.
-func (db *URLPgStore) GetURL(ctx context.Context, id string) (string, error) {
-	row := db.pool.QueryRow(ctx,
-		`SELECT original, is_deleted FROM shorten_urls WHERE shorten = $1`,
-		id,
-	)
+// retryInsertURL повторяет сохранение ссылки в транзакции с новыми сгенерированными id.
+func (db *URLPgStore) retryInsertURL(ctx context.Context, tx pgx.Tx, url *ShortenURL, userID int) error {
+	var userIDValue interface{}
+	if userID != 0 {
+		userIDValue = userID
+	}
+	for attempt := 1; attempt < maxIDAttempts; attempt++ {
+		id, err := db.idGen.Generate(url.Original, attempt)
+		if err != nil {
+			return err
+		}
+		tag, err := tx.Exec(ctx, insertURLStmt, url.Original, id, userIDValue, expiresAtValue(url.ExpiresAt),
+			url.MaxClicks, url.PasswordHash, db.uniqueKeyValue(url.canonicalURL(), userID), canonicalValue(*url))
+		if err != nil {
+			var pgErr *pgconn.PgError
+			if errors.As(err, &pgErr) && pgErr.Code == pgerrcode.UniqueViolation {
+				return ErrConflict
+			}
+			return fmt.Errorf("failed to save batch of urls: %w", err)
+		}
+		if tag.RowsAffected() > 0 {
+			url.Shorten = id
+			return nil
+		}
+		logger.Log.Debugw("Generated short url id already exists, retrying", "id", id, "attempt", attempt)
+	}
+	return ErrIDGeneration
+}
 
-	var url string
-	var isDeleted bool
-	if err := row.Scan(&url, &isDeleted); err != nil {
+// GetURL возвращает полную ссылку по сокращенной (если ссылка не найдена, то без учета регистра, см. IDFormat.fold)
+// и сокращенную ссылку, под которой она сохранена. Счетчик переходов ведется только для ссылок с лимитом переходов и увеличивается одним запросом,
+// поэтому лимит не может быть превышен при параллельных запросах.
+// Для защищенных ссылок счетчик увеличивается только после проверки пароля.
+func (db *URLPgStore) GetURL(ctx context.Context, id string, password string) (string, string, error) {
+	var url, passwordHash string
+	var isDeleted, isExpired bool
+	var maxClicks int
+	selectURL := func(id string) error {
+		return db.pool.QueryRow(ctx, getURLStmt, id).Scan(&url, &isDeleted, &isExpired, &passwordHash, &maxClicks)
+	}
+	err := selectURL(id)
+	if folded := db.idFormat.fold(id); errors.Is(err, pgx.ErrNoRows) && folded != id {
+		id = folded
+		err = selectURL(id)
+	}
+	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
-			return "", nil
+			return "", "", nil
 		}
-		return "", fmt.Errorf("failed to select url from db: %w", err)
+		return "", "", fmt.Errorf("failed to select url from db: %w", err)
 	}
-	if isDeleted {
-		return "", ErrIsDeleted
+	switch {
+	case isDeleted:
+		return "", "", ErrIsDeleted
+	case isExpired:
+		return "", "", ErrExpired
+	}
+	if passwordHash != "" {
+		if err = checkPassword(passwordHash, password); err != nil {
+			return "", "", err
+		}
 	}
-	return url, nil
+	// Переход по ссылке без лимита переходов ничего не записывает в БД
+	if maxClicks == 0 {
+		return url, id, nil
+	}
+	if err = db.pool.QueryRow(ctx, clickURLStmt, id).Scan(&url); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", "", ErrClickLimit
+		}
+		return "", "", fmt.Errorf("failed to update url clicks in db: %w", err)
+	}
+	return url, id, nil
 }
 
 // CreateUser сохраняет нового пользователя.
@@ -297,51 +643,309 @@ func (db *URLPgStore) GetUser(ctx context.Context, id int) (*User, error) {
 	return &user, nil
 }
 
-// GetUserURLs возвращает все сохраненные ссылки пользователя.
-func (db *URLPgStore) GetUserURLs(ctx context.Context, userID int) ([]ShortenURL, error) {
+// GetUserURLs возвращает сохраненные ссылки пользователя, подходящие под фильтр.
+func (db *URLPgStore) GetUserURLs(ctx context.Context, userID int, filter UserURLsFilter) ([]ShortenURL, error) {
+	var userURLs []ShortenURL
+	err := db.forEachUserURL(ctx, userID, filter, func(url ShortenURL) error {
+		userURLs = append(userURLs, url)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return userURLs, nil
+}
+
+// ExportUserURLs передает функции fn по очереди все сохраненные ссылки пользователя.
+// Ссылки читаются из БД по мере обработки, не загружаясь в память целиком.
+func (db *URLPgStore) ExportUserURLs(ctx context.Context, userID int, fn func(url ShortenURL) error) error {
+	return db.forEachUserURL(ctx, userID, UserURLsFilter{}, fn)
+}
+
+// forEachUserURL передает функции fn по очереди ссылки пользователя, подходящие под фильтр.
+// Ошибка fn прерывает чтение ссылок и возвращается как есть.
+func (db *URLPgStore) forEachUserURL(
+	ctx context.Context, userID int, filter UserURLsFilter, fn func(url ShortenURL) error,
+) error {
 	if userID <= 0 {
-		return nil, errors.New("invalid user id")
+		return errors.New("invalid user id")
 	}
-	rows, err := db.pool.Query(ctx,
-		`SELECT original, shorten FROM shorten_urls WHERE is_deleted = FALSE AND user_id = $1`,
-		userID,
-	)
-	var userURLs []ShortenURL
+	query := `SELECT u.original, u.shorten, u.expires_at,
+			COALESCE(array_agg(t.tag ORDER BY t.tag) FILTER (WHERE t.tag IS NOT NULL), '{}')
+		FROM shorten_urls u LEFT JOIN url_tags t ON t.shorten = u.shorten
+		WHERE u.is_deleted = FALSE AND u.user_id = $1`
+	args := []any{userID}
+	if len(filter.Tags) > 0 {
+		query += ` AND u.shorten IN (
+			SELECT shorten FROM url_tags WHERE tag = ANY($2) GROUP BY shorten HAVING count(DISTINCT tag) = $3
+		)`
+		args = append(args, filter.Tags, len(filter.Tags))
+	}
+	if filter.Query != "" {
+		args = append(args, "%"+escapeLike(filter.Query)+"%")
+		query += fmt.Sprintf(` AND u.original ILIKE $%d`, len(args))
+	}
+	if filter.Domain != "" {
+		args = append(args, filter.Domain)
+		query += fmt.Sprintf(` AND u.domain = lower($%d)`, len(args))
+	}
+	// Сокращенные ссылки сравниваются побайтово (COLLATE "C"), как в хранилище в памяти,
+	// чтобы порядок страниц не зависел от правил сортировки БД
+	if filter.After != "" {
+		args = append(args, filter.After)
+		query += fmt.Sprintf(` AND u.shorten COLLATE "C" > $%d`, len(args))
+	}
+	query += ` GROUP BY u.original, u.shorten, u.expires_at ORDER BY u.shorten COLLATE "C"`
+	if filter.Limit > 0 {
+		args = append(args, filter.Limit)
+		query += fmt.Sprintf(` LIMIT $%d`, len(args))
+	}
+	rows, err := db.pool.Query(ctx, query, args...)
 	if err != nil {
-		return nil, fmt.Errorf("failed to select user urls from db: %w", err)
+		return fmt.Errorf("failed to select user urls from db: %w", err)
 	}
 	defer rows.Close()
 
 	for rows.Next() {
 		var shortenURL ShortenURL
-		if err = rows.Scan(&shortenURL.Original, &shortenURL.Shorten); err != nil {
-			return nil, fmt.Errorf("failed to read data from db url row: %w", err)
+		var expiresAt *time.Time
+		if err = rows.Scan(&shortenURL.Original, &shortenURL.Shorten, &expiresAt, &shortenURL.Tags); err != nil {
+			return fmt.Errorf("failed to read data from db url row: %w", err)
+		}
+		if expiresAt != nil {
+			shortenURL.ExpiresAt = *expiresAt
+		}
+		if err = fn(shortenURL); err != nil {
+			return err
 		}
-		userURLs = append(userURLs, shortenURL)
 	}
 	if err = rows.Err(); err != nil {
-		return nil, fmt.Errorf("failed to select user urls from db: %w", err)
+		return fmt.Errorf("failed to select user urls from db: %w", err)
+	}
+	return nil
+}
+
+// GetDeletedUserURLs возвращает удаленные ссылки пользователя.
+func (db *URLPgStore) GetDeletedUserURLs(ctx context.Context, userID int) ([]ShortenURL, error) {
+	if userID <= 0 {
+		return nil, errors.New("invalid user id")
+	}
+	rows, err := db.pool.Query(ctx,
+		`SELECT original, shorten, expires_at FROM shorten_urls WHERE is_deleted = TRUE AND user_id = $1`,
+		userID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to select deleted user urls from db: %w", err)
+	}
+	userURLs, err := pgx.CollectRows(rows, scanShortenURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to select deleted user urls from db: %w", err)
 	}
 	return userURLs, nil
 }
 
+// RestoreUserURLs восстанавливает удаленные ссылки пользователя.
+// Чужие и не удаленные ссылки пропускаются.
+func (db *URLPgStore) RestoreUserURLs(ctx context.Context, userID int, urls []string) error {
+	_, err := db.pool.Exec(ctx,
+		`UPDATE shorten_urls SET is_deleted = FALSE, deleted_at = NULL
+			WHERE shorten = ANY($1) AND user_id = $2 AND is_deleted = TRUE;`,
+		urls, userID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to restore user urls in db: %w", err)
+	}
+	return nil
+}
+
+// scanShortenURL читает ссылку из строки результата запроса (колонки original, shorten, expires_at).
+func scanShortenURL(row pgx.CollectableRow) (ShortenURL, error) {
+	var shortenURL ShortenURL
+	var expiresAt *time.Time
+	if err := row.Scan(&shortenURL.Original, &shortenURL.Shorten, &expiresAt); err != nil {
+		return shortenURL, fmt.Errorf("failed to read data from db url row: %w", err)
+	}
+	if expiresAt != nil {
+		shortenURL.ExpiresAt = *expiresAt
+	}
+	return shortenURL, nil
+}
+
+// escapeLike экранирует спецсимволы шаблона LIKE, чтобы строка искалась как есть.
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
+
+// insertTags сохраняет теги сокращенной ссылки.
+func insertTags(ctx context.Context, db pgExecer, id string, tags []string) error {
+	if len(tags) == 0 {
+		return nil
+	}
+	_, err := db.Exec(ctx,
+		`INSERT INTO url_tags(shorten, tag) SELECT $1, unnest($2::varchar[]) ON CONFLICT DO NOTHING;`,
+		id, tags,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to insert url tags to db: %w", err)
+	}
+	return nil
+}
+
+// UpdateURL изменяет полную ссылку и (или) теги сокращенной ссылки пользователя в одной транзакции.
+// Возвращает сохраненную ссылку после изменения.
+func (db *URLPgStore) UpdateURL(ctx context.Context, userID int, id string, update URLUpdate) (*ShortenURL, error) {
+	tx, err := db.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var row pgx.Row
+	if update.Original != "" {
+		url := ShortenURL{Original: update.Original, Canonical: update.Canonical}
+		row = tx.QueryRow(ctx,
+			`UPDATE shorten_urls SET original = $1, unique_key = $4, canonical = $5
+			WHERE shorten = $2 AND user_id = $3 AND is_deleted = FALSE
+			RETURNING original, ARRAY(SELECT tag FROM url_tags WHERE shorten = $2 ORDER BY tag);`,
+			update.Original, id, userID, db.uniqueKeyValue(url.canonicalURL(), userID), canonicalValue(url),
+		)
+	} else {
+		row = tx.QueryRow(ctx,
+			`SELECT original, ARRAY(SELECT tag FROM url_tags WHERE shorten = $1 ORDER BY tag)
+			FROM shorten_urls WHERE shorten = $1 AND user_id = $2 AND is_deleted = FALSE FOR UPDATE;`,
+			id, userID,
+		)
+	}
+	shortenURL := ShortenURL{Shorten: id}
+	if err = row.Scan(&shortenURL.Original, &shortenURL.Tags); err != nil {
+		var pgErr *pgconn.PgError
+		switch {
+		case errors.Is(err, pgx.ErrNoRows):
+			return nil, ErrNoData
+		case errors.As(err, &pgErr) && pgErr.Code == pgerrcode.UniqueViolation:
+			return nil, ErrConflict
+		}
+		return nil, fmt.Errorf("failed to update url in db: %w", err)
+	}
+	if update.UpdateTags {
+		if _, err = tx.Exec(ctx, `DELETE FROM url_tags WHERE shorten = $1;`, id); err != nil {
+			return nil, fmt.Errorf("failed to delete url tags from db: %w", err)
+		}
+		if err = insertTags(ctx, tx, id, update.Tags); err != nil {
+			return nil, err
+		}
+		shortenURL.Tags = update.Tags
+	}
+	if err = tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit url update: %w", err)
+	}
+	return &shortenURL, nil
+}
+
 // DeleteUserURLs удаляет сокращенные ссылки пользователя.
 func (db *URLPgStore) DeleteUserURLs(userID int, urls []string) error {
 	db.urlDelCh <- urlDelBatchData{userID: userID, urls: urls}
 	return nil
 }
 
+// SaveClick добавляет событие перехода по ссылке в очередь на сохранение.
+func (db *URLPgStore) SaveClick(event ClickEvent) error {
+	db.clickQueue.push(event)
+	return nil
+}
+
+// saveClicks сохраняет пачку событий переходов в БД одним батчем.
+func (db *URLPgStore) saveClicks(ctx context.Context, events []ClickEvent) error {
+	batch := &pgx.Batch{}
+	stmt := `INSERT INTO clicks(shorten, clicked_at, referrer, user_agent, ip) VALUES ($1, $2, $3, $4, $5);`
+	for _, event := range events {
+		batch.Queue(stmt, event.ShortURL, event.Time, event.Referrer, event.UserAgent, event.IP)
+	}
+	if err := db.pool.SendBatch(ctx, batch).Close(); err != nil {
+		return fmt.Errorf("failed to insert click events: %w", err)
+	}
+	return nil
+}
+
+// GetURLStats возвращает статистику переходов по ссылке пользователя.
+// События, еще не сохраненные из очереди, в статистике не учитываются.
+func (db *URLPgStore) GetURLStats(ctx context.Context, userID int, id string) (*URLStats, error) {
+	stats := URLStats{
+		ClicksPerDay:  []DayClicks{},
+		TopReferrers:  []TopValue{},
+		TopUserAgents: []TopValue{},
+	}
+	row := db.pool.QueryRow(ctx,
+		`SELECT count(c.id), count(DISTINCT c.ip) FROM shorten_urls u
+		LEFT JOIN clicks c ON c.shorten = u.shorten
+		WHERE u.shorten = $1 AND u.user_id = $2 AND u.is_deleted = FALSE
+		GROUP BY u.shorten;`,
+		id, userID,
+	)
+	if err := row.Scan(&stats.TotalClicks, &stats.UniqueVisitors); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNoData
+		}
+		return nil, fmt.Errorf("failed to select url stats from db: %w", err)
+	}
+
+	rows, err := db.pool.Query(ctx,
+		`SELECT to_char(clicked_at AT TIME ZONE 'UTC', 'YYYY-MM-DD') AS day, count(*) FROM clicks
+		WHERE shorten = $1 GROUP BY day ORDER BY day;`,
+		id,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to select url clicks per day from db: %w", err)
+	}
+	stats.ClicksPerDay, err = pgx.CollectRows(rows, func(row pgx.CollectableRow) (DayClicks, error) {
+		var day DayClicks
+		err := row.Scan(&day.Day, &day.Clicks)
+		return day, err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to select url clicks per day from db: %w", err)
+	}
+
+	if stats.TopReferrers, err = db.selectTopClickValues(ctx, "referrer", id); err != nil {
+		return nil, err
+	}
+	if stats.TopUserAgents, err = db.selectTopClickValues(ctx, "user_agent", id); err != nil {
+		return nil, err
+	}
+	return &stats, nil
+}
+
+// selectTopClickValues возвращает самые частые непустые значения колонки column таблицы clicks для ссылки.
+func (db *URLPgStore) selectTopClickValues(ctx context.Context, column string, id string) ([]TopValue, error) {
+	rows, err := db.pool.Query(ctx,
+		fmt.Sprintf(`SELECT %[1]s, count(*) AS cnt FROM clicks
+		WHERE shorten = $1 AND %[1]s <> '' GROUP BY %[1]s ORDER BY cnt DESC, %[1]s LIMIT $2;`, column),
+		id, urlStatsTopSize,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to select top %s from db: %w", column, err)
+	}
+	top, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (TopValue, error) {
+		var value TopValue
+		err := row.Scan(&value.Value, &value.Count)
+		return value, err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to select top %s from db: %w", column, err)
+	}
+	return top, nil
+}
+
 // IsValidID проверяет валидность сокращенной ссылки (проверка формата).
+// Валидными считаются сгенерированные ссылки текущего и прежних форматов, а также пользовательские алиасы.
 func (db *URLPgStore) IsValidID(id string) bool {
-	regStr := fmt.Sprintf(`^[a-zA-Z0-9]{%d}$`, urlIDLength)
-	validIDReg := regexp.MustCompile(regStr)
-	return validIDReg.MatchString(id)
+	return db.idFormat.isValid(id) || isValidAlias(db.aliasReg, id)
 }
 
 // GetURLsCount возвращает количество сокращенных ссылок в БД.
 func (db *URLPgStore) GetURLsCount(ctx context.Context) (int, error) {
 	row := db.pool.QueryRow(ctx,
-		`SELECT count(*) FROM shorten_urls WHERE is_deleted = false;`,
+		`SELECT count(*) FROM shorten_urls WHERE is_deleted = false AND is_expired = false;`,
 	)
 	var count int
 	if err := row.Scan(&count); err != nil {
@@ -362,6 +966,193 @@ func (db *URLPgStore) GetUsersCount(ctx context.Context) (int, error) {
 	return count, nil
 }
 
+// GetPurgedURLsCount возвращает количество окончательно удаленных из БД ссылок.
+func (db *URLPgStore) GetPurgedURLsCount(ctx context.Context) (int, error) {
+	row := db.pool.QueryRow(ctx,
+		`SELECT COALESCE((SELECT value FROM store_counters WHERE name = $1), 0);`,
+		purgedURLsCounter,
+	)
+	var count int
+	if err := row.Scan(&count); err != nil {
+		return count, fmt.Errorf("failed to select purged urls count from db: %w", err)
+	}
+	return count, nil
+}
+
+// DumpUsers передает функции fn по очереди всех пользователей БД (по возрастанию ID).
+func (db *URLPgStore) DumpUsers(ctx context.Context, fn func(user User) error) error {
+	rows, err := db.pool.Query(ctx, `SELECT id FROM users ORDER BY id`)
+	if err != nil {
+		return fmt.Errorf("failed to select users from db: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var user User
+		if err = rows.Scan(&user.ID); err != nil {
+			return fmt.Errorf("failed to read data from db user row: %w", err)
+		}
+		if err = fn(user); err != nil {
+			return err
+		}
+	}
+	if err = rows.Err(); err != nil {
+		return fmt.Errorf("failed to select users from db: %w", err)
+	}
+	return nil
+}
+
+// DumpURLs передает функции fn по очереди все ссылки БД, включая удаленные (по возрастанию сокращенной ссылки).
+// Ссылки читаются из БД по мере обработки, не загружаясь в память целиком.
+func (db *URLPgStore) DumpURLs(ctx context.Context, fn func(url URLRecord) error) error {
+	rows, err := db.pool.Query(ctx,
+		`SELECT u.original, COALESCE(u.canonical, ''), u.shorten, COALESCE(u.user_id, 0), u.is_deleted, u.deleted_at,
+			u.expires_at, u.is_expired, u.max_clicks, u.clicks, u.password_hash,
+			ARRAY(SELECT t.tag FROM url_tags t WHERE t.shorten = u.shorten ORDER BY t.tag)
+		FROM shorten_urls u ORDER BY u.shorten`,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to select urls from db: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var url URLRecord
+		var deletedAt, expiresAt *time.Time
+		err = rows.Scan(&url.Original, &url.Canonical, &url.Shorten, &url.UserID, &url.IsDeleted, &deletedAt,
+			&expiresAt, &url.IsExpired, &url.MaxClicks, &url.Clicks, &url.PasswordHash, &url.Tags)
+		if err != nil {
+			return fmt.Errorf("failed to read data from db url row: %w", err)
+		}
+		if deletedAt != nil {
+			url.DeletedAt = *deletedAt
+		}
+		if expiresAt != nil {
+			url.ExpiresAt = *expiresAt
+		}
+		if err = fn(url); err != nil {
+			return err
+		}
+	}
+	if err = rows.Err(); err != nil {
+		return fmt.Errorf("failed to select urls from db: %w", err)
+	}
+	return nil
+}
+
+// LoadUsers сохраняет пользователей с их ID. Уже существующие пользователи пропускаются.
+// Последовательность ID сдвигается, чтобы новые пользователи не получили ID перенесенных.
+func (db *URLPgStore) LoadUsers(ctx context.Context, users []User) error {
+	if len(users) == 0 {
+		return nil
+	}
+	ids := make([]int, 0, len(users))
+	for _, user := range users {
+		if user.ID <= 0 {
+			return errors.New("invalid user id")
+		}
+		ids = append(ids, user.ID)
+	}
+	_, err := db.pool.Exec(ctx,
+		`INSERT INTO users (id) SELECT unnest($1::int[]) ON CONFLICT DO NOTHING;`,
+		ids,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to insert users to db: %w", err)
+	}
+	_, err = db.pool.Exec(ctx,
+		`SELECT setval(pg_get_serial_sequence('users', 'id'), (SELECT COALESCE(max(id), 0) + 1 FROM users), false);`,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update users id sequence: %w", err)
+	}
+	return nil
+}
+
+// LoadURLs сохраняет ссылки с их сокращенными ссылками в одной транзакции.
+// Уже сохраненные ссылки пропускаются, занятая другой ссылкой сокращенная ссылка - конфликт (ErrConflict).
+// Возвращает количество сохраненных ссылок.
+func (db *URLPgStore) LoadURLs(ctx context.Context, urls []URLRecord) (int, error) {
+	if len(urls) == 0 {
+		return 0, nil
+	}
+	ids := make([]string, 0, len(urls))
+	for _, url := range urls {
+		ids = append(ids, url.Shorten)
+	}
+
+	tx, err := db.pool.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	rows, err := tx.Query(ctx,
+		`SELECT shorten, original, COALESCE(user_id, 0) FROM shorten_urls WHERE shorten = ANY($1)`,
+		ids,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to select existing urls from db: %w", err)
+	}
+	existing := make(map[string]loadedURL, len(ids))
+	var id string
+	var saved loadedURL
+	_, err = pgx.ForEachRow(rows, []any{&id, &saved.original, &saved.userID}, func() error {
+		existing[id] = saved
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to select existing urls from db: %w", err)
+	}
+
+	var loaded int
+	for _, url := range urls {
+		if saved, ok := existing[url.Shorten]; ok {
+			if saved != (loadedURL{original: url.Original, userID: url.UserID}) {
+				return 0, errShortenTaken(url.Shorten)
+			}
+			continue
+		}
+		// оставляем возможность сохранять url неавторизованными пользователями
+		var userIDValue interface{}
+		if url.UserID != 0 {
+			userIDValue = url.UserID
+		}
+		var deletedAtValue interface{}
+		if url.IsDeleted {
+			deletedAtValue = url.DeletedAt
+			if url.DeletedAt.IsZero() {
+				deletedAtValue = time.Now()
+			}
+		}
+		_, err = tx.Exec(ctx,
+			`INSERT INTO shorten_urls(original, shorten, user_id, is_deleted, deleted_at,
+				expires_at, is_expired, max_clicks, clicks, password_hash, unique_key, canonical)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12);`,
+			url.Original, url.Shorten, userIDValue, url.IsDeleted, deletedAtValue,
+			expiresAtValue(url.ExpiresAt), url.IsExpired, url.MaxClicks, url.Clicks, url.PasswordHash,
+			db.uniqueKeyValue(url.canonicalURL(), url.UserID), canonicalValue(url.ShortenURL),
+		)
+		if err != nil {
+			var pgErr *pgconn.PgError
+			if errors.As(err, &pgErr) && pgErr.Code == pgerrcode.UniqueViolation {
+				return 0, fmt.Errorf("failed to load url %s: %w", url.Shorten, ErrConflict)
+			}
+			return 0, fmt.Errorf("failed to insert url to db: %w", err)
+		}
+		if err = insertTags(ctx, tx, url.Shorten, url.Tags); err != nil {
+			return 0, err
+		}
+		// В батче могут повторяться сокращенные ссылки, сохраняется первая из них
+		existing[url.Shorten] = loadedURL{original: url.Original, userID: url.UserID}
+		loaded++
+	}
+	if err = tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("failed to commit loaded urls: %w", err)
+	}
+	return loaded, nil
+}
+
 // Ping проверяет связь с БД.
 func (db *URLPgStore) Ping(ctx context.Context) error {
 	err := db.pool.Ping(ctx)
@@ -373,6 +1164,7 @@ func (db *URLPgStore) Close() error {
 	logger.Log.Debug("Closing pg store...")
 	db.ctxCancel()
 	close(db.urlDelCh)
+	db.clickQueue.close()
 	db.wg.Wait()
 	db.pool.Close()
 	return nil
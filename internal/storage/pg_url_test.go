@@ -4,7 +4,10 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"os"
+	"strconv"
 	"testing"
+	"time"
 
 	"github.com/jackc/pgerrcode"
 	"github.com/jackc/pgx/v5"
@@ -12,6 +15,7 @@ import (
 	"github.com/pashagolub/pgxmock/v4"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
 )
 
 func TestPgGetURL(t *testing.T) {
@@ -24,6 +28,8 @@ func TestPgGetURL(t *testing.T) {
 	urlPgStore := &URLPgStore{
 		pool: mock,
 	}
+	passwordHash, err := bcrypt.GenerateFromPassword([]byte("secret"), bcrypt.MinCost)
+	require.NoError(t, err)
 
 	type dbRes struct {
 		rows []any
@@ -36,16 +42,31 @@ func TestPgGetURL(t *testing.T) {
 	}
 
 	tests := []struct {
-		name  string
-		urlID string
-		dbRes *dbRes
-		want  want
+		name     string
+		urlID    string
+		password string
+		dbSelect *dbRes
+		dbUpdate *dbRes
+		want     want
 	}{
 		{
-			name:  "Успешно получен url",
+			name:  "Успешно получен url без лимита переходов",
 			urlID: "shortenURL",
-			dbRes: &dbRes{
-				rows: []any{"some", false},
+			dbSelect: &dbRes{
+				rows: []any{"some", false, false, "", 0},
+			},
+			want: want{
+				url: "some",
+			},
+		},
+		{
+			name:  "Успешно получен url с лимитом переходов",
+			urlID: "shortenURL",
+			dbSelect: &dbRes{
+				rows: []any{"some", false, false, "", 2},
+			},
+			dbUpdate: &dbRes{
+				rows: []any{"some"},
 			},
 			want: want{
 				url: "some",
@@ -54,7 +75,7 @@ func TestPgGetURL(t *testing.T) {
 		{
 			name:  "Ссылка не найдена",
 			urlID: "shortenURL",
-			dbRes: &dbRes{
+			dbSelect: &dbRes{
 				err: pgx.ErrNoRows,
 			},
 			want: want{
@@ -64,18 +85,92 @@ func TestPgGetURL(t *testing.T) {
 		{
 			name:  "Ссылка удалена",
 			urlID: "shortenURL",
-			dbRes: &dbRes{
-				rows: []any{"some", true},
+			dbSelect: &dbRes{
+				rows: []any{"some", true, false, "", 0},
 			},
 			want: want{
 				url: "",
 				err: ErrIsDeleted,
 			},
 		},
+		{
+			name:  "Истек срок действия ссылки",
+			urlID: "shortenURL",
+			dbSelect: &dbRes{
+				rows: []any{"some", false, true, "", 0},
+			},
+			want: want{
+				url: "",
+				err: ErrExpired,
+			},
+		},
+		{
+			name:  "Исчерпан лимит переходов",
+			urlID: "shortenURL",
+			dbSelect: &dbRes{
+				rows: []any{"some", false, false, "", 2},
+			},
+			dbUpdate: &dbRes{
+				err: pgx.ErrNoRows,
+			},
+			want: want{
+				url: "",
+				err: ErrClickLimit,
+			},
+		},
+		{
+			name:  "Требуется пароль",
+			urlID: "shortenURL",
+			dbSelect: &dbRes{
+				rows: []any{"some", false, false, string(passwordHash), 1},
+			},
+			want: want{
+				url: "",
+				err: ErrPasswordRequired,
+			},
+		},
+		{
+			name:     "Неверный пароль",
+			urlID:    "shortenURL",
+			password: "wrong",
+			dbSelect: &dbRes{
+				rows: []any{"some", false, false, string(passwordHash), 1},
+			},
+			want: want{
+				url: "",
+				err: ErrWrongPassword,
+			},
+		},
+		{
+			name:     "Верный пароль",
+			urlID:    "shortenURL",
+			password: "secret",
+			dbSelect: &dbRes{
+				rows: []any{"some", false, false, string(passwordHash), 0},
+			},
+			want: want{
+				url: "some",
+			},
+		},
+		{
+			name:     "Верный пароль, но исчерпан лимит переходов",
+			urlID:    "shortenURL",
+			password: "secret",
+			dbSelect: &dbRes{
+				rows: []any{"some", false, false, string(passwordHash), 1},
+			},
+			dbUpdate: &dbRes{
+				err: pgx.ErrNoRows,
+			},
+			want: want{
+				url: "",
+				err: ErrClickLimit,
+			},
+		},
 		{
 			name:  "Ошибка БД",
 			urlID: "shortenURL",
-			dbRes: &dbRes{
+			dbSelect: &dbRes{
 				err: errors.New("db error"),
 			},
 			want: want{
@@ -83,27 +178,92 @@ func TestPgGetURL(t *testing.T) {
 				err: fmt.Errorf("failed to select url from db: %w", errors.New("db error")),
 			},
 		},
+		{
+			name:  "Ошибка БД при увеличении счетчика переходов",
+			urlID: "shortenURL",
+			dbSelect: &dbRes{
+				rows: []any{"some", false, false, "", 2},
+			},
+			dbUpdate: &dbRes{
+				err: errors.New("db error"),
+			},
+			want: want{
+				url: "",
+				err: fmt.Errorf("failed to update url clicks in db: %w", errors.New("db error")),
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if tt.dbRes != nil {
-				mockExpectQuery := mock.ExpectQuery("SELECT .+ FROM shorten_urls WHERE .+").WithArgs(tt.urlID)
-				if tt.dbRes.err != nil {
-					mockExpectQuery.WillReturnError(tt.dbRes.err)
-				} else if tt.dbRes.rows != nil {
-					mockExpectQuery.WillReturnRows(mock.NewRows([]string{"original", "is_deleted"}).
-						AddRow(tt.dbRes.rows...))
+			selectExpectQuery := mock.ExpectQuery("SELECT .+ FROM shorten_urls WHERE .+").WithArgs(tt.urlID)
+			if tt.dbSelect.err != nil {
+				selectExpectQuery.WillReturnError(tt.dbSelect.err)
+			} else {
+				selectExpectQuery.WillReturnRows(mock.NewRows(
+					[]string{"original", "is_deleted", "is_expired", "password_hash", "max_clicks"}).
+					AddRow(tt.dbSelect.rows...))
+			}
+			if tt.dbUpdate != nil {
+				updateExpectQuery := mock.ExpectQuery("UPDATE shorten_urls SET clicks = clicks \\+ 1").WithArgs(tt.urlID)
+				if tt.dbUpdate.err != nil {
+					updateExpectQuery.WillReturnError(tt.dbUpdate.err)
+				} else {
+					updateExpectQuery.WillReturnRows(mock.NewRows([]string{"original"}).AddRow(tt.dbUpdate.rows...))
 				}
 			}
-			url, storeErr := urlPgStore.GetURL(context.TODO(), tt.urlID)
+			url, _, storeErr := urlPgStore.GetURL(context.TODO(), tt.urlID, tt.password)
 			assert.Equal(t, tt.want.url, url)
 			if tt.want.err != nil {
 				assert.Equal(t, tt.want.err, storeErr)
+			} else {
+				require.NoError(t, storeErr)
 			}
+			require.NoError(t, mock.ExpectationsWereMet())
 		})
 	}
 }
 
+func TestPgGetURLCaseInsensitive(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	urlPgStore := &URLPgStore{
+		pool:     mock,
+		idFormat: IDFormat{Alphabet: "abcdefghijklmnopqrstuvwxyz0123456789"},
+	}
+	columns := []string{"original", "is_deleted", "is_expired", "password_hash", "max_clicks"}
+	mock.ExpectQuery("SELECT .+ FROM shorten_urls WHERE .+").WithArgs("ABCD1234").WillReturnError(pgx.ErrNoRows)
+	mock.ExpectQuery("SELECT .+ FROM shorten_urls WHERE .+").WithArgs("abcd1234").
+		WillReturnRows(mock.NewRows(columns).AddRow("some", false, false, "", 0))
+	url, shortID, err := urlPgStore.GetURL(context.TODO(), "ABCD1234", "")
+	require.NoError(t, err)
+	assert.Equal(t, "some", url)
+	assert.Equal(t, "abcd1234", shortID)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPgSequenceIDGenerator(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	gen := &pgSequenceIDGenerator{
+		store:  &URLPgStore{pool: mock, ctx: context.Background()},
+		format: IDFormat{},
+	}
+	mock.ExpectQuery("SELECT nextval\\('shorten_id_seq'\\)").
+		WillReturnRows(mock.NewRows([]string{"nextval"}).AddRow(uint64(62)))
+	id, err := gen.Generate("http://example.com", 0)
+	require.NoError(t, err)
+	assert.Equal(t, "00000010", id)
+
+	mock.ExpectQuery("SELECT nextval").WillReturnError(errors.New("db error"))
+	_, err = gen.Generate("http://example.com", 0)
+	assert.Error(t, err)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
 func TestPgSaveURL(t *testing.T) {
 	mock, err := pgxmock.NewPool()
 	if err != nil {
@@ -111,27 +271,35 @@ func TestPgSaveURL(t *testing.T) {
 	}
 	defer mock.Close()
 
+	aliasReg, err := compileAliasPattern("")
+	require.NoError(t, err)
 	urlPgStore := &URLPgStore{
-		pool: mock,
+		pool:     mock,
+		aliasReg: aliasReg,
+		idGen:    NewRandomIDGenerator(IDFormat{}),
 	}
 
 	type dbRes struct {
 		rows []any
 		err  error
 	}
-
 	type want struct {
 		err   error
 		urlID string
 	}
 
 	tests := []struct {
-		name     string
-		url      string
-		userID   int
-		dbInsert *dbRes
-		dbSelect *dbRes
-		want     want
+		name      string
+		url       string
+		alias     string
+		expiresAt time.Time
+		userID    int
+		tags      []string
+		noInsert  bool
+		collided  bool
+		dbInsert  *dbRes
+		dbSelect  *dbRes
+		want      want
 	}{
 		{
 			name:     "Успешное сохранение ссылки",
@@ -139,12 +307,26 @@ func TestPgSaveURL(t *testing.T) {
 			userID:   1,
 			dbInsert: &dbRes{},
 		},
+		{
+			name:     "Успешное сохранение ссылки с тегами",
+			url:      "url_to_save",
+			userID:   1,
+			tags:     []string{"news"},
+			dbInsert: &dbRes{},
+		},
+		{
+			name:      "Успешное сохранение ссылки со сроком действия",
+			url:       "url_to_save",
+			expiresAt: time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC),
+			userID:    1,
+			dbInsert:  &dbRes{},
+		},
 		{
 			name:   "Ссылка уже была сохранена",
 			url:    "url_to_save",
 			userID: 1,
 			dbInsert: &dbRes{
-				err: &pgconn.PgError{Code: pgerrcode.UniqueViolation},
+				err: &pgconn.PgError{Code: pgerrcode.UniqueViolation, ConstraintName: "shorten_urls_original_key"},
 			},
 			dbSelect: &dbRes{
 				rows: []any{"shortURL"},
@@ -154,6 +336,43 @@ func TestPgSaveURL(t *testing.T) {
 				urlID: "shortURL",
 			},
 		},
+		{
+			name:     "Сгенерированная ссылка уже занята (повторная генерация)",
+			url:      "url_to_save",
+			userID:   1,
+			collided: true,
+			dbInsert: &dbRes{},
+		},
+		{
+			name:     "Успешное сохранение ссылки с алиасом",
+			url:      "url_to_save",
+			alias:    "my-alias",
+			userID:   1,
+			dbInsert: &dbRes{},
+			want: want{
+				urlID: "my-alias",
+			},
+		},
+		{
+			name:     "Алиас уже занят",
+			url:      "url_to_save",
+			alias:    "my-alias",
+			userID:   1,
+			dbInsert: &dbRes{},
+			noInsert: true,
+			want: want{
+				err: ErrAliasConflict,
+			},
+		},
+		{
+			name:   "Некорректный алиас",
+			url:    "url_to_save",
+			alias:  "bad alias!",
+			userID: 1,
+			want: want{
+				err: ErrInvalidAlias,
+			},
+		},
 		{
 			name:   "Ошибка БД при сохранении",
 			url:    "url_to_save",
@@ -171,7 +390,7 @@ func TestPgSaveURL(t *testing.T) {
 			url:    "url_to_save",
 			userID: 1,
 			dbInsert: &dbRes{
-				err: &pgconn.PgError{Code: pgerrcode.UniqueViolation},
+				err: &pgconn.PgError{Code: pgerrcode.UniqueViolation, ConstraintName: "shorten_urls_original_key"},
 			},
 			dbSelect: &dbRes{
 				err: errors.New("db error"),
@@ -185,12 +404,34 @@ func TestPgSaveURL(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			insertExpectExec := mock.ExpectExec("INSERT INTO shorten_urls").
-				WithArgs(tt.url, pgxmock.AnyArg(), tt.userID)
-			if tt.dbInsert.err != nil {
-				insertExpectExec.WillReturnError(tt.dbInsert.err)
-			} else {
-				insertExpectExec.WillReturnResult(pgxmock.NewResult("INSERT", 1))
+			if tt.dbInsert != nil {
+				mock.ExpectBegin()
+			}
+			if tt.collided {
+				// Занятая сокращенная ссылка не сохраняется без ошибки
+				mock.ExpectExec("INSERT INTO shorten_urls.+ON CONFLICT \\(shorten\\) DO NOTHING").
+					WithArgs(tt.url, pgxmock.AnyArg(), tt.userID, expiresAtValue(tt.expiresAt), 0, "", tt.url, nil).
+					WillReturnResult(pgxmock.NewResult("INSERT", 0))
+			}
+			if tt.dbInsert != nil {
+				insertExpectExec := mock.ExpectExec("INSERT INTO shorten_urls.+ON CONFLICT \\(shorten\\) DO NOTHING").
+					WithArgs(tt.url, pgxmock.AnyArg(), tt.userID, expiresAtValue(tt.expiresAt), 0, "", tt.url, nil)
+				switch {
+				case tt.dbInsert.err != nil:
+					insertExpectExec.WillReturnError(tt.dbInsert.err)
+					mock.ExpectRollback()
+				case tt.noInsert:
+					insertExpectExec.WillReturnResult(pgxmock.NewResult("INSERT", 0))
+					mock.ExpectRollback()
+				default:
+					insertExpectExec.WillReturnResult(pgxmock.NewResult("INSERT", 1))
+					if len(tt.tags) > 0 {
+						mock.ExpectExec("INSERT INTO url_tags").
+							WithArgs(pgxmock.AnyArg(), tt.tags).
+							WillReturnResult(pgxmock.NewResult("INSERT", int64(len(tt.tags))))
+					}
+					mock.ExpectCommit()
+				}
 			}
 
 			if tt.dbSelect != nil {
@@ -204,7 +445,9 @@ func TestPgSaveURL(t *testing.T) {
 				}
 			}
 
-			urlID, storeErr := urlPgStore.SaveURL(context.TODO(), tt.url, tt.userID)
+			urlID, storeErr := urlPgStore.SaveURL(
+				context.TODO(), ShortenURL{Original: tt.url, Shorten: tt.alias, ExpiresAt: tt.expiresAt, Tags: tt.tags}, tt.userID,
+			)
 			if tt.want.err != nil {
 				assert.Equal(t, tt.want.err, storeErr)
 			} else {
@@ -213,6 +456,7 @@ func TestPgSaveURL(t *testing.T) {
 			if tt.want.urlID != "" {
 				assert.Equal(t, tt.want.urlID, urlID)
 			}
+			require.NoError(t, mock.ExpectationsWereMet())
 		})
 	}
 }
@@ -293,23 +537,50 @@ func TestPgSaveBatchURL(t *testing.T) {
 	}
 	defer mock.Close()
 
+	aliasReg, err := compileAliasPattern("")
+	require.NoError(t, err)
 	urlPgStore := &URLPgStore{
-		pool: mock,
+		pool:     mock,
+		aliasReg: aliasReg,
+		idGen:    NewRandomIDGenerator(IDFormat{}),
 	}
 
 	tests := []struct {
-		name   string
-		dbErr  error
-		resErr error
+		name     string
+		alias    string
+		noInsert bool
+		collided bool
+		dbErr    error
+		resErr   error
 	}{
 		{
 			name: "Успешное сохранение",
 		},
+		{
+			name:  "Успешное сохранение с алиасом",
+			alias: "my-alias",
+		},
+		{
+			name:     "Сгенерированная ссылка уже занята (повторная генерация)",
+			noInsert: true,
+			collided: true,
+		},
 		{
 			name:   "Ссылка уже была сохранена",
 			dbErr:  &pgconn.PgError{Code: pgerrcode.UniqueViolation},
 			resErr: ErrConflict,
 		},
+		{
+			name:     "Алиас уже занят",
+			alias:    "my-alias",
+			noInsert: true,
+			resErr:   ErrAliasConflict,
+		},
+		{
+			name:   "Некорректный алиас",
+			alias:  "!a",
+			resErr: ErrInvalidAlias,
+		},
 		{
 			name:   "Ошибка БД",
 			dbErr:  errors.New("db error"),
@@ -319,21 +590,42 @@ func TestPgSaveBatchURL(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			mockExpectBatch := mock.ExpectBatch().
-				ExpectExec("INSERT INTO shorten_urls").
-				WithArgs("some", pgxmock.AnyArg(), 1)
-			if tt.dbErr != nil {
-				mockExpectBatch.WillReturnError(tt.dbErr)
-			} else {
-				mockExpectBatch.WillReturnResult(pgxmock.NewResult("INSERT", 1))
+			if !errors.Is(tt.resErr, ErrInvalidAlias) {
+				mock.ExpectBegin()
+				mockExpectBatch := mock.ExpectBatch().
+					ExpectExec("INSERT INTO shorten_urls").
+					WithArgs("some", pgxmock.AnyArg(), 1, nil, 0, "", "some", nil)
+				switch {
+				case tt.dbErr != nil:
+					mockExpectBatch.WillReturnError(tt.dbErr)
+				case tt.noInsert:
+					mockExpectBatch.WillReturnResult(pgxmock.NewResult("INSERT", 0))
+				default:
+					mockExpectBatch.WillReturnResult(pgxmock.NewResult("INSERT", 1))
+				}
+				if tt.collided {
+					mock.ExpectExec("INSERT INTO shorten_urls").
+						WithArgs("some", pgxmock.AnyArg(), 1, nil, 0, "", "some", nil).
+						WillReturnResult(pgxmock.NewResult("INSERT", 1))
+				}
+				if tt.resErr != nil {
+					mock.ExpectRollback()
+				} else {
+					mock.ExpectCommit()
+				}
 			}
 
-			storeErr := urlPgStore.SaveBatchURL(context.TODO(), []ShortenURL{{Original: "some"}}, 1)
+			urls := []ShortenURL{{Original: "some", Shorten: tt.alias}}
+			storeErr := urlPgStore.SaveBatchURL(context.TODO(), urls, 1)
 			if tt.resErr != nil {
 				assert.EqualError(t, tt.resErr, storeErr.Error())
 			} else {
 				require.NoError(t, storeErr)
+				if tt.alias != "" {
+					assert.Equal(t, tt.alias, urls[0].Shorten)
+				}
 			}
+			require.NoError(t, mock.ExpectationsWereMet())
 		})
 	}
 }
@@ -438,6 +730,7 @@ func TestPgGetUserURLs(t *testing.T) {
 	urlPgStore := &URLPgStore{
 		pool: mock,
 	}
+	expiresAt := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
 
 	type want struct {
 		urls []ShortenURL
@@ -452,6 +745,7 @@ func TestPgGetUserURLs(t *testing.T) {
 	tests := []struct {
 		name   string
 		userID int
+		filter UserURLsFilter
 		dbRes  *dbRes
 		want   want
 	}{
@@ -459,12 +753,52 @@ func TestPgGetUserURLs(t *testing.T) {
 			name:   "Успешное чтение данных",
 			userID: 1,
 			dbRes: &dbRes{
-				rows: [][]any{{"origin1", "shorten1"}, {"origin2", "shorten2"}},
+				rows: [][]any{
+					{"origin1", "shorten1", nil, []string{}},
+					{"origin2", "shorten2", &expiresAt, []string{"news", "sale"}},
+				},
+			},
+			want: want{
+				urls: []ShortenURL{
+					{Original: "origin1", Shorten: "shorten1", Tags: []string{}},
+					{Original: "origin2", Shorten: "shorten2", ExpiresAt: expiresAt, Tags: []string{"news", "sale"}},
+				},
+			},
+		},
+		{
+			name:   "Фильтр по тегам",
+			userID: 1,
+			filter: UserURLsFilter{Tags: []string{"news", "sale"}},
+			dbRes: &dbRes{
+				rows: [][]any{{"origin2", "shorten2", nil, []string{"news", "sale", "spring"}}},
+			},
+			want: want{
+				urls: []ShortenURL{
+					{Original: "origin2", Shorten: "shorten2", Tags: []string{"news", "sale", "spring"}},
+				},
+			},
+		},
+		{
+			name:   "Поиск по подстроке и домену",
+			userID: 1,
+			filter: UserURLsFilter{Query: "50%_off", Domain: "shop.ru"},
+			dbRes: &dbRes{
+				rows: [][]any{{"http://shop.ru/50%_off", "shorten3", nil, []string{}}},
+			},
+			want: want{
+				urls: []ShortenURL{{Original: "http://shop.ru/50%_off", Shorten: "shorten3", Tags: []string{}}},
+			},
+		},
+		{
+			name:   "Страница ссылок",
+			userID: 1,
+			filter: UserURLsFilter{Tags: []string{"news"}, After: "shorten1", Limit: 2},
+			dbRes: &dbRes{
+				rows: [][]any{{"origin2", "shorten2", nil, []string{"news"}}},
 			},
 			want: want{
 				urls: []ShortenURL{
-					{Original: "origin1", Shorten: "shorten1"},
-					{Original: "origin2", Shorten: "shorten2"},
+					{Original: "origin2", Shorten: "shorten2", Tags: []string{"news"}},
 				},
 			},
 		},
@@ -490,17 +824,34 @@ func TestPgGetUserURLs(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			if tt.dbRes != nil {
-				mockExpectQuery := mock.ExpectQuery("SELECT .+ FROM shorten_urls WHERE .+").
-					WithArgs(tt.userID)
+				args := []any{tt.userID}
+				if len(tt.filter.Tags) > 0 {
+					args = append(args, tt.filter.Tags, len(tt.filter.Tags))
+				}
+				if tt.filter.Query != "" {
+					args = append(args, "%"+escapeLike(tt.filter.Query)+"%")
+				}
+				if tt.filter.Domain != "" {
+					args = append(args, tt.filter.Domain)
+				}
+				if tt.filter.After != "" {
+					args = append(args, tt.filter.After)
+				}
+				if tt.filter.Limit > 0 {
+					args = append(args, tt.filter.Limit)
+				}
+				// Страницы упорядочены побайтово, как в хранилище в памяти
+				mockExpectQuery := mock.ExpectQuery(`SELECT .+ FROM shorten_urls u LEFT JOIN url_tags .+ ORDER BY u.shorten COLLATE "C"`).
+					WithArgs(args...)
 				if tt.dbRes.err != nil {
 					mockExpectQuery.WillReturnError(tt.dbRes.err)
 				} else {
-					mockExpectQuery.WillReturnRows(mock.NewRows([]string{"original", "shorten"}).
+					mockExpectQuery.WillReturnRows(mock.NewRows([]string{"original", "shorten", "expires_at", "tags"}).
 						AddRows(tt.dbRes.rows...))
 				}
 			}
 
-			urls, storeErr := urlPgStore.GetUserURLs(context.TODO(), tt.userID)
+			urls, storeErr := urlPgStore.GetUserURLs(context.TODO(), tt.userID, tt.filter)
 			if tt.want.err != nil {
 				assert.EqualError(t, tt.want.err, storeErr.Error())
 			} else {
@@ -512,12 +863,12 @@ func TestPgGetUserURLs(t *testing.T) {
 }
 
 func TestPgIsValidID(t *testing.T) {
-	urlPgStore := &URLPgStore{}
-
 	tests := []struct {
-		name    string
-		id      string
-		isValid bool
+		name         string
+		id           string
+		aliasPattern string
+		idFormat     IDFormat
+		isValid      bool
 	}{
 		{
 			name:    "Валидный ID",
@@ -534,10 +885,40 @@ func TestPgIsValidID(t *testing.T) {
 			id:      "!,abcd56",
 			isValid: false,
 		},
+		{
+			name:         "Валидный алиас",
+			id:           "promo-spring",
+			aliasPattern: `^promo-[a-z]+$`,
+			isValid:      true,
+		},
+		{
+			name:         "Алиас не соответствует шаблону",
+			id:           "spring-sale",
+			aliasPattern: `^promo-[a-z]+$`,
+			isValid:      false,
+		},
+		{
+			name:     "ID прежнего формата",
+			id:       "abcd5678",
+			idFormat: IDFormat{Length: 6, Alphabet: "abcdefghjkmnpqrstuvwxyz", LegacyLengths: []int{8}},
+			isValid:  true,
+		},
+		{
+			name:     "Символы вне нового алфавита",
+			id:       "abcd56",
+			idFormat: IDFormat{Length: 6, Alphabet: "abcdefghjkmnpqrstuvwxyz", LegacyLengths: []int{8}},
+			isValid:  false,
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
+			urlPgStore := &URLPgStore{idFormat: tt.idFormat}
+			if tt.aliasPattern != "" {
+				aliasReg, err := compileAliasPattern(tt.aliasPattern)
+				require.NoError(t, err)
+				urlPgStore.aliasReg = aliasReg
+			}
 			isValid := urlPgStore.IsValidID(tt.id)
 			assert.Equal(t, tt.isValid, isValid)
 		})
@@ -564,20 +945,25 @@ func TestPgPing(t *testing.T) {
 	require.NoError(t, storeErr)
 }
 
-func TestPgInitSchema(t *testing.T) {
+func TestPgMarkExpiredURLs(t *testing.T) {
 	mock, err := pgxmock.NewPool()
 	if err != nil {
 		t.Fatal(err)
 	}
 	defer mock.Close()
 
-	mock.ExpectBegin()
-	mock.ExpectExec("CREATE TABLE IF NOT EXISTS users").WillReturnResult(pgxmock.NewResult("CREATE TABLE", 0))
-	mock.ExpectExec("CREATE TABLE IF NOT EXISTS shorten_urls").WillReturnResult(pgxmock.NewResult("CREATE TABLE", 0))
-	mock.ExpectCommit()
+	urlPgStore := &URLPgStore{
+		pool: mock,
+	}
 
-	err = initSchema(context.TODO(), mock)
-	require.NoError(t, err)
+	mock.ExpectExec("UPDATE shorten_urls SET is_expired = TRUE").
+		WillReturnResult(pgxmock.NewResult("UPDATE", 2))
+	require.NoError(t, urlPgStore.markExpiredURLs(context.TODO()))
+
+	mock.ExpectExec("UPDATE shorten_urls SET is_expired = TRUE").
+		WillReturnError(errors.New("db error"))
+	assert.EqualError(t, urlPgStore.markExpiredURLs(context.TODO()), "failed to mark expired urls: db error")
+	require.NoError(t, mock.ExpectationsWereMet())
 }
 
 func TestPgExecuteDelBatch(t *testing.T) {
@@ -606,6 +992,477 @@ func TestPgExecuteDelBatch(t *testing.T) {
 	urlPgStore.executeDelBatch(context.TODO(), delBatch)
 }
 
+func TestPgSaveClicks(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+
+	urlPgStore := &URLPgStore{
+		pool: mock,
+	}
+
+	clickTime := time.Now()
+	batch := mock.ExpectBatch()
+	batch.ExpectExec("INSERT INTO clicks").
+		WithArgs("short1", clickTime, "http://ref.ru", "agent", "127.0.0.1").
+		WillReturnResult(pgxmock.NewResult("INSERT", 1))
+	batch.ExpectExec("INSERT INTO clicks").
+		WithArgs("short2", clickTime, "", "", "").
+		WillReturnResult(pgxmock.NewResult("INSERT", 1))
+
+	err = urlPgStore.saveClicks(context.TODO(), []ClickEvent{
+		{ShortURL: "short1", Time: clickTime, Referrer: "http://ref.ru", UserAgent: "agent", IP: "127.0.0.1"},
+		{ShortURL: "short2", Time: clickTime},
+	})
+	require.NoError(t, err)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPgGetURLStats(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+
+	urlPgStore := &URLPgStore{
+		pool: mock,
+	}
+
+	mock.ExpectQuery("SELECT count\\(c.id\\), count\\(DISTINCT c.ip\\) FROM shorten_urls").
+		WithArgs("short", 1).
+		WillReturnRows(mock.NewRows([]string{"count", "count"}).AddRow(3, 2))
+	mock.ExpectQuery("SELECT to_char").
+		WithArgs("short").
+		WillReturnRows(mock.NewRows([]string{"day", "count"}).AddRow("2024-05-01", 2).AddRow("2024-05-02", 1))
+	mock.ExpectQuery("SELECT referrer").
+		WithArgs("short", urlStatsTopSize).
+		WillReturnRows(mock.NewRows([]string{"referrer", "cnt"}).AddRow("http://ref.ru", 2))
+	mock.ExpectQuery("SELECT user_agent").
+		WithArgs("short", urlStatsTopSize).
+		WillReturnRows(mock.NewRows([]string{"user_agent", "cnt"}))
+
+	stats, err := urlPgStore.GetURLStats(context.TODO(), 1, "short")
+	require.NoError(t, err)
+	assert.Equal(t, &URLStats{
+		TotalClicks:    3,
+		UniqueVisitors: 2,
+		ClicksPerDay:   []DayClicks{{Day: "2024-05-01", Clicks: 2}, {Day: "2024-05-02", Clicks: 1}},
+		TopReferrers:   []TopValue{{Value: "http://ref.ru", Count: 2}},
+		TopUserAgents:  []TopValue{},
+	}, stats)
+
+	// Ссылка не принадлежит пользователю
+	mock.ExpectQuery("SELECT count\\(c.id\\), count\\(DISTINCT c.ip\\) FROM shorten_urls").
+		WithArgs("short", 2).
+		WillReturnError(pgx.ErrNoRows)
+	_, err = urlPgStore.GetURLStats(context.TODO(), 2, "short")
+	assert.ErrorIs(t, err, ErrNoData)
+
+	mock.ExpectQuery("SELECT count\\(c.id\\), count\\(DISTINCT c.ip\\) FROM shorten_urls").
+		WithArgs("short", 1).
+		WillReturnError(errors.New("db error"))
+	_, err = urlPgStore.GetURLStats(context.TODO(), 1, "short")
+	assert.EqualError(t, err, "failed to select url stats from db: db error")
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPgSyncUniqueKeys(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+
+	mock.ExpectExec("UPDATE shorten_urls SET unique_key = COALESCE\\(user_id, 0\\)").
+		WillReturnResult(pgxmock.NewResult("UPDATE", 3))
+	require.NoError(t, syncUniqueKeys(context.TODO(), mock, UniquePerUser))
+
+	// Сохраненные ссылки нарушают уникальность в новой области
+	mock.ExpectExec("UPDATE shorten_urls SET unique_key = COALESCE\\(canonical, original\\)").
+		WillReturnError(&pgconn.PgError{Code: pgerrcode.UniqueViolation})
+	assert.ErrorIs(t, syncUniqueKeys(context.TODO(), mock, UniqueGlobal), ErrConflict)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPgUpdateURL(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+
+	urlPgStore := &URLPgStore{
+		pool: mock,
+	}
+	tags := []string{"news", "sale"}
+
+	tests := []struct {
+		name    string
+		update  URLUpdate
+		dbErr   error
+		want    *ShortenURL
+		wantErr error
+	}{
+		{
+			name:   "Изменение полной ссылки",
+			update: URLUpdate{Original: "http://New.ru", Canonical: "http://new.ru/"},
+			want:   &ShortenURL{Original: "http://New.ru", Shorten: "short", Tags: []string{"old"}},
+		},
+		{
+			name:   "Изменение только тегов",
+			update: URLUpdate{Tags: tags, UpdateTags: true},
+			want:   &ShortenURL{Original: "http://old.ru", Shorten: "short", Tags: tags},
+		},
+		{
+			name:   "Изменение полной ссылки и тегов",
+			update: URLUpdate{Original: "http://New.ru", Canonical: "http://new.ru/", Tags: tags, UpdateTags: true},
+			want:   &ShortenURL{Original: "http://New.ru", Shorten: "short", Tags: tags},
+		},
+		{
+			name:    "Ссылка не принадлежит пользователю",
+			update:  URLUpdate{Tags: tags, UpdateTags: true},
+			dbErr:   pgx.ErrNoRows,
+			wantErr: ErrNoData,
+		},
+		{
+			name:    "Такая ссылка уже сохранена",
+			update:  URLUpdate{Original: "http://New.ru", Canonical: "http://new.ru/", Tags: tags, UpdateTags: true},
+			dbErr:   &pgconn.PgError{Code: pgerrcode.UniqueViolation},
+			wantErr: ErrConflict,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock.ExpectBegin()
+			var query *pgxmock.ExpectedQuery
+			if tt.update.Original != "" {
+				query = mock.ExpectQuery("UPDATE shorten_urls SET original .+ RETURNING original").
+					WithArgs("http://New.ru", "short", 1, "http://new.ru/", "http://new.ru/")
+			} else {
+				query = mock.ExpectQuery("SELECT original, .+ FROM shorten_urls WHERE .+ FOR UPDATE").
+					WithArgs("short", 1)
+			}
+			if tt.dbErr != nil {
+				query.WillReturnError(tt.dbErr)
+				mock.ExpectRollback()
+			} else {
+				original := "http://old.ru"
+				if tt.update.Original != "" {
+					original = tt.update.Original
+				}
+				query.WillReturnRows(mock.NewRows([]string{"original", "tags"}).AddRow(original, []string{"old"}))
+				if tt.update.UpdateTags {
+					mock.ExpectExec("DELETE FROM url_tags WHERE shorten").
+						WithArgs("short").
+						WillReturnResult(pgxmock.NewResult("DELETE", 1))
+					mock.ExpectExec("INSERT INTO url_tags").
+						WithArgs("short", tags).
+						WillReturnResult(pgxmock.NewResult("INSERT", 2))
+				}
+				mock.ExpectCommit()
+			}
+			url, err := urlPgStore.UpdateURL(context.TODO(), 1, "short", tt.update)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, tt.want, url)
+			}
+			require.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestPgExportUserURLs(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+
+	urlPgStore := &URLPgStore{
+		pool: mock,
+	}
+
+	mock.ExpectQuery("SELECT .+ FROM shorten_urls u LEFT JOIN url_tags .+ ORDER BY u.shorten").
+		WithArgs(1).
+		WillReturnRows(mock.NewRows([]string{"original", "shorten", "expires_at", "tags"}).
+			AddRow("http://one.ru", "short1", nil, []string{"news"}).
+			AddRow("http://two.ru", "short2", nil, []string{}))
+	var urls []ShortenURL
+	err = urlPgStore.ExportUserURLs(context.TODO(), 1, func(url ShortenURL) error {
+		urls = append(urls, url)
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []ShortenURL{
+		{Original: "http://one.ru", Shorten: "short1", Tags: []string{"news"}},
+		{Original: "http://two.ru", Shorten: "short2", Tags: []string{}},
+	}, urls)
+
+	// Ошибка обработки ссылки прерывает выгрузку
+	fnErr := errors.New("write error")
+	mock.ExpectQuery("SELECT .+ FROM shorten_urls u LEFT JOIN url_tags .+").
+		WithArgs(1).
+		WillReturnRows(mock.NewRows([]string{"original", "shorten", "expires_at", "tags"}).
+			AddRow("http://one.ru", "short1", nil, []string{}).
+			AddRow("http://two.ru", "short2", nil, []string{}))
+	err = urlPgStore.ExportUserURLs(context.TODO(), 1, func(_ ShortenURL) error {
+		return fnErr
+	})
+	assert.ErrorIs(t, err, fnErr)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPgDumpURLs(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+
+	urlPgStore := &URLPgStore{
+		pool: mock,
+	}
+	deletedAt := time.Now().Add(-time.Hour)
+
+	mock.ExpectQuery("SELECT .+ FROM shorten_urls u ORDER BY u.shorten").
+		WillReturnRows(mock.NewRows([]string{
+			"original", "canonical", "shorten", "user_id", "is_deleted", "deleted_at",
+			"expires_at", "is_expired", "max_clicks", "clicks", "password_hash", "tags",
+		}).
+			AddRow("http://One.ru", "http://one.ru/", "short1", 1, false, nil, nil, false, 5, 2, "", []string{"news"}).
+			AddRow("http://two.ru", "", "short2", 0, true, &deletedAt, nil, false, 0, 0, "", []string{}))
+	var urls []URLRecord
+	err = urlPgStore.DumpURLs(context.TODO(), func(url URLRecord) error {
+		urls = append(urls, url)
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []URLRecord{
+		{
+			ShortenURL: ShortenURL{
+				Original:  "http://One.ru",
+				Canonical: "http://one.ru/",
+				Shorten:   "short1",
+				MaxClicks: 5,
+				Tags:      []string{"news"},
+			},
+			UserID: 1,
+			Clicks: 2,
+		},
+		{
+			ShortenURL: ShortenURL{Original: "http://two.ru", Shorten: "short2", Tags: []string{}},
+			IsDeleted:  true,
+			DeletedAt:  deletedAt,
+		},
+	}, urls)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPgLoadUsers(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+
+	urlPgStore := &URLPgStore{
+		pool: mock,
+	}
+
+	mock.ExpectExec("INSERT INTO users \\(id\\) .+ ON CONFLICT DO NOTHING").
+		WithArgs([]int{3, 7}).
+		WillReturnResult(pgxmock.NewResult("INSERT", 2))
+	mock.ExpectExec("SELECT setval").
+		WillReturnResult(pgxmock.NewResult("SELECT", 1))
+	require.NoError(t, urlPgStore.LoadUsers(context.TODO(), []User{{ID: 3}, {ID: 7}}))
+
+	assert.Error(t, urlPgStore.LoadUsers(context.TODO(), []User{{ID: 0}}))
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPgLoadURLs(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+
+	urlPgStore := &URLPgStore{
+		pool: mock,
+	}
+	urls := []URLRecord{
+		{ShortenURL: ShortenURL{Original: "http://one.ru", Shorten: "short1", Tags: []string{"news"}}, UserID: 1},
+		{ShortenURL: ShortenURL{Original: "http://two.ru", Shorten: "short2"}},
+	}
+
+	existingColumns := []string{"shorten", "original", "user_id"}
+	// Уже перенесенная ссылка пропускается
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT shorten, original, .+ FROM shorten_urls WHERE shorten = ANY").
+		WithArgs([]string{"short1", "short2"}).
+		WillReturnRows(mock.NewRows(existingColumns).AddRow("short2", "http://two.ru", 0))
+	mock.ExpectExec("INSERT INTO shorten_urls").
+		WithArgs("http://one.ru", "short1", 1, false, nil, nil, false, 0, 0, "", "http://one.ru", nil).
+		WillReturnResult(pgxmock.NewResult("INSERT", 1))
+	mock.ExpectExec("INSERT INTO url_tags").
+		WithArgs("short1", []string{"news"}).
+		WillReturnResult(pgxmock.NewResult("INSERT", 1))
+	mock.ExpectCommit()
+	loaded, err := urlPgStore.LoadURLs(context.TODO(), urls)
+	require.NoError(t, err)
+	assert.Equal(t, 1, loaded)
+
+	// Сокращенная ссылка занята другой ссылкой
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT shorten, original, .+ FROM shorten_urls WHERE shorten = ANY").
+		WithArgs([]string{"short1", "short2"}).
+		WillReturnRows(mock.NewRows(existingColumns).AddRow("short1", "http://other.ru", 1))
+	mock.ExpectRollback()
+	_, err = urlPgStore.LoadURLs(context.TODO(), urls)
+	assert.ErrorIs(t, err, ErrConflict)
+
+	// Полная ссылка уже сохранена под другой сокращенной ссылкой
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT shorten, original, .+ FROM shorten_urls WHERE shorten = ANY").
+		WithArgs([]string{"short1", "short2"}).
+		WillReturnRows(mock.NewRows(existingColumns).AddRow("short1", "http://one.ru", 1))
+	mock.ExpectExec("INSERT INTO shorten_urls").
+		WithArgs("http://two.ru", "short2", nil, false, nil, nil, false, 0, 0, "", "http://two.ru", nil).
+		WillReturnError(&pgconn.PgError{Code: pgerrcode.UniqueViolation})
+	mock.ExpectRollback()
+	_, err = urlPgStore.LoadURLs(context.TODO(), urls)
+	assert.ErrorIs(t, err, ErrConflict)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPgGetDeletedUserURLs(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+
+	urlPgStore := &URLPgStore{
+		pool: mock,
+	}
+
+	expiresAt := time.Now().Add(time.Hour)
+	mock.ExpectQuery("SELECT original, shorten, expires_at FROM shorten_urls WHERE is_deleted = TRUE").
+		WithArgs(1).
+		WillReturnRows(mock.NewRows([]string{"original", "shorten", "expires_at"}).
+			AddRow("http://one.ru", "short1", nil).
+			AddRow("http://two.ru", "short2", &expiresAt))
+	urls, err := urlPgStore.GetDeletedUserURLs(context.TODO(), 1)
+	require.NoError(t, err)
+	assert.Equal(t, []ShortenURL{
+		{Original: "http://one.ru", Shorten: "short1"},
+		{Original: "http://two.ru", Shorten: "short2", ExpiresAt: expiresAt},
+	}, urls)
+
+	mock.ExpectQuery("SELECT original, shorten, expires_at FROM shorten_urls WHERE is_deleted = TRUE").
+		WithArgs(1).
+		WillReturnError(errors.New("db error"))
+	_, err = urlPgStore.GetDeletedUserURLs(context.TODO(), 1)
+	require.Error(t, err)
+
+	_, err = urlPgStore.GetDeletedUserURLs(context.TODO(), 0)
+	require.Error(t, err)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPgRestoreUserURLs(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+
+	urlPgStore := &URLPgStore{
+		pool: mock,
+	}
+
+	mock.ExpectExec("UPDATE shorten_urls SET is_deleted = FALSE").
+		WithArgs([]string{"short1", "short2"}, 1).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 2))
+	require.NoError(t, urlPgStore.RestoreUserURLs(context.TODO(), 1, []string{"short1", "short2"}))
+
+	mock.ExpectExec("UPDATE shorten_urls SET is_deleted = FALSE").
+		WithArgs([]string{"short1"}, 1).
+		WillReturnError(errors.New("db error"))
+	assert.EqualError(t, urlPgStore.RestoreUserURLs(context.TODO(), 1, []string{"short1"}),
+		"failed to restore user urls in db: db error")
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPgPurgeDeletedURLs(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+
+	urlPgStore := &URLPgStore{
+		pool: mock,
+	}
+	deletedBefore := time.Now().Add(-time.Hour)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("WITH purged AS").
+		WithArgs(deletedBefore).
+		WillReturnRows(mock.NewRows([]string{"count"}).AddRow(3))
+	mock.ExpectExec("INSERT INTO store_counters").
+		WithArgs(purgedURLsCounter, 3).
+		WillReturnResult(pgxmock.NewResult("INSERT", 1))
+	mock.ExpectCommit()
+	count, err := urlPgStore.purgeDeletedURLs(context.TODO(), deletedBefore)
+	require.NoError(t, err)
+	assert.Equal(t, 3, count)
+
+	// Без удаленных ссылок счетчик не изменяется
+	mock.ExpectBegin()
+	mock.ExpectQuery("WITH purged AS").
+		WithArgs(deletedBefore).
+		WillReturnRows(mock.NewRows([]string{"count"}).AddRow(0))
+	mock.ExpectRollback()
+	count, err = urlPgStore.purgeDeletedURLs(context.TODO(), deletedBefore)
+	require.NoError(t, err)
+	assert.Equal(t, 0, count)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("WITH purged AS").
+		WithArgs(deletedBefore).
+		WillReturnError(errors.New("db error"))
+	mock.ExpectRollback()
+	_, err = urlPgStore.purgeDeletedURLs(context.TODO(), deletedBefore)
+	assert.EqualError(t, err, "failed to purge deleted urls: db error")
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPgGetPurgedURLsCount(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mock.Close()
+
+	urlPgStore := &URLPgStore{
+		pool: mock,
+	}
+
+	mock.ExpectQuery("SELECT COALESCE").
+		WithArgs(purgedURLsCounter).
+		WillReturnRows(mock.NewRows([]string{"count"}).AddRow(7))
+	count, err := urlPgStore.GetPurgedURLsCount(context.TODO())
+	require.NoError(t, err)
+	assert.Equal(t, 7, count)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
 func TestPgGetUsersCount(t *testing.T) {
 	mock, err := pgxmock.NewPool()
 	if err != nil {
@@ -722,7 +1579,7 @@ func TestPgGetURLsCount(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			if tt.dbRes != nil {
-				mockExpectQuery := mock.ExpectQuery("SELECT .+ FROM shorten_urls WHERE is_deleted = false;")
+				mockExpectQuery := mock.ExpectQuery("SELECT .+ FROM shorten_urls WHERE is_deleted = false AND is_expired = false;")
 				if tt.dbRes.err != nil {
 					mockExpectQuery.WillReturnError(tt.dbRes.err)
 				} else if tt.dbRes.rows != nil {
@@ -741,3 +1598,42 @@ func TestPgGetURLsCount(t *testing.T) {
 		})
 	}
 }
+
+// benchURLsCount - количество ссылок в таблице при замере времени перехода по ссылке.
+const benchURLsCount = 100_000
+
+// BenchmarkPgGetURL замеряет время перехода по ссылке на большой таблице (нужна тестовая БД, см. testDatabaseDSNEnv).
+// Хранилище при открытии применяет все миграции, поэтому для сравнения с поиском без первичного ключа замер
+// запускается на версии приложения до миграции 0008_shorten_primary_key.
+func BenchmarkPgGetURL(b *testing.B) {
+	dsn := os.Getenv(testDatabaseDSNEnv)
+	if dsn == "" {
+		b.Skipf("%s is not set", testDatabaseDSNEnv)
+	}
+	store, err := NewURLPgStore(PgConfig{DSN: dsn})
+	require.NoError(b, err)
+	defer store.Close()
+
+	ctx := context.Background()
+	prefix := fmt.Sprintf("bench%d-", time.Now().UnixNano())
+	_, err = store.pool.Exec(ctx,
+		`INSERT INTO shorten_urls (original, shorten)
+			SELECT 'https://bench.example/' || $1::text || g, $1::text || g FROM generate_series(1, $2::int) g`,
+		prefix, benchURLsCount,
+	)
+	require.NoError(b, err)
+	defer func() {
+		_, err = store.pool.Exec(ctx, `DELETE FROM shorten_urls WHERE shorten LIKE $1`, prefix+"%")
+		require.NoError(b, err)
+	}()
+	_, err = store.pool.Exec(ctx, `ANALYZE shorten_urls`)
+	require.NoError(b, err)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		id := prefix + strconv.Itoa(i%benchURLsCount+1)
+		if _, _, err = store.GetURL(ctx, id, ""); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
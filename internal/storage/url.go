@@ -3,10 +3,17 @@ package storage
 import (
 	"context"
 	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
 )
 
-// Длина сокращенной ссылки.
-const urlIDLength = 8
+// DefaultAliasPattern - шаблон пользовательских сокращенных ссылок (алиасов), используемый по умолчанию.
+const DefaultAliasPattern = `^[a-zA-Z0-9_-]{3,64}$`
 
 // ErrConflict - ошибка, указывающая на конфликт данных в хранилище.
 var ErrConflict = errors.New("data conflict")
@@ -17,41 +24,150 @@ var ErrNoData = errors.New("no data")
 // ErrIsDeleted - ошибка, указывающая на то, что ссылка была удалена.
 var ErrIsDeleted = errors.New("deleted")
 
+// ErrExpired - ошибка, указывающая на то, что истек срок действия ссылки.
+var ErrExpired = errors.New("expired")
+
+// ErrClickLimit - ошибка, указывающая на то, что исчерпан лимит переходов по ссылке.
+var ErrClickLimit = errors.New("click limit reached")
+
+// ErrPasswordRequired - ошибка, указывающая на то, что для перехода по ссылке требуется пароль.
+var ErrPasswordRequired = errors.New("password required")
+
+// ErrWrongPassword - ошибка, указывающая на то, что передан неверный пароль ссылки.
+var ErrWrongPassword = errors.New("wrong password")
+
+// ErrAliasConflict - ошибка, указывающая на то, что пользовательская сокращенная ссылка уже занята.
+var ErrAliasConflict = errors.New("alias already taken")
+
+// ErrInvalidAlias - ошибка, указывающая на некорректный формат пользовательской сокращенной ссылки.
+var ErrInvalidAlias = errors.New("invalid alias")
+
 // ErrNotImplemented - ошибка, указывающая на то, что метод не реализован.
 var ErrNotImplemented = errors.New("not implemented")
 
 // URLStorage описывает интерфейс хранилища приложения.
 type URLStorage interface {
-	// Сохранить сокращенную ссылку
-	SaveURL(ctx context.Context, url string, userID int) (id string, err error)
+	// Сохранить сокращенную ссылку (если задан url.Shorten, он используется как алиас).
+	// Если полная ссылка уже сохранена в пределах области уникальности, возвращается ее id и ErrConflict.
+	SaveURL(ctx context.Context, url ShortenURL, userID int) (id string, err error)
 	// Сохранить массив ссылок
 	SaveBatchURL(ctx context.Context, urls []ShortenURL, userID int) error
-	// Получить полную ссылку по сокращенной
-	GetURL(ctx context.Context, id string) (url string, err error)
+	// Получить полную ссылку по сокращенной (учитывается как переход по ссылке) и сокращенную ссылку, под которой
+	// она сохранена (может отличаться от id регистром). password проверяется только для защищенных паролем ссылок.
+	GetURL(ctx context.Context, id string, password string) (url string, shortID string, err error)
 	// Создать нового пользователя
 	CreateUser(ctx context.Context) (*User, error)
 	// Получить данные пользователя по ID
 	GetUser(ctx context.Context, id int) (*User, error)
-	// Получить все сокращенные пользователем ссылки
-	GetUserURLs(ctx context.Context, id int) (urls []ShortenURL, err error)
+	// Получить сокращенные пользователем ссылки, подходящие под фильтр
+	GetUserURLs(ctx context.Context, id int, filter UserURLsFilter) (urls []ShortenURL, err error)
+	// Передать функции fn по очереди все сокращенные пользователем ссылки (без загрузки их в память целиком).
+	// Ошибка fn прерывает выгрузку и возвращается как есть.
+	ExportUserURLs(ctx context.Context, userID int, fn func(url ShortenURL) error) error
+	// Получить удаленные пользователем ссылки
+	GetDeletedUserURLs(ctx context.Context, id int) (urls []ShortenURL, err error)
+	// Восстановить удаленные ссылки пользователя
+	RestoreUserURLs(ctx context.Context, userID int, urls []string) error
+	// Изменить полную ссылку (и ее каноническую форму) и (или) теги сокращенной ссылки пользователя одной операцией.
+	// Возвращает сохраненную ссылку с полной ссылкой и тегами после изменения
+	// (ErrNoData, если ссылка не принадлежит пользователю; ErrConflict, если такая полная ссылка уже сохранена)
+	UpdateURL(ctx context.Context, userID int, id string, update URLUpdate) (*ShortenURL, error)
 	// Удалить сокращенные ссылки пользователя
 	DeleteUserURLs(userID int, urls []string) error
+	// Сохранить событие перехода по ссылке (сохранение происходит асинхронно)
+	SaveClick(event ClickEvent) error
+	// Получить статистику переходов по ссылке пользователя (ErrNoData, если ссылка не принадлежит пользователю)
+	GetURLStats(ctx context.Context, userID int, id string) (*URLStats, error)
 	// Проверить валидность сокращенной ссылки (проверка формата)
 	IsValidID(id string) bool
 	// Проверка связи с БД (для всех остальных хранилищ ничего не делает)
 	Ping(ctx context.Context) error
 	// Получить количество ссылок в хранилище.
 	GetURLsCount(ctx context.Context) (count int, err error)
+	// Получить количество окончательно удаленных (по сроку хранения) ссылок.
+	GetPurgedURLsCount(ctx context.Context) (count int, err error)
 	// Получить количество пользователей в хранилище.
 	GetUsersCount(ctx context.Context) (count int, err error)
+	// Передать функции fn по очереди всех пользователей хранилища (для переноса данных между хранилищами)
+	DumpUsers(ctx context.Context, fn func(user User) error) error
+	// Передать функции fn по очереди все ссылки хранилища, включая удаленные (для переноса данных между хранилищами)
+	DumpURLs(ctx context.Context, fn func(url URLRecord) error) error
+	// Сохранить пользователей с их ID (уже существующие пользователи пропускаются)
+	LoadUsers(ctx context.Context, users []User) error
+	// Сохранить ссылки с их сокращенными ссылками. Уже сохраненные ссылки (та же сокращенная ссылка с той же полной ссылкой
+	// и тем же пользователем) пропускаются. Возвращает количество сохраненных ссылок (ErrConflict, если сокращенная ссылка
+	// занята другой ссылкой или полная ссылка сохранена под другой сокращенной).
+	LoadURLs(ctx context.Context, urls []URLRecord) (loaded int, err error)
 	// Закрыть хранилище (БД или файл)
 	Close() error
 }
 
 // ShortenURL описывает структуру представляющую пару оригинальной и сокращенной ссылок.
+// При сохранении заполненное поле Shorten используется как пользовательский алиас.
 type ShortenURL struct {
-	Original string
-	Shorten  string
+	Original     string
+	Canonical    string // Каноническая форма полной ссылки, по которой проверяется уникальность (пустая строка - Original)
+	Shorten      string
+	ExpiresAt    time.Time // Время истечения срока действия ссылки (нулевое значение - бессрочная ссылка)
+	MaxClicks    int       // Максимальное количество переходов по ссылке (0 - без ограничений)
+	PasswordHash string    // bcrypt хэш пароля ссылки (пустая строка - ссылка без пароля)
+	Tags         []string  // Теги ссылки
+}
+
+// URLUpdate описывает изменение сокращенной ссылки пользователя.
+type URLUpdate struct {
+	Original   string   // Новая полная ссылка (пустая строка - не изменять)
+	Canonical  string   // Каноническая форма новой полной ссылки (пустая строка - совпадает с Original)
+	Tags       []string // Новые теги ссылки (заменяют текущие)
+	UpdateTags bool     // Признак изменения тегов (позволяет удалить все теги пустым Tags)
+}
+
+// canonicalURL возвращает полную ссылку, по которой проверяется уникальность.
+func (url ShortenURL) canonicalURL() string {
+	if url.Canonical != "" {
+		return url.Canonical
+	}
+	return url.Original
+}
+
+// canonicalData возвращает каноническую форму полной ссылки для хранения (пустая строка, если она совпадает с полной ссылкой).
+func canonicalData(original string, canonical string) string {
+	if canonical == original {
+		return ""
+	}
+	return canonical
+}
+
+// URLRecord описывает все данные сокращенной ссылки, переносимые между хранилищами.
+type URLRecord struct {
+	ShortenURL
+	UserID    int       // Пользователь, сохранивший ссылку (0 - неавторизованный пользователь)
+	IsDeleted bool      // Ссылка удалена пользователем
+	DeletedAt time.Time // Время удаления ссылки
+	IsExpired bool      // Ссылка помечена как просроченная
+	Clicks    int       // Количество совершенных переходов
+}
+
+// loadedURL описывает ссылку, уже сохраненную под сокращенной ссылкой загружаемой ссылки.
+// Загружаемая ссылка пропускается, только если совпадают полная ссылка и пользователь.
+type loadedURL struct {
+	original string
+	userID   int
+}
+
+// errShortenTaken возвращает ошибку загрузки ссылки, сокращенная ссылка которой занята другой ссылкой.
+func errShortenTaken(id string) error {
+	return fmt.Errorf("failed to load url %s: short url is taken by another url: %w", id, ErrConflict)
+}
+
+// UserURLsFilter описывает фильтр ссылок пользователя.
+// Ссылки возвращаются упорядоченными по сокращенной ссылке, что позволяет получать их постранично.
+type UserURLsFilter struct {
+	Tags   []string // Теги, которые должны быть у ссылки (все перечисленные)
+	Query  string   // Подстрока полной ссылки (без учета регистра)
+	Domain string   // Хост полной ссылки (без учета регистра)
+	After  string   // Вернуть ссылки, следующие за этой сокращенной ссылкой (пустая строка - с начала)
+	Limit  int      // Максимальное количество ссылок (0 - без ограничений)
 }
 
 // User описывает структуру данных пользователя.
@@ -59,16 +175,158 @@ type User struct {
 	ID int
 }
 
+// UniquenessScope определяет, в каких пределах полная ссылка может быть сокращена только один раз.
+type UniquenessScope string
+
+// Области уникальности полных ссылок.
+const (
+	UniqueGlobal  UniquenessScope = "global" // Одна сокращенная ссылка на полную для всех пользователей (по умолчанию)
+	UniquePerUser UniquenessScope = "user"   // Своя сокращенная ссылка на полную у каждого пользователя
+	UniqueNone    UniquenessScope = "none"   // Полную ссылку можно сокращать повторно
+)
+
+// ParseUniquenessScope разбирает область уникальности полных ссылок (пустая строка - UniqueGlobal).
+func ParseUniquenessScope(scope string) (UniquenessScope, error) {
+	switch UniquenessScope(scope) {
+	case "", UniqueGlobal:
+		return UniqueGlobal, nil
+	case UniquePerUser, UniqueNone:
+		return UniquenessScope(scope), nil
+	}
+	return "", fmt.Errorf("unknown url uniqueness scope %q", scope)
+}
+
+// uniqueKey возвращает ключ уникальности ссылки: ссылки с одинаковым ключом не могут быть сохранены дважды
+// (пустая строка - ссылка не проверяется на уникальность). Нулевое значение области соответствует UniqueGlobal.
+// canonical - каноническая форма полной ссылки.
+func (scope UniquenessScope) uniqueKey(canonical string, userID int) string {
+	switch scope {
+	case UniquePerUser:
+		return strconv.Itoa(userID) + ":" + canonical
+	case UniqueNone:
+		return ""
+	}
+	return canonical
+}
+
+// uniqueKeyExpr возвращает SQL выражение, вычисляющее ключ уникальности по столбцам canonical, original и user_id
+// так же, как uniqueKey.
+func (scope UniquenessScope) uniqueKeyExpr() string {
+	switch scope {
+	case UniquePerUser:
+		return `COALESCE(user_id, 0) || ':' || COALESCE(canonical, original)`
+	case UniqueNone:
+		return `NULL`
+	}
+	return `COALESCE(canonical, original)`
+}
+
 // URLStorageConfig описывает структуру конфигурации хранилища приложения.
 type URLStorageConfig struct {
-	StorageFile string
-	DSN         string
+	StorageFile      string
+	DSN              string
+	AliasPattern     string
+	IDGenerator      string
+	IDFormat         IDFormat
+	DeletedRetention time.Duration // Срок хранения удаленных ссылок (0 - хранятся бессрочно)
+	Uniqueness       string        // Область уникальности полных ссылок (global, user, none)
+	FileSync         string        // Политика сброса журнала операций файла на диск (always, interval, never)
+	FileFormat       string        // Формат снимка данных файла (json, binary)
+	ReadOnly         bool          // Открыть хранилище только для чтения (источник переноса данных)
 }
 
 // NewURLStorage создает новое хранилище согласно переданным настройкам.
 func NewURLStorage(cfg URLStorageConfig) (URLStorage, error) {
+	idGen, err := NewIDGenerator(cfg.IDGenerator, cfg.IDFormat)
+	if err != nil {
+		return nil, err
+	}
+	uniqueness, err := ParseUniquenessScope(cfg.Uniqueness)
+	if err != nil {
+		return nil, err
+	}
+	fileSync, err := ParseFileSyncPolicy(cfg.FileSync)
+	if err != nil {
+		return nil, err
+	}
+	fileFormat, err := ParseFileFormat(cfg.FileFormat)
+	if err != nil {
+		return nil, err
+	}
 	if cfg.DSN != "" {
-		return NewURLPgStore(PgConfig{DSN: cfg.DSN})
+		return NewURLPgStore(PgConfig{
+			DSN:              cfg.DSN,
+			AliasPattern:     cfg.AliasPattern,
+			IDGenerator:      idGen,
+			IDFormat:         cfg.IDFormat,
+			DeletedRetention: cfg.DeletedRetention,
+			Uniqueness:       uniqueness,
+			ReadOnly:         cfg.ReadOnly,
+		})
+	}
+	return NewURLMapStore(MapConfig{
+		StorageFile:      cfg.StorageFile,
+		AliasPattern:     cfg.AliasPattern,
+		IDGenerator:      idGen,
+		IDFormat:         cfg.IDFormat,
+		DeletedRetention: cfg.DeletedRetention,
+		Uniqueness:       uniqueness,
+		FileSync:         fileSync,
+		FileFormat:       fileFormat,
+		ReadOnly:         cfg.ReadOnly,
+	})
+}
+
+// expireURLsInterval - интервал (в секундах) между запусками пометки ссылок с истекшим сроком действия.
+const expireURLsInterval = 60
+
+// purgeURLsInterval - интервал (в секундах) между запусками окончательного удаления ссылок с истекшим сроком хранения.
+const purgeURLsInterval = 600
+
+// urlDomainPattern - шаблон выделения хоста из полной ссылки (хост - первая группа).
+// Используется и в хранилище в памяти, и в вычисляемом столбце БД (миграция 0007_url_search),
+// чтобы домен определялся одинаково.
+const urlDomainPattern = `^[a-zA-Z][a-zA-Z0-9+.-]*://(?:[^/?#@]*@)?([^/?#:]+)`
+
+var urlDomainReg = regexp.MustCompile(urlDomainPattern)
+
+// urlDomain возвращает хост полной ссылки в нижнем регистре (пустую строку, если хост не найден).
+func urlDomain(original string) string {
+	match := urlDomainReg.FindStringSubmatch(original)
+	if match == nil {
+		return ""
+	}
+	return strings.ToLower(match[1])
+}
+
+// isExpired проверяет, истек ли к моменту now срок действия ссылки.
+func isExpired(expiresAt, now time.Time) bool {
+	return !expiresAt.IsZero() && !now.Before(expiresAt)
+}
+
+// checkPassword проверяет пароль защищенной ссылки по его bcrypt хэшу.
+func checkPassword(passwordHash, password string) error {
+	if password == "" {
+		return ErrPasswordRequired
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(passwordHash), []byte(password)); err != nil {
+		if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+			return ErrWrongPassword
+		}
+		return err
 	}
-	return NewURLMapStore(cfg.StorageFile)
+	return nil
+}
+
+// compileAliasPattern компилирует шаблон алиасов (если шаблон не задан, используется DefaultAliasPattern).
+func compileAliasPattern(pattern string) (*regexp.Regexp, error) {
+	if pattern == "" {
+		pattern = DefaultAliasPattern
+	}
+	return regexp.Compile(pattern)
+}
+
+// isValidAlias проверяет соответствие алиаса шаблону.
+func isValidAlias(aliasReg *regexp.Regexp, alias string) bool {
+	return aliasReg != nil && aliasReg.MatchString(alias)
 }
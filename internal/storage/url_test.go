@@ -0,0 +1,42 @@
+package storage
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseUniquenessScope(t *testing.T) {
+	tests := []struct {
+		name    string
+		scope   string
+		want    UniquenessScope
+		wantErr bool
+	}{
+		{name: "По умолчанию", scope: "", want: UniqueGlobal},
+		{name: "Глобальная", scope: "global", want: UniqueGlobal},
+		{name: "В пределах пользователя", scope: "user", want: UniquePerUser},
+		{name: "Без уникальности", scope: "none", want: UniqueNone},
+		{name: "Неизвестная область", scope: "tenant", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scope, err := ParseUniquenessScope(tt.scope)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, scope)
+		})
+	}
+}
+
+func TestUniqueKey(t *testing.T) {
+	assert.Equal(t, "http://some.ru", UniqueGlobal.uniqueKey("http://some.ru", 1))
+	assert.Equal(t, "http://some.ru", UniquenessScope("").uniqueKey("http://some.ru", 1))
+	assert.Equal(t, "1:http://some.ru", UniquePerUser.uniqueKey("http://some.ru", 1))
+	assert.Equal(t, "0:http://some.ru", UniquePerUser.uniqueKey("http://some.ru", 0))
+	assert.Empty(t, UniqueNone.uniqueKey("http://some.ru", 1))
+}
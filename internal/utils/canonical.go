@@ -0,0 +1,99 @@
+package utils
+
+import (
+	"net"
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/idna"
+)
+
+// trackingParamPrefix - префикс параметров запроса, используемых для отслеживания переходов (utm метки).
+const trackingParamPrefix = "utm_"
+
+// trackingParams - параметры запроса, используемые для отслеживания переходов (идентификаторы кликов рекламных систем).
+var trackingParams = map[string]struct{}{
+	"fbclid":    {},
+	"gclid":     {},
+	"dclid":     {},
+	"yclid":     {},
+	"msclkid":   {},
+	"mc_cid":    {},
+	"mc_eid":    {},
+	"_openstat": {},
+}
+
+// defaultPorts - порты, используемые схемами по умолчанию.
+var defaultPorts = map[string]string{
+	"http":  "80",
+	"https": "443",
+}
+
+// CanonicalURL приводит ссылку к канонической форме: схема и хост в нижнем регистре, хост в punycode,
+// без порта по умолчанию и фрагмента, параметры запроса отсортированы по имени.
+// Если stripTracking, то также удаляются параметры отслеживания (utm_*, fbclid, gclid и т.п.).
+func CanonicalURL(rawURL string, stripTracking bool) (string, error) {
+	parsedURL, err := url.Parse(rawURL)
+	if err != nil {
+		return "", err
+	}
+	parsedURL.Scheme = strings.ToLower(parsedURL.Scheme)
+
+	host, err := canonicalHost(parsedURL.Hostname())
+	if err != nil {
+		return "", err
+	}
+	port := parsedURL.Port()
+	if port == defaultPorts[parsedURL.Scheme] {
+		port = ""
+	}
+	if port != "" {
+		parsedURL.Host = net.JoinHostPort(host, port)
+	} else if strings.Contains(host, ":") {
+		parsedURL.Host = "[" + host + "]"
+	} else {
+		parsedURL.Host = host
+	}
+
+	if parsedURL.Path == "" && parsedURL.Host != "" {
+		parsedURL.Path = "/"
+	}
+	parsedURL.Fragment = ""
+	parsedURL.RawFragment = ""
+
+	// Некорректный запрос оставляется как есть, чтобы не потерять его часть
+	if query, err := url.ParseQuery(parsedURL.RawQuery); err == nil {
+		if stripTracking {
+			for param := range query {
+				if isTrackingParam(param) {
+					query.Del(param)
+				}
+			}
+		}
+		// Encode сортирует параметры по имени, сохраняя порядок значений одного параметра
+		parsedURL.RawQuery = query.Encode()
+		parsedURL.ForceQuery = false
+	}
+	return parsedURL.String(), nil
+}
+
+// canonicalHost приводит хост к нижнему регистру и переводит интернационализированное имя в punycode.
+func canonicalHost(host string) (string, error) {
+	host = strings.ToLower(host)
+	for i := 0; i < len(host); i++ {
+		if host[i] >= 0x80 {
+			return idna.Lookup.ToASCII(host)
+		}
+	}
+	return host, nil
+}
+
+// isTrackingParam проверяет, используется ли параметр запроса для отслеживания переходов.
+func isTrackingParam(param string) bool {
+	param = strings.ToLower(param)
+	if strings.HasPrefix(param, trackingParamPrefix) {
+		return true
+	}
+	_, ok := trackingParams[param]
+	return ok
+}
@@ -0,0 +1,87 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCanonicalURL(t *testing.T) {
+	tests := []struct {
+		name          string
+		url           string
+		stripTracking bool
+		want          string
+		wantErr       bool
+	}{
+		{
+			name: "Схема, хост и порт по умолчанию",
+			url:  "HTTPS://Example.COM:443/Path?b=1&a=2",
+			want: "https://example.com/Path?a=2&b=1",
+		},
+		{
+			name: "Порт не по умолчанию сохраняется",
+			url:  "http://example.com:8080/a",
+			want: "http://example.com:8080/a",
+		},
+		{
+			name: "Порт http для https сохраняется",
+			url:  "https://example.com:80/a",
+			want: "https://example.com:80/a",
+		},
+		{
+			name: "Фрагмент и пустой путь",
+			url:  "http://example.com#section",
+			want: "http://example.com/",
+		},
+		{
+			name: "Порядок значений одного параметра сохраняется",
+			url:  "http://example.com/?b=2&a=3&b=1",
+			want: "http://example.com/?a=3&b=2&b=1",
+		},
+		{
+			name: "Параметры отслеживания сохраняются без stripTracking",
+			url:  "http://example.com/?utm_source=mail&id=1",
+			want: "http://example.com/?id=1&utm_source=mail",
+		},
+		{
+			name:          "Удаление параметров отслеживания",
+			url:           "http://example.com/?UTM_Source=mail&id=1&fbclid=abc&gclid=def",
+			stripTracking: true,
+			want:          "http://example.com/?id=1",
+		},
+		{
+			name:          "Удаление всех параметров",
+			url:           "http://example.com/a?utm_medium=cpc",
+			stripTracking: true,
+			want:          "http://example.com/a",
+		},
+		{
+			name: "Интернационализированный домен",
+			url:  "http://Пример.РФ/путь",
+			want: "http://xn--e1afmkfd.xn--p1ai/%D0%BF%D1%83%D1%82%D1%8C",
+		},
+		{
+			name: "IPv6 адрес",
+			url:  "http://[::1]:80/",
+			want: "http://[::1]/",
+		},
+		{
+			name:    "Некорректная ссылка",
+			url:     "http://exa mple.com:port/",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := CanonicalURL(tt.url, tt.stripTracking)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
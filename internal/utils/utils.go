@@ -2,9 +2,8 @@
 package utils
 
 import (
-	"math/rand"
+	"math/rand/v2"
 	"net/url"
-	"time"
 )
 
 // Набор символов, из которых формируется случайная строка.
@@ -12,11 +11,9 @@ const chars = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789"
 
 // NewRandomString генерирует случайную строку из символов chars длиной length.
 func NewRandomString(length int) string {
-	rnd := rand.New(rand.NewSource(time.Now().UnixNano()))
-
 	randomBytes := make([]byte, length)
 	for i := range randomBytes {
-		randomBytes[i] = chars[rnd.Intn(len(chars))]
+		randomBytes[i] = chars[rand.IntN(len(chars))]
 	}
 
 	return string(randomBytes)